@@ -4,7 +4,9 @@ import (
 	"context"
 	"crypto/tls"
 	"crypto/x509"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/signal"
 	"path/filepath"
@@ -14,14 +16,23 @@ import (
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/application"
+	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/domain"
+	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/infrastructure/backup"
+	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/infrastructure/breach"
+	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/infrastructure/clipboard"
 	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/infrastructure/config"
+	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/infrastructure/importer"
 	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/infrastructure/persistence"
 	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/interfaces/grpc"
+	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/interfaces/grpc/interceptors"
 	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/presentation/cli"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 )
 
+// sessionExpiryWarning is how long before token expiry the user is warned.
+const sessionExpiryWarning = 10 * time.Minute
+
 func main() {
 	file, err := createLogFile()
 	if err != nil {
@@ -32,10 +43,58 @@ func main() {
 	output := zerolog.ConsoleWriter{Out: file, TimeFormat: time.RFC3339}
 	log.Logger = log.Output(output)
 
+	// Global flags select the profile and have to be known before wiring the services
+	globals, err := cli.ParseGlobalFlags(os.Args[1:])
+	if err != nil {
+		fatal(err, "Failed to parse global flags")
+	}
+
+	// Config commands have to work with a broken configuration to be able to fix it
+	configCmd := globals.Command() == "config"
+
 	// Load configuration
-	conf, err := config.Parse()
+	conf, err := config.Parse(globals.ConfigPath)
+	if err != nil {
+		if !configCmd {
+			fatal(err, "Failed to parse config")
+		}
+		log.Warn().Err(err).Msg("Failed to parse config, using defaults")
+		conf = config.GetDefault()
+	}
+
+	// Resolve the selected profile and apply its connection settings
+	profileRepo, err := persistence.NewProfileRepo()
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to initialize profile repo")
+	}
+	profileService := application.NewProfileService(profileRepo)
+
+	profile, err := profileService.ResolveProfile(context.Background(), globals.Profile)
+	if err != nil {
+		fatal(err, "Failed to resolve profile")
+	}
+
+	var profileName string
+	if profile != nil {
+		conf.ApplyProfile(*profile)
+		profileName = profile.Name
+		log.Info().Str("profile", profileName).Msg("Using profile")
+	}
+
+	// Command line flags take precedence over all other sources
+	conf.ApplyOverrides(config.Overrides{
+		GRPCRunAddr: globals.Server,
+		LogLvl:      globals.LogLevel,
+		GRPCTimeout: globals.Timeout,
+		TLSCertPath: globals.TLSCert,
+	})
+	if err := conf.Validate(); err != nil && !configCmd {
+		fatal(err, "Invalid config")
+	}
+
+	configService, err := config.NewService(globals.ConfigPath, conf)
 	if err != nil {
-		log.Fatal().Err(err).Msg("Failed to parse config")
+		log.Fatal().Err(err).Msg("Failed to initialize config service")
 	}
 
 	// Set up context with graceful shutdown
@@ -56,11 +115,29 @@ func main() {
 	log.Info().Str("level", logLvl.String()).Msg("Logging level configured")
 
 	// Initialize token repository
-	tokenRepo, err := persistence.NewTokenRepo()
+	tokenRepo, err := persistence.NewTokenRepo(profileName)
 	if err != nil {
 		log.Fatal().Err(err).Msg("Failed to initialize token repo")
 	}
 
+	// Initialize key repository and client-side cipher
+	keyRepo, err := persistence.NewKeyRepo()
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to initialize key repo")
+	}
+
+	passwordPrompt := cli.PromptMasterPassword
+	if conf.MasterPassword != "" {
+		passwordPrompt = func(bool) (string, error) { return conf.MasterPassword, nil }
+	}
+	secretCipher := application.NewSecretCipher(keyRepo, passwordPrompt)
+
+	// Initialize local vault for offline access
+	vaultRepo, err := persistence.NewVaultRepo(secretCipher, profileName)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to initialize vault repo")
+	}
+
 	// Create context with timeout for gRPC requests
 	grpcCtx, grpcCancel := context.WithTimeout(ctx, conf.GRPCTimeout)
 	defer grpcCancel()
@@ -90,23 +167,68 @@ func main() {
 	}
 	defer authClient.Close()
 
-	secretClient, err := grpc.NewSecretClient(conf.GRPCRunAddr, tokenRepo, creds)
+	authService := application.NewAuthService(authClient, tokenRepo)
+
+	// Renew the session interactively when the token is missing, expired or rejected
+	authInterceptor := interceptors.NewAuthInterceptor(tokenRepo,
+		interceptors.WithReauth(cli.ReauthPrompt(authService, conf.GRPCTimeout)),
+		interceptors.WithRequestTimeout(conf.GRPCTimeout),
+		interceptors.WithExpiryWarning(sessionExpiryWarning, cli.WarnSessionExpiry(os.Stderr)))
+
+	// Secrets stored before client-side encryption are only accepted on request
+	var clientOpts []grpc.SecretClientOption
+	if conf.AllowPlaintext {
+		clientOpts = append(clientOpts, grpc.WithPlaintextSecrets(cli.WarnPlaintextSecret(os.Stderr)))
+	}
+
+	secretClient, err := grpc.NewSecretClient(conf.GRPCRunAddr, authInterceptor, creds, secretCipher, clientOpts...)
 	if err != nil {
 		log.Fatal().Err(err).Msg("Failed to initialize secret client")
 	}
 	defer secretClient.Close()
 
 	// Initialize services
-	secretService := application.NewSecretService(secretClient)
-	authService := application.NewAuthService(authClient, tokenRepo)
+	secretService := application.NewSecretService(secretClient, vaultRepo)
 
 	// Initialize and run CLI
-	rootCmd := cli.NewCLI(grpcCtx, secretService, authService)
+	rootCmd := cli.NewCLI(grpcCtx, secretService, authService,
+		cli.WithVaultUnlocker(secretCipher.Unlock),
+		cli.WithProfileService(profileService),
+		cli.WithConfigService(configService),
+		cli.WithBreachCorpus(conf.BreachFile, func(path string) (domain.BreachCorpus, error) {
+			return breach.Open(path)
+		}),
+		cli.WithClipboard(conf.Clipboard, conf.ClipboardClearAfter, clipboard.New),
+		cli.WithImporter(importer.New),
+		cli.WithBackup(func(w io.Writer, passphrase string, allVersions bool) (domain.BackupWriter, error) {
+			return backup.Create(w, passphrase, allVersions)
+		}, func(path, passphrase string) (domain.BackupReader, error) {
+			return backup.Open(path, passphrase)
+		}),
+		cli.WithRequestTimeout(conf.GRPCTimeout),
+		cli.WithShell(func() bool {
+			if secretCipher.Locked() {
+				return false
+			}
+			secretCipher.Lock()
+			return true
+		}))
 	if err := rootCmd.Execute(); err != nil {
+		// 'run' exits with the status of the command it ran
+		var exitErr *cli.ExitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.Code)
+		}
 		log.Fatal().Err(err).Msg("Fatal cli error")
 	}
 }
 
+// fatal reports an error the user can fix on stderr before terminating.
+func fatal(err error, msg string) {
+	fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	log.Fatal().Err(err).Msg(msg)
+}
+
 func createLogFile() (*os.File, error) {
 	path, err := getLogFilePath()
 	if err != nil {
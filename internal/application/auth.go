@@ -48,3 +48,27 @@ func (s *AuthService) Login(ctx context.Context, login, passsword string) error
 	}
 	return nil
 }
+
+// Logout removes the stored JWT token from the token repository.
+// Returns an error if the token can't be removed.
+func (s *AuthService) Logout(ctx context.Context) error {
+	if err := s.tokenRepository.DeleteToken(); err != nil {
+		return fmt.Errorf("tokenRepository.DeleteToken: %w", err)
+	}
+	return nil
+}
+
+// WhoAmI decodes the claims of the stored JWT token.
+// Returns ErrTokenNotFound if the user is not logged in.
+func (s *AuthService) WhoAmI(ctx context.Context) (*domain.TokenClaims, error) {
+	token, err := s.tokenRepository.GetToken()
+	if err != nil {
+		return nil, fmt.Errorf("tokenRepository.GetToken: %w", err)
+	}
+
+	claims, err := domain.ParseTokenClaims(token)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse token: %w", err)
+	}
+	return claims, nil
+}
@@ -0,0 +1,84 @@
+package application_test
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/application"
+	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/domain"
+	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/mocks"
+)
+
+func TestAuthService_WhoAmI(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient := mocks.NewMockAuthClient(ctrl)
+	mockRepo := mocks.NewMockTokenRepository(ctrl)
+	service := application.NewAuthService(mockClient, mockRepo)
+
+	ctx := context.Background()
+	jwt := func(payload string) string {
+		return "eyJhbGciOiJIUzI1NiJ9." + base64.RawURLEncoding.EncodeToString([]byte(payload)) + ".c2ln"
+	}
+
+	tests := []struct {
+		name           string
+		token          string
+		tokenErr       error
+		expectedClaims *domain.TokenClaims
+		expectedError  error
+	}{
+		{
+			name:           "login claim with expiry",
+			token:          jwt(`{"login":"testuser","exp":1700000000}`),
+			expectedClaims: &domain.TokenClaims{Login: "testuser", ExpiresAt: time.Unix(1700000000, 0)},
+		},
+		{
+			name:           "subject claim without expiry",
+			token:          jwt(`{"sub":"testuser"}`),
+			expectedClaims: &domain.TokenClaims{Login: "testuser"},
+		},
+		{
+			name:          "malformed token",
+			token:         "not-a-jwt",
+			expectedError: domain.ErrMalformedToken,
+		},
+		{
+			name:          "not logged in",
+			tokenErr:      domain.ErrTokenNotFound,
+			expectedError: domain.ErrTokenNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo.EXPECT().GetToken().Return(tt.token, tt.tokenErr)
+
+			claims, err := service.WhoAmI(ctx)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedClaims, claims)
+			}
+		})
+	}
+}
+
+func TestAuthService_Logout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient := mocks.NewMockAuthClient(ctrl)
+	mockRepo := mocks.NewMockTokenRepository(ctrl)
+	service := application.NewAuthService(mockClient, mockRepo)
+
+	mockRepo.EXPECT().DeleteToken().Return(nil)
+	assert.NoError(t, service.Logout(context.Background()))
+}
@@ -0,0 +1,203 @@
+package application
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"sync"
+
+	"golang.org/x/crypto/argon2"
+
+	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/domain"
+)
+
+const (
+	kdfAlgorithm  = "argon2id"
+	keyLength     = 32
+	saltLength    = 16
+	argon2Time    = 3
+	argon2Memory  = 64 * 1024
+	argon2Threads = 4
+
+	keyCheckPlaintext = "gophkeeper-key-check"
+)
+
+// PasswordPrompt returns the master password used to derive the encryption key.
+// When confirm is true the password is being set for the first time
+// and implementations should ask the user to repeat it.
+type PasswordPrompt func(confirm bool) (string, error)
+
+// SecretCipher provides AES-256-GCM encryption with a key derived from the
+// master password using Argon2id. The key is derived lazily on first use.
+type SecretCipher struct {
+	keyRepository domain.KeyRepository
+	prompt        PasswordPrompt
+
+	mu   sync.Mutex
+	aead cipher.AEAD
+}
+
+// NewSecretCipher creates a new instance of SecretCipher with the required dependencies.
+func NewSecretCipher(keyRepository domain.KeyRepository, prompt PasswordPrompt) *SecretCipher {
+	return &SecretCipher{
+		keyRepository: keyRepository,
+		prompt:        prompt,
+	}
+}
+
+// Unlock derives the encryption key from the master password.
+// On first use it generates new key parameters and saves them to the key repository.
+// Returns ErrInvalidMasterPassword if the password does not match the stored key check.
+func (c *SecretCipher) Unlock() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.aead != nil {
+		return nil
+	}
+
+	params, err := c.keyRepository.GetKeyParams()
+	if errors.Is(err, domain.ErrKeyParamsNotFound) {
+		return c.initKey()
+	}
+	if err != nil {
+		return fmt.Errorf("keyRepository.GetKeyParams: %w", err)
+	}
+
+	password, err := c.prompt(false)
+	if err != nil {
+		return fmt.Errorf("failed to read master password: %w", err)
+	}
+
+	aead, err := deriveAEAD(password, *params)
+	if err != nil {
+		return err
+	}
+
+	if _, err = open(aead, params.KeyCheck, []byte(keyCheckPlaintext)); err != nil {
+		return domain.ErrInvalidMasterPassword
+	}
+
+	c.aead = aead
+	return nil
+}
+
+// Lock discards the encryption key. The master password is asked again on next use.
+func (c *SecretCipher) Lock() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.aead = nil
+}
+
+// Locked reports whether the encryption key has not been derived yet or was discarded.
+func (c *SecretCipher) Locked() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.aead == nil
+}
+
+// Seal encrypts and authenticates plaintext together with additionalData.
+// The random nonce is prepended to the returned ciphertext.
+func (c *SecretCipher) Seal(plaintext, additionalData []byte) ([]byte, error) {
+	if err := c.Unlock(); err != nil {
+		return nil, err
+	}
+	return seal(c.aead, plaintext, additionalData)
+}
+
+// Open authenticates and decrypts ciphertext produced by Seal.
+// Returns ErrDecryptionFailed if the ciphertext or additionalData were modified.
+func (c *SecretCipher) Open(ciphertext, additionalData []byte) ([]byte, error) {
+	if err := c.Unlock(); err != nil {
+		return nil, err
+	}
+	return open(c.aead, ciphertext, additionalData)
+}
+
+// initKey generates new key parameters for the given master password
+// and stores them together with a key check value.
+func (c *SecretCipher) initKey() error {
+	password, err := c.prompt(true)
+	if err != nil {
+		return fmt.Errorf("failed to read master password: %w", err)
+	}
+
+	salt := make([]byte, saltLength)
+	if _, err = rand.Read(salt); err != nil {
+		return fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	params := domain.KeyParams{
+		Algorithm: kdfAlgorithm,
+		Salt:      salt,
+		Time:      argon2Time,
+		Memory:    argon2Memory,
+		Threads:   argon2Threads,
+	}
+
+	aead, err := deriveAEAD(password, params)
+	if err != nil {
+		return err
+	}
+
+	params.KeyCheck, err = seal(aead, []byte(keyCheckPlaintext), []byte(keyCheckPlaintext))
+	if err != nil {
+		return err
+	}
+
+	if err = c.keyRepository.SaveKeyParams(params); err != nil {
+		return fmt.Errorf("keyRepository.SaveKeyParams: %w", err)
+	}
+
+	c.aead = aead
+	return nil
+}
+
+// deriveAEAD derives the master key with Argon2id and wraps it into AES-256-GCM.
+func deriveAEAD(password string, params domain.KeyParams) (cipher.AEAD, error) {
+	if params.Algorithm != kdfAlgorithm {
+		return nil, fmt.Errorf("unsupported key derivation algorithm: %s", params.Algorithm)
+	}
+
+	key := argon2.IDKey([]byte(password), params.Salt, params.Time, params.Memory, params.Threads, keyLength)
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create block cipher: %w", err)
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AEAD: %w", err)
+	}
+
+	return aead, nil
+}
+
+// seal encrypts plaintext with a random nonce and returns nonce||ciphertext.
+func seal(aead cipher.AEAD, plaintext, additionalData []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+// open splits nonce||ciphertext and decrypts it.
+func open(aead cipher.AEAD, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(ciphertext) < aead.NonceSize() {
+		return nil, domain.ErrDecryptionFailed
+	}
+
+	nonce, sealed := ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, sealed, additionalData)
+	if err != nil {
+		return nil, domain.ErrDecryptionFailed
+	}
+
+	return plaintext, nil
+}
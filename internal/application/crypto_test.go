@@ -0,0 +1,114 @@
+package application_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/application"
+	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/domain"
+	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/mocks"
+)
+
+func staticPrompt(password string) application.PasswordPrompt {
+	return func(bool) (string, error) { return password, nil }
+}
+
+func TestSecretCipher(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockKeyRepo := mocks.NewMockKeyRepository(ctrl)
+
+	// First use generates and stores new key parameters
+	var stored domain.KeyParams
+	mockKeyRepo.EXPECT().GetKeyParams().Return(nil, domain.ErrKeyParamsNotFound)
+	mockKeyRepo.EXPECT().SaveKeyParams(gomock.Any()).DoAndReturn(func(params domain.KeyParams) error {
+		stored = params
+		return nil
+	})
+
+	c := application.NewSecretCipher(mockKeyRepo, staticPrompt("master"))
+	sealed, err := c.Seal([]byte("s3cr3t"), []byte("github"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(sealed), "s3cr3t")
+
+	tests := []struct {
+		name          string
+		password      string
+		aad           string
+		expectedError error
+	}{
+		{
+			name:     "same password opens ciphertext",
+			password: "master",
+			aad:      "github",
+		},
+		{
+			name:          "wrong password is rejected",
+			password:      "wrong",
+			aad:           "github",
+			expectedError: domain.ErrInvalidMasterPassword,
+		},
+		{
+			name:          "different additional data is rejected",
+			password:      "master",
+			aad:           "gitlab",
+			expectedError: domain.ErrDecryptionFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockKeyRepo.EXPECT().GetKeyParams().Return(&stored, nil)
+
+			c := application.NewSecretCipher(mockKeyRepo, staticPrompt(tt.password))
+			plaintext, err := c.Open(sealed, []byte(tt.aad))
+
+			if tt.expectedError != nil {
+				assert.True(t, errors.Is(err, tt.expectedError))
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, "s3cr3t", string(plaintext))
+			}
+		})
+	}
+}
+
+func TestSecretCipher_Lock(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockKeyRepo := mocks.NewMockKeyRepository(ctrl)
+
+	var stored domain.KeyParams
+	mockKeyRepo.EXPECT().GetKeyParams().Return(nil, domain.ErrKeyParamsNotFound)
+	mockKeyRepo.EXPECT().SaveKeyParams(gomock.Any()).DoAndReturn(func(params domain.KeyParams) error {
+		stored = params
+		return nil
+	})
+
+	prompts := 0
+	c := application.NewSecretCipher(mockKeyRepo, func(bool) (string, error) {
+		prompts++
+		return "master", nil
+	})
+	assert.True(t, c.Locked())
+
+	sealed, err := c.Seal([]byte("s3cr3t"), nil)
+	require.NoError(t, err)
+	assert.False(t, c.Locked())
+	assert.Equal(t, 1, prompts)
+
+	// A locked cipher asks for the master password again
+	c.Lock()
+	assert.True(t, c.Locked())
+
+	mockKeyRepo.EXPECT().GetKeyParams().Return(&stored, nil)
+	plaintext, err := c.Open(sealed, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "s3cr3t", string(plaintext))
+	assert.Equal(t, 2, prompts)
+}
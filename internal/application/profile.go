@@ -0,0 +1,138 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/domain"
+)
+
+// profileNamePattern restricts profile names to characters safe for directory names.
+var profileNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// ProfileService provides operations for managing named profiles.
+type ProfileService struct {
+	repo domain.ProfileRepository
+}
+
+// NewProfileService creates a new instance of ProfileService with the required dependencies.
+func NewProfileService(repo domain.ProfileRepository) *ProfileService {
+	return &ProfileService{repo: repo}
+}
+
+// ListProfiles retrieves all stored profiles and the name of the current one.
+// The current name is DefaultProfileName if no named profile is selected.
+func (s *ProfileService) ListProfiles(ctx context.Context) ([]domain.Profile, string, error) {
+	profiles, err := s.repo.ListProfiles()
+	if err != nil {
+		return nil, "", fmt.Errorf("repo.ListProfiles: %w", err)
+	}
+
+	current, err := s.repo.GetCurrentProfile()
+	if err != nil {
+		return nil, "", fmt.Errorf("repo.GetCurrentProfile: %w", err)
+	}
+	if current == "" {
+		current = domain.DefaultProfileName
+	}
+
+	return profiles, current, nil
+}
+
+// AddProfile validates and stores a new profile.
+// Returns ErrInvalidProfile if the name or server address is invalid,
+// or ErrProfileAlreadyExists if the profile exists.
+func (s *ProfileService) AddProfile(ctx context.Context, profile domain.Profile) error {
+	if err := validateProfile(profile); err != nil {
+		return err
+	}
+
+	_, err := s.repo.GetProfile(profile.Name)
+	switch {
+	case err == nil:
+		return fmt.Errorf("%w: '%s'", domain.ErrProfileAlreadyExists, profile.Name)
+	case !errors.Is(err, domain.ErrProfileNotFound):
+		return fmt.Errorf("repo.GetProfile: %w", err)
+	}
+
+	if err := s.repo.SaveProfile(profile); err != nil {
+		return fmt.Errorf("repo.SaveProfile: %w", err)
+	}
+	return nil
+}
+
+// RemoveProfile deletes a profile. If it was current, the default profile becomes current.
+// Returns ErrProfileNotFound if the profile does not exist.
+func (s *ProfileService) RemoveProfile(ctx context.Context, name string) error {
+	if _, err := s.repo.GetProfile(name); err != nil {
+		return fmt.Errorf("repo.GetProfile: %w", err)
+	}
+
+	current, err := s.repo.GetCurrentProfile()
+	if err != nil {
+		return fmt.Errorf("repo.GetCurrentProfile: %w", err)
+	}
+	if current == name {
+		if err := s.repo.SaveCurrentProfile(""); err != nil {
+			return fmt.Errorf("repo.SaveCurrentProfile: %w", err)
+		}
+	}
+
+	if err := s.repo.DeleteProfile(name); err != nil {
+		return fmt.Errorf("repo.DeleteProfile: %w", err)
+	}
+	return nil
+}
+
+// UseProfile makes the profile current. DefaultProfileName selects the default profile.
+// Returns ErrProfileNotFound if the profile does not exist.
+func (s *ProfileService) UseProfile(ctx context.Context, name string) error {
+	if name == domain.DefaultProfileName {
+		name = ""
+	} else if _, err := s.repo.GetProfile(name); err != nil {
+		return fmt.Errorf("repo.GetProfile: %w", err)
+	}
+
+	if err := s.repo.SaveCurrentProfile(name); err != nil {
+		return fmt.Errorf("repo.SaveCurrentProfile: %w", err)
+	}
+	return nil
+}
+
+// ResolveProfile retrieves the profile with the given name, or the current one if name is empty.
+// Returns nil if the default profile is selected.
+func (s *ProfileService) ResolveProfile(ctx context.Context, name string) (*domain.Profile, error) {
+	if name == "" {
+		current, err := s.repo.GetCurrentProfile()
+		if err != nil {
+			return nil, fmt.Errorf("repo.GetCurrentProfile: %w", err)
+		}
+		name = current
+	}
+	if name == "" || name == domain.DefaultProfileName {
+		return nil, nil
+	}
+
+	profile, err := s.repo.GetProfile(name)
+	if err != nil {
+		return nil, fmt.Errorf("repo.GetProfile: %w", err)
+	}
+	return profile, nil
+}
+
+// validateProfile checks that the profile can be stored and used to connect.
+func validateProfile(profile domain.Profile) error {
+	if profile.Name == domain.DefaultProfileName || !profileNamePattern.MatchString(profile.Name) {
+		return fmt.Errorf("%w: name '%s' must consist of letters, digits, '.', '_' or '-' and must not be '%s'",
+			domain.ErrInvalidProfile, profile.Name, domain.DefaultProfileName)
+	}
+	if profile.ServerAddr == "" {
+		return fmt.Errorf("%w: server address cannot be empty", domain.ErrInvalidProfile)
+	}
+	if profile.Timeout < 0 {
+		return fmt.Errorf("%w: timeout cannot be negative", domain.ErrInvalidProfile)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package application_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/application"
+	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/domain"
+	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/mocks"
+)
+
+func TestProfileService(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockProfileRepository(ctrl)
+	service := application.NewProfileService(mockRepo)
+
+	ctx := context.Background()
+	staging := domain.Profile{Name: "staging", ServerAddr: "staging.example.com:443"}
+
+	t.Run("add profile", func(t *testing.T) {
+		mockRepo.EXPECT().GetProfile("staging").Return(nil, domain.ErrProfileNotFound)
+		mockRepo.EXPECT().SaveProfile(staging).Return(nil)
+
+		assert.NoError(t, service.AddProfile(ctx, staging))
+	})
+
+	t.Run("add existing profile", func(t *testing.T) {
+		mockRepo.EXPECT().GetProfile("staging").Return(&staging, nil)
+
+		err := service.AddProfile(ctx, staging)
+		assert.ErrorIs(t, err, domain.ErrProfileAlreadyExists)
+	})
+
+	t.Run("add invalid profile", func(t *testing.T) {
+		for _, profile := range []domain.Profile{
+			{Name: "default", ServerAddr: "localhost:8097"},
+			{Name: "../etc", ServerAddr: "localhost:8097"},
+			{Name: "staging"},
+		} {
+			err := service.AddProfile(ctx, profile)
+			assert.ErrorIs(t, err, domain.ErrInvalidProfile)
+		}
+	})
+
+	t.Run("remove current profile selects default", func(t *testing.T) {
+		gomock.InOrder(
+			mockRepo.EXPECT().GetProfile("staging").Return(&staging, nil),
+			mockRepo.EXPECT().GetCurrentProfile().Return("staging", nil),
+			mockRepo.EXPECT().SaveCurrentProfile("").Return(nil),
+			mockRepo.EXPECT().DeleteProfile("staging").Return(nil),
+		)
+
+		assert.NoError(t, service.RemoveProfile(ctx, "staging"))
+	})
+
+	t.Run("use default profile", func(t *testing.T) {
+		mockRepo.EXPECT().SaveCurrentProfile("").Return(nil)
+
+		assert.NoError(t, service.UseProfile(ctx, domain.DefaultProfileName))
+	})
+
+	t.Run("resolve current profile", func(t *testing.T) {
+		mockRepo.EXPECT().GetCurrentProfile().Return("staging", nil)
+		mockRepo.EXPECT().GetProfile("staging").Return(&staging, nil)
+
+		profile, err := service.ResolveProfile(ctx, "")
+		assert.NoError(t, err)
+		assert.Equal(t, &staging, profile)
+	})
+
+	t.Run("resolve without current profile", func(t *testing.T) {
+		mockRepo.EXPECT().GetCurrentProfile().Return("", nil)
+
+		profile, err := service.ResolveProfile(ctx, "")
+		assert.NoError(t, err)
+		assert.Nil(t, profile)
+	})
+}
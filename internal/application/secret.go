@@ -2,31 +2,47 @@ package application
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"sort"
+	"time"
+
+	"github.com/rs/zerolog/log"
 
 	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/domain"
 )
 
 // SecretService provides operations for managing secrets of different types.
 // It handles both regular secrets and streaming secrets (like files).
+// Fetched secrets are mirrored into the local vault, which serves reads
+// and queues writes while the server is unavailable.
 type SecretService struct {
 	client domain.SecretClient
+	vault  domain.VaultRepository
 }
 
-// NewSecretService creates a new instance of SecretService with the required client.
-
-func NewSecretService(client domain.SecretClient) *SecretService {
-	return &SecretService{client: client}
+// NewSecretService creates a new instance of SecretService with the required dependencies.
+func NewSecretService(client domain.SecretClient, vault domain.VaultRepository) *SecretService {
+	return &SecretService{client: client, vault: vault}
 }
 
 // CreateSecret creates a new secret based on its type.
 // For credential and payment card types, it reads all data from the reader first.
 // For file and text types, it streams the content directly.
+// Returns a VersionConflictError if the secret was changed on the server after its base version.
+// If the server is unavailable, the operation is queued in the local vault.
 // Returns an error if the operation fails.
 func (s *SecretService) CreateSecret(ctx context.Context, secret domain.Secret, contentReader io.Reader) error {
+	s.replayPending(ctx)
+
+	latest, checked, err := s.checkConflict(ctx, secret)
+	if err != nil {
+		return err
+	}
+
 	switch secret.Info.Type {
-	case domain.CredentialsSecretType, domain.PaymentCardSecretType:
+	case domain.CredentialsSecretType, domain.PaymentCardSecretType, domain.SSHKeySecretType:
 		secretData, err := io.ReadAll(contentReader)
 		if err != nil {
 			return fmt.Errorf("failed to read secret content: %w", err)
@@ -34,75 +50,482 @@ func (s *SecretService) CreateSecret(ctx context.Context, secret domain.Secret,
 		secret.Data = string(secretData)
 
 		err = s.client.CreateSecret(ctx, secret)
+		if errors.Is(err, domain.ErrServerUnavailable) {
+			return s.enqueue(domain.CreateOperationType, secret, nil)
+		}
 		if err != nil {
 			return fmt.Errorf("client.CreateSecret: %w", err)
 		}
 	case domain.FileSecretType, domain.TextSecretType:
-		err := s.client.CreateSecretStream(ctx, secret, contentReader)
+		counter := &countingReader{r: contentReader}
+		err := s.client.CreateSecretStream(ctx, secret, counter)
+		// Content can only be queued if the failed attempt did not consume it
+		if errors.Is(err, domain.ErrServerUnavailable) && counter.n == 0 {
+			return s.enqueue(domain.CreateOperationType, secret, contentReader)
+		}
 		if err != nil {
 			return fmt.Errorf("client.CreateSecretStream: %w", err)
 		}
 	}
 
+	// The version fetched by the conflict check is followed by the one just created
+	if checked {
+		created := secret.Info
+		created.Version = latest + 1
+		s.saveKnownVersion(created)
+	} else {
+		s.refreshKnownVersion(ctx, secret.Info)
+	}
 	return nil
 }
 
 // ListSecrets retrieves a list of all secret names available to the user.
+// Falls back to the names stored in the local vault if the server is unavailable.
 // Returns an error if the operation fails.
 func (s *SecretService) ListSecrets(ctx context.Context) ([]string, error) {
 	secretList, err := s.client.ListSecrets(ctx)
+	if errors.Is(err, domain.ErrServerUnavailable) {
+		cached, vaultErr := s.vault.GetSecretNames()
+		if vaultErr != nil {
+			return nil, fmt.Errorf("client.ListSecrets: %w", err)
+		}
+		log.Warn().Err(err).Msg("Server unavailable, serving secret list from local vault")
+		return cached, nil
+	}
 	if err != nil {
 		return nil, fmt.Errorf("client.ListSecrets: %w", err)
 	}
+
+	if err := s.vault.SaveSecretNames(secretList); err != nil {
+		log.Warn().Err(err).Msg("Failed to mirror secret list into local vault")
+	}
 	return secretList, nil
 }
 
+// ListSecretsInfo retrieves the info of the latest version of every secret.
+// If the server is unavailable, the infos stored in the local vault are returned;
+// secrets only known by name are listed without further details.
+func (s *SecretService) ListSecretsInfo(ctx context.Context) ([]domain.SecretInfo, error) {
+	infos, err := s.client.ListSecretsInfo(ctx)
+	if errors.Is(err, domain.ErrServerUnavailable) {
+		cached, vaultErr := s.vault.GetSecretInfos()
+		if vaultErr != nil {
+			names, namesErr := s.vault.GetSecretNames()
+			if namesErr != nil {
+				return nil, fmt.Errorf("client.ListSecretsInfo: %w", err)
+			}
+			cached = make([]domain.SecretInfo, 0, len(names))
+			for _, name := range names {
+				cached = append(cached, domain.SecretInfo{Name: name})
+			}
+		}
+		log.Warn().Err(err).Msg("Server unavailable, serving secret infos from local vault")
+		return cached, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("client.ListSecretsInfo: %w", err)
+	}
+
+	names := make([]string, 0, len(infos))
+	for _, info := range infos {
+		names = append(names, info.Name)
+	}
+	if err := s.vault.SaveSecretInfos(infos); err != nil {
+		log.Warn().Err(err).Msg("Failed to mirror secret infos into local vault")
+	}
+	if err := s.vault.SaveSecretNames(names); err != nil {
+		log.Warn().Err(err).Msg("Failed to mirror secret list into local vault")
+	}
+	return infos, nil
+}
+
+// ListSecretVersions retrieves every version of a secret ordered by version number.
+func (s *SecretService) ListSecretVersions(ctx context.Context, secretName string) ([]domain.SecretVersion, error) {
+	versions, err := s.client.ListSecretVersions(ctx, secretName)
+	if err != nil {
+		return nil, fmt.Errorf("client.ListSecretVersions: %w", err)
+	}
+
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].Info.Version < versions[j].Info.Version
+	})
+	return versions, nil
+}
+
+// GetSecretInfo retrieves the info of a version of a secret, version 0 being the latest one.
+// Unlike ListSecretVersions it does not download other versions from servers that cannot list them.
+func (s *SecretService) GetSecretInfo(ctx context.Context, secretName string, version int32) (*domain.SecretInfo, error) {
+	info, err := s.client.GetSecretInfo(ctx, secretName, version)
+	if err != nil {
+		return nil, fmt.Errorf("client.GetSecretInfo: %w", err)
+	}
+	return info, nil
+}
+
 // GetLatestSecret retrieves the most recent version of a secret by name.
 // Returns the secret or an error if the operation fails.
 func (s *SecretService) GetLatestSecret(ctx context.Context, secretName string) (*domain.Secret, error) {
-	secret, err := s.client.GetLatestSecret(ctx, secretName)
-	if err != nil {
-		return nil, fmt.Errorf("client.GetLatestSecret: %w", err)
-	}
-	return secret, nil
+	return s.getSecret(ctx, secretName, 0)
 }
 
 // GetLatestSecretStream retrieves the most recent version of a streamable secret by name.
 // Returns a reader for the content, secret info, or an error if the operation fails.
 func (s *SecretService) GetLatestSecretStream(ctx context.Context, secretName string) (io.Reader, *domain.SecretInfo, error) {
-	stream, secretInfo, err := s.client.GetLatestSecretStream(ctx, secretName)
-	if err != nil {
-		return nil, nil, fmt.Errorf("client.GetLatestSecretStream: %w", err)
-	}
-	return stream, secretInfo, nil
+	return s.getSecretStream(ctx, secretName, 0)
 }
 
 // GetSecretByVersion retrieves a specific version of a secret by name and version number.
 // Returns the secret or an error if the operation fails.
 func (s *SecretService) GetSecretByVersion(ctx context.Context, secretName string, version int32) (*domain.Secret, error) {
-	secret, err := s.client.GetSecretByVersion(ctx, secretName, version)
-	if err != nil {
-		return nil, fmt.Errorf("client.GetSecretByVersion: %w", err)
-	}
-	return secret, nil
+	return s.getSecret(ctx, secretName, version)
 }
 
 // GetSecretStreamByVersion retrieves a specific version of a streamable secret by name and version.
 // Returns a reader for the content, secret info, or an error if the operation fails.
 func (s *SecretService) GetSecretStreamByVersion(ctx context.Context, secretName string, version int32) (io.Reader, *domain.SecretInfo, error) {
-	stream, secretInfo, err := s.client.GetSecretStreamByVersion(ctx, secretName, version)
-	if err != nil {
-		return nil, nil, fmt.Errorf("client.GetSecretStreamByVersion: %w", err)
-	}
-	return stream, secretInfo, nil
+	return s.getSecretStream(ctx, secretName, version)
 }
 
 // DeleteSecret removes a secret and all its versions by name.
+// If the server is unavailable, the operation is queued in the local vault.
 // Returns an error if the operation fails.
 func (s *SecretService) DeleteSecret(ctx context.Context, secretName string) error {
+	s.replayPending(ctx)
+
 	err := s.client.DeleteSecret(ctx, secretName)
+	if errors.Is(err, domain.ErrServerUnavailable) {
+		err = s.enqueue(domain.DeleteOperationType, domain.Secret{Info: domain.SecretInfo{Name: secretName}}, nil)
+	}
 	if err != nil {
 		return fmt.Errorf("client.DeleteSecret: %w", err)
 	}
+
+	if err := s.vault.DeleteSecret(secretName); err != nil {
+		log.Warn().Err(err).Str("secret", secretName).Msg("Failed to remove secret from local vault")
+	}
+	return nil
+}
+
+// Sync replays all pending operations against the server.
+// Returns an error if replay fails.
+func (s *SecretService) Sync(ctx context.Context) error {
+	return s.replay(ctx)
+}
+
+// GetSyncStatus retrieves pending operations and the last synchronization time.
+func (s *SecretService) GetSyncStatus(ctx context.Context) (*domain.SyncStatus, error) {
+	pending, err := s.vault.ListPendingOperations()
+	if err != nil {
+		return nil, fmt.Errorf("vault.ListPendingOperations: %w", err)
+	}
+
+	lastSync, err := s.vault.GetLastSync()
+	if err != nil {
+		return nil, fmt.Errorf("vault.GetLastSync: %w", err)
+	}
+
+	return &domain.SyncStatus{Pending: pending, LastSync: lastSync}, nil
+}
+
+// getSecret retrieves a secret version from the server and mirrors it into the vault.
+// Version 0 means the latest version.
+func (s *SecretService) getSecret(ctx context.Context, secretName string, version int32) (*domain.Secret, error) {
+	var (
+		secret *domain.Secret
+		err    error
+	)
+	if version == 0 {
+		secret, err = s.client.GetLatestSecret(ctx, secretName)
+	} else {
+		secret, err = s.client.GetSecretByVersion(ctx, secretName, version)
+	}
+
+	if errors.Is(err, domain.ErrServerUnavailable) {
+		cached, vaultErr := s.vault.GetSecret(secretName, version)
+		if vaultErr != nil {
+			return nil, fmt.Errorf("failed to get secret: %w", err)
+		}
+		log.Warn().Err(err).Str("secret", secretName).Msg("Server unavailable, serving secret from local vault")
+		return cached, nil
+	}
+	if err != nil {
+		if version == 0 {
+			return nil, fmt.Errorf("client.GetLatestSecret: %w", err)
+		}
+		return nil, fmt.Errorf("client.GetSecretByVersion: %w", err)
+	}
+
+	if err := s.vault.SaveSecret(*secret); err != nil {
+		log.Warn().Err(err).Str("secret", secretName).Msg("Failed to mirror secret into local vault")
+	}
+	if version == 0 {
+		s.saveKnownVersion(secret.Info)
+	}
+	return secret, nil
+}
+
+// getSecretStream retrieves a streamable secret version from the server
+// and mirrors its content into the vault while it is being read.
+// Version 0 means the latest version.
+func (s *SecretService) getSecretStream(ctx context.Context, secretName string, version int32) (io.Reader, *domain.SecretInfo, error) {
+	var (
+		stream     io.Reader
+		secretInfo *domain.SecretInfo
+		err        error
+	)
+	if version == 0 {
+		stream, secretInfo, err = s.client.GetLatestSecretStream(ctx, secretName)
+	} else {
+		stream, secretInfo, err = s.client.GetSecretStreamByVersion(ctx, secretName, version)
+	}
+
+	if errors.Is(err, domain.ErrServerUnavailable) {
+		cached, cachedInfo, vaultErr := s.vault.GetSecretStream(secretName, version)
+		if vaultErr != nil {
+			return nil, nil, fmt.Errorf("failed to get secret stream: %w", err)
+		}
+		log.Warn().Err(err).Str("secret", secretName).Msg("Server unavailable, serving secret from local vault")
+		return cached, cachedInfo, nil
+	}
+	if err != nil {
+		if version == 0 {
+			return nil, nil, fmt.Errorf("client.GetLatestSecretStream: %w", err)
+		}
+		return nil, nil, fmt.Errorf("client.GetSecretStreamByVersion: %w", err)
+	}
+
+	if version == 0 {
+		s.saveKnownVersion(*secretInfo)
+	}
+
+	writer, err := s.vault.SaveSecretStream(*secretInfo)
+	if err != nil {
+		log.Warn().Err(err).Str("secret", secretName).Msg("Failed to mirror secret into local vault")
+		return stream, secretInfo, nil
+	}
+	return &mirrorReader{r: stream, w: writer}, secretInfo, nil
+}
+
+// checkConflict verifies that the latest version on the server is not newer than
+// the version the secret is based on. Without a base version and a version seen on this device
+// nothing is expected of the server, so the check is skipped unless CheckUnseen is set.
+// Returns the latest version on the server, zero for a new secret, and whether it was checked;
+// the check is also skipped while the server is unavailable.
+func (s *SecretService) checkConflict(ctx context.Context, secret domain.Secret) (int32, bool, error) {
+	base := secret.BaseVersion
+	if base == 0 {
+		known, err := s.vault.GetKnownVersion(secret.Info.Name)
+		if err != nil {
+			log.Warn().Err(err).Str("secret", secret.Info.Name).Msg("Failed to get known secret version")
+		}
+		base = known
+	}
+	if base == 0 && !secret.CheckUnseen {
+		return 0, false, nil
+	}
+
+	latest, err := s.getLatestFromServer(ctx, secret.Info)
+	if errors.Is(err, domain.ErrServerUnavailable) {
+		return 0, false, nil
+	}
+	if errors.Is(err, domain.ErrSecretNotFound) {
+		return 0, true, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to check latest version: %w", err)
+	}
+	if latest.Info.Version <= base {
+		return latest.Info.Version, true, nil
+	}
+
+	conflict := &domain.VersionConflictError{
+		Name:          secret.Info.Name,
+		BaseVersion:   base,
+		LatestVersion: latest.Info.Version,
+	}
+	if secret.Info.Type.Streamable() {
+		return 0, false, conflict
+	}
+
+	conflict.Theirs = latest
+	if base > 0 {
+		baseSecret, err := s.client.GetSecretByVersion(ctx, secret.Info.Name, base)
+		if err != nil {
+			log.Warn().Err(err).Str("secret", secret.Info.Name).Msg("Failed to get base version for merge")
+		} else {
+			conflict.Base = baseSecret
+		}
+	}
+	return 0, false, conflict
+}
+
+// getLatestFromServer retrieves the latest version of a secret directly from the server.
+// For streamable secrets only the secret info is retrieved and the stream is cancelled.
+func (s *SecretService) getLatestFromServer(ctx context.Context, info domain.SecretInfo) (*domain.Secret, error) {
+	if !info.Type.Streamable() {
+		return s.client.GetLatestSecret(ctx, info.Name)
+	}
+
+	streamCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	_, latestInfo, err := s.client.GetLatestSecretStream(streamCtx, info.Name)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.Secret{Info: *latestInfo}, nil
+}
+
+// refreshKnownVersion records the version created by this device as seen.
+func (s *SecretService) refreshKnownVersion(ctx context.Context, info domain.SecretInfo) {
+	latest, err := s.getLatestFromServer(ctx, info)
+	if err != nil {
+		log.Warn().Err(err).Str("secret", info.Name).Msg("Failed to get created secret version")
+		return
+	}
+	s.saveKnownVersion(latest.Info)
+}
+
+// saveKnownVersion stores the latest version of a secret seen on this device.
+func (s *SecretService) saveKnownVersion(info domain.SecretInfo) {
+	if err := s.vault.SaveKnownVersion(info.Name, info.Version); err != nil {
+		log.Warn().Err(err).Str("secret", info.Name).Msg("Failed to save known secret version")
+	}
+}
+
+// enqueue stores a write operation in the vault to be replayed later.
+func (s *SecretService) enqueue(opType domain.OperationType, secret domain.Secret, content io.Reader) error {
+	now := time.Now()
+	op := domain.PendingOperation{
+		ID:       fmt.Sprintf("%020d", now.UnixNano()),
+		Type:     opType,
+		Secret:   secret,
+		QueuedAt: now,
+	}
+
+	if err := s.vault.EnqueueOperation(op, content); err != nil {
+		return fmt.Errorf("vault.EnqueueOperation: %w", err)
+	}
+
+	log.Warn().Str("secret", secret.Info.Name).Str("operation", string(opType)).
+		Msg("Server unavailable, operation queued for sync")
 	return nil
 }
+
+// replayPending replays queued operations before a write, so writes reach the server in order.
+// Reads do not replay, as replaying may need the vault key to decrypt queued payloads.
+// Failures are logged, the operations stay queued until the next attempt.
+func (s *SecretService) replayPending(ctx context.Context) {
+	pending, err := s.vault.ListPendingOperations()
+	if err != nil || len(pending) == 0 {
+		return
+	}
+
+	if err := s.replay(ctx); err != nil {
+		log.Warn().Err(err).Msg("Failed to replay pending operations")
+	}
+}
+
+// replay sends queued operations to the server in the order they were made.
+// It stops at the first failure, so that later operations are not applied out of order.
+func (s *SecretService) replay(ctx context.Context) error {
+	pending, err := s.vault.ListPendingOperations()
+	if err != nil {
+		return fmt.Errorf("vault.ListPendingOperations: %w", err)
+	}
+
+	for _, op := range pending {
+		if err := s.replayOperation(ctx, op); err != nil {
+			return fmt.Errorf("failed to replay %s of '%s': %w", op.Type, op.Secret.Info.Name, err)
+		}
+		if err := s.vault.RemovePendingOperation(op.ID); err != nil {
+			return fmt.Errorf("vault.RemovePendingOperation: %w", err)
+		}
+	}
+
+	if err := s.vault.SaveLastSync(time.Now()); err != nil {
+		return fmt.Errorf("vault.SaveLastSync: %w", err)
+	}
+	return nil
+}
+
+// replayOperation sends a single queued operation to the server.
+func (s *SecretService) replayOperation(ctx context.Context, op domain.PendingOperation) error {
+	switch op.Type {
+	case domain.DeleteOperationType:
+		return s.client.DeleteSecret(ctx, op.Secret.Info.Name)
+	case domain.CreateOperationType:
+		switch op.Secret.Info.Type {
+		case domain.FileSecretType, domain.TextSecretType:
+			content, err := s.vault.GetPendingContent(op.ID)
+			if err != nil {
+				return fmt.Errorf("vault.GetPendingContent: %w", err)
+			}
+			return s.client.CreateSecretStream(ctx, op.Secret, content)
+		default:
+			return s.client.CreateSecret(ctx, op.Secret)
+		}
+	default:
+		return fmt.Errorf("unknown operation type: %s", op.Type)
+	}
+}
+
+// countingReader counts the bytes read from the underlying reader.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+// Read implements io.Reader interface.
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// mirrorReader copies everything read from the server stream into the vault.
+// The mirrored copy is committed only if the stream was read completely.
+type mirrorReader struct {
+	r    io.Reader
+	w    domain.VaultWriter
+	done bool
+}
+
+// Read implements io.Reader interface.
+func (m *mirrorReader) Read(p []byte) (int, error) {
+	n, err := m.r.Read(p)
+	if m.done {
+		return n, err
+	}
+
+	if n > 0 {
+		if _, werr := m.w.Write(p[:n]); werr != nil {
+			log.Warn().Err(werr).Msg("Failed to mirror secret content into local vault")
+			_ = m.w.Abort()
+			m.done = true
+		}
+	}
+
+	switch {
+	case m.done:
+	case errors.Is(err, io.EOF):
+		if cerr := m.w.Commit(); cerr != nil {
+			log.Warn().Err(cerr).Msg("Failed to mirror secret content into local vault")
+		}
+		m.done = true
+	case err != nil:
+		_ = m.w.Abort()
+		m.done = true
+	}
+
+	return n, err
+}
+
+// Close drops the mirrored copy if the stream was not read completely.
+func (m *mirrorReader) Close() error {
+	if m.done {
+		return nil
+	}
+	m.done = true
+	return m.w.Abort()
+}
@@ -0,0 +1,220 @@
+package application_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/application"
+	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/domain"
+	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/mocks"
+)
+
+func TestSecretService_OfflineVault(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient := mocks.NewMockSecretClient(ctrl)
+	mockVault := mocks.NewMockVaultRepository(ctrl)
+	service := application.NewSecretService(mockClient, mockVault)
+
+	ctx := context.Background()
+	unavailable := fmt.Errorf("%w: connection refused", domain.ErrServerUnavailable)
+	secret := &domain.Secret{
+		Info: domain.SecretInfo{Name: "github", Type: domain.CredentialsSecretType, Version: 2},
+		Data: `{"Login":"user","Password":"pass"}`,
+	}
+
+	t.Run("online read is mirrored into vault", func(t *testing.T) {
+		// Reads never replay queued operations, so they are not even listed
+		mockClient.EXPECT().GetLatestSecret(ctx, "github").Return(secret, nil)
+		mockVault.EXPECT().SaveSecret(*secret).Return(nil)
+		mockVault.EXPECT().SaveKnownVersion("github", int32(2)).Return(nil)
+
+		got, err := service.GetLatestSecret(ctx, "github")
+		assert.NoError(t, err)
+		assert.Equal(t, secret, got)
+	})
+
+	t.Run("offline read is served from vault", func(t *testing.T) {
+		mockClient.EXPECT().GetSecretByVersion(ctx, "github", int32(2)).Return(nil, unavailable)
+		mockVault.EXPECT().GetSecret("github", int32(2)).Return(secret, nil)
+
+		got, err := service.GetSecretByVersion(ctx, "github", 2)
+		assert.NoError(t, err)
+		assert.Equal(t, secret, got)
+	})
+
+	t.Run("offline read of unknown secret fails", func(t *testing.T) {
+		mockClient.EXPECT().GetLatestSecret(ctx, "gitlab").Return(nil, unavailable)
+		mockVault.EXPECT().GetSecret("gitlab", int32(0)).Return(nil, domain.ErrSecretNotInVault)
+
+		_, err := service.GetLatestSecret(ctx, "gitlab")
+		assert.True(t, errors.Is(err, domain.ErrServerUnavailable))
+	})
+
+	t.Run("online list of infos is mirrored into vault", func(t *testing.T) {
+		infos := []domain.SecretInfo{secret.Info}
+		mockClient.EXPECT().ListSecretsInfo(ctx).Return(infos, nil)
+		mockVault.EXPECT().SaveSecretInfos(infos).Return(nil)
+		mockVault.EXPECT().SaveSecretNames([]string{"github"}).Return(nil)
+
+		got, err := service.ListSecretsInfo(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, infos, got)
+	})
+
+	t.Run("offline list of infos is served from vault", func(t *testing.T) {
+		infos := []domain.SecretInfo{secret.Info}
+		mockClient.EXPECT().ListSecretsInfo(ctx).Return(nil, unavailable)
+		mockVault.EXPECT().GetSecretInfos().Return(infos, nil)
+
+		got, err := service.ListSecretsInfo(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, infos, got)
+	})
+
+	t.Run("offline list of infos falls back to names", func(t *testing.T) {
+		mockClient.EXPECT().ListSecretsInfo(ctx).Return(nil, unavailable)
+		mockVault.EXPECT().GetSecretInfos().Return(nil, domain.ErrSecretNotInVault)
+		mockVault.EXPECT().GetSecretNames().Return([]string{"github"}, nil)
+
+		got, err := service.ListSecretsInfo(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.SecretInfo{{Name: "github"}}, got)
+	})
+
+	t.Run("versions are ordered by version number", func(t *testing.T) {
+		v1 := domain.SecretVersion{Info: domain.SecretInfo{Name: "github", Version: 1}, Size: 10}
+		v2 := domain.SecretVersion{Info: domain.SecretInfo{Name: "github", Version: 2}, Size: 12}
+		mockClient.EXPECT().ListSecretVersions(ctx, "github").Return([]domain.SecretVersion{v2, v1}, nil)
+
+		got, err := service.ListSecretVersions(ctx, "github")
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.SecretVersion{v1, v2}, got)
+	})
+
+	t.Run("offline delete is queued", func(t *testing.T) {
+		mockVault.EXPECT().ListPendingOperations().Return(nil, nil)
+		mockClient.EXPECT().DeleteSecret(ctx, "github").Return(unavailable)
+		mockVault.EXPECT().EnqueueOperation(gomock.Any(), nil).
+			DoAndReturn(func(op domain.PendingOperation, _ any) error {
+				assert.Equal(t, domain.DeleteOperationType, op.Type)
+				assert.Equal(t, "github", op.Secret.Info.Name)
+				return nil
+			})
+		mockVault.EXPECT().DeleteSecret("github").Return(nil)
+
+		assert.NoError(t, service.DeleteSecret(ctx, "github"))
+	})
+
+	t.Run("sync replays queued operations in order", func(t *testing.T) {
+		pending := []domain.PendingOperation{
+			{ID: "1", Type: domain.CreateOperationType, Secret: *secret},
+			{ID: "2", Type: domain.DeleteOperationType, Secret: domain.Secret{Info: domain.SecretInfo{Name: "old"}}},
+		}
+		mockVault.EXPECT().ListPendingOperations().Return(pending, nil)
+		gomock.InOrder(
+			mockClient.EXPECT().CreateSecret(ctx, *secret).Return(nil),
+			mockVault.EXPECT().RemovePendingOperation("1").Return(nil),
+			mockClient.EXPECT().DeleteSecret(ctx, "old").Return(nil),
+			mockVault.EXPECT().RemovePendingOperation("2").Return(nil),
+		)
+		mockVault.EXPECT().SaveLastSync(gomock.Any()).Return(nil)
+
+		assert.NoError(t, service.Sync(ctx))
+	})
+
+	t.Run("sync keeps operations while offline", func(t *testing.T) {
+		mockVault.EXPECT().ListPendingOperations().
+			Return([]domain.PendingOperation{{ID: "1", Type: domain.CreateOperationType, Secret: *secret}}, nil)
+		mockClient.EXPECT().CreateSecret(ctx, *secret).Return(unavailable)
+
+		err := service.Sync(ctx)
+		assert.True(t, errors.Is(err, domain.ErrServerUnavailable))
+	})
+}
+
+func TestSecretService_VersionConflict(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient := mocks.NewMockSecretClient(ctrl)
+	mockVault := mocks.NewMockVaultRepository(ctrl)
+	service := application.NewSecretService(mockClient, mockVault)
+
+	ctx := context.Background()
+	info := domain.SecretInfo{Name: "github", Type: domain.CredentialsSecretType}
+	data := `{"Login":"user","Password":"new"}`
+	secretAt := func(version int32, data string) *domain.Secret {
+		return &domain.Secret{
+			Info: domain.SecretInfo{Name: "github", Type: domain.CredentialsSecretType, Version: version},
+			Data: data,
+		}
+	}
+
+	t.Run("create based on latest version succeeds", func(t *testing.T) {
+		mockVault.EXPECT().ListPendingOperations().Return(nil, nil)
+		mockVault.EXPECT().GetKnownVersion("github").Return(int32(2), nil)
+		mockClient.EXPECT().GetLatestSecret(ctx, "github").Return(secretAt(2, data), nil)
+		mockClient.EXPECT().CreateSecret(ctx, domain.Secret{Info: info, Data: data}).Return(nil)
+		mockVault.EXPECT().SaveKnownVersion("github", int32(3)).Return(nil)
+
+		err := service.CreateSecret(ctx, domain.Secret{Info: info}, strings.NewReader(data))
+		assert.NoError(t, err)
+	})
+
+	t.Run("create of an unseen secret is not checked", func(t *testing.T) {
+		mockVault.EXPECT().ListPendingOperations().Return(nil, nil)
+		mockVault.EXPECT().GetKnownVersion("github").Return(int32(0), nil)
+		mockClient.EXPECT().CreateSecret(ctx, domain.Secret{Info: info, Data: data}).Return(nil)
+		mockClient.EXPECT().GetLatestSecret(ctx, "github").Return(secretAt(4, data), nil)
+		mockVault.EXPECT().SaveKnownVersion("github", int32(4)).Return(nil)
+
+		err := service.CreateSecret(ctx, domain.Secret{Info: info}, strings.NewReader(data))
+		assert.NoError(t, err)
+	})
+
+	t.Run("create of a new secret checked for unseen versions succeeds", func(t *testing.T) {
+		mockVault.EXPECT().ListPendingOperations().Return(nil, nil)
+		mockVault.EXPECT().GetKnownVersion("github").Return(int32(0), nil)
+		mockClient.EXPECT().GetLatestSecret(ctx, "github").Return(nil, domain.ErrSecretNotFound)
+		mockClient.EXPECT().CreateSecret(ctx, domain.Secret{Info: info, Data: data, CheckUnseen: true}).Return(nil)
+		mockVault.EXPECT().SaveKnownVersion("github", int32(1)).Return(nil)
+
+		err := service.CreateSecret(ctx, domain.Secret{Info: info, CheckUnseen: true}, strings.NewReader(data))
+		assert.NoError(t, err)
+	})
+
+	t.Run("create of an unseen secret checked for unseen versions returns conflict", func(t *testing.T) {
+		mockVault.EXPECT().ListPendingOperations().Return(nil, nil)
+		mockVault.EXPECT().GetKnownVersion("github").Return(int32(0), nil)
+		mockClient.EXPECT().GetLatestSecret(ctx, "github").Return(secretAt(4, data), nil)
+
+		err := service.CreateSecret(ctx, domain.Secret{Info: info, CheckUnseen: true}, strings.NewReader(data))
+		assert.True(t, errors.Is(err, domain.ErrVersionConflict))
+	})
+
+	t.Run("create based on outdated version returns conflict", func(t *testing.T) {
+		base := secretAt(2, `{"Login":"user","Password":"old"}`)
+		theirs := secretAt(3, `{"Login":"other","Password":"old"}`)
+
+		mockVault.EXPECT().ListPendingOperations().Return(nil, nil)
+		mockClient.EXPECT().GetLatestSecret(ctx, "github").Return(theirs, nil)
+		mockClient.EXPECT().GetSecretByVersion(ctx, "github", int32(2)).Return(base, nil)
+
+		err := service.CreateSecret(ctx, domain.Secret{Info: info, BaseVersion: 2}, strings.NewReader(data))
+
+		var conflict *domain.VersionConflictError
+		assert.True(t, errors.As(err, &conflict))
+		assert.True(t, errors.Is(err, domain.ErrVersionConflict))
+		assert.Equal(t, int32(2), conflict.BaseVersion)
+		assert.Equal(t, int32(3), conflict.LatestVersion)
+		assert.Equal(t, base, conflict.Base)
+		assert.Equal(t, theirs, conflict.Theirs)
+	})
+}
@@ -0,0 +1,128 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AuditIssue is a kind of problem found by a security audit.
+type AuditIssue string
+
+const (
+	ReusedPasswordAuditIssue   AuditIssue = "reused_password"
+	WeakPasswordAuditIssue     AuditIssue = "weak_password"
+	OldSecretAuditIssue        AuditIssue = "old_secret"
+	ExpiredCardAuditIssue      AuditIssue = "expired_card"
+	BreachedPasswordAuditIssue AuditIssue = "breached_password"
+)
+
+// AuditFinding is a problem with a secret. Detail describes it without revealing the secret.
+type AuditFinding struct {
+	Issue  AuditIssue
+	Secret string
+	Detail string
+}
+
+// AuditPolicy holds the thresholds of a security audit.
+// Passwords below MinEntropy bits are weak; secrets whose latest version is older than MaxAge are old.
+// A zero threshold disables the check.
+type AuditPolicy struct {
+	MinEntropy float64
+	MaxAge     time.Duration
+}
+
+// DefaultAuditPolicy flags passwords below 60 bits of entropy and secrets not changed for a year.
+var DefaultAuditPolicy = AuditPolicy{MinEntropy: 60, MaxAge: 365 * 24 * time.Hour}
+
+// AuditSecrets checks the latest versions of secrets for reused and weak passwords,
+// old secrets and expired payment cards. Payloads are only needed for credentials and payment cards.
+// Findings are ordered by secret name and issue.
+func AuditSecrets(secrets []Secret, policy AuditPolicy, now time.Time) ([]AuditFinding, error) {
+	var findings []AuditFinding
+	passwords := make(map[string][]string)
+
+	for _, secret := range secrets {
+		name := secret.Info.Name
+
+		if age := now.Sub(secret.Info.CreatedAt); policy.MaxAge > 0 && !secret.Info.CreatedAt.IsZero() && age > policy.MaxAge {
+			findings = append(findings, AuditFinding{
+				Issue:  OldSecretAuditIssue,
+				Secret: name,
+				Detail: fmt.Sprintf("latest version is %d days old", int(age.Hours()/24)),
+			})
+		}
+
+		switch secret.Info.Type {
+		case CredentialsSecretType:
+			credentials, err := decodeCredentials(secret)
+			if err != nil {
+				return nil, err
+			}
+			if credentials.Password == "" {
+				continue
+			}
+			passwords[credentials.Password] = append(passwords[credentials.Password], name)
+
+			if entropy := EstimatePasswordEntropy(credentials.Password); entropy < policy.MinEntropy {
+				findings = append(findings, AuditFinding{
+					Issue:  WeakPasswordAuditIssue,
+					Secret: name,
+					Detail: fmt.Sprintf("estimated entropy is %.0f bits, below %.0f", math.Floor(entropy), policy.MinEntropy),
+				})
+			}
+		case PaymentCardSecretType:
+			var card PaymentCardSecret
+			if err := json.Unmarshal([]byte(secret.Data), &card); err != nil {
+				return nil, fmt.Errorf("failed to decode card '%s': %w", name, err)
+			}
+			if card.Expired(now) {
+				findings = append(findings, AuditFinding{
+					Issue:  ExpiredCardAuditIssue,
+					Secret: name,
+					Detail: fmt.Sprintf("card expired after %02d/%d", card.ExpiryMonth, card.ExpiryYear),
+				})
+			}
+		}
+	}
+
+	for _, names := range passwords {
+		if len(names) < 2 {
+			continue
+		}
+		for _, name := range names {
+			others := make([]string, 0, len(names)-1)
+			for _, other := range names {
+				if other != name {
+					others = append(others, other)
+				}
+			}
+			sort.Strings(others)
+			findings = append(findings, AuditFinding{
+				Issue:  ReusedPasswordAuditIssue,
+				Secret: name,
+				Detail: "password is also used by " + strings.Join(others, ", "),
+			})
+		}
+	}
+
+	sort.Slice(findings, func(i, j int) bool {
+		if findings[i].Secret != findings[j].Secret {
+			return findings[i].Secret < findings[j].Secret
+		}
+		return findings[i].Issue < findings[j].Issue
+	})
+	return findings, nil
+}
+
+// decodeCredentials decodes the payload of a credentials secret.
+func decodeCredentials(secret Secret) (CredentialsSecret, error) {
+	var credentials CredentialsSecret
+	if err := json.Unmarshal([]byte(secret.Data), &credentials); err != nil {
+		return credentials, fmt.Errorf("failed to decode credentials '%s': %w", secret.Info.Name, err)
+	}
+	return credentials, nil
+}
@@ -0,0 +1,92 @@
+package domain_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/domain"
+)
+
+func TestEstimatePasswordEntropy(t *testing.T) {
+	tests := []struct {
+		password string
+		entropy  float64
+	}{
+		{"", 0},
+		{"password", 33.9},
+		{"aaaaaaaa", 11.7},
+		{"12345678", 10.3},
+		{"Tr0ub4dor&3", 72.3},
+		{"correct-horse-battery-staple", 140.3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.password, func(t *testing.T) {
+			assert.InDelta(t, tt.entropy, domain.EstimatePasswordEntropy(tt.password), 0.1)
+		})
+	}
+}
+
+func TestAuditSecrets(t *testing.T) {
+	now := time.Date(2025, 6, 15, 12, 0, 0, 0, time.UTC)
+
+	credentials := func(name, password string, createdAt time.Time) domain.Secret {
+		data, err := json.Marshal(domain.CredentialsSecret{Login: "user", Password: password})
+		require.NoError(t, err)
+		return domain.Secret{
+			Info: domain.SecretInfo{Name: name, Type: domain.CredentialsSecretType, CreatedAt: createdAt},
+			Data: string(data),
+		}
+	}
+	card := func(name string, month, year int) domain.Secret {
+		data, err := json.Marshal(domain.PaymentCardSecret{Number: "4111111111111111", ExpiryMonth: month, ExpiryYear: year})
+		require.NoError(t, err)
+		return domain.Secret{
+			Info: domain.SecretInfo{Name: name, Type: domain.PaymentCardSecretType, CreatedAt: now},
+			Data: string(data),
+		}
+	}
+	const strong = "x7#Kq9!vLm2@Zr5$Wt8&"
+
+	secrets := []domain.Secret{
+		credentials("github", strong, now.AddDate(0, -1, 0)),
+		credentials("gitlab", strong, now.AddDate(0, -2, 0)),
+		credentials("mail", "password", now),
+		credentials("bank", "Yh4%pQ8*nB3!sF6^", now.AddDate(-2, 0, 0)),
+		card("visa", 5, 2025),
+		card("mir", 6, 2025),
+		card("debit", 0, 0),
+		{Info: domain.SecretInfo{Name: "notes", Type: domain.TextSecretType, CreatedAt: now.AddDate(0, 0, -400)}},
+		{Info: domain.SecretInfo{Name: "offline"}},
+	}
+
+	findings, err := domain.AuditSecrets(secrets, domain.DefaultAuditPolicy, now)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.AuditFinding{
+		{Issue: domain.OldSecretAuditIssue, Secret: "bank", Detail: "latest version is 731 days old"},
+		{Issue: domain.ReusedPasswordAuditIssue, Secret: "github", Detail: "password is also used by gitlab"},
+		{Issue: domain.ReusedPasswordAuditIssue, Secret: "gitlab", Detail: "password is also used by github"},
+		{Issue: domain.WeakPasswordAuditIssue, Secret: "mail", Detail: "estimated entropy is 33 bits, below 60"},
+		{Issue: domain.OldSecretAuditIssue, Secret: "notes", Detail: "latest version is 400 days old"},
+		{Issue: domain.ExpiredCardAuditIssue, Secret: "visa", Detail: "card expired after 05/2025"},
+	}, findings)
+
+	t.Run("disabled checks", func(t *testing.T) {
+		findings, err := domain.AuditSecrets(secrets, domain.AuditPolicy{}, now)
+		require.NoError(t, err)
+		for _, f := range findings {
+			assert.NotEqual(t, domain.OldSecretAuditIssue, f.Issue)
+			assert.NotEqual(t, domain.WeakPasswordAuditIssue, f.Issue)
+		}
+	})
+
+	t.Run("undecodable payload", func(t *testing.T) {
+		_, err := domain.AuditSecrets([]domain.Secret{
+			{Info: domain.SecretInfo{Name: "broken", Type: domain.CredentialsSecretType}, Data: "{"},
+		}, domain.DefaultAuditPolicy, now)
+		assert.ErrorContains(t, err, "failed to decode credentials 'broken'")
+	})
+}
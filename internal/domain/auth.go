@@ -2,7 +2,12 @@ package domain
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"strings"
+	"time"
 )
 
 // AuthReq represents authentication request data containing login credentials.
@@ -11,6 +16,60 @@ type AuthReq struct {
 	Password string
 }
 
+// TokenClaims contains the claims of a JWT token relevant to the client.
+type TokenClaims struct {
+	Login     string
+	ExpiresAt time.Time
+}
+
+// Expired reports whether the token is expired at the given time.
+// Tokens without an expiry claim never expire.
+func (c TokenClaims) Expired(now time.Time) bool {
+	return !c.ExpiresAt.IsZero() && !now.Before(c.ExpiresAt)
+}
+
+// ParseTokenClaims decodes the claims of a JWT token without verifying its signature.
+// The signature can only be verified by the server; the claims are used for display and expiry checks.
+func ParseTokenClaims(token string) (*TokenClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: expected 3 parts, got %d", ErrMalformedToken, len(parts))
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformedToken, err)
+	}
+
+	var raw struct {
+		Exp      json.Number `json:"exp"`
+		Login    string      `json:"login"`
+		Username string      `json:"username"`
+		Sub      string      `json:"sub"`
+	}
+	if err := json.Unmarshal(payload, &raw); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformedToken, err)
+	}
+
+	claims := &TokenClaims{Login: raw.Login}
+	if claims.Login == "" {
+		claims.Login = raw.Username
+	}
+	if claims.Login == "" {
+		claims.Login = raw.Sub
+	}
+
+	if raw.Exp != "" {
+		exp, err := raw.Exp.Float64()
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid exp claim", ErrMalformedToken)
+		}
+		claims.ExpiresAt = time.Unix(int64(exp), 0)
+	}
+
+	return claims, nil
+}
+
 // AuthService defines the interface for authentication operations.
 // Implementations should handle user registration and login processes.
 type AuthService interface {
@@ -21,6 +80,14 @@ type AuthService interface {
 	// Login authenticates a user with the provided credentials.
 	// Returns an error if authentication fails (e.g., invalid credentials).
 	Login(ctx context.Context, login, passsword string) error
+
+	// Logout removes the stored authentication token.
+	// Returns an error if the token can't be removed.
+	Logout(ctx context.Context) error
+
+	// WhoAmI retrieves the claims of the stored authentication token.
+	// Returns ErrTokenNotFound if the user is not logged in.
+	WhoAmI(ctx context.Context) (*TokenClaims, error)
 }
 
 // AuthClient defines the interface for authentication client operations.
@@ -45,6 +112,10 @@ type TokenRepository interface {
 	// SaveToken stores the authentication token securely.
 	// Returns an error if storage fails.
 	SaveToken(token string) error
+
+	// DeleteToken removes the stored authentication token.
+	// Returns nil if no token is stored.
+	DeleteToken() error
 }
 
 var (
@@ -52,4 +123,6 @@ var (
 	ErrLoginAlreayExists  = errors.New("login already exists")
 	ErrInvalidCredentials = errors.New("invalid credentials")
 	ErrUserNotFound       = errors.New("user not found")
+	ErrMalformedToken     = errors.New("malformed token")
+	ErrUnauthenticated    = errors.New("not authenticated, please log in")
 )
@@ -0,0 +1,113 @@
+package domain
+
+import (
+	"errors"
+	"io"
+	"time"
+)
+
+// BackupFormatVersion is the version of the backup archive layout written by this client.
+const BackupFormatVersion = 1
+
+// BackupManifest lists the secret versions stored in a backup archive.
+type BackupManifest struct {
+	FormatVersion int           `json:"format_version"`
+	CreatedAt     time.Time     `json:"created_at"`
+	AllVersions   bool          `json:"all_versions"`
+	Entries       []BackupEntry `json:"entries"`
+}
+
+// BackupEntry describes a secret version stored in a backup archive.
+// SHA256 is the hex encoded checksum of the content, Size its length in bytes.
+type BackupEntry struct {
+	Name      string     `json:"name"`
+	Type      SecretType `json:"type"`
+	Metadata  string     `json:"metadata,omitempty"`
+	Version   int32      `json:"version"`
+	CreatedAt time.Time  `json:"created_at"`
+	Size      int64      `json:"size"`
+	SHA256    string     `json:"sha256"`
+}
+
+// BackupWriter writes secret versions into an encrypted backup archive.
+type BackupWriter interface {
+	// Add writes the content of a secret version to the archive.
+	// Returns an error if the content cannot be read or written.
+	Add(info SecretInfo, content io.Reader) error
+
+	// Close writes the manifest and finishes the archive.
+	// The archive is incomplete and cannot be read if Close is not called.
+	Close() error
+}
+
+// BackupReader reads an encrypted backup archive.
+type BackupReader interface {
+	// Verify reads the whole archive and checks the checksum of every entry against the manifest.
+	// Returns the manifest, or an error if the archive is damaged or the passphrase is wrong.
+	Verify() (*BackupManifest, error)
+
+	// Walk calls fn with every entry and its content, in manifest order.
+	// Content that fn does not read is skipped. Walk stops at the first error.
+	Walk(fn func(entry BackupEntry, content io.Reader) error) error
+}
+
+// PlannedRestore is a backup entry with the name it is restored under and the action taken.
+// BaseVersion is the latest version of an overwritten secret, Reason explains a skip.
+type PlannedRestore struct {
+	Entry       BackupEntry
+	Name        string
+	Action      ImportAction
+	BaseVersion int32
+	Reason      string
+}
+
+// PlanRestore decides how every entry of a backup is restored next to the existing secrets.
+// Names are resolved with the policy like in PlanImport, once per secret, so all versions of a secret
+// are restored under the same name, oldest first. Only the first restored version of an overwritten secret
+// is based on its existing version, later ones follow the version created before them.
+func PlanRestore(entries []BackupEntry, existing []SecretInfo, policy ImportCollisionPolicy) ([]PlannedRestore, error) {
+	// The latest version of every secret decides its type
+	latest := make(map[string]int)
+	var names []string
+	for i, e := range entries {
+		j, seen := latest[e.Name]
+		if !seen {
+			names = append(names, e.Name)
+		}
+		if !seen || e.Version >= entries[j].Version {
+			latest[e.Name] = i
+		}
+	}
+
+	imports := make([]ImportEntry, len(names))
+	for i, name := range names {
+		e := entries[latest[name]]
+		imports[i] = ImportEntry{Name: e.Name, Type: e.Type, Metadata: e.Metadata}
+	}
+	planned, err := PlanImport(imports, existing, policy)
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]PlannedImport, len(planned))
+	for _, p := range planned {
+		byName[p.Entry.Name] = p
+	}
+
+	plan := make([]PlannedRestore, len(entries))
+	for i, e := range entries {
+		p := byName[e.Name]
+		plan[i] = PlannedRestore{Entry: e, Name: p.Name, Action: p.Action, BaseVersion: p.BaseVersion, Reason: p.Reason}
+		if p.Action == OverwriteImportAction {
+			// Later versions are based on the version this device has just created
+			p.BaseVersion = 0
+			byName[e.Name] = p
+		}
+	}
+	return plan, nil
+}
+
+var (
+	ErrInvalidBackup    = errors.New("invalid backup archive")
+	ErrBackupPassphrase = errors.New("wrong passphrase or damaged backup archive")
+	ErrBackupChecksum   = errors.New("backup entry does not match its checksum")
+)
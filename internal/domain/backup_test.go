@@ -0,0 +1,87 @@
+package domain_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/domain"
+)
+
+func TestPlanRestore(t *testing.T) {
+	existing := []domain.SecretInfo{
+		{Name: "github", Type: domain.CredentialsSecretType, Version: 7},
+		{Name: "notes", Type: domain.FileSecretType, Version: 2},
+	}
+	entries := []domain.BackupEntry{
+		{Name: "github", Type: domain.CredentialsSecretType, Version: 1},
+		{Name: "github", Type: domain.CredentialsSecretType, Version: 2},
+		{Name: "notes", Type: domain.TextSecretType, Version: 4},
+		{Name: "mail", Type: domain.CredentialsSecretType, Version: 1},
+	}
+
+	type planned struct {
+		name   string
+		action domain.ImportAction
+		base   int32
+		reason string
+	}
+	tests := []struct {
+		name   string
+		policy domain.ImportCollisionPolicy
+		want   []planned
+	}{
+		{
+			name:   "skip",
+			policy: domain.SkipImportCollisionPolicy,
+			want: []planned{
+				{name: "github", action: domain.SkipImportAction, reason: "a secret with this name exists"},
+				{name: "github", action: domain.SkipImportAction, reason: "a secret with this name exists"},
+				{name: "notes", action: domain.SkipImportAction, reason: "a secret with this name exists"},
+				{name: "mail", action: domain.CreateImportAction},
+			},
+		},
+		{
+			name:   "overwrite",
+			policy: domain.OverwriteImportCollisionPolicy,
+			want: []planned{
+				{name: "github", action: domain.OverwriteImportAction, base: 7},
+				{name: "github", action: domain.OverwriteImportAction},
+				{name: "notes", action: domain.SkipImportAction, reason: "a file secret with this name exists"},
+				{name: "mail", action: domain.CreateImportAction},
+			},
+		},
+		{
+			name:   "rename",
+			policy: domain.RenameImportCollisionPolicy,
+			want: []planned{
+				{name: "github-2", action: domain.CreateImportAction},
+				{name: "github-2", action: domain.CreateImportAction},
+				{name: "notes-2", action: domain.CreateImportAction},
+				{name: "mail", action: domain.CreateImportAction},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := domain.PlanRestore(entries, existing, tt.policy)
+			require.NoError(t, err)
+			require.Len(t, plan, len(tt.want))
+			for i, want := range tt.want {
+				assert.Equal(t, entries[i], plan[i].Entry)
+				assert.Equal(t, want, planned{
+					name:   plan[i].Name,
+					action: plan[i].Action,
+					base:   plan[i].BaseVersion,
+					reason: plan[i].Reason,
+				})
+			}
+		})
+	}
+
+	t.Run("fail", func(t *testing.T) {
+		_, err := domain.PlanRestore(entries, existing, domain.FailImportCollisionPolicy)
+		assert.ErrorIs(t, err, domain.ErrImportCollision)
+	})
+}
@@ -0,0 +1,62 @@
+package domain
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// BreachCorpus is a local corpus of passwords known from data breaches,
+// such as a downloaded Pwned Passwords hash file.
+type BreachCorpus interface {
+	// Count returns how many times the password was seen in data breaches, zero if never.
+	// Returns an error if the corpus cannot be read.
+	Count(password string) (int64, error)
+
+	// Close releases the resources of the corpus.
+	Close() error
+}
+
+// PasswordSHA1 returns the uppercase hex SHA-1 hash of a password, as used by Pwned Passwords.
+func PasswordSHA1(password string) string {
+	sum := sha1.Sum([]byte(password))
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+// AuditBreaches checks the passwords of credentials against a breach corpus.
+// Payloads are only needed for credentials. Findings are ordered like the secrets.
+func AuditBreaches(secrets []Secret, corpus BreachCorpus) ([]AuditFinding, error) {
+	var findings []AuditFinding
+	for _, secret := range secrets {
+		if secret.Info.Type != CredentialsSecretType {
+			continue
+		}
+
+		credentials, err := decodeCredentials(secret)
+		if err != nil {
+			return nil, err
+		}
+		if credentials.Password == "" {
+			continue
+		}
+
+		count, err := corpus.Count(credentials.Password)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check password of '%s': %w", secret.Info.Name, err)
+		}
+		if count > 0 {
+			findings = append(findings, AuditFinding{
+				Issue:  BreachedPasswordAuditIssue,
+				Secret: secret.Info.Name,
+				Detail: fmt.Sprintf("password was seen %d times in data breaches", count),
+			})
+		}
+	}
+	return findings, nil
+}
+
+var (
+	ErrInvalidBreachCorpus = errors.New("invalid breach corpus")
+)
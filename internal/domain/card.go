@@ -0,0 +1,205 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CardNetwork is the payment system that issued a card, detected from the card number.
+type CardNetwork string
+
+const (
+	VisaCardNetwork       CardNetwork = "visa"
+	MastercardCardNetwork CardNetwork = "mastercard"
+	MIRCardNetwork        CardNetwork = "mir"
+	AmexCardNetwork       CardNetwork = "amex"
+	UnknownCardNetwork    CardNetwork = "unknown"
+)
+
+// PaymentCardSecret represents payment card information.
+// Payloads stored before the card details were added contain only the number.
+type PaymentCardSecret struct {
+	Number      string
+	Holder      string `json:",omitempty"`
+	ExpiryMonth int    `json:",omitempty"`
+	ExpiryYear  int    `json:",omitempty"`
+	CVV         string `json:",omitempty"`
+	PIN         string `json:",omitempty"`
+	Issuer      string `json:",omitempty"`
+	Notes       string `json:",omitempty"`
+}
+
+// cardNumberLengths are the valid card number lengths of each network.
+var cardNumberLengths = map[CardNetwork][]int{
+	VisaCardNetwork:       {13, 16, 19},
+	MastercardCardNetwork: {16},
+	MIRCardNetwork:        {16, 17, 18, 19},
+	AmexCardNetwork:       {15},
+	UnknownCardNetwork:    {12, 13, 14, 15, 16, 17, 18, 19},
+}
+
+// NormalizeCardNumber removes spaces and dashes used to group card number digits.
+func NormalizeCardNumber(number string) string {
+	return strings.NewReplacer(" ", "", "-", "").Replace(number)
+}
+
+// DetectCardNetwork detects the card network from the number prefix.
+func DetectCardNetwork(number string) CardNetwork {
+	prefix := func(n int) int {
+		if len(number) < n {
+			return -1
+		}
+		p, err := strconv.Atoi(number[:n])
+		if err != nil {
+			return -1
+		}
+		return p
+	}
+
+	switch p2, p4 := prefix(2), prefix(4); {
+	case p2 == 34 || p2 == 37:
+		return AmexCardNetwork
+	case p4 >= 2200 && p4 <= 2204:
+		return MIRCardNetwork
+	case (p2 >= 51 && p2 <= 55) || (p4 >= 2221 && p4 <= 2720):
+		return MastercardCardNetwork
+	case strings.HasPrefix(number, "4"):
+		return VisaCardNetwork
+	default:
+		return UnknownCardNetwork
+	}
+}
+
+// LuhnValid reports whether the digits pass the Luhn checksum.
+func LuhnValid(digits string) bool {
+	if digits == "" {
+		return false
+	}
+
+	sum := 0
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if d < 0 || d > 9 {
+			return false
+		}
+		if double {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// Network returns the network of the card.
+func (c PaymentCardSecret) Network() CardNetwork {
+	return DetectCardNetwork(NormalizeCardNumber(c.Number))
+}
+
+// Expired reports whether the card expiry month has passed. Cards without expiry never expire.
+func (c PaymentCardSecret) Expired(now time.Time) bool {
+	if c.ExpiryYear == 0 {
+		return false
+	}
+	// Cards are valid through the last day of the expiry month
+	expiresAt := time.Date(c.ExpiryYear, time.Month(c.ExpiryMonth)+1, 1, 0, 0, 0, 0, now.Location())
+	return !now.Before(expiresAt)
+}
+
+// Validate checks the card number against the Luhn checksum and the lengths of its network,
+// and the format of expiry date, CVV and PIN. Expired cards are valid.
+func (c PaymentCardSecret) Validate() error {
+	number := NormalizeCardNumber(c.Number)
+	if !isDigits(number) || !LuhnValid(number) {
+		return fmt.Errorf("%w: checksum mismatch", ErrInvalidCardNumber)
+	}
+
+	network := DetectCardNetwork(number)
+	lengths := cardNumberLengths[network]
+	valid := false
+	for _, l := range lengths {
+		valid = valid || len(number) == l
+	}
+	if !valid {
+		return fmt.Errorf("%w: %s card numbers have %s digits, got %d",
+			ErrInvalidCardNumber, network, joinInts(lengths), len(number))
+	}
+
+	if c.ExpiryMonth != 0 || c.ExpiryYear != 0 {
+		if c.ExpiryMonth < 1 || c.ExpiryMonth > 12 {
+			return fmt.Errorf("%w: month must be between 1 and 12", ErrInvalidCardExpiry)
+		}
+		if c.ExpiryYear < 2000 || c.ExpiryYear > 2099 {
+			return fmt.Errorf("%w: year must be between 2000 and 2099", ErrInvalidCardExpiry)
+		}
+	}
+
+	cvvLength := 3
+	if network == AmexCardNetwork {
+		cvvLength = 4
+	}
+	if c.CVV != "" && (!isDigits(c.CVV) || len(c.CVV) != cvvLength) {
+		return fmt.Errorf("%w: %s cards have a %d digit CVV", ErrInvalidCardCVV, network, cvvLength)
+	}
+
+	if c.PIN != "" && (!isDigits(c.PIN) || len(c.PIN) < 4 || len(c.PIN) > 12) {
+		return fmt.Errorf("%w: PIN must have 4 to 12 digits", ErrInvalidCardPIN)
+	}
+	return nil
+}
+
+// ParseCardExpiry parses an expiry date in MM/YY or MM/YYYY format.
+func ParseCardExpiry(s string) (month, year int, err error) {
+	m, y, ok := strings.Cut(strings.TrimSpace(s), "/")
+	if !ok {
+		return 0, 0, fmt.Errorf("%w: '%s' must be MM/YY or MM/YYYY", ErrInvalidCardExpiry, s)
+	}
+
+	month, monthErr := strconv.Atoi(m)
+	year, yearErr := strconv.Atoi(y)
+	if err := errors.Join(monthErr, yearErr); err != nil || len(m) != 2 || (len(y) != 2 && len(y) != 4) {
+		return 0, 0, fmt.Errorf("%w: '%s' must be MM/YY or MM/YYYY", ErrInvalidCardExpiry, s)
+	}
+	if len(y) == 2 {
+		year += 2000
+	}
+	return month, year, nil
+}
+
+// isDigits reports whether s is a non-empty string of ASCII digits.
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// joinInts formats numbers as a human-readable list.
+func joinInts(ns []int) string {
+	parts := make([]string, len(ns))
+	for i, n := range ns {
+		parts[i] = strconv.Itoa(n)
+	}
+	if len(parts) == 1 {
+		return parts[0]
+	}
+	return strings.Join(parts[:len(parts)-1], ", ") + " or " + parts[len(parts)-1]
+}
+
+var (
+	ErrInvalidCardNumber = errors.New("invalid card number")
+	ErrInvalidCardExpiry = errors.New("invalid card expiry")
+	ErrInvalidCardCVV    = errors.New("invalid card CVV")
+	ErrInvalidCardPIN    = errors.New("invalid card PIN")
+)
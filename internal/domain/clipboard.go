@@ -0,0 +1,46 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Clipboard is the system clipboard that secrets can be copied to.
+type Clipboard interface {
+	// Copy replaces the clipboard content with the text.
+	// Returns an error if the operation fails.
+	Copy(text string) error
+
+	// Clear removes the text from the clipboard if the clipboard still holds it,
+	// so content copied in the meantime is kept. Backends that cannot read the clipboard
+	// back clear it regardless. Reports whether the clipboard was cleared.
+	// Returns an error if the operation fails.
+	Clear(text string) (bool, error)
+}
+
+// ClipboardBackend selects how secrets are copied to the clipboard.
+type ClipboardBackend string
+
+const (
+	AutoClipboardBackend   ClipboardBackend = "auto"
+	OSC52ClipboardBackend  ClipboardBackend = "osc52"
+	XClipClipboardBackend  ClipboardBackend = "xclip"
+	WLCopyClipboardBackend ClipboardBackend = "wl-copy"
+
+	DefaultClipboardBackend = OSC52ClipboardBackend
+)
+
+// ParseClipboardBackend converts a string into a ClipboardBackend.
+// Returns an error if the backend is unknown.
+func ParseClipboardBackend(s string) (ClipboardBackend, error) {
+	switch b := ClipboardBackend(s); b {
+	case AutoClipboardBackend, OSC52ClipboardBackend, XClipClipboardBackend, WLCopyClipboardBackend:
+		return b, nil
+	default:
+		return "", fmt.Errorf("%w '%s' (must be auto, osc52, xclip or wl-copy)", ErrUnknownClipboard, s)
+	}
+}
+
+var (
+	ErrUnknownClipboard = errors.New("unknown clipboard backend")
+)
@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"context"
+	"errors"
+)
+
+// ConfigSetting is a configuration key with its value.
+type ConfigSetting struct {
+	Key   string
+	Value string
+}
+
+// ConfigService defines the interface for inspecting and editing the configuration file.
+type ConfigService interface {
+	// Path returns the path of the configuration file.
+	Path() string
+
+	// ListSettings retrieves the effective value of every configuration key,
+	// after the config file, environment, profile and command line flags are applied.
+	ListSettings(ctx context.Context) ([]ConfigSetting, error)
+
+	// GetSetting retrieves the effective value of a configuration key.
+	// Returns ErrUnknownConfigKey if the key does not exist.
+	GetSetting(ctx context.Context, key string) (string, error)
+
+	// SetSetting stores a value in the configuration file.
+	// Returns ErrUnknownConfigKey if the key does not exist or ErrInvalidConfig if the value is invalid.
+	SetSetting(ctx context.Context, key, value string) error
+
+	// Validate checks the configuration file and the effective configuration.
+	// Returns ErrInvalidConfig describing the first problem found.
+	Validate(ctx context.Context) error
+}
+
+var (
+	ErrUnknownConfigKey = errors.New("unknown config key")
+	ErrInvalidConfig    = errors.New("invalid config")
+)
@@ -0,0 +1,136 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// ConflictPolicy defines how a version conflict is resolved.
+type ConflictPolicy string
+
+const (
+	PromptConflictPolicy     ConflictPolicy = "prompt"
+	KeepMineConflictPolicy   ConflictPolicy = "mine"
+	KeepTheirsConflictPolicy ConflictPolicy = "theirs"
+	MergeConflictPolicy      ConflictPolicy = "merge"
+	FailConflictPolicy       ConflictPolicy = "fail"
+)
+
+// ParseConflictPolicy converts a string into a ConflictPolicy.
+// Returns an error if the policy is unknown.
+func ParseConflictPolicy(s string) (ConflictPolicy, error) {
+	switch p := ConflictPolicy(s); p {
+	case PromptConflictPolicy, KeepMineConflictPolicy, KeepTheirsConflictPolicy, MergeConflictPolicy, FailConflictPolicy:
+		return p, nil
+	default:
+		return "", fmt.Errorf("%w '%s' (must be prompt, mine, theirs, merge or fail)", ErrUnknownConflictPolicy, s)
+	}
+}
+
+// VersionConflictError is returned when a secret was changed on the server
+// after the version a write operation is based on.
+// Base and Theirs are only populated for non-streamable secrets.
+type VersionConflictError struct {
+	Name          string
+	BaseVersion   int32
+	LatestVersion int32
+	Base          *Secret
+	Theirs        *Secret
+}
+
+// Error implements the error interface.
+func (e *VersionConflictError) Error() string {
+	return fmt.Sprintf("secret '%s' was changed on the server: based on version %d, latest version is %d",
+		e.Name, e.BaseVersion, e.LatestVersion)
+}
+
+// Is reports whether the error matches ErrVersionConflict.
+func (e *VersionConflictError) Is(target error) bool {
+	return target == ErrVersionConflict
+}
+
+// MergeSecretData performs a field-level three-way merge of JSON object secret payloads,
+// such as credentials and payment cards. Fields changed only on one side are taken from that side.
+// Fields changed on both sides keep the value from mine and are returned as conflicting fields.
+// An empty base means the secret has no common ancestor.
+func MergeSecretData(base, mine, theirs string) (merged string, conflicts []string, err error) {
+	baseFields, err := decodeFields(base)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to decode base version: %w", err)
+	}
+	mineFields, err := decodeFields(mine)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to decode local version: %w", err)
+	}
+	theirFields, err := decodeFields(theirs)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to decode server version: %w", err)
+	}
+
+	keys := make(map[string]struct{})
+	for _, fields := range []map[string]json.RawMessage{baseFields, mineFields, theirFields} {
+		for k := range fields {
+			keys[k] = struct{}{}
+		}
+	}
+
+	result := make(map[string]json.RawMessage, len(keys))
+	for k := range keys {
+		b, m, t := baseFields[k], mineFields[k], theirFields[k]
+		switch {
+		case rawEqual(m, t), rawEqual(t, b):
+			if m != nil {
+				result[k] = m
+			}
+		case rawEqual(m, b):
+			if t != nil {
+				result[k] = t
+			}
+		default:
+			conflicts = append(conflicts, k)
+			if m != nil {
+				result[k] = m
+			}
+		}
+	}
+	sort.Strings(conflicts)
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to encode merged version: %w", err)
+	}
+	return string(data), conflicts, nil
+}
+
+// decodeFields decodes a JSON object into raw fields. An empty string is an empty object.
+func decodeFields(data string) (map[string]json.RawMessage, error) {
+	fields := make(map[string]json.RawMessage)
+	if data == "" {
+		return fields, nil
+	}
+	if err := json.Unmarshal([]byte(data), &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// rawEqual compares two raw JSON values ignoring insignificant whitespace.
+func rawEqual(a, b json.RawMessage) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+
+	var ca, cb bytes.Buffer
+	if json.Compact(&ca, a) != nil || json.Compact(&cb, b) != nil {
+		return bytes.Equal(a, b)
+	}
+	return bytes.Equal(ca.Bytes(), cb.Bytes())
+}
+
+var (
+	ErrVersionConflict       = errors.New("version conflict")
+	ErrUnknownConflictPolicy = errors.New("unknown conflict policy")
+)
@@ -0,0 +1,46 @@
+package domain
+
+import "errors"
+
+// KeyParams holds the parameters required to re-derive the master encryption key.
+// It never contains the key itself, only the KDF settings and a key check value.
+type KeyParams struct {
+	Algorithm string
+	Salt      []byte
+	Time      uint32
+	Memory    uint32
+	Threads   uint8
+	KeyCheck  []byte
+}
+
+// KeyRepository defines the interface for key parameters storage operations.
+// Implementations should persist the parameters locally, never on the server.
+type KeyRepository interface {
+	// GetKeyParams retrieves the stored key derivation parameters.
+	// Returns ErrKeyParamsNotFound if no parameters have been stored yet.
+	GetKeyParams() (*KeyParams, error)
+
+	// SaveKeyParams stores the key derivation parameters.
+	// Returns an error if storage fails.
+	SaveKeyParams(params KeyParams) error
+}
+
+// SecretCipher defines the interface for client-side authenticated encryption.
+// Implementations are used to encrypt secret payloads before they leave the client.
+type SecretCipher interface {
+	// Seal encrypts and authenticates plaintext together with additionalData.
+	// Returns the ciphertext or an error if encryption fails.
+	Seal(plaintext, additionalData []byte) ([]byte, error)
+
+	// Open authenticates and decrypts ciphertext produced by Seal.
+	// Returns the plaintext or an error if the ciphertext was tampered with.
+	Open(ciphertext, additionalData []byte) ([]byte, error)
+}
+
+var (
+	ErrKeyParamsNotFound     = errors.New("key parameters not found in storage")
+	ErrInvalidMasterPassword = errors.New("invalid master password")
+	ErrDecryptionFailed      = errors.New("failed to decrypt secret data")
+	ErrUnencryptedSecret     = errors.New("secret is stored unencrypted")
+	ErrSecretMismatch        = errors.New("server returned another secret than requested")
+)
@@ -0,0 +1,147 @@
+package domain
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// GitCredentialSecretPrefix prefixes the URL in the names of secrets created for git credentials.
+const GitCredentialSecretPrefix = "git:"
+
+// GitCredential is a credential exchanged with git over its credential helper protocol.
+// Path is only sent by git if credential.useHttpPath is set.
+type GitCredential struct {
+	Protocol string
+	Host     string
+	Path     string
+	Username string
+	Password string
+}
+
+// ParseGitCredential reads the attributes git writes to a credential helper, one key=value per line,
+// up to an empty line or the end of input. A url attribute sets the attributes it contains,
+// attributes unknown to the helper are ignored.
+func ParseGitCredential(r io.Reader) (GitCredential, error) {
+	var c GitCredential
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if line == "" {
+			break
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return GitCredential{}, fmt.Errorf("%w: line '%s' is not key=value", ErrInvalidGitCredential, line)
+		}
+
+		switch key {
+		case "protocol":
+			c.Protocol = value
+		case "host":
+			c.Host = value
+		case "path":
+			c.Path = value
+		case "username":
+			c.Username = value
+		case "password":
+			c.Password = value
+		case "url":
+			u, err := url.Parse(value)
+			if err != nil || u.Scheme == "" {
+				return GitCredential{}, fmt.Errorf("%w: invalid url '%s'", ErrInvalidGitCredential, value)
+			}
+			c.Protocol, c.Host, c.Path = u.Scheme, u.Host, strings.TrimPrefix(u.Path, "/")
+			if u.User != nil {
+				c.Username = u.User.Username()
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return GitCredential{}, err
+	}
+	return c, nil
+}
+
+// WriteTo writes the username and password in the format git reads from a credential helper.
+func (c GitCredential) WriteTo(w io.Writer) (int64, error) {
+	n, err := fmt.Fprintf(w, "username=%s\npassword=%s\n", c.Username, c.Password)
+	return int64(n), err
+}
+
+// URL returns the remote the credential is for, as protocol://host[/path].
+func (c GitCredential) URL() string {
+	u := c.Protocol + "://" + c.Host
+	if c.Path != "" {
+		u += "/" + strings.Trim(c.Path, "/")
+	}
+	return u
+}
+
+// SecretName returns the name of the secret the credential is stored in by convention.
+func (c GitCredential) SecretName() string {
+	return GitCredentialSecretPrefix + c.URL()
+}
+
+// SecretNames returns the names of the secrets the credential is looked up in by convention,
+// the one of the URL with the path first.
+func (c GitCredential) SecretNames() []string {
+	urls := c.urls()
+	names := make([]string, len(urls))
+	for i, u := range urls {
+		names[i] = GitCredentialSecretPrefix + u
+	}
+	return names
+}
+
+// urls returns the URL of the remote, followed by the URL of the host if the credential has a path.
+func (c GitCredential) urls() []string {
+	urls := []string{c.URL()}
+	if c.Path != "" {
+		host := c
+		host.Path = ""
+		urls = append(urls, host.URL())
+	}
+	return urls
+}
+
+// MatchGitCredential finds the credentials secret for the remote of a credential.
+// A secret named after the URL is preferred over one whose metadata is the URL,
+// and a URL with the path over the one of the host; several secrets with the same metadata
+// are resolved by name. Returns false if no credentials secret matches.
+func MatchGitCredential(infos []SecretInfo, c GitCredential) (SecretInfo, bool) {
+	urls := c.urls()
+
+	byName := make(map[string]SecretInfo)
+	byMetadata := make(map[string][]SecretInfo)
+	for _, info := range infos {
+		if info.Type != CredentialsSecretType {
+			continue
+		}
+		byName[info.Name] = info
+		if metadata := strings.TrimSuffix(strings.TrimSpace(info.Metadata), "/"); metadata != "" {
+			byMetadata[metadata] = append(byMetadata[metadata], info)
+		}
+	}
+
+	for _, u := range urls {
+		if info, ok := byName[GitCredentialSecretPrefix+u]; ok {
+			return info, true
+		}
+	}
+	for _, u := range urls {
+		if matches := byMetadata[u]; len(matches) > 0 {
+			sort.Slice(matches, func(i, j int) bool { return matches[i].Name < matches[j].Name })
+			return matches[0], true
+		}
+	}
+	return SecretInfo{}, false
+}
+
+var (
+	ErrInvalidGitCredential = errors.New("invalid git credential")
+)
@@ -0,0 +1,136 @@
+package domain_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/domain"
+)
+
+func TestParseGitCredential(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    domain.GitCredential
+		wantErr bool
+	}{
+		{
+			name:  "attributes",
+			input: "protocol=https\nhost=github.com\npath=org/repo.git\nusername=octo\npassword=a=b\n\n",
+			want: domain.GitCredential{
+				Protocol: "https", Host: "github.com", Path: "org/repo.git", Username: "octo", Password: "a=b",
+			},
+		},
+		{
+			name:  "url and unknown attributes",
+			input: "capability[]=authtype\nurl=https://octo@example.com:8443/repo.git\nwwwauth[]=Basic\n",
+			want:  domain.GitCredential{Protocol: "https", Host: "example.com:8443", Path: "repo.git", Username: "octo"},
+		},
+		{
+			name:  "stops at an empty line",
+			input: "protocol=https\r\nhost=github.com\r\n\r\nhost=example.com\r\n",
+			want:  domain.GitCredential{Protocol: "https", Host: "github.com"},
+		},
+		{
+			name:    "not key=value",
+			input:   "protocol https\n",
+			wantErr: true,
+		},
+		{
+			name:    "url without protocol",
+			input:   "url=github.com\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := domain.ParseGitCredential(strings.NewReader(tt.input))
+			if tt.wantErr {
+				assert.ErrorIs(t, err, domain.ErrInvalidGitCredential)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestGitCredential_SecretName(t *testing.T) {
+	c := domain.GitCredential{Protocol: "https", Host: "github.com"}
+	assert.Equal(t, "git:https://github.com", c.SecretName())
+
+	c.Path = "/org/repo.git/"
+	assert.Equal(t, "git:https://github.com/org/repo.git", c.SecretName())
+	assert.Equal(t, []string{"git:https://github.com/org/repo.git", "git:https://github.com"}, c.SecretNames())
+
+	c.Path = ""
+	assert.Equal(t, []string{"git:https://github.com"}, c.SecretNames())
+}
+
+func TestMatchGitCredential(t *testing.T) {
+	infos := []domain.SecretInfo{
+		{Name: "git:https://github.com", Type: domain.CredentialsSecretType},
+		{Name: "git:https://github.com/org/private.git", Type: domain.CredentialsSecretType},
+		{Name: "work-gitlab", Type: domain.CredentialsSecretType, Metadata: " https://gitlab.example.com/ "},
+		{Name: "old-gitlab", Type: domain.CredentialsSecretType, Metadata: "https://gitlab.example.com"},
+		{Name: "git:https://bitbucket.org", Type: domain.TextSecretType},
+		{Name: "mirror", Type: domain.CredentialsSecretType, Metadata: "https://github.com/org/mirror.git"},
+	}
+
+	tests := []struct {
+		name   string
+		cred   domain.GitCredential
+		want   string
+		wantOK bool
+	}{
+		{
+			name:   "host",
+			cred:   domain.GitCredential{Protocol: "https", Host: "github.com"},
+			want:   "git:https://github.com",
+			wantOK: true,
+		},
+		{
+			name:   "path before host",
+			cred:   domain.GitCredential{Protocol: "https", Host: "github.com", Path: "org/private.git"},
+			want:   "git:https://github.com/org/private.git",
+			wantOK: true,
+		},
+		{
+			name:   "host of an unknown path",
+			cred:   domain.GitCredential{Protocol: "https", Host: "github.com", Path: "org/public.git"},
+			want:   "git:https://github.com",
+			wantOK: true,
+		},
+		{
+			name:   "name before metadata",
+			cred:   domain.GitCredential{Protocol: "https", Host: "github.com", Path: "org/mirror.git"},
+			want:   "git:https://github.com",
+			wantOK: true,
+		},
+		{
+			name:   "metadata resolved by name",
+			cred:   domain.GitCredential{Protocol: "https", Host: "gitlab.example.com", Path: "team/app.git"},
+			want:   "old-gitlab",
+			wantOK: true,
+		},
+		{
+			name: "only credentials",
+			cred: domain.GitCredential{Protocol: "https", Host: "bitbucket.org"},
+		},
+		{
+			name: "protocol must match",
+			cred: domain.GitCredential{Protocol: "http", Host: "github.com"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := domain.MatchGitCredential(infos, tt.cred)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, got.Name)
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"strconv"
+)
+
+// ImportEntry is an entry of another password manager mapped onto a secret.
+// Content is the JSON payload of credentials and payment cards, or the raw content of texts and files.
+type ImportEntry struct {
+	Name     string
+	Type     SecretType
+	Metadata string
+	Content  []byte
+}
+
+// SkippedImportEntry is an entry that could not be mapped onto a secret.
+type SkippedImportEntry struct {
+	Name   string
+	Reason string
+}
+
+// Importer parses the export of another password manager.
+type Importer interface {
+	// Parse reads the export at path, a file or a directory depending on the format.
+	// Entries that cannot be imported are returned as skipped together with the reason.
+	// Returns an error if the export cannot be read.
+	Parse(path string) ([]ImportEntry, []SkippedImportEntry, error)
+}
+
+// NewCredentialsImportEntry creates an import entry holding credentials.
+func NewCredentialsImportEntry(name, metadata string, credentials CredentialsSecret) ImportEntry {
+	content, _ := json.Marshal(credentials)
+	return ImportEntry{Name: name, Type: CredentialsSecretType, Metadata: metadata, Content: content}
+}
+
+// NewPaymentCardImportEntry creates an import entry holding a payment card.
+func NewPaymentCardImportEntry(name, metadata string, card PaymentCardSecret) ImportEntry {
+	content, _ := json.Marshal(card)
+	return ImportEntry{Name: name, Type: PaymentCardSecretType, Metadata: metadata, Content: content}
+}
+
+// ImportCollisionPolicy defines what happens to an imported entry whose name is already taken.
+type ImportCollisionPolicy string
+
+const (
+	SkipImportCollisionPolicy      ImportCollisionPolicy = "skip"
+	OverwriteImportCollisionPolicy ImportCollisionPolicy = "overwrite"
+	RenameImportCollisionPolicy    ImportCollisionPolicy = "rename"
+	FailImportCollisionPolicy      ImportCollisionPolicy = "fail"
+)
+
+// ParseImportCollisionPolicy converts a string into an ImportCollisionPolicy.
+// Returns an error if the policy is unknown.
+func ParseImportCollisionPolicy(s string) (ImportCollisionPolicy, error) {
+	switch p := ImportCollisionPolicy(s); p {
+	case SkipImportCollisionPolicy, OverwriteImportCollisionPolicy, RenameImportCollisionPolicy, FailImportCollisionPolicy:
+		return p, nil
+	default:
+		return "", fmt.Errorf("unknown collision policy '%s' (must be skip, overwrite, rename or fail)", s)
+	}
+}
+
+// ImportAction is what an import does with an entry.
+type ImportAction string
+
+const (
+	CreateImportAction    ImportAction = "create"
+	OverwriteImportAction ImportAction = "overwrite"
+	SkipImportAction      ImportAction = "skip"
+)
+
+// PlannedImport is an import entry with the name it is stored under and the action taken.
+// BaseVersion is the latest version of an overwritten secret, Reason explains a skip.
+type PlannedImport struct {
+	Entry       ImportEntry
+	Name        string
+	Action      ImportAction
+	BaseVersion int32
+	Reason      string
+}
+
+// PlanImport decides how every entry is imported next to the existing secrets.
+// Names taken by an existing secret or an earlier entry are resolved with the policy:
+// skip keeps the existing secret, overwrite stores the entry as a new version of an existing secret
+// of the same type, rename stores it under the first free name with a numeric suffix,
+// and fail returns ErrImportCollision.
+func PlanImport(entries []ImportEntry, existing []SecretInfo, policy ImportCollisionPolicy) ([]PlannedImport, error) {
+	taken := make(map[string]*SecretInfo, len(existing)+len(entries))
+	for i := range existing {
+		taken[existing[i].Name] = &existing[i]
+	}
+	imported := make(map[string]bool, len(entries))
+
+	plan := make([]PlannedImport, 0, len(entries))
+	for _, entry := range entries {
+		p := PlannedImport{Entry: entry, Name: entry.Name, Action: CreateImportAction}
+
+		info, exists := taken[entry.Name]
+		duplicate := imported[entry.Name]
+		switch {
+		case !exists && !duplicate:
+		case policy == FailImportCollisionPolicy:
+			return nil, fmt.Errorf("%w: '%s'", ErrImportCollision, entry.Name)
+		case policy == RenameImportCollisionPolicy:
+			p.Name = freeImportName(entry, func(name string) bool { return taken[name] != nil || imported[name] })
+		case duplicate:
+			p.Action, p.Reason = SkipImportAction, "duplicate name in the import"
+		case policy == OverwriteImportCollisionPolicy && info.Type != entry.Type:
+			p.Action, p.Reason = SkipImportAction, fmt.Sprintf("a %s secret with this name exists", info.Type)
+		case policy == OverwriteImportCollisionPolicy:
+			p.Action, p.BaseVersion = OverwriteImportAction, info.Version
+		default:
+			p.Action, p.Reason = SkipImportAction, "a secret with this name exists"
+		}
+
+		if p.Action != SkipImportAction {
+			imported[p.Name] = true
+		}
+		plan = append(plan, p)
+	}
+	return plan, nil
+}
+
+// freeImportName returns the first free name made of the entry name and a numeric suffix.
+// The suffix of a file goes before its extension, so that the file keeps its type when downloaded.
+func freeImportName(entry ImportEntry, isTaken func(name string) bool) string {
+	base, ext := entry.Name, ""
+	if entry.Type == FileSecretType {
+		ext = path.Ext(entry.Name)
+		base = entry.Name[:len(entry.Name)-len(ext)]
+	}
+	for i := 2; ; i++ {
+		name := base + "-" + strconv.Itoa(i) + ext
+		if !isTaken(name) {
+			return name
+		}
+	}
+}
+
+var (
+	ErrImportCollision = errors.New("a secret with this name already exists")
+	ErrUnknownImporter = errors.New("unknown import format")
+	ErrInvalidImport   = errors.New("invalid import file")
+	ErrEncryptedImport = errors.New("encrypted exports are not supported")
+)
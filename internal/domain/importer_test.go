@@ -0,0 +1,114 @@
+package domain_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/domain"
+)
+
+func TestPlanImport(t *testing.T) {
+	existing := []domain.SecretInfo{
+		{Name: "github", Type: domain.CredentialsSecretType, Version: 3},
+		{Name: "notes", Type: domain.TextSecretType, Version: 1},
+		{Name: "id_rsa.pub", Type: domain.FileSecretType, Version: 1},
+		{Name: "id_rsa-2.pub", Type: domain.FileSecretType, Version: 1},
+	}
+	entries := []domain.ImportEntry{
+		domain.NewCredentialsImportEntry("github", "", domain.CredentialsSecret{Login: "octocat", Password: "pass"}),
+		domain.NewCredentialsImportEntry("notes", "", domain.CredentialsSecret{Login: "user", Password: "pass"}),
+		domain.NewCredentialsImportEntry("mail", "", domain.CredentialsSecret{Login: "user", Password: "pass"}),
+		domain.NewCredentialsImportEntry("mail", "", domain.CredentialsSecret{Login: "other", Password: "pass"}),
+		{Name: "id_rsa.pub", Type: domain.FileSecretType, Content: []byte("ssh-ed25519 AAAA")},
+	}
+
+	type planned struct {
+		name   string
+		action domain.ImportAction
+		base   int32
+		reason string
+	}
+	tests := []struct {
+		name   string
+		policy domain.ImportCollisionPolicy
+		want   []planned
+	}{
+		{
+			name:   "skip",
+			policy: domain.SkipImportCollisionPolicy,
+			want: []planned{
+				{name: "github", action: domain.SkipImportAction, reason: "a secret with this name exists"},
+				{name: "notes", action: domain.SkipImportAction, reason: "a secret with this name exists"},
+				{name: "mail", action: domain.CreateImportAction},
+				{name: "mail", action: domain.SkipImportAction, reason: "duplicate name in the import"},
+				{name: "id_rsa.pub", action: domain.SkipImportAction, reason: "a secret with this name exists"},
+			},
+		},
+		{
+			name:   "overwrite",
+			policy: domain.OverwriteImportCollisionPolicy,
+			want: []planned{
+				{name: "github", action: domain.OverwriteImportAction, base: 3},
+				{name: "notes", action: domain.SkipImportAction, reason: "a text secret with this name exists"},
+				{name: "mail", action: domain.CreateImportAction},
+				{name: "mail", action: domain.SkipImportAction, reason: "duplicate name in the import"},
+				{name: "id_rsa.pub", action: domain.OverwriteImportAction, base: 1},
+			},
+		},
+		{
+			name:   "rename",
+			policy: domain.RenameImportCollisionPolicy,
+			want: []planned{
+				{name: "github-2", action: domain.CreateImportAction},
+				{name: "notes-2", action: domain.CreateImportAction},
+				{name: "mail", action: domain.CreateImportAction},
+				{name: "mail-2", action: domain.CreateImportAction},
+				{name: "id_rsa-3.pub", action: domain.CreateImportAction},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := domain.PlanImport(entries, existing, tt.policy)
+			require.NoError(t, err)
+
+			got := make([]planned, len(plan))
+			for i, p := range plan {
+				assert.Equal(t, entries[i], p.Entry)
+				got[i] = planned{name: p.Name, action: p.Action, base: p.BaseVersion, reason: p.Reason}
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	t.Run("fail", func(t *testing.T) {
+		_, err := domain.PlanImport(entries, existing, domain.FailImportCollisionPolicy)
+		assert.ErrorIs(t, err, domain.ErrImportCollision)
+		assert.EqualError(t, err, "a secret with this name already exists: 'github'")
+	})
+}
+
+func TestNewCredentialsImportEntry(t *testing.T) {
+	credentials := domain.CredentialsSecret{Login: "octocat", Password: "pass", URL: "https://github.com"}
+	entry := domain.NewCredentialsImportEntry("github", "work", credentials)
+
+	assert.Equal(t, "github", entry.Name)
+	assert.Equal(t, "work", entry.Metadata)
+	assert.Equal(t, domain.CredentialsSecretType, entry.Type)
+
+	var decoded domain.CredentialsSecret
+	require.NoError(t, json.Unmarshal(entry.Content, &decoded))
+	assert.Equal(t, credentials, decoded)
+}
+
+func TestParseImportCollisionPolicy(t *testing.T) {
+	policy, err := domain.ParseImportCollisionPolicy("rename")
+	require.NoError(t, err)
+	assert.Equal(t, domain.RenameImportCollisionPolicy, policy)
+
+	_, err = domain.ParseImportCollisionPolicy("merge")
+	assert.EqualError(t, err, "unknown collision policy 'merge' (must be skip, overwrite, rename or fail)")
+}
@@ -0,0 +1,208 @@
+package domain
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// OTPType is the kind of one-time password.
+type OTPType string
+
+const (
+	TOTPType OTPType = "totp"
+	HOTPType OTPType = "hotp"
+)
+
+// OTP algorithm defaults from the Key URI Format.
+const (
+	defaultOTPAlgorithm = "SHA1"
+	defaultOTPDigits    = 6
+	defaultOTPPeriod    = 30 * time.Second
+)
+
+// OTPKey is a one-time password seed with its parameters, as described by an otpauth:// URI.
+type OTPKey struct {
+	Type      OTPType
+	Issuer    string
+	Account   string
+	Secret    []byte
+	Algorithm string
+	Digits    int
+	Period    time.Duration
+	Counter   uint64
+}
+
+// ParseOTPURI parses an otpauth://totp/... or otpauth://hotp/... key URI.
+func ParseOTPURI(uri string) (*OTPKey, error) {
+	u, err := url.Parse(strings.TrimSpace(uri))
+	if err != nil || u.Scheme != "otpauth" {
+		return nil, fmt.Errorf("%w: not an otpauth URI", ErrInvalidOTP)
+	}
+
+	key := &OTPKey{
+		Type:      OTPType(strings.ToLower(u.Host)),
+		Algorithm: defaultOTPAlgorithm,
+		Digits:    defaultOTPDigits,
+		Period:    defaultOTPPeriod,
+	}
+	if key.Type != TOTPType && key.Type != HOTPType {
+		return nil, fmt.Errorf("%w: unknown type '%s' (must be totp or hotp)", ErrInvalidOTP, u.Host)
+	}
+
+	label := strings.TrimPrefix(u.Path, "/")
+	if issuer, account, ok := strings.Cut(label, ":"); ok {
+		key.Issuer, key.Account = strings.TrimSpace(issuer), strings.TrimSpace(account)
+	} else {
+		key.Account = label
+	}
+
+	q := u.Query()
+	if issuer := q.Get("issuer"); issuer != "" {
+		key.Issuer = issuer
+	}
+	if key.Secret, err = DecodeOTPSecret(q.Get("secret")); err != nil {
+		return nil, err
+	}
+	if algorithm := q.Get("algorithm"); algorithm != "" {
+		key.Algorithm = strings.ToUpper(algorithm)
+	}
+	if digits := q.Get("digits"); digits != "" {
+		if key.Digits, err = strconv.Atoi(digits); err != nil {
+			return nil, fmt.Errorf("%w: digits must be a number", ErrInvalidOTP)
+		}
+	}
+	if period := q.Get("period"); period != "" {
+		seconds, err := strconv.Atoi(period)
+		if err != nil || seconds <= 0 {
+			return nil, fmt.Errorf("%w: period must be a positive number of seconds", ErrInvalidOTP)
+		}
+		key.Period = time.Duration(seconds) * time.Second
+	}
+	if counter := q.Get("counter"); counter != "" {
+		if key.Counter, err = strconv.ParseUint(counter, 10, 64); err != nil {
+			return nil, fmt.Errorf("%w: counter must be a non-negative number", ErrInvalidOTP)
+		}
+	} else if key.Type == HOTPType {
+		return nil, fmt.Errorf("%w: hotp URIs require a counter", ErrInvalidOTP)
+	}
+
+	if err := key.validate(); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// DecodeOTPSecret decodes a base32 seed. Padding, spaces and letter case are ignored.
+func DecodeOTPSecret(secret string) ([]byte, error) {
+	secret = strings.ToUpper(strings.NewReplacer(" ", "", "=", "").Replace(secret))
+	if secret == "" {
+		return nil, fmt.Errorf("%w: secret is missing", ErrInvalidOTP)
+	}
+
+	decoded, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+	if err != nil {
+		return nil, fmt.Errorf("%w: secret is not valid base32", ErrInvalidOTP)
+	}
+	return decoded, nil
+}
+
+// URI encodes the key as an otpauth:// URI.
+func (k OTPKey) URI() string {
+	label := k.Account
+	if k.Issuer != "" {
+		label = k.Issuer + ":" + k.Account
+	}
+
+	q := url.Values{}
+	q.Set("secret", base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(k.Secret))
+	if k.Issuer != "" {
+		q.Set("issuer", k.Issuer)
+	}
+	q.Set("algorithm", k.Algorithm)
+	q.Set("digits", strconv.Itoa(k.Digits))
+	if k.Type == HOTPType {
+		q.Set("counter", strconv.FormatUint(k.Counter, 10))
+	} else {
+		q.Set("period", strconv.Itoa(int(k.Period/time.Second)))
+	}
+
+	u := url.URL{Scheme: "otpauth", Host: string(k.Type), Path: "/" + label, RawQuery: q.Encode()}
+	return u.String()
+}
+
+// TOTP computes the RFC 6238 code valid at the given time and the time left until it changes.
+func (k OTPKey) TOTP(now time.Time) (code string, remaining time.Duration, err error) {
+	period := int64(k.Period / time.Second)
+	step := now.Unix() / period
+	code, err = k.HOTP(uint64(step))
+	if err != nil {
+		return "", 0, err
+	}
+
+	next := time.Unix((step+1)*period, 0)
+	return code, next.Sub(now), nil
+}
+
+// HOTP computes the RFC 4226 code for the counter.
+func (k OTPKey) HOTP(counter uint64) (string, error) {
+	newHash, err := otpHash(k.Algorithm)
+	if err != nil {
+		return "", err
+	}
+
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], counter)
+	mac := hmac.New(newHash, k.Secret)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	// Dynamic truncation
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for range k.Digits {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", k.Digits, value%mod), nil
+}
+
+// validate checks the key parameters.
+func (k OTPKey) validate() error {
+	if _, err := otpHash(k.Algorithm); err != nil {
+		return err
+	}
+	if k.Digits < 6 || k.Digits > 8 {
+		return fmt.Errorf("%w: digits must be between 6 and 8", ErrInvalidOTP)
+	}
+	return nil
+}
+
+// otpHash returns the hash function of an OTP algorithm.
+func otpHash(algorithm string) (func() hash.Hash, error) {
+	switch algorithm {
+	case "SHA1":
+		return sha1.New, nil
+	case "SHA256":
+		return sha256.New, nil
+	case "SHA512":
+		return sha512.New, nil
+	default:
+		return nil, fmt.Errorf("%w: unsupported algorithm '%s' (must be SHA1, SHA256 or SHA512)", ErrInvalidOTP, algorithm)
+	}
+}
+
+var (
+	ErrInvalidOTP = errors.New("invalid one-time password seed")
+)
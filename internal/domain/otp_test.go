@@ -0,0 +1,98 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/domain"
+)
+
+func TestOTPKey_HOTP(t *testing.T) {
+	// RFC 4226 appendix D
+	key := domain.OTPKey{Secret: []byte("12345678901234567890"), Algorithm: "SHA1", Digits: 6}
+	expected := []string{"755224", "287082", "359152", "969429", "338314", "254676", "287922", "162583", "399871", "520489"}
+
+	for counter, code := range expected {
+		got, err := key.HOTP(uint64(counter))
+		require.NoError(t, err)
+		assert.Equal(t, code, got, "counter %d", counter)
+	}
+}
+
+func TestOTPKey_TOTP(t *testing.T) {
+	// RFC 6238 appendix B
+	secrets := map[string][]byte{
+		"SHA1":   []byte("12345678901234567890"),
+		"SHA256": []byte("12345678901234567890123456789012"),
+		"SHA512": []byte("1234567890123456789012345678901234567890123456789012345678901234"),
+	}
+	tests := []struct {
+		unix      int64
+		algorithm string
+		code      string
+	}{
+		{59, "SHA1", "94287082"},
+		{59, "SHA256", "46119246"},
+		{59, "SHA512", "90693936"},
+		{1111111109, "SHA1", "07081804"},
+		{1234567890, "SHA256", "91819424"},
+		{20000000000, "SHA512", "47863826"},
+	}
+
+	for _, tt := range tests {
+		key := domain.OTPKey{Secret: secrets[tt.algorithm], Algorithm: tt.algorithm, Digits: 8, Period: 30 * time.Second}
+		code, remaining, err := key.TOTP(time.Unix(tt.unix, 0))
+		require.NoError(t, err)
+		assert.Equal(t, tt.code, code, "%s at %d", tt.algorithm, tt.unix)
+		assert.Equal(t, time.Duration(30-tt.unix%30)*time.Second, remaining)
+	}
+}
+
+func TestParseOTPURI(t *testing.T) {
+	tests := []struct {
+		name        string
+		uri         string
+		expected    *domain.OTPKey
+		expectedErr string
+	}{
+		{
+			name: "totp with defaults",
+			uri:  "otpauth://totp/Example:alice@example.com?secret=JBSWY3DPEHPK3PXP&issuer=Example",
+			expected: &domain.OTPKey{
+				Type: domain.TOTPType, Issuer: "Example", Account: "alice@example.com",
+				Secret: []byte("Hello!\xde\xad\xbe\xef"), Algorithm: "SHA1", Digits: 6, Period: 30 * time.Second,
+			},
+		},
+		{
+			name: "hotp with parameters",
+			uri:  "otpauth://hotp/bob?secret=jbswy3dpehpk3pxp&algorithm=sha256&digits=8&counter=7",
+			expected: &domain.OTPKey{
+				Type: domain.HOTPType, Account: "bob",
+				Secret: []byte("Hello!\xde\xad\xbe\xef"), Algorithm: "SHA256", Digits: 8, Period: 30 * time.Second, Counter: 7,
+			},
+		},
+		{name: "not otpauth", uri: "https://example.com", expectedErr: "invalid one-time password seed: not an otpauth URI"},
+		{name: "missing secret", uri: "otpauth://totp/a", expectedErr: "invalid one-time password seed: secret is missing"},
+		{name: "hotp without counter", uri: "otpauth://hotp/a?secret=JBSWY3DP", expectedErr: "invalid one-time password seed: hotp URIs require a counter"},
+		{name: "unknown algorithm", uri: "otpauth://totp/a?secret=JBSWY3DP&algorithm=MD5", expectedErr: "invalid one-time password seed: unsupported algorithm 'MD5' (must be SHA1, SHA256 or SHA512)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := domain.ParseOTPURI(tt.uri)
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, key)
+
+			// Encoding and parsing again keeps every parameter
+			reparsed, err := domain.ParseOTPURI(key.URI())
+			require.NoError(t, err)
+			assert.Equal(t, key, reparsed)
+		})
+	}
+}
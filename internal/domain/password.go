@@ -0,0 +1,239 @@
+package domain
+
+import (
+	"bufio"
+	"crypto/rand"
+	_ "embed"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+	"sync"
+)
+
+// Character classes of generated passwords.
+const (
+	lowerChars  = "abcdefghijklmnopqrstuvwxyz"
+	upperChars  = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	digitChars  = "0123456789"
+	symbolChars = "!#$%&*+-.:;<=>?@^_~()[]{}/|"
+)
+
+// ambiguousChars are characters that are easily confused with each other when read or typed.
+const ambiguousChars = "Il1|O0o"
+
+// Generated password limits.
+const (
+	MaxPasswordLength  = 1024
+	MaxPassphraseWords = 64
+)
+
+// PasswordPolicy describes a random password: its length and the character classes it is made of.
+// Every enabled class is used at least once.
+type PasswordPolicy struct {
+	Length           int
+	Lower            bool
+	Upper            bool
+	Digits           bool
+	Symbols          bool
+	ExcludeAmbiguous bool
+}
+
+// PassphrasePolicy describes a diceware passphrase of words from the EFF large wordlist.
+type PassphrasePolicy struct {
+	Words     int
+	Separator string
+}
+
+// DefaultPasswordPolicy is a 20 character password made of all character classes.
+var DefaultPasswordPolicy = PasswordPolicy{Length: 20, Lower: true, Upper: true, Digits: true, Symbols: true}
+
+// DefaultPassphrasePolicy is a passphrase of 6 words separated by dashes.
+var DefaultPassphrasePolicy = PassphrasePolicy{Words: 6, Separator: "-"}
+
+// GeneratePassword generates a random password using crypto/rand.
+// It returns the password and its estimated entropy in bits.
+func GeneratePassword(policy PasswordPolicy) (string, float64, error) {
+	classes := policy.classes()
+	if len(classes) == 0 {
+		return "", 0, fmt.Errorf("%w: no character classes enabled", ErrInvalidPasswordPolicy)
+	}
+	if policy.Length < len(classes) || policy.Length > MaxPasswordLength {
+		return "", 0, fmt.Errorf("%w: length must be between %d and %d",
+			ErrInvalidPasswordPolicy, len(classes), MaxPasswordLength)
+	}
+
+	alphabet := strings.Join(classes, "")
+	password := make([]byte, policy.Length)
+	for {
+		for i := range password {
+			n, err := randomIndex(len(alphabet))
+			if err != nil {
+				return "", 0, err
+			}
+			password[i] = alphabet[n]
+		}
+		// Passwords missing a class are generated again, which keeps the choice uniform
+		if containsAllClasses(string(password), classes) {
+			break
+		}
+	}
+
+	return string(password), float64(policy.Length) * math.Log2(float64(len(alphabet))), nil
+}
+
+// GeneratePassphrase generates a diceware passphrase using crypto/rand.
+// It returns the passphrase and its estimated entropy in bits.
+func GeneratePassphrase(policy PassphrasePolicy) (string, float64, error) {
+	if policy.Words < 1 || policy.Words > MaxPassphraseWords {
+		return "", 0, fmt.Errorf("%w: number of words must be between 1 and %d",
+			ErrInvalidPasswordPolicy, MaxPassphraseWords)
+	}
+
+	wordlist, err := loadWordlist()
+	if err != nil {
+		return "", 0, err
+	}
+
+	words := make([]string, policy.Words)
+	for i := range words {
+		n, err := randomIndex(len(wordlist))
+		if err != nil {
+			return "", 0, err
+		}
+		words[i] = wordlist[n]
+	}
+
+	return strings.Join(words, policy.Separator), float64(policy.Words) * math.Log2(float64(len(wordlist))), nil
+}
+
+// Character pool sizes used to estimate the entropy of a password.
+const (
+	asciiSymbolPool = 33
+	otherCharPool   = 100
+)
+
+// EstimatePasswordEntropy estimates the entropy of a password in bits from the character classes it uses.
+// Characters repeating the previous one or continuing a sequence such as "abc" or "321" add only one bit.
+func EstimatePasswordEntropy(password string) float64 {
+	var lower, upper, digits, symbols, other bool
+	for _, r := range password {
+		switch {
+		case strings.ContainsRune(lowerChars, r):
+			lower = true
+		case strings.ContainsRune(upperChars, r):
+			upper = true
+		case strings.ContainsRune(digitChars, r):
+			digits = true
+		case r < 128:
+			symbols = true
+		default:
+			other = true
+		}
+	}
+
+	pool := 0
+	for _, class := range []struct {
+		used bool
+		size int
+	}{
+		{lower, len(lowerChars)},
+		{upper, len(upperChars)},
+		{digits, len(digitChars)},
+		{symbols, asciiSymbolPool},
+		{other, otherCharPool},
+	} {
+		if class.used {
+			pool += class.size
+		}
+	}
+	if pool == 0 {
+		return 0
+	}
+
+	bitsPerChar := math.Log2(float64(pool))
+	entropy := 0.0
+	prev := rune(-1)
+	for _, r := range password {
+		if d := r - prev; d >= -1 && d <= 1 {
+			entropy++
+		} else {
+			entropy += bitsPerChar
+		}
+		prev = r
+	}
+	return entropy
+}
+
+// classes returns the enabled character classes without the excluded characters.
+func (p PasswordPolicy) classes() []string {
+	var classes []string
+	for _, class := range []struct {
+		enabled bool
+		chars   string
+	}{
+		{p.Lower, lowerChars},
+		{p.Upper, upperChars},
+		{p.Digits, digitChars},
+		{p.Symbols, symbolChars},
+	} {
+		if !class.enabled {
+			continue
+		}
+		chars := class.chars
+		if p.ExcludeAmbiguous {
+			chars = strings.Map(func(r rune) rune {
+				if strings.ContainsRune(ambiguousChars, r) {
+					return -1
+				}
+				return r
+			}, chars)
+		}
+		classes = append(classes, chars)
+	}
+	return classes
+}
+
+// containsAllClasses reports whether the password has at least one character of every class.
+func containsAllClasses(password string, classes []string) bool {
+	for _, chars := range classes {
+		if !strings.ContainsAny(password, chars) {
+			return false
+		}
+	}
+	return true
+}
+
+// randomIndex returns a uniformly distributed random number in [0, n).
+func randomIndex(n int) (int, error) {
+	i, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
+	if err != nil {
+		return 0, fmt.Errorf("failed to read random data: %w", err)
+	}
+	return int(i.Int64()), nil
+}
+
+// effLargeWordlist is the EFF large wordlist for diceware passphrases,
+// https://www.eff.org/deeplinks/2016/07/new-wordlists-random-passphrases (CC BY 3.0 US).
+//
+//go:embed wordlists/eff_large_wordlist.txt
+var effLargeWordlist string
+
+// loadWordlist parses the embedded wordlist once. Each line holds the dice rolls and a word.
+var loadWordlist = sync.OnceValues(func() ([]string, error) {
+	var words []string
+	scanner := bufio.NewScanner(strings.NewReader(effLargeWordlist))
+	for scanner.Scan() {
+		_, word, ok := strings.Cut(scanner.Text(), "\t")
+		if !ok || word == "" {
+			return nil, fmt.Errorf("malformed wordlist line %q", scanner.Text())
+		}
+		words = append(words, word)
+	}
+	return words, scanner.Err()
+})
+
+var (
+	ErrInvalidPasswordPolicy = errors.New("invalid password policy")
+)
@@ -0,0 +1,84 @@
+package domain_test
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/domain"
+)
+
+func TestGeneratePassword(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   domain.PasswordPolicy
+		alphabet int
+		required []string
+		excluded string
+		wantErr  string
+	}{
+		{
+			name:     "all classes",
+			policy:   domain.DefaultPasswordPolicy,
+			alphabet: 89,
+			required: []string{"abcdefghijklmnopqrstuvwxyz", "ABCDEFGHIJKLMNOPQRSTUVWXYZ", "0123456789", "!#$%&*+-.:;<=>?@^_~()[]{}/|"},
+		},
+		{
+			name:     "digits only",
+			policy:   domain.PasswordPolicy{Length: 8, Digits: true},
+			alphabet: 10,
+			required: []string{"0123456789"},
+			excluded: "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ!#$%&*+-.:;<=>?@^_~()[]{}/|",
+		},
+		{
+			name:     "without ambiguous characters",
+			policy:   domain.PasswordPolicy{Length: 64, Lower: true, Upper: true, Digits: true, Symbols: true, ExcludeAmbiguous: true},
+			alphabet: 82,
+			excluded: "Il1|O0o",
+		},
+		{
+			name:    "no classes",
+			policy:  domain.PasswordPolicy{Length: 16},
+			wantErr: "invalid password policy: no character classes enabled",
+		},
+		{
+			name:    "shorter than the number of classes",
+			policy:  domain.PasswordPolicy{Length: 3, Lower: true, Upper: true, Digits: true, Symbols: true},
+			wantErr: "invalid password policy: length must be between 4 and 1024",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			password, entropy, err := domain.GeneratePassword(tt.policy)
+			if tt.wantErr != "" {
+				assert.ErrorIs(t, err, domain.ErrInvalidPasswordPolicy)
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Len(t, password, tt.policy.Length)
+			assert.InDelta(t, float64(tt.policy.Length)*math.Log2(float64(tt.alphabet)), entropy, 0.001)
+			for _, class := range tt.required {
+				assert.True(t, strings.ContainsAny(password, class), "password %q has no character of %q", password, class)
+			}
+			if tt.excluded != "" {
+				assert.False(t, strings.ContainsAny(password, tt.excluded), "password %q contains excluded characters", password)
+			}
+		})
+	}
+}
+
+func TestGeneratePassphrase(t *testing.T) {
+	passphrase, entropy, err := domain.GeneratePassphrase(domain.PassphrasePolicy{Words: 5, Separator: " "})
+	require.NoError(t, err)
+
+	assert.Len(t, strings.Split(passphrase, " "), 5)
+	assert.InDelta(t, 5*math.Log2(7776), entropy, 0.001)
+
+	_, _, err = domain.GeneratePassphrase(domain.PassphrasePolicy{Words: 0})
+	assert.EqualError(t, err, "invalid password policy: number of words must be between 1 and 64")
+}
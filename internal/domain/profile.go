@@ -0,0 +1,72 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// DefaultProfileName is the name of the implicit profile used when no named profile is selected.
+// It uses the server settings from the configuration and the legacy token store.
+const DefaultProfileName = "default"
+
+// Profile represents a named set of connection settings for a GophKeeper server account.
+// Each profile has its own token store and local vault.
+type Profile struct {
+	Name        string
+	ServerAddr  string
+	TLSCertPath string
+	Timeout     time.Duration
+}
+
+// ProfileService defines the interface for managing named profiles.
+type ProfileService interface {
+	// ListProfiles retrieves all profiles and the name of the current one.
+	ListProfiles(ctx context.Context) ([]Profile, string, error)
+
+	// AddProfile stores a new profile.
+	// Returns ErrProfileAlreadyExists if a profile with the same name exists.
+	AddProfile(ctx context.Context, profile Profile) error
+
+	// RemoveProfile deletes a profile and its stored token.
+	// Returns ErrProfileNotFound if the profile does not exist.
+	RemoveProfile(ctx context.Context, name string) error
+
+	// UseProfile makes the profile current for subsequent commands.
+	// Returns ErrProfileNotFound if the profile does not exist.
+	UseProfile(ctx context.Context, name string) error
+
+	// ResolveProfile retrieves the profile with the given name, or the current one if name is empty.
+	// Returns nil if the default profile is selected.
+	ResolveProfile(ctx context.Context, name string) (*Profile, error)
+}
+
+// ProfileRepository defines the interface for profile persistence.
+type ProfileRepository interface {
+	// ListProfiles retrieves all stored profiles sorted by name.
+	ListProfiles() ([]Profile, error)
+
+	// GetProfile retrieves a profile by name.
+	// Returns ErrProfileNotFound if the profile does not exist.
+	GetProfile(name string) (*Profile, error)
+
+	// SaveProfile creates or replaces a profile.
+	SaveProfile(profile Profile) error
+
+	// DeleteProfile removes a profile and its stored token.
+	DeleteProfile(name string) error
+
+	// GetCurrentProfile retrieves the name of the current profile.
+	// Returns an empty string if no profile was selected.
+	GetCurrentProfile() (string, error)
+
+	// SaveCurrentProfile stores the name of the current profile.
+	// An empty name selects the default profile.
+	SaveCurrentProfile(name string) error
+}
+
+var (
+	ErrProfileNotFound      = errors.New("profile not found")
+	ErrProfileAlreadyExists = errors.New("profile already exists")
+	ErrInvalidProfile       = errors.New("invalid profile")
+)
@@ -15,13 +15,35 @@ const (
 	PaymentCardSecretType SecretType = "payment_card"
 	FileSecretType        SecretType = "file"
 	TextSecretType        SecretType = "text"
+	SSHKeySecretType      SecretType = "ssh_key"
 )
 
+// ParseSecretType converts a string into a SecretType.
+// Returns an error if the type is unknown.
+func ParseSecretType(s string) (SecretType, error) {
+	switch t := SecretType(s); t {
+	case CredentialsSecretType, PaymentCardSecretType, FileSecretType, TextSecretType, SSHKeySecretType:
+		return t, nil
+	default:
+		return "", fmt.Errorf("%w '%s' (must be credentials, payment_card, file, text or ssh_key)", ErrUnknownSecretType, s)
+	}
+}
+
+// Streamable reports whether the content of secrets of this type is transferred as a stream.
+func (t SecretType) Streamable() bool {
+	return t == FileSecretType || t == TextSecretType
+}
+
 // Secret represents a protected piece of information with its metadata.
 // The Data field contains the actual secret content in string form.
+// BaseVersion is the latest version known to the writer when the secret is created;
+// zero means the version last seen on this device. If no version was seen on this device either,
+// existing versions only conflict with the write if CheckUnseen is set.
 type Secret struct {
-	Info SecretInfo
-	Data string
+	Info        SecretInfo
+	Data        string
+	BaseVersion int32
+	CheckUnseen bool
 }
 
 // SecretInfo contains metadata about a secret.
@@ -33,15 +55,21 @@ type SecretInfo struct {
 	CreatedAt time.Time
 }
 
+// SecretVersion describes a stored version of a secret.
+// Size is the size of the stored, encrypted payload in bytes.
+type SecretVersion struct {
+	Info SecretInfo
+	Size int64
+}
+
 // CredentialsSecret represents login/password credentials.
+// OTP holds an optional otpauth:// URI with the one-time password seed.
 type CredentialsSecret struct {
 	Login    string
 	Password string
-}
-
-// PaymentCardSecret represents payment card information.
-type PaymentCardSecret struct {
-	Number string
+	URL      string `json:",omitempty"`
+	Notes    string `json:",omitempty"`
+	OTP      string `json:",omitempty"`
 }
 
 // SecretClient defines the interface for client-server operations.
@@ -59,6 +87,18 @@ type SecretClient interface {
 	// Returns a list of secret names or an error if the operation fails.
 	ListSecrets(ctx context.Context) ([]string, error)
 
+	// ListSecretsInfo retrieves the info of the latest version of every secret.
+	// Returns a list of secret infos or an error if the operation fails.
+	ListSecretsInfo(ctx context.Context) ([]SecretInfo, error)
+
+	// ListSecretVersions retrieves every version of a secret, oldest first.
+	// Returns a list of versions or an error if the operation fails.
+	ListSecretVersions(ctx context.Context, secretName string) ([]SecretVersion, error)
+
+	// GetSecretInfo retrieves the info of a version of a secret, version 0 being the latest one.
+	// Returns the info or an error if the operation fails.
+	GetSecretInfo(ctx context.Context, secretName string, version int32) (*SecretInfo, error)
+
 	// GetLatestSecret retrieves the most recent version of a secret.
 	// Returns the secret or an error if the operation fails.
 	GetLatestSecret(ctx context.Context, secretName string) (*Secret, error)
@@ -90,6 +130,18 @@ type SecretService interface {
 	// Returns a list of secret names or an error if the operation fails.
 	ListSecrets(ctx context.Context) ([]string, error)
 
+	// ListSecretsInfo retrieves the info of the latest version of every secret.
+	// Returns a list of secret infos or an error if the operation fails.
+	ListSecretsInfo(ctx context.Context) ([]SecretInfo, error)
+
+	// ListSecretVersions retrieves every version of a secret, oldest first.
+	// Returns a list of versions or an error if the operation fails.
+	ListSecretVersions(ctx context.Context, secretName string) ([]SecretVersion, error)
+
+	// GetSecretInfo retrieves the info of a version of a secret, version 0 being the latest one.
+	// Returns the info or an error if the operation fails.
+	GetSecretInfo(ctx context.Context, secretName string, version int32) (*SecretInfo, error)
+
 	// GetLatestSecret retrieves the most recent version of a secret.
 	// Returns the secret or an error if the operation fails.
 	GetLatestSecret(ctx context.Context, secretName string) (*Secret, error)
@@ -109,8 +161,17 @@ type SecretService interface {
 	// DeleteSecret removes a secret and all its versions.
 	// Returns an error if the operation fails.
 	DeleteSecret(ctx context.Context, secretName string) error
+
+	// Sync replays operations queued while the server was unavailable.
+	// Returns an error if the operation fails.
+	Sync(ctx context.Context) error
+
+	// GetSyncStatus retrieves pending operations and the last synchronization time.
+	// Returns the synchronization status or an error if the operation fails.
+	GetSyncStatus(ctx context.Context) (*SyncStatus, error)
 }
 
 var (
 	ErrUnknownSecretType = fmt.Errorf("unknown secret type")
+	ErrSecretNotFound    = fmt.Errorf("secret not found")
 )
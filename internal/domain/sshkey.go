@@ -0,0 +1,73 @@
+package domain
+
+import (
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// SSHKeySecret represents an SSH private key in OpenSSH or PEM format.
+// Passphrase is set for encrypted keys so they can be loaded without a prompt.
+type SSHKeySecret struct {
+	PrivateKey string
+	Passphrase string `json:",omitempty"`
+}
+
+// RawKey decrypts and parses the private key. The passphrase of unencrypted keys is ignored.
+// The result can be passed to ssh.NewSignerFromKey or added to an SSH agent.
+func (k SSHKeySecret) RawKey() (any, error) {
+	key, err := ssh.ParseRawPrivateKey([]byte(k.PrivateKey))
+
+	var missing *ssh.PassphraseMissingError
+	if errors.As(err, &missing) {
+		if k.Passphrase == "" {
+			return nil, fmt.Errorf("%w: the key is encrypted", ErrSSHKeyPassphrase)
+		}
+		key, err = ssh.ParseRawPrivateKeyWithPassphrase([]byte(k.PrivateKey), []byte(k.Passphrase))
+		if errors.Is(err, x509.IncorrectPasswordError) {
+			return nil, fmt.Errorf("%w: the passphrase does not match", ErrSSHKeyPassphrase)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSSHKey, err)
+	}
+	return key, nil
+}
+
+// Encrypted reports whether the private key is protected by a passphrase.
+func (k SSHKeySecret) Encrypted() bool {
+	_, err := ssh.ParseRawPrivateKey([]byte(k.PrivateKey))
+	var missing *ssh.PassphraseMissingError
+	return errors.As(err, &missing)
+}
+
+// PublicKey returns the public part of the private key.
+func (k SSHKeySecret) PublicKey() (ssh.PublicKey, error) {
+	key, err := k.RawKey()
+	if err != nil {
+		return nil, err
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSSHKey, err)
+	}
+	return signer.PublicKey(), nil
+}
+
+// SSHKeyMetadata describes a public key as stored in the metadata of ssh_key secrets:
+// the SHA256 fingerprint followed by the key in authorized_keys format.
+func SSHKeyMetadata(publicKey ssh.PublicKey, comment string) string {
+	authorizedKey := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(publicKey)))
+	if comment != "" {
+		authorizedKey += " " + comment
+	}
+	return ssh.FingerprintSHA256(publicKey) + " " + authorizedKey
+}
+
+var (
+	ErrInvalidSSHKey    = errors.New("invalid SSH key")
+	ErrSSHKeyPassphrase = errors.New("missing or incorrect SSH key passphrase")
+)
@@ -0,0 +1,122 @@
+package domain_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/domain"
+	"golang.org/x/crypto/ssh"
+)
+
+func TestSSHKeySecret_PublicKey(t *testing.T) {
+	_, ed25519Key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	openSSH, err := ssh.MarshalPrivateKey(ed25519Key, "")
+	require.NoError(t, err)
+	encrypted, err := ssh.MarshalPrivateKeyWithPassphrase(ed25519Key, "", []byte("secret"))
+	require.NoError(t, err)
+	pkcs1 := &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)}
+
+	tests := []struct {
+		name       string
+		key        domain.SSHKeySecret
+		algorithm  string
+		wantErr    error
+		errMessage string
+	}{
+		{
+			name:      "openssh key",
+			key:       domain.SSHKeySecret{PrivateKey: string(pem.EncodeToMemory(openSSH))},
+			algorithm: ssh.KeyAlgoED25519,
+		},
+		{
+			name:      "pem key",
+			key:       domain.SSHKeySecret{PrivateKey: string(pem.EncodeToMemory(pkcs1))},
+			algorithm: ssh.KeyAlgoRSA,
+		},
+		{
+			name:      "encrypted key",
+			key:       domain.SSHKeySecret{PrivateKey: string(pem.EncodeToMemory(encrypted)), Passphrase: "secret"},
+			algorithm: ssh.KeyAlgoED25519,
+		},
+		{
+			name:      "passphrase of unencrypted key is ignored",
+			key:       domain.SSHKeySecret{PrivateKey: string(pem.EncodeToMemory(openSSH)), Passphrase: "secret"},
+			algorithm: ssh.KeyAlgoED25519,
+		},
+		{
+			name:       "missing passphrase",
+			key:        domain.SSHKeySecret{PrivateKey: string(pem.EncodeToMemory(encrypted))},
+			wantErr:    domain.ErrSSHKeyPassphrase,
+			errMessage: "missing or incorrect SSH key passphrase: the key is encrypted",
+		},
+		{
+			name:       "incorrect passphrase",
+			key:        domain.SSHKeySecret{PrivateKey: string(pem.EncodeToMemory(encrypted)), Passphrase: "wrong"},
+			wantErr:    domain.ErrSSHKeyPassphrase,
+			errMessage: "missing or incorrect SSH key passphrase: the passphrase does not match",
+		},
+		{
+			name:       "not a key",
+			key:        domain.SSHKeySecret{PrivateKey: "hello"},
+			wantErr:    domain.ErrInvalidSSHKey,
+			errMessage: "invalid SSH key: ssh: no key found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			publicKey, err := tt.key.PublicKey()
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.EqualError(t, err, tt.errMessage)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.algorithm, publicKey.Type())
+		})
+	}
+}
+
+func TestSSHKeySecret_Encrypted(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	plain, err := ssh.MarshalPrivateKey(key, "")
+	require.NoError(t, err)
+	encrypted, err := ssh.MarshalPrivateKeyWithPassphrase(key, "", []byte("secret"))
+	require.NoError(t, err)
+
+	assert.False(t, domain.SSHKeySecret{PrivateKey: string(pem.EncodeToMemory(plain))}.Encrypted())
+	assert.True(t, domain.SSHKeySecret{PrivateKey: string(pem.EncodeToMemory(encrypted))}.Encrypted())
+}
+
+func TestSSHKeyMetadata(t *testing.T) {
+	publicKey, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	sshPublicKey, err := ssh.NewPublicKey(publicKey)
+	require.NoError(t, err)
+
+	metadata := domain.SSHKeyMetadata(sshPublicKey, "deploy@ci")
+
+	fields := strings.Fields(metadata)
+	require.Len(t, fields, 4)
+	assert.Equal(t, ssh.FingerprintSHA256(sshPublicKey), fields[0])
+	assert.Equal(t, ssh.KeyAlgoED25519, fields[1])
+	assert.Equal(t, "deploy@ci", fields[3])
+
+	parsed, comment, _, _, err := ssh.ParseAuthorizedKey([]byte(strings.Join(fields[1:], " ")))
+	require.NoError(t, err)
+	assert.Equal(t, sshPublicKey.Marshal(), parsed.Marshal())
+	assert.Equal(t, "deploy@ci", comment)
+}
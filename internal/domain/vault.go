@@ -0,0 +1,109 @@
+package domain
+
+import (
+	"errors"
+	"io"
+	"time"
+)
+
+// OperationType represents the kind of a write operation queued while offline.
+type OperationType string
+
+const (
+	CreateOperationType OperationType = "create"
+	DeleteOperationType OperationType = "delete"
+)
+
+// PendingOperation represents a write operation waiting to be replayed against the server.
+// For streamable secrets the content is stored separately in the vault.
+type PendingOperation struct {
+	ID       string
+	Type     OperationType
+	Secret   Secret
+	QueuedAt time.Time
+}
+
+// SyncStatus describes the state of the local vault synchronization.
+type SyncStatus struct {
+	Pending  []PendingOperation
+	LastSync time.Time
+}
+
+// VaultWriter receives streamed secret content for the local vault.
+// The content becomes visible only after Commit.
+type VaultWriter interface {
+	io.Writer
+
+	// Commit makes the written content available for reading.
+	Commit() error
+
+	// Abort discards the written content.
+	Abort() error
+}
+
+// VaultRepository defines the interface for the local offline vault.
+// Implementations should mirror secrets fetched from the server and
+// keep a queue of write operations made while the server was unreachable.
+type VaultRepository interface {
+	// SaveSecret stores a copy of a non-streamable secret version.
+	SaveSecret(secret Secret) error
+
+	// GetSecret retrieves a stored secret version, or the latest one if version is 0.
+	// Returns ErrSecretNotInVault if the secret was never mirrored.
+	GetSecret(secretName string, version int32) (*Secret, error)
+
+	// SaveSecretStream returns a writer that stores the content of a streamable secret version.
+	SaveSecretStream(info SecretInfo) (VaultWriter, error)
+
+	// GetSecretStream retrieves stored streamable secret content, or the latest one if version is 0.
+	// Returns ErrSecretNotInVault if the secret was never mirrored.
+	GetSecretStream(secretName string, version int32) (io.Reader, *SecretInfo, error)
+
+	// DeleteSecret removes all stored versions of a secret and its known version.
+	DeleteSecret(secretName string) error
+
+	// SaveKnownVersion stores the latest version of a secret seen on this device.
+	SaveKnownVersion(secretName string, version int32) error
+
+	// GetKnownVersion retrieves the latest version of a secret seen on this device.
+	// Returns zero if the secret was never seen.
+	GetKnownVersion(secretName string) (int32, error)
+
+	// SaveSecretNames stores the list of secret names known to the server.
+	SaveSecretNames(names []string) error
+
+	// GetSecretNames retrieves the stored list of secret names.
+	GetSecretNames() ([]string, error)
+
+	// SaveSecretInfos stores the infos of the latest secret versions known to the server.
+	SaveSecretInfos(infos []SecretInfo) error
+
+	// GetSecretInfos retrieves the stored infos of the latest secret versions.
+	// Returns ErrSecretNotInVault if the infos were never stored.
+	GetSecretInfos() ([]SecretInfo, error)
+
+	// EnqueueOperation adds a write operation to the pending queue.
+	// Content is only required for streamable secrets and may be nil otherwise.
+	EnqueueOperation(op PendingOperation, content io.Reader) error
+
+	// ListPendingOperations retrieves queued operations in the order they were made.
+	ListPendingOperations() ([]PendingOperation, error)
+
+	// GetPendingContent retrieves the content of a queued streamable secret.
+	GetPendingContent(id string) (io.Reader, error)
+
+	// RemovePendingOperation removes an operation from the queue.
+	RemovePendingOperation(id string) error
+
+	// GetLastSync retrieves the time of the last successful synchronization.
+	// Returns zero time if the vault was never synchronized.
+	GetLastSync() (time.Time, error)
+
+	// SaveLastSync stores the time of the last successful synchronization.
+	SaveLastSync(t time.Time) error
+}
+
+var (
+	ErrServerUnavailable = errors.New("server is unavailable")
+	ErrSecretNotInVault  = errors.New("secret not found in local vault")
+)
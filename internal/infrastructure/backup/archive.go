@@ -0,0 +1,373 @@
+package backup
+
+import (
+	"bufio"
+	"crypto/cipher"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"time"
+
+	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/domain"
+)
+
+// Record kinds of the decrypted archive.
+const (
+	entryRecord    byte = 'E'
+	manifestRecord byte = 'M'
+)
+
+// frameSize is the largest frame of content written at once.
+const frameSize = 32 * 1024
+
+// Writer implements domain.BackupWriter.
+//
+// The decrypted archive is a sequence of entry records, one per secret version, followed by the manifest record.
+// A record is its kind followed by frames, each a uvarint length and data, ended by a zero length:
+//
+//	'E' frames ... 'E' frames 'M' frames(manifest JSON)
+//
+// The manifest comes last, as the checksums are only known once the content has been written.
+type Writer struct {
+	enc      *encrypter
+	w        *bufio.Writer
+	manifest domain.BackupManifest
+	buf      []byte
+}
+
+// Create writes the header of an archive encrypted with a key derived from the passphrase
+// and returns a writer for its entries.
+func Create(w io.Writer, passphrase string, allVersions bool) (*Writer, error) {
+	enc, err := newEncrypter(w, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return &Writer{
+		enc: enc,
+		w:   bufio.NewWriterSize(enc, chunkSize),
+		manifest: domain.BackupManifest{
+			FormatVersion: domain.BackupFormatVersion,
+			CreatedAt:     time.Now().UTC(),
+			AllVersions:   allVersions,
+			Entries:       []domain.BackupEntry{},
+		},
+		buf: make([]byte, frameSize),
+	}, nil
+}
+
+// Add writes the content of a secret version and records its checksum in the manifest.
+func (w *Writer) Add(info domain.SecretInfo, content io.Reader) error {
+	if err := w.w.WriteByte(entryRecord); err != nil {
+		return fmt.Errorf("failed to write backup archive: %w", err)
+	}
+
+	sum := sha256.New()
+	frames := &frameWriter{w: w.w}
+	size, err := io.CopyBuffer(io.MultiWriter(frames, sum), content, w.buf)
+	if err != nil {
+		return fmt.Errorf("failed to back up version %d of '%s': %w", info.Version, info.Name, err)
+	}
+	if err := frames.Close(); err != nil {
+		return fmt.Errorf("failed to write backup archive: %w", err)
+	}
+
+	w.manifest.Entries = append(w.manifest.Entries, domain.BackupEntry{
+		Name:      info.Name,
+		Type:      info.Type,
+		Metadata:  info.Metadata,
+		Version:   info.Version,
+		CreatedAt: info.CreatedAt,
+		Size:      size,
+		SHA256:    hex.EncodeToString(sum.Sum(nil)),
+	})
+	return nil
+}
+
+// Close writes the manifest and seals the final chunk.
+func (w *Writer) Close() error {
+	manifest, err := json.Marshal(w.manifest)
+	if err != nil {
+		return fmt.Errorf("failed to marshal backup manifest: %w", err)
+	}
+
+	if err := w.w.WriteByte(manifestRecord); err != nil {
+		return fmt.Errorf("failed to write backup archive: %w", err)
+	}
+	frames := &frameWriter{w: w.w}
+	if _, err := frames.Write(manifest); err != nil {
+		return fmt.Errorf("failed to write backup archive: %w", err)
+	}
+	if err := frames.Close(); err != nil {
+		return fmt.Errorf("failed to write backup archive: %w", err)
+	}
+	if err := w.w.Flush(); err != nil {
+		return fmt.Errorf("failed to write backup archive: %w", err)
+	}
+	return w.enc.Close()
+}
+
+// Reader implements domain.BackupReader on top of an archive file.
+// The file is read once to verify it and again to walk its entries, so entries are never buffered.
+type Reader struct {
+	path     string
+	aead     cipher.AEAD
+	header   header
+	encoded  []byte
+	manifest *domain.BackupManifest
+}
+
+// Open reads the header of the archive at path, derives the key from the passphrase and checks it
+// against the first chunk. Returns domain.ErrBackupPassphrase if the passphrase is wrong.
+func Open(path, passphrase string) (*Reader, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open backup archive: %w", err)
+	}
+	defer file.Close()
+
+	h, encoded, err := readHeader(file)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := h.deriveAEAD(passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := newDecrypter(file, aead, h, encoded).Read(make([]byte, 1)); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%w: archive is empty", domain.ErrInvalidBackup)
+		}
+		return nil, err
+	}
+	return &Reader{path: path, aead: aead, header: h, encoded: encoded}, nil
+}
+
+// Verify reads the whole archive and checks the size and checksum of every entry against the manifest.
+func (r *Reader) Verify() (*domain.BackupManifest, error) {
+	var (
+		sums     []checksum
+		manifest *domain.BackupManifest
+	)
+	err := r.decrypt(func(br *bufio.Reader) error {
+		for {
+			kind, err := readKind(br)
+			if err != nil {
+				return err
+			}
+
+			switch kind {
+			case entryRecord:
+				content := newChecksumReader(&frameReader{r: br})
+				if _, err := io.Copy(io.Discard, content); err != nil {
+					return err
+				}
+				sums = append(sums, content.checksum())
+			case manifestRecord:
+				if manifest, err = readManifest(br); err != nil {
+					return err
+				}
+				if _, err := br.ReadByte(); !errors.Is(err, io.EOF) {
+					return fmt.Errorf("%w: data after the manifest", domain.ErrInvalidBackup)
+				}
+				return nil
+			default:
+				return fmt.Errorf("%w: unknown record kind %q", domain.ErrInvalidBackup, kind)
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sums) != len(manifest.Entries) {
+		return nil, fmt.Errorf("%w: manifest lists %d entries, archive holds %d",
+			domain.ErrInvalidBackup, len(manifest.Entries), len(sums))
+	}
+	for i, entry := range manifest.Entries {
+		if err := sums[i].check(entry); err != nil {
+			return nil, err
+		}
+	}
+
+	r.manifest = manifest
+	return manifest, nil
+}
+
+// Walk calls fn with every entry and its content, verifying the archive first if needed.
+// The checksum of every entry is checked again after fn returns.
+func (r *Reader) Walk(fn func(entry domain.BackupEntry, content io.Reader) error) error {
+	if r.manifest == nil {
+		if _, err := r.Verify(); err != nil {
+			return err
+		}
+	}
+
+	return r.decrypt(func(br *bufio.Reader) error {
+		for _, entry := range r.manifest.Entries {
+			kind, err := readKind(br)
+			if err != nil {
+				return err
+			}
+			if kind != entryRecord {
+				return fmt.Errorf("%w: unexpected record kind %q", domain.ErrInvalidBackup, kind)
+			}
+
+			content := newChecksumReader(&frameReader{r: br})
+			if err := fn(entry, content); err != nil {
+				return err
+			}
+			if _, err := io.Copy(io.Discard, content); err != nil {
+				return err
+			}
+			if err := content.checksum().check(entry); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// decrypt opens the archive and calls fn with its decrypted content.
+func (r *Reader) decrypt(fn func(br *bufio.Reader) error) error {
+	file, err := os.Open(r.path)
+	if err != nil {
+		return fmt.Errorf("failed to open backup archive: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Seek(int64(headerLength), io.SeekStart); err != nil {
+		return fmt.Errorf("failed to read backup archive: %w", err)
+	}
+	return fn(bufio.NewReader(newDecrypter(file, r.aead, r.header, r.encoded)))
+}
+
+// readKind reads the kind of the next record.
+func readKind(br *bufio.Reader) (byte, error) {
+	kind, err := br.ReadByte()
+	if errors.Is(err, io.EOF) {
+		return 0, fmt.Errorf("%w: manifest is missing", domain.ErrInvalidBackup)
+	}
+	return kind, err
+}
+
+// readManifest reads and decodes the manifest record.
+func readManifest(br *bufio.Reader) (*domain.BackupManifest, error) {
+	data, err := io.ReadAll(&frameReader{r: br})
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest domain.BackupManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("%w: invalid manifest: %v", domain.ErrInvalidBackup, err)
+	}
+	if manifest.FormatVersion != domain.BackupFormatVersion {
+		return nil, fmt.Errorf("%w: unsupported format version %d", domain.ErrInvalidBackup, manifest.FormatVersion)
+	}
+	return &manifest, nil
+}
+
+// frameWriter writes every non-empty write as a frame.
+type frameWriter struct {
+	w      *bufio.Writer
+	length [binary.MaxVarintLen64]byte
+}
+
+// Write writes p as a single frame.
+func (f *frameWriter) Write(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	n := binary.PutUvarint(f.length[:], uint64(len(p)))
+	if _, err := f.w.Write(f.length[:n]); err != nil {
+		return 0, err
+	}
+	return f.w.Write(p)
+}
+
+// Close ends the record with an empty frame.
+func (f *frameWriter) Close() error {
+	return f.w.WriteByte(0)
+}
+
+// frameReader reads the frames of a record until the empty frame.
+type frameReader struct {
+	r         *bufio.Reader
+	remaining uint64
+	done      bool
+}
+
+// Read returns the data of the frames, io.EOF after the empty frame.
+func (f *frameReader) Read(p []byte) (int, error) {
+	for f.remaining == 0 {
+		if f.done {
+			return 0, io.EOF
+		}
+		n, err := binary.ReadUvarint(f.r)
+		if err != nil {
+			return 0, truncated(err)
+		}
+		f.remaining, f.done = n, n == 0
+	}
+
+	if uint64(len(p)) > f.remaining {
+		p = p[:f.remaining]
+	}
+	n, err := f.r.Read(p)
+	f.remaining -= uint64(n)
+	if err != nil {
+		return n, truncated(err)
+	}
+	return n, nil
+}
+
+// truncated reports an archive that ends within a record.
+func truncated(err error) error {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return fmt.Errorf("%w: record is truncated", domain.ErrInvalidBackup)
+	}
+	return err
+}
+
+// checksum is the size and SHA-256 checksum of the content of an entry.
+type checksum struct {
+	size int64
+	sum  string
+}
+
+// check compares the checksum to the one recorded for the entry.
+func (c checksum) check(entry domain.BackupEntry) error {
+	if c.size != entry.Size || c.sum != entry.SHA256 {
+		return fmt.Errorf("%w: version %d of '%s'", domain.ErrBackupChecksum, entry.Version, entry.Name)
+	}
+	return nil
+}
+
+// checksumReader computes the checksum of the content read through it.
+type checksumReader struct {
+	r    io.Reader
+	hash hash.Hash
+	size int64
+}
+
+func newChecksumReader(r io.Reader) *checksumReader {
+	return &checksumReader{r: r, hash: sha256.New()}
+}
+
+func (c *checksumReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.hash.Write(p[:n])
+	c.size += int64(n)
+	return n, err
+}
+
+func (c *checksumReader) checksum() checksum {
+	return checksum{size: c.size, sum: hex.EncodeToString(c.hash.Sum(nil))}
+}
@@ -0,0 +1,148 @@
+package backup_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/domain"
+	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/infrastructure/backup"
+)
+
+const passphrase = "correct horse battery staple"
+
+// writeArchive writes an archive holding the contents as versions of 'secret' and returns its path.
+func writeArchive(t *testing.T, contents ...[]byte) string {
+	t.Helper()
+
+	var buf bytes.Buffer
+	w, err := backup.Create(&buf, passphrase, true)
+	require.NoError(t, err)
+	for i, content := range contents {
+		info := domain.SecretInfo{
+			Name:      "secret",
+			Type:      domain.FileSecretType,
+			Metadata:  "report.pdf",
+			Version:   int32(i + 1),
+			CreatedAt: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+		}
+		require.NoError(t, w.Add(info, bytes.NewReader(content)))
+	}
+	require.NoError(t, w.Close())
+
+	path := filepath.Join(t.TempDir(), "vault.gkb")
+	require.NoError(t, os.WriteFile(path, buf.Bytes(), 0600))
+	return path
+}
+
+func TestArchive(t *testing.T) {
+	large := make([]byte, 200*1024)
+	_, err := rand.Read(large)
+	require.NoError(t, err)
+	contents := [][]byte{[]byte("first"), {}, large}
+
+	path := writeArchive(t, contents...)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.False(t, bytes.Contains(data, []byte("report.pdf")), "archive must be encrypted")
+
+	r, err := backup.Open(path, passphrase)
+	require.NoError(t, err)
+
+	manifest, err := r.Verify()
+	require.NoError(t, err)
+	assert.Equal(t, domain.BackupFormatVersion, manifest.FormatVersion)
+	assert.True(t, manifest.AllVersions)
+	require.Len(t, manifest.Entries, 3)
+	assert.Equal(t, domain.BackupEntry{
+		Name:      "secret",
+		Type:      domain.FileSecretType,
+		Metadata:  "report.pdf",
+		Version:   1,
+		CreatedAt: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+		Size:      5,
+		SHA256:    "a7937b64b8caa58f03721bb6bacf5c78cb235febe0e70b1b84cd99541461a08e",
+	}, manifest.Entries[0])
+	assert.Equal(t, int64(len(large)), manifest.Entries[2].Size)
+
+	var read [][]byte
+	err = r.Walk(func(entry domain.BackupEntry, content io.Reader) error {
+		if entry.Version == 2 {
+			// Content that is not read is skipped
+			read = append(read, nil)
+			return nil
+		}
+		data, err := io.ReadAll(content)
+		read = append(read, data)
+		return err
+	})
+	require.NoError(t, err)
+	assert.Equal(t, [][]byte{contents[0], nil, large}, read)
+}
+
+func TestArchive_Damaged(t *testing.T) {
+	large := make([]byte, 150*1024)
+	path := writeArchive(t, []byte("first"), large)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	write := func(t *testing.T, data []byte) string {
+		path := filepath.Join(t.TempDir(), "damaged.gkb")
+		require.NoError(t, os.WriteFile(path, data, 0600))
+		return path
+	}
+	verify := func(path string) error {
+		r, err := backup.Open(path, passphrase)
+		if err != nil {
+			return err
+		}
+		_, err = r.Verify()
+		return err
+	}
+
+	t.Run("wrong passphrase", func(t *testing.T) {
+		_, err := backup.Open(path, "wrong")
+		assert.ErrorIs(t, err, domain.ErrBackupPassphrase)
+	})
+
+	t.Run("not an archive", func(t *testing.T) {
+		err := verify(write(t, []byte(strings.Repeat("not a backup ", 10))))
+		assert.ErrorIs(t, err, domain.ErrInvalidBackup)
+		assert.ErrorContains(t, err, "not a backup archive")
+	})
+
+	t.Run("excessive key derivation parameters", func(t *testing.T) {
+		// The argon2 time and memory follow the magic and the version byte
+		for _, offset := range []int{9, 13} {
+			modified := bytes.Clone(data)
+			binary.BigEndian.PutUint32(modified[offset:], 1<<30)
+			err := verify(write(t, modified))
+			assert.ErrorIs(t, err, domain.ErrInvalidBackup)
+			assert.ErrorContains(t, err, "key derivation parameters exceed the limits")
+		}
+	})
+
+	t.Run("modified byte", func(t *testing.T) {
+		modified := bytes.Clone(data)
+		modified[len(modified)-100] ^= 1
+		assert.ErrorIs(t, verify(write(t, modified)), domain.ErrInvalidBackup)
+	})
+
+	t.Run("truncated at a chunk", func(t *testing.T) {
+		// The header is 41 bytes, every full chunk 64 KiB and a 16 byte tag
+		truncated := data[:41+2*(64*1024+16)]
+		assert.ErrorIs(t, verify(write(t, truncated)), domain.ErrInvalidBackup)
+	})
+
+	t.Run("truncated within a chunk", func(t *testing.T) {
+		assert.ErrorIs(t, verify(write(t, data[:len(data)-1])), domain.ErrInvalidBackup)
+	})
+}
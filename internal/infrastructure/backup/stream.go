@@ -0,0 +1,291 @@
+package backup
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"golang.org/x/crypto/argon2"
+
+	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/domain"
+)
+
+// magic starts every backup archive.
+const magic = "GKBACKUP"
+
+// headerVersion is the version of the encryption header.
+const headerVersion = 1
+
+const (
+	keyLength     = 32
+	saltLength    = 16
+	prefixLength  = 7
+	argon2Time    = 3
+	argon2Memory  = 64 * 1024
+	argon2Threads = 4
+
+	// Upper bounds of the key derivation parameters read from an archive, so a crafted
+	// header cannot make opening it allocate gigabytes or run for hours.
+	// Threads are bounded to 255 by their encoding.
+	maxArgon2Time   = 10
+	maxArgon2Memory = 1024 * 1024 // KiB, 1 GiB
+
+	// chunkSize is the size of the plaintext chunks that are sealed one by one.
+	chunkSize = 64 * 1024
+)
+
+// header holds the key derivation parameters and the nonce prefix of an archive.
+// The encoded header authenticates every chunk as additional data.
+//
+//	magic | version | argon2 time, memory (uint32) | threads (uint8) | salt | nonce prefix
+type header struct {
+	time    uint32
+	memory  uint32
+	threads uint8
+	salt    []byte
+	prefix  []byte
+}
+
+// headerLength is the length of an encoded header.
+const headerLength = len(magic) + 1 + 4 + 4 + 1 + saltLength + prefixLength
+
+// newHeader generates a header with a random salt and nonce prefix.
+func newHeader() (header, error) {
+	random := make([]byte, saltLength+prefixLength)
+	if _, err := rand.Read(random); err != nil {
+		return header{}, fmt.Errorf("failed to generate salt: %w", err)
+	}
+	return header{
+		time:    argon2Time,
+		memory:  argon2Memory,
+		threads: argon2Threads,
+		salt:    random[:saltLength],
+		prefix:  random[saltLength:],
+	}, nil
+}
+
+// encode returns the binary form of the header.
+func (h header) encode() []byte {
+	b := make([]byte, 0, headerLength)
+	b = append(b, magic...)
+	b = append(b, headerVersion)
+	b = binary.BigEndian.AppendUint32(b, h.time)
+	b = binary.BigEndian.AppendUint32(b, h.memory)
+	b = append(b, h.threads)
+	b = append(b, h.salt...)
+	return append(b, h.prefix...)
+}
+
+// readHeader reads and decodes the header at the start of an archive.
+func readHeader(r io.Reader) (header, []byte, error) {
+	b := make([]byte, headerLength)
+	if _, err := io.ReadFull(r, b); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return header{}, nil, fmt.Errorf("%w: file is too short", domain.ErrInvalidBackup)
+		}
+		return header{}, nil, fmt.Errorf("failed to read backup header: %w", err)
+	}
+	if string(b[:len(magic)]) != magic {
+		return header{}, nil, fmt.Errorf("%w: not a backup archive", domain.ErrInvalidBackup)
+	}
+	rest := b[len(magic):]
+	if rest[0] != headerVersion {
+		return header{}, nil, fmt.Errorf("%w: unsupported version %d", domain.ErrInvalidBackup, rest[0])
+	}
+
+	h := header{
+		time:    binary.BigEndian.Uint32(rest[1:5]),
+		memory:  binary.BigEndian.Uint32(rest[5:9]),
+		threads: rest[9],
+		salt:    rest[10 : 10+saltLength],
+		prefix:  rest[10+saltLength:],
+	}
+	if h.time == 0 || h.memory == 0 || h.threads == 0 {
+		return header{}, nil, fmt.Errorf("%w: invalid key derivation parameters", domain.ErrInvalidBackup)
+	}
+	if h.time > maxArgon2Time || h.memory > maxArgon2Memory {
+		return header{}, nil, fmt.Errorf("%w: key derivation parameters exceed the limits", domain.ErrInvalidBackup)
+	}
+	return h, b, nil
+}
+
+// deriveAEAD derives the archive key from the passphrase with Argon2id and wraps it into AES-256-GCM.
+func (h header) deriveAEAD(passphrase string) (cipher.AEAD, error) {
+	key := argon2.IDKey([]byte(passphrase), h.salt, h.time, h.memory, h.threads, keyLength)
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create block cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AEAD: %w", err)
+	}
+	return aead, nil
+}
+
+// chunkNonce builds the nonce of a chunk from the prefix, the chunk counter and a flag
+// marking the final chunk, so chunks cannot be reordered, dropped or truncated unnoticed.
+func chunkNonce(prefix []byte, counter uint32, final bool) []byte {
+	nonce := make([]byte, 0, prefixLength+5)
+	nonce = append(nonce, prefix...)
+	nonce = binary.BigEndian.AppendUint32(nonce, counter)
+	if final {
+		return append(nonce, 1)
+	}
+	return append(nonce, 0)
+}
+
+// encrypter seals the plaintext written to it in chunks.
+type encrypter struct {
+	w       io.Writer
+	aead    cipher.AEAD
+	header  []byte
+	prefix  []byte
+	counter uint32
+	buf     []byte
+	sealed  []byte
+}
+
+// newEncrypter writes the header and returns an encrypter for the rest of the archive.
+func newEncrypter(w io.Writer, passphrase string) (*encrypter, error) {
+	h, err := newHeader()
+	if err != nil {
+		return nil, err
+	}
+	aead, err := h.deriveAEAD(passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	encoded := h.encode()
+	if _, err := w.Write(encoded); err != nil {
+		return nil, fmt.Errorf("failed to write backup header: %w", err)
+	}
+	return &encrypter{
+		w:      w,
+		aead:   aead,
+		header: encoded,
+		prefix: h.prefix,
+		buf:    make([]byte, 0, chunkSize),
+		sealed: make([]byte, 0, chunkSize+aead.Overhead()),
+	}, nil
+}
+
+// Write buffers p and seals every chunk that is followed by more data.
+// The last chunk is only sealed by Close, as it is marked as final.
+func (e *encrypter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		if len(e.buf) == chunkSize {
+			if err := e.seal(false); err != nil {
+				return written, err
+			}
+		}
+		n := copy(e.buf[len(e.buf):chunkSize], p)
+		e.buf = e.buf[:len(e.buf)+n]
+		p = p[n:]
+		written += n
+	}
+	return written, nil
+}
+
+// Close seals the final chunk, which may be empty.
+func (e *encrypter) Close() error {
+	return e.seal(true)
+}
+
+// seal encrypts the buffered chunk and writes it.
+func (e *encrypter) seal(final bool) error {
+	if e.counter == math.MaxUint32 {
+		return fmt.Errorf("backup archive is too large")
+	}
+	e.sealed = e.aead.Seal(e.sealed[:0], chunkNonce(e.prefix, e.counter, final), e.buf, e.header)
+	if _, err := e.w.Write(e.sealed); err != nil {
+		return fmt.Errorf("failed to write backup archive: %w", err)
+	}
+	e.counter++
+	e.buf = e.buf[:0]
+	return nil
+}
+
+// decrypter authenticates and decrypts the chunks of an archive.
+// It returns io.EOF only after the chunk marked as final.
+type decrypter struct {
+	r       *bufio.Reader
+	aead    cipher.AEAD
+	header  []byte
+	prefix  []byte
+	counter uint32
+	done    bool
+	err     error
+	sealed  []byte
+	plain   []byte
+	pending []byte
+}
+
+// newDecrypter returns a decrypter for the chunks following the header.
+func newDecrypter(r io.Reader, aead cipher.AEAD, h header, encoded []byte) *decrypter {
+	return &decrypter{
+		r:      bufio.NewReaderSize(r, chunkSize+aead.Overhead()),
+		aead:   aead,
+		header: encoded,
+		prefix: h.prefix,
+		sealed: make([]byte, chunkSize+aead.Overhead()),
+		plain:  make([]byte, 0, chunkSize),
+	}
+}
+
+// Read returns decrypted plaintext. Errors are sticky, nothing is returned after a damaged chunk.
+func (d *decrypter) Read(p []byte) (int, error) {
+	for len(d.pending) == 0 {
+		if d.done {
+			return 0, io.EOF
+		}
+		if d.err != nil {
+			return 0, d.err
+		}
+		d.err = d.open()
+	}
+	n := copy(p, d.pending)
+	d.pending = d.pending[n:]
+	return n, nil
+}
+
+// open reads and decrypts the next chunk. A chunk is final if no data follows it.
+func (d *decrypter) open() error {
+	n, err := io.ReadFull(d.r, d.sealed)
+	final := false
+	switch {
+	case errors.Is(err, io.EOF):
+		return fmt.Errorf("%w: archive is truncated", domain.ErrInvalidBackup)
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		final = true
+	case err != nil:
+		return fmt.Errorf("failed to read backup archive: %w", err)
+	default:
+		_, err = d.r.Peek(1)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("failed to read backup archive: %w", err)
+		}
+		final = errors.Is(err, io.EOF)
+	}
+
+	d.plain, err = d.aead.Open(d.plain[:0], chunkNonce(d.prefix, d.counter, final), d.sealed[:n], d.header)
+	if err != nil {
+		if d.counter == 0 {
+			return domain.ErrBackupPassphrase
+		}
+		return fmt.Errorf("%w: chunk %d is damaged or the archive is truncated", domain.ErrInvalidBackup, d.counter)
+	}
+	d.counter++
+	d.pending = d.plain
+	d.done = final
+	return nil
+}
@@ -0,0 +1,169 @@
+package breach
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/domain"
+)
+
+// hashLength is the length of a hex SHA-1 hash.
+const hashLength = 40
+
+// linearScanSize is the size of the file range below which lines are scanned one by one.
+const linearScanSize = 4096
+
+// HashFile implements domain.BreachCorpus on top of a Pwned Passwords SHA-1 file ordered by hash.
+// Every line holds an uppercase hex hash, optionally followed by a colon and the number of times it was seen:
+//
+//	000000005AD76BD555C1D6D771DE417A4B87E4B4:10
+//
+// Lookups use binary search, so the file is never loaded into memory.
+type HashFile struct {
+	file *os.File
+	size int64
+}
+
+// Open opens the hash file at path and checks that it looks like a Pwned Passwords SHA-1 file.
+func Open(path string) (*HashFile, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open hash file: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to stat hash file: %w", err)
+	}
+
+	h := &HashFile{file: file, size: info.Size()}
+	line, _, err := h.lineAt(0)
+	if err == nil {
+		_, _, err = parseLine(line)
+	}
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("%w: '%s' is not a SHA-1 hash file: %v", domain.ErrInvalidBreachCorpus, path, err)
+	}
+	return h, nil
+}
+
+// Count returns how many times the password was seen in data breaches, zero if never.
+// Hashes without a count are counted once.
+func (h *HashFile) Count(password string) (int64, error) {
+	hash := []byte(domain.PasswordSHA1(password))
+
+	// lo is always the start of a line, hi the start of a line or the end of the file
+	lo, hi := int64(0), h.size
+	for hi-lo > linearScanSize {
+		mid := lo + (hi-lo)/2
+		start, err := h.nextLineStart(mid)
+		if err != nil {
+			return 0, err
+		}
+		if start >= hi {
+			break
+		}
+
+		line, next, err := h.lineAt(start)
+		if err != nil {
+			return 0, err
+		}
+		key, count, err := parseLine(line)
+		if err != nil {
+			return 0, fmt.Errorf("%w: line at offset %d: %v", domain.ErrInvalidBreachCorpus, start, err)
+		}
+
+		switch c := bytes.Compare(key, hash); {
+		case c == 0:
+			return count, nil
+		case c < 0:
+			lo = next
+		default:
+			hi = start
+		}
+	}
+
+	return h.scan(lo, hi, hash)
+}
+
+// Close closes the hash file.
+func (h *HashFile) Close() error {
+	return h.file.Close()
+}
+
+// scan looks for the hash in the lines starting between lo and hi.
+func (h *HashFile) scan(lo, hi int64, hash []byte) (int64, error) {
+	reader := bufio.NewReader(io.NewSectionReader(h.file, lo, h.size-lo))
+	for offset := lo; offset < hi; {
+		line, err := reader.ReadBytes('\n')
+		if len(line) == 0 && err != nil {
+			if err == io.EOF {
+				return 0, nil
+			}
+			return 0, fmt.Errorf("failed to read hash file: %w", err)
+		}
+		offset += int64(len(line))
+
+		key, count, parseErr := parseLine(line)
+		if parseErr != nil {
+			return 0, fmt.Errorf("%w: line before offset %d: %v", domain.ErrInvalidBreachCorpus, offset, parseErr)
+		}
+		switch c := bytes.Compare(key, hash); {
+		case c == 0:
+			return count, nil
+		case c > 0:
+			return 0, nil
+		}
+	}
+	return 0, nil
+}
+
+// nextLineStart returns the offset of the first line starting at or after offset.
+func (h *HashFile) nextLineStart(offset int64) (int64, error) {
+	if offset == 0 {
+		return 0, nil
+	}
+	_, next, err := h.lineAt(offset - 1)
+	return next, err
+}
+
+// lineAt reads the rest of the line at offset and returns it with the offset of the next line.
+func (h *HashFile) lineAt(offset int64) ([]byte, int64, error) {
+	reader := bufio.NewReader(io.NewSectionReader(h.file, offset, h.size-offset))
+	line, err := reader.ReadBytes('\n')
+	if err != nil && err != io.EOF {
+		return nil, 0, fmt.Errorf("failed to read hash file: %w", err)
+	}
+	return line, offset + int64(len(line)), nil
+}
+
+// parseLine splits a line into its uppercase hash and count.
+// Lowercase hashes sort the same way and are accepted too.
+func parseLine(line []byte) ([]byte, int64, error) {
+	line = bytes.TrimRight(line, "\r\n")
+	hash, count, found := bytes.Cut(line, []byte(":"))
+	if len(hash) != hashLength {
+		return nil, 0, fmt.Errorf("expected a %d character hash, got %q", hashLength, line)
+	}
+	hash = bytes.ToUpper(hash)
+	for _, c := range hash {
+		if !(c >= '0' && c <= '9' || c >= 'A' && c <= 'F') {
+			return nil, 0, fmt.Errorf("expected a hex hash, got %q", line)
+		}
+	}
+
+	if !found {
+		return hash, 1, nil
+	}
+	n, err := strconv.ParseInt(string(count), 10, 64)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid count in %q", line)
+	}
+	return hash, n, nil
+}
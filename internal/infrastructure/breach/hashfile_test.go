@@ -0,0 +1,88 @@
+package breach_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/domain"
+	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/infrastructure/breach"
+)
+
+// writeHashFile writes a sorted hash file with the passwords seen i+1 times and returns its path.
+func writeHashFile(t *testing.T, passwords []string, lineEnd string, withCounts bool) string {
+	t.Helper()
+
+	lines := make([]string, len(passwords))
+	for i, password := range passwords {
+		lines[i] = domain.PasswordSHA1(password)
+		if withCounts {
+			lines[i] += fmt.Sprintf(":%d", i+1)
+		}
+	}
+	sort.Strings(lines)
+
+	path := filepath.Join(t.TempDir(), "pwned-passwords-sha1-ordered-by-hash.txt")
+	require.NoError(t, os.WriteFile(path, []byte(strings.Join(lines, lineEnd)+lineEnd), 0600))
+	return path
+}
+
+func TestHashFile_Count(t *testing.T) {
+	passwords := make([]string, 5000)
+	for i := range passwords {
+		passwords[i] = fmt.Sprintf("password%d", i)
+	}
+
+	tests := []struct {
+		name       string
+		lineEnd    string
+		withCounts bool
+	}{
+		{name: "crlf with counts", lineEnd: "\r\n", withCounts: true},
+		{name: "lf without counts", lineEnd: "\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			corpus, err := breach.Open(writeHashFile(t, passwords, tt.lineEnd, tt.withCounts))
+			require.NoError(t, err)
+			defer corpus.Close()
+
+			for i, password := range passwords {
+				count, err := corpus.Count(password)
+				require.NoError(t, err)
+				if tt.withCounts {
+					require.Equal(t, int64(i+1), count, password)
+				} else {
+					require.Equal(t, int64(1), count, password)
+				}
+			}
+
+			for _, password := range []string{"", "correct horse battery staple", "password5000"} {
+				count, err := corpus.Count(password)
+				require.NoError(t, err)
+				assert.Zero(t, count, password)
+			}
+		})
+	}
+}
+
+func TestOpen(t *testing.T) {
+	t.Run("missing file", func(t *testing.T) {
+		_, err := breach.Open(filepath.Join(t.TempDir(), "missing.txt"))
+		assert.ErrorContains(t, err, "failed to open hash file")
+	})
+
+	t.Run("not a hash file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "words.txt")
+		require.NoError(t, os.WriteFile(path, []byte("password\n123456\n"), 0600))
+
+		_, err := breach.Open(path)
+		assert.ErrorIs(t, err, domain.ErrInvalidBreachCorpus)
+	})
+}
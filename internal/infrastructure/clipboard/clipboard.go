@@ -0,0 +1,154 @@
+package clipboard
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/domain"
+)
+
+// pipeWaitDelay is how long a clipboard tool that exited may keep its stderr open.
+// xclip and wl-copy fork a child serving the selection, which inherits the pipe.
+const pipeWaitDelay = 500 * time.Millisecond
+
+// New creates the clipboard of the given backend.
+// The auto backend prefers wl-copy on Wayland and xclip on X11 if they are installed, and OSC 52 otherwise.
+func New(backend string) (domain.Clipboard, error) {
+	b, err := domain.ParseClipboardBackend(backend)
+	if err != nil {
+		return nil, err
+	}
+
+	switch b {
+	case domain.AutoClipboardBackend:
+		if os.Getenv("WAYLAND_DISPLAY") != "" && installed("wl-copy") {
+			return newWLCopy(), nil
+		}
+		if os.Getenv("DISPLAY") != "" && installed("xclip") {
+			return newXClip(), nil
+		}
+		return NewOSC52(nil), nil
+	case domain.XClipClipboardBackend:
+		return newXClip(), nil
+	case domain.WLCopyClipboardBackend:
+		return newWLCopy(), nil
+	default:
+		return NewOSC52(nil), nil
+	}
+}
+
+// OSC52 copies text with the OSC 52 terminal escape sequence, which also works over SSH.
+// The sequence is written to the controlling terminal, never to stdout.
+type OSC52 struct {
+	terminal io.Writer
+}
+
+// NewOSC52 creates an OSC 52 clipboard writing to the terminal, or to /dev/tty if it is nil.
+func NewOSC52(terminal io.Writer) *OSC52 {
+	return &OSC52{terminal: terminal}
+}
+
+// Copy sets the clipboard content.
+func (c *OSC52) Copy(text string) error {
+	return c.write(base64.StdEncoding.EncodeToString([]byte(text)))
+}
+
+// Clear sets the clipboard content to an empty string. Terminals do not let OSC 52
+// read the clipboard back, so it is cleared even if something else was copied since.
+func (c *OSC52) Clear(string) (bool, error) {
+	if err := c.write(""); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// write sends the sequence with the base64 payload, wrapped for tmux if needed.
+func (c *OSC52) write(payload string) error {
+	sequence := "\x1b]52;c;" + payload + "\a"
+	if os.Getenv("TMUX") != "" {
+		// tmux passes sequences wrapped in a DCS through to the outer terminal
+		sequence = "\x1bPtmux;" + strings.ReplaceAll(sequence, "\x1b", "\x1b\x1b") + "\x1b\\"
+	}
+
+	terminal := c.terminal
+	if terminal == nil {
+		tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+		if err != nil {
+			return fmt.Errorf("failed to open terminal: %w", err)
+		}
+		defer tty.Close()
+		terminal = tty
+	}
+
+	if _, err := io.WriteString(terminal, sequence); err != nil {
+		return fmt.Errorf("failed to write to terminal: %w", err)
+	}
+	return nil
+}
+
+// command copies text by piping it to a clipboard tool.
+type command struct {
+	copyArgs  []string
+	pasteArgs []string
+	clearArgs []string
+}
+
+// newXClip creates a clipboard using xclip on X11.
+func newXClip() *command {
+	args := []string{"xclip", "-selection", "clipboard"}
+	return &command{copyArgs: args, pasteArgs: []string{"xclip", "-selection", "clipboard", "-o"}, clearArgs: args}
+}
+
+// newWLCopy creates a clipboard using wl-copy on Wayland.
+func newWLCopy() *command {
+	return &command{
+		copyArgs:  []string{"wl-copy"},
+		pasteArgs: []string{"wl-paste", "--no-newline"},
+		clearArgs: []string{"wl-copy", "--clear"},
+	}
+}
+
+// Copy sets the clipboard content.
+func (c *command) Copy(text string) error {
+	_, err := run(c.copyArgs, text)
+	return err
+}
+
+// Clear removes the clipboard content if it is still the text.
+// A clipboard that cannot be read, such as an empty one, is cleared.
+func (c *command) Clear(text string) (bool, error) {
+	content, err := run(c.pasteArgs, "")
+	if err == nil && content != text {
+		return false, nil
+	}
+	if _, err := run(c.clearArgs, ""); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// run runs the tool with the text on its stdin and returns its output.
+func run(args []string, text string) (string, error) {
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = strings.NewReader(text)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	cmd.WaitDelay = pipeWaitDelay
+	if err := cmd.Run(); err != nil && !errors.Is(err, exec.ErrWaitDelay) {
+		return "", fmt.Errorf("%s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
+
+// installed reports whether the tool is on the PATH.
+func installed(name string) bool {
+	_, err := exec.LookPath(name)
+	return err == nil
+}
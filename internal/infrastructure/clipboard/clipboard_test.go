@@ -0,0 +1,105 @@
+package clipboard_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/domain"
+	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/infrastructure/clipboard"
+)
+
+func TestOSC52(t *testing.T) {
+	t.Setenv("TMUX", "")
+
+	var terminal bytes.Buffer
+	c := clipboard.NewOSC52(&terminal)
+
+	require.NoError(t, c.Copy("hunter2"))
+	assert.Equal(t, "\x1b]52;c;aHVudGVyMg==\a", terminal.String())
+
+	// The clipboard cannot be read back, so it is cleared whatever it holds
+	terminal.Reset()
+	cleared, err := c.Clear("hunter2")
+	require.NoError(t, err)
+	assert.True(t, cleared)
+	assert.Equal(t, "\x1b]52;c;\a", terminal.String())
+}
+
+func TestOSC52_Tmux(t *testing.T) {
+	t.Setenv("TMUX", "/tmp/tmux-1000/default,1,0")
+
+	var terminal bytes.Buffer
+	require.NoError(t, clipboard.NewOSC52(&terminal).Copy("hunter2"))
+	assert.Equal(t, "\x1bPtmux;\x1b\x1b]52;c;aHVudGVyMg==\a\x1b\\", terminal.String())
+}
+
+func TestNew(t *testing.T) {
+	for _, backend := range []string{"osc52", "xclip", "wl-copy", "auto"} {
+		c, err := clipboard.New(backend)
+		assert.NoError(t, err, backend)
+		assert.NotNil(t, c, backend)
+	}
+
+	_, err := clipboard.New("pbcopy")
+	assert.ErrorIs(t, err, domain.ErrUnknownClipboard)
+	assert.EqualError(t, err, "unknown clipboard backend 'pbcopy' (must be auto, osc52, xclip or wl-copy)")
+}
+
+func TestXClip_ForkedSelectionOwner(t *testing.T) {
+	// Like xclip, the fake tool leaves a child serving the selection that keeps stderr open
+	dir := t.TempDir()
+	script := "#!/bin/sh\ncat > \"$0.out\"\nsleep 10 &\nexit 0\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "xclip"), []byte(script), 0700))
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	c, err := clipboard.New(string(domain.XClipClipboardBackend))
+	require.NoError(t, err)
+
+	start := time.Now()
+	require.NoError(t, c.Copy("hunter2"))
+	assert.Less(t, time.Since(start), 5*time.Second)
+
+	copied, err := os.ReadFile(filepath.Join(dir, "xclip.out"))
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", string(copied))
+}
+
+func TestXClip_Clear(t *testing.T) {
+	// The fake tool keeps the selection in a file: -o prints it, otherwise stdin replaces it
+	dir := t.TempDir()
+	selection := filepath.Join(dir, "selection")
+	script := "#!/bin/sh\nfor a; do [ \"$a\" = -o ] && exec cat \"" + selection + "\"; done\ncat > \"" + selection + "\"\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "xclip"), []byte(script), 0700))
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	c, err := clipboard.New(string(domain.XClipClipboardBackend))
+	require.NoError(t, err)
+
+	t.Run("clears the copied text", func(t *testing.T) {
+		require.NoError(t, c.Copy("hunter2"))
+		cleared, err := c.Clear("hunter2")
+		require.NoError(t, err)
+		assert.True(t, cleared)
+
+		content, err := os.ReadFile(selection)
+		require.NoError(t, err)
+		assert.Empty(t, content)
+	})
+
+	t.Run("keeps text copied since", func(t *testing.T) {
+		require.NoError(t, c.Copy("hunter2"))
+		require.NoError(t, os.WriteFile(selection, []byte("copied later"), 0600))
+		cleared, err := c.Clear("hunter2")
+		require.NoError(t, err)
+		assert.False(t, cleared)
+
+		content, err := os.ReadFile(selection)
+		require.NoError(t, err)
+		assert.Equal(t, "copied later", string(content))
+	})
+}
@@ -1,40 +1,103 @@
 package config
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/caarlos0/env"
 	"github.com/joho/godotenv"
+	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/domain"
+	"gopkg.in/yaml.v3"
 )
 
 // Config holds application configuration parameters.
+// Values are layered: defaults, config file, environment variables, profile and command line flags.
 type Config struct {
-	GRPCRunAddr string        `env:"GRPC_RUN_ADDRESS"` // gRPC server address
-	LogLvl      string        `env:"LOGLVL"`           // Logging level (Debug, Info, Warn, Error)
-	GRPCTimeout time.Duration `env:"GRPC_TIMEOUT"`
-	TLSCertPath string        `env:"TLS_CERT_PATH"`
+	GRPCRunAddr string        `env:"GRPC_RUN_ADDRESS" yaml:"server,omitempty"` // gRPC server address
+	LogLvl      string        `env:"LOGLVL" yaml:"log_level,omitempty"`        // Logging level (Debug, Info, Warn, Error)
+	GRPCTimeout time.Duration `env:"GRPC_TIMEOUT" yaml:"timeout,omitempty"`
+	TLSCertPath string        `env:"TLS_CERT_PATH" yaml:"tls_cert,omitempty"`
+	BreachFile  string        `env:"BREACH_FILE" yaml:"breach_file,omitempty"` // Pwned Passwords SHA-1 file
+	// Clipboard backend for --copy and the delay after which the clipboard is cleared
+	Clipboard           string        `env:"CLIPBOARD" yaml:"clipboard,omitempty"`
+	ClipboardClearAfter time.Duration `env:"CLIPBOARD_CLEAR_AFTER" yaml:"clipboard_clear_after,omitempty"`
+	// Accept secrets stored before client-side encryption was introduced, with a warning
+	AllowPlaintext bool `env:"ALLOW_PLAINTEXT" yaml:"allow_plaintext,omitempty"`
+	// Master password for client-side encryption; prompted interactively if empty.
+	// It is never read from or written to the config file.
+	MasterPassword string `env:"MASTER_PASSWORD" yaml:"-"`
 }
 
-// Parse loads configuration from environment variables with fallback to defaults.
-// Returns the configuration or an error if environment loading fails.
-func Parse() (*Config, error) {
+// Overrides holds configuration values set on the command line. Zero values are not applied.
+type Overrides struct {
+	GRPCRunAddr string
+	LogLvl      string
+	GRPCTimeout time.Duration
+	TLSCertPath string
+}
+
+// Parse loads configuration from the config file and environment variables with fallback to defaults.
+// A missing config file is not an error. An empty path selects the default config file path.
+// The result is not validated, as profile and flag overrides are applied afterwards; call Validate.
+func Parse(path string) (*Config, error) {
 	// Try to load .env file if it exists (optional)
 	_ = godotenv.Load()
 
 	conf := GetDefault()
 
+	path, err := resolvePath(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := loadFile(path, conf); err != nil {
+		return nil, err
+	}
+
 	if err := env.Parse(conf); err != nil {
 		return nil, fmt.Errorf("failed to parse config from env variables: %w", err)
 	}
 
-	if err := validateConfig(conf); err != nil {
-		return nil, fmt.Errorf("failed to validate config: %w", err)
+	return conf, nil
+}
+
+// Validate checks if the configuration values are valid.
+func (c *Config) Validate() error {
+	if err := validateConfig(c); err != nil {
+		return fmt.Errorf("failed to validate config: %w", err)
 	}
+	return nil
+}
 
-	return conf, nil
+// ApplyProfile overrides the connection settings with the values set in the profile.
+func (c *Config) ApplyProfile(p domain.Profile) {
+	c.apply(Overrides{GRPCRunAddr: p.ServerAddr, TLSCertPath: p.TLSCertPath, GRPCTimeout: p.Timeout})
+}
+
+// ApplyOverrides overrides the configuration with the values set on the command line.
+func (c *Config) ApplyOverrides(o Overrides) {
+	c.apply(o)
+}
+
+// apply overrides the configuration with the non-zero values.
+func (c *Config) apply(o Overrides) {
+	if o.GRPCRunAddr != "" {
+		c.GRPCRunAddr = o.GRPCRunAddr
+	}
+	if o.LogLvl != "" {
+		c.LogLvl = o.LogLvl
+	}
+	if o.GRPCTimeout > 0 {
+		c.GRPCTimeout = o.GRPCTimeout
+	}
+	if o.TLSCertPath != "" {
+		c.TLSCertPath = o.TLSCertPath
+	}
 }
 
 // GetDefault returns the default configuration values.
@@ -44,9 +107,58 @@ func GetDefault() (conf *Config) {
 		LogLvl:      "Info",
 		GRPCTimeout: 30 * time.Second,
 		TLSCertPath: "",
+
+		Clipboard:           string(domain.DefaultClipboardBackend),
+		ClipboardClearAfter: 30 * time.Second,
 	}
 }
 
+// DefaultPath returns the XDG-compliant path of the config file,
+// $XDG_CONFIG_HOME/gophkeeper-cli/config.yaml or ~/.config/gophkeeper-cli/config.yaml.
+func DefaultPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to determine config directory: %w", err)
+	}
+	return filepath.Join(dir, "gophkeeper-cli", "config.yaml"), nil
+}
+
+// resolvePath returns the given config file path, or the default one if it is empty.
+func resolvePath(path string) (string, error) {
+	if path != "" {
+		return path, nil
+	}
+	return DefaultPath()
+}
+
+// loadFile decodes the config file at path into conf, keeping values missing in the file.
+// Unknown keys are rejected to catch typos.
+func loadFile(path string, conf *Config) error {
+	data, err := readFile(path)
+	if err != nil || len(data) == 0 {
+		return err
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(conf); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to parse config file '%s': %w", path, err)
+	}
+	return nil
+}
+
+// readFile reads the config file. A missing file is returned as empty content.
+func readFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	return data, nil
+}
+
 // normalizeLogLevel standardizes the log level string format.
 func normalizeLogLevel(level string) string {
 	return strings.ToLower(strings.TrimSpace(level))
@@ -58,6 +170,18 @@ func validateConfig(c *Config) error {
 		return errors.New("GRPC_RUN_ADDRESS cannot be empty")
 	}
 
+	if c.GRPCTimeout <= 0 {
+		return fmt.Errorf("invalid timeout: %s (must be positive)", c.GRPCTimeout)
+	}
+
+	if _, err := domain.ParseClipboardBackend(c.Clipboard); err != nil {
+		return fmt.Errorf("invalid clipboard: %w", err)
+	}
+
+	if c.ClipboardClearAfter < 0 {
+		return fmt.Errorf("invalid clipboard clear delay: %s (must not be negative)", c.ClipboardClearAfter)
+	}
+
 	switch normalizeLogLevel(c.LogLvl) {
 	case "debug", "info", "warn", "error":
 		return nil
@@ -0,0 +1,88 @@
+package config_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/domain"
+	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/infrastructure/config"
+)
+
+func TestParse_Precedence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("server: file:1\ntimeout: 5s\nlog_level: debug\n"), 0600))
+
+	t.Setenv("GRPC_RUN_ADDRESS", "env:2")
+	t.Setenv("LOGLVL", "")
+
+	conf, err := config.Parse(path)
+	require.NoError(t, err)
+	assert.Equal(t, "env:2", conf.GRPCRunAddr)
+	assert.Equal(t, 5*time.Second, conf.GRPCTimeout)
+	assert.Equal(t, "debug", conf.LogLvl)
+
+	conf.ApplyProfile(domain.Profile{Name: "staging", ServerAddr: "profile:3", TLSCertPath: "staging.crt"})
+	conf.ApplyOverrides(config.Overrides{GRPCRunAddr: "flag:4", LogLvl: "warn"})
+
+	assert.Equal(t, "flag:4", conf.GRPCRunAddr)
+	assert.Equal(t, "staging.crt", conf.TLSCertPath)
+	assert.Equal(t, "warn", conf.LogLvl)
+	assert.Equal(t, 5*time.Second, conf.GRPCTimeout)
+	assert.NoError(t, conf.Validate())
+}
+
+func TestParse_InvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("sever: typo:1\n"), 0600))
+
+	_, err := config.Parse(path)
+	assert.Error(t, err)
+}
+
+func TestService(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "gophkeeper-cli", "config.yaml")
+
+	effective := config.GetDefault()
+	service, err := config.NewService(path, effective)
+	require.NoError(t, err)
+
+	t.Run("set keeps other keys", func(t *testing.T) {
+		require.NoError(t, service.SetSetting(ctx, "server", "example.com:443"))
+		require.NoError(t, service.SetSetting(ctx, "timeout", "10s"))
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, "server: example.com:443\ntimeout: 10s\n", string(data))
+
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	})
+
+	t.Run("set invalid value", func(t *testing.T) {
+		assert.ErrorIs(t, service.SetSetting(ctx, "log_level", "verbose"), domain.ErrInvalidConfig)
+		assert.ErrorIs(t, service.SetSetting(ctx, "timeout", "soon"), domain.ErrInvalidConfig)
+		assert.ErrorIs(t, service.SetSetting(ctx, "allow_plaintext", "maybe"), domain.ErrInvalidConfig)
+		assert.ErrorIs(t, service.SetSetting(ctx, "clipboard", "pbcopy"), domain.ErrInvalidConfig)
+		assert.ErrorIs(t, service.SetSetting(ctx, "password", "secret"), domain.ErrUnknownConfigKey)
+	})
+
+	t.Run("get reports effective value", func(t *testing.T) {
+		value, err := service.GetSetting(ctx, "server")
+		require.NoError(t, err)
+		assert.Equal(t, effective.GRPCRunAddr, value)
+	})
+
+	t.Run("validate", func(t *testing.T) {
+		assert.NoError(t, service.Validate(ctx))
+
+		require.NoError(t, os.WriteFile(path, []byte("log_level: verbose\n"), 0600))
+		assert.ErrorIs(t, service.Validate(ctx), domain.ErrInvalidConfig)
+	})
+}
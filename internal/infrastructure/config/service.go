@@ -0,0 +1,194 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/domain"
+	"gopkg.in/yaml.v3"
+)
+
+// setting describes a configuration key that can be read and written by name.
+type setting struct {
+	key string
+	get func(c *Config) string
+	set func(c *Config, value string) error
+}
+
+// settings lists the configuration keys in display order. Keys match the config file keys.
+var settings = []setting{
+	{
+		key: "server",
+		get: func(c *Config) string { return c.GRPCRunAddr },
+		set: func(c *Config, v string) error { c.GRPCRunAddr = v; return nil },
+	},
+	{
+		key: "timeout",
+		get: func(c *Config) string { return c.GRPCTimeout.String() },
+		set: func(c *Config, v string) error {
+			timeout, err := time.ParseDuration(v)
+			if err != nil {
+				return fmt.Errorf("invalid timeout '%s': %w", v, err)
+			}
+			c.GRPCTimeout = timeout
+			return nil
+		},
+	},
+	{
+		key: "tls_cert",
+		get: func(c *Config) string { return c.TLSCertPath },
+		set: func(c *Config, v string) error { c.TLSCertPath = v; return nil },
+	},
+	{
+		key: "breach_file",
+		get: func(c *Config) string { return c.BreachFile },
+		set: func(c *Config, v string) error { c.BreachFile = v; return nil },
+	},
+	{
+		key: "clipboard",
+		get: func(c *Config) string { return c.Clipboard },
+		set: func(c *Config, v string) error { c.Clipboard = v; return nil },
+	},
+	{
+		key: "clipboard_clear_after",
+		get: func(c *Config) string { return c.ClipboardClearAfter.String() },
+		set: func(c *Config, v string) error {
+			delay, err := time.ParseDuration(v)
+			if err != nil {
+				return fmt.Errorf("invalid clipboard clear delay '%s': %w", v, err)
+			}
+			c.ClipboardClearAfter = delay
+			return nil
+		},
+	},
+	{
+		key: "allow_plaintext",
+		get: func(c *Config) string { return strconv.FormatBool(c.AllowPlaintext) },
+		set: func(c *Config, v string) error {
+			allow, err := strconv.ParseBool(v)
+			if err != nil {
+				return fmt.Errorf("invalid allow_plaintext '%s' (must be true or false)", v)
+			}
+			c.AllowPlaintext = allow
+			return nil
+		},
+	},
+	{
+		key: "log_level",
+		get: func(c *Config) string { return c.LogLvl },
+		set: func(c *Config, v string) error { c.LogLvl = v; return nil },
+	},
+}
+
+// Service implements domain.ConfigService on top of the YAML config file.
+type Service struct {
+	path      string
+	effective *Config
+}
+
+// NewService creates a new Service for the config file at path.
+// The effective configuration is used to report values after all overrides.
+func NewService(path string, effective *Config) (*Service, error) {
+	path, err := resolvePath(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Service{path: path, effective: effective}, nil
+}
+
+// Path returns the path of the config file.
+func (s *Service) Path() string {
+	return s.path
+}
+
+// ListSettings retrieves the effective value of every configuration key.
+func (s *Service) ListSettings(ctx context.Context) ([]domain.ConfigSetting, error) {
+	result := make([]domain.ConfigSetting, 0, len(settings))
+	for _, st := range settings {
+		result = append(result, domain.ConfigSetting{Key: st.key, Value: st.get(s.effective)})
+	}
+	return result, nil
+}
+
+// GetSetting retrieves the effective value of a configuration key.
+// Returns ErrUnknownConfigKey if the key does not exist.
+func (s *Service) GetSetting(ctx context.Context, key string) (string, error) {
+	st, err := findSetting(key)
+	if err != nil {
+		return "", err
+	}
+	return st.get(s.effective), nil
+}
+
+// SetSetting stores a value in the config file, keeping the other values in the file.
+// The file with the new value applied over defaults has to pass validation.
+func (s *Service) SetSetting(ctx context.Context, key, value string) error {
+	st, err := findSetting(key)
+	if err != nil {
+		return err
+	}
+
+	// fileConf holds only the values present in the file, merged holds them over defaults
+	fileConf, merged := &Config{}, GetDefault()
+	for _, c := range []*Config{fileConf, merged} {
+		if err := loadFile(s.path, c); err != nil {
+			return fmt.Errorf("%w: %v", domain.ErrInvalidConfig, err)
+		}
+		if err := st.set(c, value); err != nil {
+			return fmt.Errorf("%w: %v", domain.ErrInvalidConfig, err)
+		}
+	}
+
+	if err := validateConfig(merged); err != nil {
+		return fmt.Errorf("%w: %v", domain.ErrInvalidConfig, err)
+	}
+
+	data, err := yaml.Marshal(fileConf)
+	if err != nil {
+		return fmt.Errorf("failed to encode config file: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := os.WriteFile(s.path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	return nil
+}
+
+// Validate checks the config file over defaults and the effective configuration.
+func (s *Service) Validate(ctx context.Context) error {
+	fileConf := GetDefault()
+	if err := loadFile(s.path, fileConf); err != nil {
+		return fmt.Errorf("%w: %v", domain.ErrInvalidConfig, err)
+	}
+	if err := validateConfig(fileConf); err != nil {
+		return fmt.Errorf("%w: config file '%s': %v", domain.ErrInvalidConfig, s.path, err)
+	}
+
+	if err := validateConfig(s.effective); err != nil {
+		return fmt.Errorf("%w: %v", domain.ErrInvalidConfig, err)
+	}
+	return nil
+}
+
+// findSetting looks up a configuration key.
+// Returns ErrUnknownConfigKey if the key does not exist.
+func findSetting(key string) (*setting, error) {
+	for i := range settings {
+		if settings[i].key == key {
+			return &settings[i], nil
+		}
+	}
+
+	keys := make([]string, 0, len(settings))
+	for _, st := range settings {
+		keys = append(keys, st.key)
+	}
+	return nil, fmt.Errorf("%w '%s' (must be one of %s)", domain.ErrUnknownConfigKey, key, strings.Join(keys, ", "))
+}
@@ -0,0 +1,137 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"strconv"
+
+	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/domain"
+)
+
+// Bitwarden item types.
+const (
+	bitwardenLogin = 1
+	bitwardenNote  = 2
+	bitwardenCard  = 3
+)
+
+// Bitwarden imports the unencrypted JSON export of Bitwarden.
+// Logins become credentials, secure notes texts and cards payment cards; entries are named after
+// their folder and name. Custom fields are appended to the notes. Identities and other items are skipped.
+type Bitwarden struct{}
+
+type bitwardenExport struct {
+	Encrypted bool `json:"encrypted"`
+	Folders   []struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"folders"`
+	Items []bitwardenItem `json:"items"`
+}
+
+type bitwardenItem struct {
+	Type     int    `json:"type"`
+	Name     string `json:"name"`
+	Notes    string `json:"notes"`
+	FolderID string `json:"folderId"`
+	Login    *struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+		TOTP     string `json:"totp"`
+		URIs     []struct {
+			URI string `json:"uri"`
+		} `json:"uris"`
+	} `json:"login"`
+	Card *struct {
+		CardholderName string `json:"cardholderName"`
+		Number         string `json:"number"`
+		ExpMonth       string `json:"expMonth"`
+		ExpYear        string `json:"expYear"`
+		Code           string `json:"code"`
+	} `json:"card"`
+	Fields []struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	} `json:"fields"`
+}
+
+// Parse reads the JSON file at path.
+func (Bitwarden) Parse(filePath string) ([]domain.ImportEntry, []domain.SkippedImportEntry, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read Bitwarden export: %w", err)
+	}
+
+	var export bitwardenExport
+	if err := json.Unmarshal(data, &export); err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", domain.ErrInvalidImport, err)
+	}
+	if export.Encrypted {
+		return nil, nil, fmt.Errorf("%w, export the vault as unencrypted JSON", domain.ErrEncryptedImport)
+	}
+
+	folders := make(map[string]string, len(export.Folders))
+	for _, f := range export.Folders {
+		folders[f.ID] = f.Name
+	}
+
+	var (
+		entries []domain.ImportEntry
+		skipped []domain.SkippedImportEntry
+	)
+	for _, item := range export.Items {
+		name := path.Join(folders[item.FolderID], item.Name)
+		if item.Name == "" {
+			skipped = append(skipped, domain.SkippedImportEntry{Name: name, Reason: noNameReason})
+			continue
+		}
+
+		notes := item.Notes
+		for _, f := range item.Fields {
+			notes = appendNote(notes, f.Name+": "+f.Value)
+		}
+
+		switch {
+		case item.Type == bitwardenLogin && item.Login != nil:
+			credentials := domain.CredentialsSecret{
+				Login:    item.Login.Username,
+				Password: item.Login.Password,
+				OTP:      item.Login.TOTP,
+				Notes:    notes,
+			}
+			if len(item.Login.URIs) > 0 {
+				credentials.URL = item.Login.URIs[0].URI
+			}
+			if e, ok := entry(name, credentials); ok {
+				entries = append(entries, e)
+			} else {
+				skipped = append(skipped, domain.SkippedImportEntry{Name: name, Reason: emptyEntryReason})
+			}
+		case item.Type == bitwardenNote:
+			if notes == "" {
+				skipped = append(skipped, domain.SkippedImportEntry{Name: name, Reason: "note is empty"})
+				continue
+			}
+			entries = append(entries, domain.ImportEntry{Name: name, Type: domain.TextSecretType, Content: []byte(notes)})
+		case item.Type == bitwardenCard && item.Card != nil:
+			month, _ := strconv.Atoi(item.Card.ExpMonth)
+			year, _ := strconv.Atoi(item.Card.ExpYear)
+			entries = append(entries, domain.NewPaymentCardImportEntry(name, "", domain.PaymentCardSecret{
+				Number:      domain.NormalizeCardNumber(item.Card.Number),
+				Holder:      item.Card.CardholderName,
+				ExpiryMonth: month,
+				ExpiryYear:  year,
+				CVV:         item.Card.Code,
+				Notes:       notes,
+			}))
+		default:
+			skipped = append(skipped, domain.SkippedImportEntry{
+				Name:   name,
+				Reason: fmt.Sprintf("unsupported item type %d", item.Type),
+			})
+		}
+	}
+	return entries, skipped, nil
+}
@@ -0,0 +1,111 @@
+package importer
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/domain"
+)
+
+// csvColumns maps the header names used by 1Password, Chrome and Firefox exports onto credentials fields.
+var csvColumns = map[string][]string{
+	"name":     {"title", "name"},
+	"url":      {"url", "website", "login_uri"},
+	"login":    {"username", "login_username", "login"},
+	"password": {"password", "login_password"},
+	"notes":    {"notes", "note"},
+	"otp":      {"otpauth", "otp", "login_totp"},
+}
+
+// CSV imports the CSV exports of 1Password, Chrome and Firefox, matching columns by their header.
+// Rows are named after their title, or the host of their URL if there is none,
+// so a site with several accounts yields several entries of the same name.
+type CSV struct{}
+
+// Parse reads the CSV file at path.
+func (CSV) Parse(filePath string) ([]domain.ImportEntry, []domain.SkippedImportEntry, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read CSV export: %w", err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: failed to read header: %v", domain.ErrInvalidImport, err)
+	}
+	columns := csvHeaderColumns(header)
+	if _, ok := columns["password"]; !ok {
+		return nil, nil, fmt.Errorf("%w: no password column in header %q", domain.ErrInvalidImport, strings.Join(header, ","))
+	}
+
+	var (
+		entries []domain.ImportEntry
+		skipped []domain.SkippedImportEntry
+	)
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: %v", domain.ErrInvalidImport, err)
+		}
+		line, _ := reader.FieldPos(0)
+
+		field := func(key string) string {
+			i, ok := columns[key]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return record[i]
+		}
+
+		credentials := domain.CredentialsSecret{
+			Login:    field("login"),
+			Password: field("password"),
+			URL:      field("url"),
+			Notes:    field("notes"),
+			OTP:      field("otp"),
+		}
+		name := field("name")
+		if name == "" {
+			name = nameFromURL(credentials.URL)
+		}
+		if name == "" {
+			skipped = append(skipped, domain.SkippedImportEntry{Name: fmt.Sprintf("line %d", line), Reason: noNameReason})
+			continue
+		}
+
+		if e, ok := entry(name, credentials); ok {
+			entries = append(entries, e)
+		} else {
+			skipped = append(skipped, domain.SkippedImportEntry{Name: name, Reason: emptyEntryReason})
+		}
+	}
+	return entries, skipped, nil
+}
+
+// csvHeaderColumns returns the index of every known column in the header.
+func csvHeaderColumns(header []string) map[string]int {
+	columns := make(map[string]int)
+	for i, h := range header {
+		h = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))
+		for key, names := range csvColumns {
+			for _, name := range names {
+				if _, found := columns[key]; !found && h == name {
+					columns[key] = i
+				}
+			}
+		}
+	}
+	return columns
+}
@@ -0,0 +1,76 @@
+package importer
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/domain"
+)
+
+// Import formats.
+const (
+	KeePassFormat     = "keepass"
+	BitwardenFormat   = "bitwarden"
+	OnePasswordFormat = "1password"
+	ChromeFormat      = "chrome"
+	FirefoxFormat     = "firefox"
+	PassFormat        = "pass"
+)
+
+// Formats lists the supported import formats.
+var Formats = []string{KeePassFormat, BitwardenFormat, OnePasswordFormat, ChromeFormat, FirefoxFormat, PassFormat}
+
+// New creates the importer of the given format.
+func New(format string) (domain.Importer, error) {
+	switch format {
+	case KeePassFormat:
+		return KeePass{}, nil
+	case BitwardenFormat:
+		return Bitwarden{}, nil
+	case OnePasswordFormat, ChromeFormat, FirefoxFormat:
+		return CSV{}, nil
+	case PassFormat:
+		return NewPass(), nil
+	default:
+		return nil, fmt.Errorf("%w '%s' (must be %s)", domain.ErrUnknownImporter, format, strings.Join(Formats, ", "))
+	}
+}
+
+// emptyEntryReason is the reason entries without login, password and notes are skipped.
+const emptyEntryReason = "entry has no login, password or notes"
+
+// noNameReason is the reason entries without a title or URL are skipped.
+const noNameReason = "entry has no title or URL"
+
+// entry maps the fields shared by all password managers onto a secret.
+// Entries without login and password become text notes; false is returned if there are no notes either.
+func entry(name string, credentials domain.CredentialsSecret) (domain.ImportEntry, bool) {
+	if credentials.Login == "" && credentials.Password == "" {
+		if credentials.Notes == "" {
+			return domain.ImportEntry{}, false
+		}
+		return domain.ImportEntry{Name: name, Type: domain.TextSecretType, Content: []byte(credentials.Notes)}, true
+	}
+	return domain.NewCredentialsImportEntry(name, "", credentials), true
+}
+
+// nameFromURL returns the host of the URL without the www prefix, or an empty string.
+func nameFromURL(rawURL string) string {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(u.Hostname(), "www.")
+}
+
+// appendNote appends a line to the notes.
+func appendNote(notes, line string) string {
+	switch {
+	case notes == "":
+		return line
+	case line == "":
+		return notes
+	}
+	return notes + "\n" + line
+}
@@ -0,0 +1,282 @@
+package importer_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/domain"
+	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/infrastructure/importer"
+)
+
+// writeFile writes the content to a file in a temporary directory and returns its path.
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0700))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func credentials(name string, c domain.CredentialsSecret) domain.ImportEntry {
+	return domain.NewCredentialsImportEntry(name, "", c)
+}
+
+func text(name, content string) domain.ImportEntry {
+	return domain.ImportEntry{Name: name, Type: domain.TextSecretType, Content: []byte(content)}
+}
+
+func TestKeePass(t *testing.T) {
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	_, _ = gz.Write([]byte("ssh-ed25519 AAAA deploy"))
+	require.NoError(t, gz.Close())
+
+	path := writeFile(t, "export.xml", `<?xml version="1.0" encoding="utf-8" standalone="yes"?>
+<KeePassFile>
+	<Meta>
+		<RecycleBinUUID>bin</RecycleBinUUID>
+		<Binaries>
+			<Binary ID="0" Compressed="True">`+base64.StdEncoding.EncodeToString(compressed.Bytes())+`</Binary>
+		</Binaries>
+	</Meta>
+	<Root>
+		<Group>
+			<UUID>root</UUID>
+			<Name>Passwords</Name>
+			<Entry>
+				<String><Key>Title</Key><Value>github</Value></String>
+				<String><Key>UserName</Key><Value>octocat</Value></String>
+				<String><Key>Password</Key><Value ProtectInMemory="True">hunter2</Value></String>
+				<String><Key>URL</Key><Value>https://github.com</Value></String>
+				<String><Key>Notes</Key><Value>personal</Value></String>
+				<String><Key>Recovery</Key><Value>1234-5678</Value></String>
+				<String><Key>otp</Key><Value>otpauth://totp/github?secret=JBSWY3DPEHPK3PXP</Value></String>
+				<Binary><Key>id_ed25519.pub</Key><Value Ref="0"/></Binary>
+				<History>
+					<Entry>
+						<String><Key>Title</Key><Value>github</Value></String>
+						<String><Key>Password</Key><Value>old</Value></String>
+					</Entry>
+				</History>
+			</Entry>
+			<Group>
+				<UUID>work</UUID>
+				<Name>Work</Name>
+				<Entry>
+					<String><Key>Title</Key><Value>wifi</Value></String>
+					<String><Key>Notes</Key><Value>guest network</Value></String>
+				</Entry>
+				<Entry>
+					<String><Key>Title</Key><Value>empty</Value></String>
+				</Entry>
+				<Entry>
+					<String><Key>URL</Key><Value>https://www.example.com/login</Value></String>
+					<String><Key>Password</Key><Value>secret</Value></String>
+				</Entry>
+			</Group>
+			<Group>
+				<UUID>bin</UUID>
+				<Name>Recycle Bin</Name>
+				<Entry>
+					<String><Key>Title</Key><Value>deleted</Value></String>
+					<String><Key>Password</Key><Value>deleted</Value></String>
+				</Entry>
+			</Group>
+		</Group>
+	</Root>
+</KeePassFile>`)
+
+	entries, skipped, err := importer.KeePass{}.Parse(path)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.ImportEntry{
+		credentials("github", domain.CredentialsSecret{
+			Login:    "octocat",
+			Password: "hunter2",
+			URL:      "https://github.com",
+			Notes:    "personal\nRecovery: 1234-5678",
+			OTP:      "otpauth://totp/github?secret=JBSWY3DPEHPK3PXP",
+		}),
+		{
+			Name:     "id_ed25519.pub",
+			Type:     domain.FileSecretType,
+			Metadata: "attachment of 'github'",
+			Content:  []byte("ssh-ed25519 AAAA deploy"),
+		},
+		text("Work/wifi", "guest network"),
+		credentials("Work/example.com", domain.CredentialsSecret{Password: "secret", URL: "https://www.example.com/login"}),
+	}, entries)
+	assert.Equal(t, []domain.SkippedImportEntry{
+		{Name: "Work/empty", Reason: "entry has no login, password or notes"},
+	}, skipped)
+
+	t.Run("not a KeePass export", func(t *testing.T) {
+		_, _, err := importer.KeePass{}.Parse(writeFile(t, "export.xml", "<html></html>"))
+		assert.ErrorIs(t, err, domain.ErrInvalidImport)
+	})
+}
+
+func TestBitwarden(t *testing.T) {
+	path := writeFile(t, "export.json", `{
+		"encrypted": false,
+		"folders": [{"id": "f1", "name": "Social"}],
+		"items": [
+			{
+				"type": 1, "name": "twitter", "folderId": "f1", "notes": null,
+				"login": {"username": "bird", "password": "tweet", "totp": "JBSWY3DPEHPK3PXP",
+					"uris": [{"match": null, "uri": "https://twitter.com"}]},
+				"fields": [{"name": "PIN", "value": "1111", "type": 1}]
+			},
+			{"type": 2, "name": "recovery codes", "folderId": null, "notes": "aaaa\nbbbb", "secureNote": {"type": 0}},
+			{
+				"type": 3, "name": "visa", "folderId": null, "notes": null,
+				"card": {"cardholderName": "John Doe", "brand": "Visa", "number": "4111 1111 1111 1111",
+					"expMonth": "7", "expYear": "2027", "code": "123"}
+			},
+			{"type": 4, "name": "passport", "folderId": null, "identity": {}},
+			{"type": 1, "name": "blank", "folderId": null, "login": {"username": null, "password": null}}
+		]
+	}`)
+
+	entries, skipped, err := importer.Bitwarden{}.Parse(path)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.ImportEntry{
+		credentials("Social/twitter", domain.CredentialsSecret{
+			Login: "bird", Password: "tweet", URL: "https://twitter.com", Notes: "PIN: 1111", OTP: "JBSWY3DPEHPK3PXP",
+		}),
+		text("recovery codes", "aaaa\nbbbb"),
+		domain.NewPaymentCardImportEntry("visa", "", domain.PaymentCardSecret{
+			Number: "4111111111111111", Holder: "John Doe", ExpiryMonth: 7, ExpiryYear: 2027, CVV: "123",
+		}),
+	}, entries)
+	assert.Equal(t, []domain.SkippedImportEntry{
+		{Name: "passport", Reason: "unsupported item type 4"},
+		{Name: "blank", Reason: "entry has no login, password or notes"},
+	}, skipped)
+
+	t.Run("encrypted export", func(t *testing.T) {
+		_, _, err := importer.Bitwarden{}.Parse(writeFile(t, "export.json", `{"encrypted": true, "passwordProtected": true}`))
+		assert.ErrorIs(t, err, domain.ErrEncryptedImport)
+	})
+}
+
+func TestCSV(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		entries []domain.ImportEntry
+		skipped []domain.SkippedImportEntry
+	}{
+		{
+			name: "1password",
+			content: "\ufeffTitle,Url,Username,Password,OTPAuth,Favorite,Archived,Tags,Notes\n" +
+				"GitHub,https://github.com,octocat,hunter2,otpauth://totp/GitHub?secret=JBSWY3DPEHPK3PXP,false,false,,\n" +
+				"Door code,,,,,false,false,,\"1234\nfront door\"\n",
+			entries: []domain.ImportEntry{
+				credentials("GitHub", domain.CredentialsSecret{
+					Login: "octocat", Password: "hunter2", URL: "https://github.com",
+					OTP: "otpauth://totp/GitHub?secret=JBSWY3DPEHPK3PXP",
+				}),
+				text("Door code", "1234\nfront door"),
+			},
+		},
+		{
+			name: "chrome",
+			content: "name,url,username,password,note\n" +
+				"github.com,https://github.com/login,octocat,hunter2,\n" +
+				",,,,\n",
+			entries: []domain.ImportEntry{
+				credentials("github.com", domain.CredentialsSecret{
+					Login: "octocat", Password: "hunter2", URL: "https://github.com/login",
+				}),
+			},
+			skipped: []domain.SkippedImportEntry{{Name: "line 3", Reason: "entry has no title or URL"}},
+		},
+		{
+			name: "firefox",
+			content: `"url","username","password","httpRealm","formActionOrigin","guid","timeCreated","timeLastUsed","timePasswordChanged"` + "\n" +
+				`"https://www.reddit.com","snoo","upvote",,"https://www.reddit.com","{1}","1","1","1"` + "\n" +
+				`"https://example.com","","",,"","{2}","1","1","1"` + "\n",
+			entries: []domain.ImportEntry{
+				credentials("reddit.com", domain.CredentialsSecret{Login: "snoo", Password: "upvote", URL: "https://www.reddit.com"}),
+			},
+			skipped: []domain.SkippedImportEntry{{Name: "example.com", Reason: "entry has no login, password or notes"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, skipped, err := importer.CSV{}.Parse(writeFile(t, "export.csv", tt.content))
+			require.NoError(t, err)
+			assert.Equal(t, tt.entries, entries)
+			assert.Equal(t, tt.skipped, skipped)
+		})
+	}
+
+	t.Run("no password column", func(t *testing.T) {
+		_, _, err := importer.CSV{}.Parse(writeFile(t, "export.csv", "name,url\nx,y\n"))
+		assert.ErrorIs(t, err, domain.ErrInvalidImport)
+		assert.EqualError(t, err, `invalid import file: no password column in header "name,url"`)
+	})
+}
+
+func TestPass(t *testing.T) {
+	store := t.TempDir()
+	files := map[string]string{
+		"email/work.gpg": "hunter2\nlogin: john@example.com\nurl: https://mail.example.com\n" +
+			"otpauth://totp/mail?secret=JBSWY3DPEHPK3PXP\nsecurity question: pet\n",
+		"wifi.gpg":        "guestpass\n",
+		"broken.gpg":      "",
+		".gpg-id":         "ABCDEF",
+		".git/HEAD.gpg":   "not a secret",
+		"readme.txt":      "not a secret",
+		"empty/blank.gpg": "\n\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(store, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0700))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	}
+
+	p := &importer.Pass{Decrypt: func(path string) ([]byte, error) {
+		if filepath.Base(path) == "broken.gpg" {
+			return nil, errors.New("failed to decrypt: no secret key")
+		}
+		return os.ReadFile(path)
+	}}
+
+	entries, skipped, err := p.Parse(store)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.ImportEntry{
+		credentials("email/work", domain.CredentialsSecret{
+			Login:    "john@example.com",
+			Password: "hunter2",
+			URL:      "https://mail.example.com",
+			Notes:    "security question: pet",
+			OTP:      "otpauth://totp/mail?secret=JBSWY3DPEHPK3PXP",
+		}),
+		credentials("wifi", domain.CredentialsSecret{Password: "guestpass"}),
+	}, entries)
+	assert.Equal(t, []domain.SkippedImportEntry{
+		{Name: "broken", Reason: "failed to decrypt: no secret key"},
+		{Name: "empty/blank", Reason: "entry has no login, password or notes"},
+	}, skipped)
+}
+
+func TestNew(t *testing.T) {
+	for _, format := range importer.Formats {
+		i, err := importer.New(format)
+		assert.NoError(t, err, format)
+		assert.NotNil(t, i, format)
+	}
+
+	_, err := importer.New("lastpass")
+	assert.ErrorIs(t, err, domain.ErrUnknownImporter)
+	assert.EqualError(t, err, "unknown import format 'lastpass' (must be keepass, bitwarden, 1password, chrome, firefox, pass)")
+}
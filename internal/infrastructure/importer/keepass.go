@@ -0,0 +1,197 @@
+package importer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/domain"
+)
+
+// KeePass imports the unencrypted XML export of KeePass 2 and KeePassXC.
+// Entries are named after their group path and title, attachments become file secrets.
+// Custom fields are appended to the notes, the entry history and the recycle bin are not imported.
+type KeePass struct{}
+
+type keepassFile struct {
+	Meta struct {
+		RecycleBinUUID string          `xml:"RecycleBinUUID"`
+		Binaries       []keepassBinary `xml:"Binaries>Binary"`
+	} `xml:"Meta"`
+	Root struct {
+		Groups []keepassGroup `xml:"Group"`
+	} `xml:"Root"`
+}
+
+type keepassBinary struct {
+	ID         string `xml:"ID,attr"`
+	Compressed bool   `xml:"Compressed,attr"`
+	Data       string `xml:",chardata"`
+}
+
+type keepassGroup struct {
+	UUID    string         `xml:"UUID"`
+	Name    string         `xml:"Name"`
+	Entries []keepassEntry `xml:"Entry"`
+	Groups  []keepassGroup `xml:"Group"`
+}
+
+type keepassEntry struct {
+	Strings []struct {
+		Key   string `xml:"Key"`
+		Value string `xml:"Value"`
+	} `xml:"String"`
+	Binaries []struct {
+		Key   string `xml:"Key"`
+		Value struct {
+			Ref        string `xml:"Ref,attr"`
+			Compressed bool   `xml:"Compressed,attr"`
+			Data       string `xml:",chardata"`
+		} `xml:"Value"`
+	} `xml:"Binary"`
+}
+
+// Parse reads the XML file at path.
+func (KeePass) Parse(filePath string) ([]domain.ImportEntry, []domain.SkippedImportEntry, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read KeePass export: %w", err)
+	}
+
+	var file keepassFile
+	if err := xml.Unmarshal(data, &file); err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", domain.ErrInvalidImport, err)
+	}
+	if len(file.Root.Groups) == 0 {
+		return nil, nil, fmt.Errorf("%w: no root group, is this a KeePass XML export?", domain.ErrInvalidImport)
+	}
+
+	binaries := make(map[string]keepassBinary, len(file.Meta.Binaries))
+	for _, b := range file.Meta.Binaries {
+		binaries[b.ID] = b
+	}
+
+	p := keepassParser{binaries: binaries, recycleBin: file.Meta.RecycleBinUUID}
+	// The top group is the database itself and is not part of entry names
+	for _, root := range file.Root.Groups {
+		p.group(root, "")
+	}
+	return p.entries, p.skipped, nil
+}
+
+// keepassParser collects the entries of a KeePass group tree.
+type keepassParser struct {
+	binaries   map[string]keepassBinary
+	recycleBin string
+	entries    []domain.ImportEntry
+	skipped    []domain.SkippedImportEntry
+}
+
+// group collects the entries of the group and its subgroups, named relative to dir.
+func (p *keepassParser) group(g keepassGroup, dir string) {
+	for _, e := range g.Entries {
+		p.entry(e, dir)
+	}
+	for _, sub := range g.Groups {
+		if p.recycleBin != "" && sub.UUID == p.recycleBin {
+			continue
+		}
+		p.group(sub, path.Join(dir, sub.Name))
+	}
+}
+
+// entry maps an entry and its attachments onto secrets.
+func (p *keepassParser) entry(e keepassEntry, dir string) {
+	var title string
+	var credentials domain.CredentialsSecret
+	for _, s := range e.Strings {
+		switch s.Key {
+		case "Title":
+			title = s.Value
+		case "UserName":
+			credentials.Login = s.Value
+		case "Password":
+			credentials.Password = s.Value
+		case "URL":
+			credentials.URL = s.Value
+		case "otp":
+			credentials.OTP = s.Value
+		case "Notes":
+			credentials.Notes = appendNote(s.Value, credentials.Notes)
+		default:
+			if s.Value != "" {
+				credentials.Notes = appendNote(credentials.Notes, s.Key+": "+s.Value)
+			}
+		}
+	}
+
+	if title == "" {
+		title = nameFromURL(credentials.URL)
+	}
+	if title == "" {
+		p.skipped = append(p.skipped, domain.SkippedImportEntry{Name: dir, Reason: noNameReason})
+		return
+	}
+	name := path.Join(dir, title)
+
+	if entry, ok := entry(name, credentials); ok {
+		p.entries = append(p.entries, entry)
+	} else if len(e.Binaries) == 0 {
+		p.skipped = append(p.skipped, domain.SkippedImportEntry{Name: name, Reason: emptyEntryReason})
+	}
+
+	for _, b := range e.Binaries {
+		attachment := keepassBinary{Compressed: b.Value.Compressed, Data: b.Value.Data}
+		if b.Value.Ref != "" {
+			var ok bool
+			if attachment, ok = p.binaries[b.Value.Ref]; !ok {
+				p.skipped = append(p.skipped, domain.SkippedImportEntry{
+					Name:   name + ": " + b.Key,
+					Reason: fmt.Sprintf("attachment refers to missing binary %s", b.Value.Ref),
+				})
+				continue
+			}
+		}
+
+		content, err := attachment.decode()
+		if err != nil {
+			p.skipped = append(p.skipped, domain.SkippedImportEntry{Name: name + ": " + b.Key, Reason: err.Error()})
+			continue
+		}
+		p.entries = append(p.entries, domain.ImportEntry{
+			Name:     b.Key,
+			Type:     domain.FileSecretType,
+			Metadata: fmt.Sprintf("attachment of '%s'", name),
+			Content:  content,
+		})
+	}
+}
+
+// decode decodes the base64 and optionally gzip compressed binary.
+func (b keepassBinary) decode() ([]byte, error) {
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(b.Data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid attachment encoding: %v", err)
+	}
+	if !b.Compressed {
+		return data, nil
+	}
+
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid compressed attachment: %v", err)
+	}
+	defer reader.Close()
+
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("invalid compressed attachment: %v", err)
+	}
+	return content, nil
+}
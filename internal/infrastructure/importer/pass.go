@@ -0,0 +1,123 @@
+package importer
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/domain"
+)
+
+// Pass imports a pass password store directory, decrypting every .gpg file.
+// Entries are named after their path in the store. The first line of a file is the password,
+// the following lines are scanned for a login, URL and otpauth:// URI, the rest are notes.
+type Pass struct {
+	// Decrypt returns the plaintext of an encrypted file.
+	Decrypt func(path string) ([]byte, error)
+}
+
+// passLoginKeys are the keys of lines holding the login, as used by pass extensions and browser plugins.
+var passLoginKeys = []string{"login", "username", "user", "email"}
+
+// NewPass creates a pass importer decrypting files with gpg.
+func NewPass() *Pass {
+	return &Pass{Decrypt: gpgDecrypt}
+}
+
+// Parse reads the password store directory at path.
+func (p *Pass) Parse(dir string) ([]domain.ImportEntry, []domain.SkippedImportEntry, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read password store: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, nil, fmt.Errorf("%w: '%s' is not a password store directory", domain.ErrInvalidImport, dir)
+	}
+
+	var (
+		entries []domain.ImportEntry
+		skipped []domain.SkippedImportEntry
+	)
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		// Skips .git and the .gpg-id files
+		if strings.HasPrefix(d.Name(), ".") && path != dir {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() || filepath.Ext(path) != ".gpg" {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		name := strings.TrimSuffix(filepath.ToSlash(rel), ".gpg")
+
+		plaintext, err := p.Decrypt(path)
+		if err != nil {
+			skipped = append(skipped, domain.SkippedImportEntry{Name: name, Reason: err.Error()})
+			return nil
+		}
+
+		if e, ok := entry(name, parsePassFile(string(plaintext))); ok {
+			entries = append(entries, e)
+		} else {
+			skipped = append(skipped, domain.SkippedImportEntry{Name: name, Reason: emptyEntryReason})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read password store: %w", err)
+	}
+	return entries, skipped, nil
+}
+
+// parsePassFile maps the content of a pass file onto credentials.
+func parsePassFile(content string) domain.CredentialsSecret {
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	credentials := domain.CredentialsSecret{Password: lines[0]}
+
+	for _, line := range lines[1:] {
+		if strings.HasPrefix(line, "otpauth://") && credentials.OTP == "" {
+			credentials.OTP = line
+			continue
+		}
+
+		key, value, found := strings.Cut(line, ":")
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+		switch {
+		case found && credentials.Login == "" && slices.Contains(passLoginKeys, key):
+			credentials.Login = value
+		case found && credentials.URL == "" && key == "url":
+			credentials.URL = value
+		default:
+			credentials.Notes = appendNote(credentials.Notes, line)
+		}
+	}
+	credentials.Notes = strings.TrimSpace(credentials.Notes)
+	return credentials
+}
+
+// gpgDecrypt decrypts a file with gpg, using the gpg agent for the passphrase.
+func gpgDecrypt(path string) ([]byte, error) {
+	cmd := exec.Command("gpg", "--quiet", "--batch", "--decrypt", path)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("failed to decrypt: %v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
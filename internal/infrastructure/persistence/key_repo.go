@@ -0,0 +1,79 @@
+package persistence
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/domain"
+)
+
+// KeyRepo implements key parameters storage and retrieval using filesystem.
+// It stores parameters in the user's home directory under .gophkeeper-cli/key.json
+type KeyRepo struct {
+	keyPath string
+}
+
+// NewKeyRepo creates a new KeyRepo instance.
+// It verifies that the key storage directory is accessible.
+func NewKeyRepo() (*KeyRepo, error) {
+	path, err := getKeyFilePath()
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize key repository: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create key directory: %w", err)
+	}
+
+	return &KeyRepo{keyPath: path}, nil
+}
+
+// GetKeyParams retrieves the key derivation parameters from the key file.
+// Returns ErrKeyParamsNotFound if the key file does not exist.
+func (r *KeyRepo) GetKeyParams() (*domain.KeyParams, error) {
+	data, err := os.ReadFile(r.keyPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, domain.ErrKeyParamsNotFound
+		}
+		return nil, fmt.Errorf("failed to read key file: %w", err)
+	}
+
+	var params domain.KeyParams
+	if err := json.Unmarshal(data, &params); err != nil {
+		return nil, fmt.Errorf("failed to decode key file: %w", err)
+	}
+
+	return &params, nil
+}
+
+// SaveKeyParams saves the key derivation parameters to the key file.
+func (r *KeyRepo) SaveKeyParams(params domain.KeyParams) error {
+	data, err := json.MarshalIndent(params, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode key parameters: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(r.keyPath), 0700); err != nil {
+		return fmt.Errorf("failed to ensure key directory exists: %w", err)
+	}
+
+	if err := os.WriteFile(r.keyPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write key file: %w", err)
+	}
+
+	return nil
+}
+
+// getKeyFilePath returns the standardized path for key parameters storage.
+func getKeyFilePath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to determine home directory: %v", err)
+	}
+
+	return filepath.Join(homeDir, ".gophkeeper-cli", "key.json"), nil
+}
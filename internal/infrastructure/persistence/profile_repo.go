@@ -0,0 +1,216 @@
+package persistence
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/domain"
+)
+
+const profilesDir = "profiles"
+
+// profileRecord is the on-disk representation of a profile.
+type profileRecord struct {
+	ServerAddr  string `json:"server_addr"`
+	TLSCertPath string `json:"tls_cert_path,omitempty"`
+	Timeout     string `json:"timeout,omitempty"`
+}
+
+// profilesFile is the on-disk representation of all profiles.
+type profilesFile struct {
+	Current  string                   `json:"current,omitempty"`
+	Profiles map[string]profileRecord `json:"profiles"`
+}
+
+// ProfileRepo implements profile storage and retrieval using filesystem.
+// It stores profiles in the user's home directory under .gophkeeper-cli/profiles.json
+type ProfileRepo struct {
+	profilesPath string
+}
+
+// NewProfileRepo creates a new ProfileRepo instance.
+// It verifies that the profile storage directory is accessible.
+func NewProfileRepo() (*ProfileRepo, error) {
+	path, err := getProfilesFilePath()
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize profile repository: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create profile directory: %w", err)
+	}
+
+	return &ProfileRepo{profilesPath: path}, nil
+}
+
+// ListProfiles retrieves all stored profiles sorted by name.
+func (r *ProfileRepo) ListProfiles() ([]domain.Profile, error) {
+	file, err := r.read()
+	if err != nil {
+		return nil, err
+	}
+
+	profiles := make([]domain.Profile, 0, len(file.Profiles))
+	for name, rec := range file.Profiles {
+		profile, err := rec.toDomain(name)
+		if err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, *profile)
+	}
+	sort.Slice(profiles, func(i, j int) bool { return profiles[i].Name < profiles[j].Name })
+
+	return profiles, nil
+}
+
+// GetProfile retrieves a profile by name.
+// Returns ErrProfileNotFound if the profile does not exist.
+func (r *ProfileRepo) GetProfile(name string) (*domain.Profile, error) {
+	file, err := r.read()
+	if err != nil {
+		return nil, err
+	}
+
+	rec, ok := file.Profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: '%s'", domain.ErrProfileNotFound, name)
+	}
+	return rec.toDomain(name)
+}
+
+// SaveProfile creates or replaces a profile.
+func (r *ProfileRepo) SaveProfile(profile domain.Profile) error {
+	file, err := r.read()
+	if err != nil {
+		return err
+	}
+
+	rec := profileRecord{ServerAddr: profile.ServerAddr, TLSCertPath: profile.TLSCertPath}
+	if profile.Timeout > 0 {
+		rec.Timeout = profile.Timeout.String()
+	}
+	file.Profiles[profile.Name] = rec
+
+	return r.write(file)
+}
+
+// DeleteProfile removes a profile and its stored token.
+// The profile's local vault is kept, so queued offline changes are not lost.
+func (r *ProfileRepo) DeleteProfile(name string) error {
+	file, err := r.read()
+	if err != nil {
+		return err
+	}
+
+	delete(file.Profiles, name)
+	if file.Current == name {
+		file.Current = ""
+	}
+	if err := r.write(file); err != nil {
+		return err
+	}
+
+	dir, err := getProfileDir(name)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(filepath.Join(dir, tokenFileName)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete profile token: %w", err)
+	}
+	return nil
+}
+
+// GetCurrentProfile retrieves the name of the current profile.
+// Returns an empty string if no profile was selected.
+func (r *ProfileRepo) GetCurrentProfile() (string, error) {
+	file, err := r.read()
+	if err != nil {
+		return "", err
+	}
+	return file.Current, nil
+}
+
+// SaveCurrentProfile stores the name of the current profile.
+func (r *ProfileRepo) SaveCurrentProfile(name string) error {
+	file, err := r.read()
+	if err != nil {
+		return err
+	}
+	file.Current = name
+	return r.write(file)
+}
+
+// read loads the profiles file. A missing file means no profiles.
+func (r *ProfileRepo) read() (*profilesFile, error) {
+	file := &profilesFile{Profiles: make(map[string]profileRecord)}
+
+	data, err := os.ReadFile(r.profilesPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return file, nil
+		}
+		return nil, fmt.Errorf("failed to read profiles file: %w", err)
+	}
+
+	if err := json.Unmarshal(data, file); err != nil {
+		return nil, fmt.Errorf("failed to decode profiles file: %w", err)
+	}
+	if file.Profiles == nil {
+		file.Profiles = make(map[string]profileRecord)
+	}
+	return file, nil
+}
+
+// write stores the profiles file atomically.
+func (r *ProfileRepo) write(file *profilesFile) error {
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode profiles file: %w", err)
+	}
+	if err := writeFileAtomic(r.profilesPath, data); err != nil {
+		return fmt.Errorf("failed to write profiles file: %w", err)
+	}
+	return nil
+}
+
+// toDomain converts the record into a domain profile.
+func (rec profileRecord) toDomain(name string) (*domain.Profile, error) {
+	profile := &domain.Profile{Name: name, ServerAddr: rec.ServerAddr, TLSCertPath: rec.TLSCertPath}
+	if rec.Timeout != "" {
+		timeout, err := time.ParseDuration(rec.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timeout of profile '%s': %w", name, err)
+		}
+		profile.Timeout = timeout
+	}
+	return profile, nil
+}
+
+// getProfilesFilePath returns the standardized path for profiles storage.
+func getProfilesFilePath() (string, error) {
+	dir, err := getProfileDir("")
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "profiles.json"), nil
+}
+
+// getProfileDir returns the directory holding the data of a profile.
+// The default profile uses the application directory itself for compatibility.
+func getProfileDir(profile string) (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to determine home directory: %v", err)
+	}
+
+	dir := filepath.Join(homeDir, ".gophkeeper-cli")
+	if profile == "" || profile == domain.DefaultProfileName {
+		return dir, nil
+	}
+	return filepath.Join(dir, profilesDir, profile), nil
+}
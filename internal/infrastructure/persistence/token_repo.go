@@ -9,18 +9,22 @@ import (
 	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/domain"
 )
 
+const tokenFileName = "token.txt"
+
 // TokenRepo implements token storage and retrieval using filesystem.
-// It stores tokens in the user's home directory under .gophkeeper-cli/token.txt
+// It stores tokens in the user's home directory under .gophkeeper-cli/token.txt,
+// or under .gophkeeper-cli/profiles/<profile>/token.txt for named profiles.
 type TokenRepo struct {
 	tokenPath string
 }
 
-// NewTokenRepo creates a new TokenRepo instance.
+// NewTokenRepo creates a new TokenRepo instance for the given profile.
+// An empty profile selects the default token store.
 // It verifies that the token storage directory is accessible.
-func NewTokenRepo() (*TokenRepo, error) {
+func NewTokenRepo(profile string) (*TokenRepo, error) {
 	repo := &TokenRepo{}
 	// Verify we can resolve the token path during initialization
-	path, err := getTokenFilePath()
+	path, err := getTokenFilePath(profile)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize token repository: %w", err)
 	}
@@ -70,13 +74,22 @@ func (r *TokenRepo) SaveToken(token string) error {
 	return nil
 }
 
+// DeleteToken removes the token from storage (file).
+// Returns nil if no token is stored.
+func (r *TokenRepo) DeleteToken() error {
+	if err := os.Remove(r.tokenPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete token: %w", err)
+	}
+	return nil
+}
+
 // getTokenFilePath returns the standardized path for token storage.
-// Uses the user's home directory with a hidden application subdirectory.
-func getTokenFilePath() (string, error) {
-	homeDir, err := os.UserHomeDir()
+// Uses the profile directory inside the user's home directory.
+func getTokenFilePath(profile string) (string, error) {
+	dir, err := getProfileDir(profile)
 	if err != nil {
-		return "", fmt.Errorf("failed to determine home directory: %v", err)
+		return "", err
 	}
 
-	return filepath.Join(homeDir, ".gophkeeper-cli", "token.txt"), nil
+	return filepath.Join(dir, tokenFileName), nil
 }
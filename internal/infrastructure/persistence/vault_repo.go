@@ -0,0 +1,612 @@
+package persistence
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/domain"
+)
+
+const (
+	vaultAADPrefix  = "gk/vault/v1"         // additional data domain for vault files
+	vaultFrameSize  = 64 * 1024             // plaintext size of a single sealed blob frame
+	vaultFrameFinal = byte(1)               // marks the last frame of a blob
+	vaultMaxSealed  = vaultFrameSize + 1024 // upper bound for a sealed frame read from disk
+
+	secretsDir    = "secrets"
+	pendingDir    = "pending"
+	namesFile     = "names.json"
+	infosFile     = "infos.json"
+	versionsFile  = "versions.json"
+	stateFile     = "state.json"
+	recordExt     = ".json"
+	blobExt       = ".blob"
+	versionFormat = "%010d"
+)
+
+// vaultState holds unencrypted bookkeeping data of the vault.
+type vaultState struct {
+	LastSync time.Time
+}
+
+// VaultRepo implements the local offline vault using filesystem.
+// It stores secrets in the user's home directory under .gophkeeper-cli/vault
+// (or the vault directory of a named profile), encrypting every record and blob with the client-side cipher.
+type VaultRepo struct {
+	root   string
+	cipher domain.SecretCipher
+}
+
+// NewVaultRepo creates a new VaultRepo instance for the given profile.
+// An empty profile selects the default vault.
+// It verifies that the vault directory is accessible.
+func NewVaultRepo(cipher domain.SecretCipher, profile string) (*VaultRepo, error) {
+	path, err := getVaultDirPath(profile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize vault repository: %w", err)
+	}
+
+	for _, dir := range []string{path, filepath.Join(path, secretsDir), filepath.Join(path, pendingDir)} {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return nil, fmt.Errorf("failed to create vault directory: %w", err)
+		}
+	}
+
+	return &VaultRepo{root: path, cipher: cipher}, nil
+}
+
+// SaveSecret stores an encrypted copy of a non-streamable secret version.
+func (r *VaultRepo) SaveSecret(secret domain.Secret) error {
+	rel, err := r.secretVersionPath(secret.Info.Name, secret.Info.Version)
+	if err != nil {
+		return err
+	}
+	return r.writeRecord(rel+recordExt, secret)
+}
+
+// GetSecret retrieves a stored secret version, or the latest one if version is 0.
+func (r *VaultRepo) GetSecret(secretName string, version int32) (*domain.Secret, error) {
+	rel, err := r.findSecretVersionPath(secretName, version)
+	if err != nil {
+		return nil, err
+	}
+
+	var secret domain.Secret
+	if err := r.readRecord(rel+recordExt, &secret); err != nil {
+		return nil, err
+	}
+	return &secret, nil
+}
+
+// SaveSecretStream returns a writer that stores encrypted content of a streamable secret version.
+func (r *VaultRepo) SaveSecretStream(info domain.SecretInfo) (domain.VaultWriter, error) {
+	rel, err := r.secretVersionPath(info.Name, info.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	w, err := r.newBlobWriter(rel + blobExt)
+	if err != nil {
+		return nil, err
+	}
+
+	// Publish the version record only once its content is complete
+	w.onCommit = func() error {
+		return r.writeRecord(rel+recordExt, domain.Secret{Info: info})
+	}
+	return w, nil
+}
+
+// GetSecretStream retrieves stored streamable secret content, or the latest one if version is 0.
+func (r *VaultRepo) GetSecretStream(secretName string, version int32) (io.Reader, *domain.SecretInfo, error) {
+	rel, err := r.findSecretVersionPath(secretName, version)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var secret domain.Secret
+	if err := r.readRecord(rel+recordExt, &secret); err != nil {
+		return nil, nil, err
+	}
+
+	reader, err := r.newBlobReader(rel + blobExt)
+	if err != nil {
+		return nil, nil, err
+	}
+	return reader, &secret.Info, nil
+}
+
+// DeleteSecret removes all stored versions of a secret and its known version.
+func (r *VaultRepo) DeleteSecret(secretName string) error {
+	if err := os.RemoveAll(filepath.Join(r.root, secretsDir, secretDirName(secretName))); err != nil {
+		return fmt.Errorf("failed to remove secret from vault: %w", err)
+	}
+	return r.SaveKnownVersion(secretName, 0)
+}
+
+// SaveKnownVersion stores the latest version of a secret seen on this device.
+// Version 0 removes the secret from the known versions.
+func (r *VaultRepo) SaveKnownVersion(secretName string, version int32) error {
+	versions, err := r.readKnownVersions()
+	if err != nil {
+		return err
+	}
+
+	if version == 0 {
+		delete(versions, secretName)
+	} else {
+		versions[secretName] = version
+	}
+	return r.writeJSON(versionsFile, versions)
+}
+
+// GetKnownVersion retrieves the latest version of a secret seen on this device.
+func (r *VaultRepo) GetKnownVersion(secretName string) (int32, error) {
+	versions, err := r.readKnownVersions()
+	if err != nil {
+		return 0, err
+	}
+	return versions[secretName], nil
+}
+
+// SaveSecretNames stores the list of secret names known to the server.
+// Names are not encrypted, as they are visible to the server as well.
+func (r *VaultRepo) SaveSecretNames(names []string) error {
+	return r.writeJSON(namesFile, names)
+}
+
+// GetSecretNames retrieves the stored list of secret names.
+func (r *VaultRepo) GetSecretNames() ([]string, error) {
+	var names []string
+	if err := r.readJSON(namesFile, &names); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, domain.ErrSecretNotInVault
+		}
+		return nil, err
+	}
+	return names, nil
+}
+
+// SaveSecretInfos stores the infos of the latest secret versions.
+// Like names, infos are not encrypted, as the server lists them in the clear,
+// so listing secrets never has to unlock the vault.
+func (r *VaultRepo) SaveSecretInfos(infos []domain.SecretInfo) error {
+	return r.writeJSON(infosFile, infos)
+}
+
+// GetSecretInfos retrieves the stored infos of the latest secret versions.
+func (r *VaultRepo) GetSecretInfos() ([]domain.SecretInfo, error) {
+	var infos []domain.SecretInfo
+	if err := r.readJSON(infosFile, &infos); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, domain.ErrSecretNotInVault
+		}
+		return nil, err
+	}
+	return infos, nil
+}
+
+// EnqueueOperation adds an encrypted write operation to the pending queue.
+func (r *VaultRepo) EnqueueOperation(op domain.PendingOperation, content io.Reader) error {
+	rel := filepath.Join(pendingDir, op.ID)
+
+	if content != nil {
+		w, err := r.newBlobWriter(rel + blobExt)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(w, content); err != nil {
+			_ = w.Abort()
+			return fmt.Errorf("failed to store pending content: %w", err)
+		}
+		if err := w.Commit(); err != nil {
+			return err
+		}
+	}
+
+	return r.writeRecord(rel+recordExt, op)
+}
+
+// ListPendingOperations retrieves queued operations in the order they were made.
+func (r *VaultRepo) ListPendingOperations() ([]domain.PendingOperation, error) {
+	entries, err := os.ReadDir(filepath.Join(r.root, pendingDir))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pending operations: %w", err)
+	}
+
+	var ops []domain.PendingOperation
+	for _, entry := range entries {
+		if !strings.HasSuffix(entry.Name(), recordExt) {
+			continue
+		}
+
+		var op domain.PendingOperation
+		if err := r.readRecord(filepath.Join(pendingDir, entry.Name()), &op); err != nil {
+			return nil, err
+		}
+		ops = append(ops, op)
+	}
+
+	sort.Slice(ops, func(i, j int) bool { return ops[i].ID < ops[j].ID })
+	return ops, nil
+}
+
+// GetPendingContent retrieves the content of a queued streamable secret.
+func (r *VaultRepo) GetPendingContent(id string) (io.Reader, error) {
+	return r.newBlobReader(filepath.Join(pendingDir, id+blobExt))
+}
+
+// RemovePendingOperation removes an operation and its content from the queue.
+func (r *VaultRepo) RemovePendingOperation(id string) error {
+	for _, ext := range []string{recordExt, blobExt} {
+		err := os.Remove(filepath.Join(r.root, pendingDir, id+ext))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove pending operation: %w", err)
+		}
+	}
+	return nil
+}
+
+// GetLastSync retrieves the time of the last successful synchronization.
+func (r *VaultRepo) GetLastSync() (time.Time, error) {
+	var state vaultState
+	if err := r.readJSON(stateFile, &state); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return time.Time{}, nil
+		}
+		return time.Time{}, err
+	}
+	return state.LastSync, nil
+}
+
+// SaveLastSync stores the time of the last successful synchronization.
+func (r *VaultRepo) SaveLastSync(t time.Time) error {
+	return r.writeJSON(stateFile, vaultState{LastSync: t})
+}
+
+// readKnownVersions reads the known secret versions, which are not encrypted
+// as the server is aware of them as well.
+func (r *VaultRepo) readKnownVersions() (map[string]int32, error) {
+	versions := make(map[string]int32)
+	if err := r.readJSON(versionsFile, &versions); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	return versions, nil
+}
+
+// secretVersionPath returns the relative path of a secret version without extension.
+func (r *VaultRepo) secretVersionPath(secretName string, version int32) (string, error) {
+	dir := filepath.Join(secretsDir, secretDirName(secretName))
+	if err := os.MkdirAll(filepath.Join(r.root, dir), 0700); err != nil {
+		return "", fmt.Errorf("failed to create secret directory: %w", err)
+	}
+	return filepath.Join(dir, fmt.Sprintf(versionFormat, version)), nil
+}
+
+// findSecretVersionPath resolves the relative path of a stored secret version.
+// Version 0 resolves to the latest stored version.
+func (r *VaultRepo) findSecretVersionPath(secretName string, version int32) (string, error) {
+	dir := filepath.Join(secretsDir, secretDirName(secretName))
+
+	if version != 0 {
+		rel := filepath.Join(dir, fmt.Sprintf(versionFormat, version))
+		if _, err := os.Stat(filepath.Join(r.root, rel+recordExt)); err != nil {
+			return "", domain.ErrSecretNotInVault
+		}
+		return rel, nil
+	}
+
+	entries, err := os.ReadDir(filepath.Join(r.root, dir))
+	if err != nil {
+		return "", domain.ErrSecretNotInVault
+	}
+
+	var latest int64
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), recordExt)
+		if !ok {
+			continue
+		}
+		if v, err := strconv.ParseInt(name, 10, 32); err == nil && v > latest {
+			latest = v
+		}
+	}
+	if latest == 0 {
+		return "", domain.ErrSecretNotInVault
+	}
+
+	return filepath.Join(dir, fmt.Sprintf(versionFormat, latest)), nil
+}
+
+// writeRecord encrypts v as JSON and writes it to the relative path.
+func (r *VaultRepo) writeRecord(rel string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode vault record: %w", err)
+	}
+
+	sealed, err := r.cipher.Seal(data, vaultAAD(rel))
+	if err != nil {
+		return fmt.Errorf("failed to encrypt vault record: %w", err)
+	}
+
+	return writeFileAtomic(filepath.Join(r.root, rel), sealed)
+}
+
+// readRecord reads and decrypts a JSON record from the relative path.
+func (r *VaultRepo) readRecord(rel string, v any) error {
+	sealed, err := os.ReadFile(filepath.Join(r.root, rel))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return domain.ErrSecretNotInVault
+		}
+		return fmt.Errorf("failed to read vault record: %w", err)
+	}
+
+	data, err := r.cipher.Open(sealed, vaultAAD(rel))
+	if err != nil {
+		return fmt.Errorf("failed to decrypt vault record: %w", err)
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to decode vault record: %w", err)
+	}
+	return nil
+}
+
+// writeJSON writes an unencrypted JSON file to the relative path.
+func (r *VaultRepo) writeJSON(rel string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", rel, err)
+	}
+	return writeFileAtomic(filepath.Join(r.root, rel), data)
+}
+
+// readJSON reads an unencrypted JSON file from the relative path.
+func (r *VaultRepo) readJSON(rel string, v any) error {
+	data, err := os.ReadFile(filepath.Join(r.root, rel))
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to decode %s: %w", rel, err)
+	}
+	return nil
+}
+
+// blobWriter encrypts streamed content into a temporary file
+// and moves it into place on Commit.
+type blobWriter struct {
+	repo     *VaultRepo
+	rel      string
+	file     *os.File
+	out      *bufio.Writer
+	buf      []byte
+	index    uint64
+	onCommit func() error
+}
+
+// newBlobWriter creates a writer for an encrypted blob at the relative path.
+func (r *VaultRepo) newBlobWriter(rel string) (*blobWriter, error) {
+	path := filepath.Join(r.root, rel)
+	file, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create vault blob: %w", err)
+	}
+
+	return &blobWriter{
+		repo: r,
+		rel:  rel,
+		file: file,
+		out:  bufio.NewWriter(file),
+		buf:  make([]byte, 0, vaultFrameSize),
+	}, nil
+}
+
+// Write buffers content and seals it frame by frame.
+func (w *blobWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		n := min(vaultFrameSize-len(w.buf), len(p))
+		w.buf = append(w.buf, p[:n]...)
+		p = p[n:]
+		written += n
+
+		if len(w.buf) == vaultFrameSize {
+			if err := w.flushFrame(0); err != nil {
+				return written, err
+			}
+		}
+	}
+	return written, nil
+}
+
+// Commit seals the remaining content as the final frame and moves the blob into place.
+func (w *blobWriter) Commit() error {
+	if err := w.flushFrame(vaultFrameFinal); err != nil {
+		_ = w.Abort()
+		return err
+	}
+	if err := w.out.Flush(); err != nil {
+		_ = w.Abort()
+		return fmt.Errorf("failed to write vault blob: %w", err)
+	}
+	if err := w.file.Close(); err != nil {
+		_ = os.Remove(w.file.Name())
+		return fmt.Errorf("failed to close vault blob: %w", err)
+	}
+	if err := os.Rename(w.file.Name(), filepath.Join(w.repo.root, w.rel)); err != nil {
+		_ = os.Remove(w.file.Name())
+		return fmt.Errorf("failed to store vault blob: %w", err)
+	}
+	if w.onCommit != nil {
+		return w.onCommit()
+	}
+	return nil
+}
+
+// Abort discards the written content.
+func (w *blobWriter) Abort() error {
+	_ = w.file.Close()
+	if err := os.Remove(w.file.Name()); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove vault blob: %w", err)
+	}
+	return nil
+}
+
+// flushFrame seals the buffered content and writes it as a length-prefixed frame.
+func (w *blobWriter) flushFrame(flags byte) error {
+	sealed, err := w.repo.cipher.Seal(w.buf, blobFrameAAD(w.rel, w.index, flags))
+	if err != nil {
+		return fmt.Errorf("failed to encrypt vault blob: %w", err)
+	}
+
+	var header [5]byte
+	header[0] = flags
+	binary.BigEndian.PutUint32(header[1:], uint32(len(sealed)))
+	if _, err := w.out.Write(header[:]); err != nil {
+		return fmt.Errorf("failed to write vault blob: %w", err)
+	}
+	if _, err := w.out.Write(sealed); err != nil {
+		return fmt.Errorf("failed to write vault blob: %w", err)
+	}
+
+	w.buf = w.buf[:0]
+	w.index++
+	return nil
+}
+
+// blobReader decrypts a blob written by blobWriter.
+// The underlying file is closed once the final frame has been read.
+type blobReader struct {
+	repo     *VaultRepo
+	rel      string
+	file     *os.File
+	in       *bufio.Reader
+	buf      []byte
+	index    uint64
+	finished bool
+}
+
+// newBlobReader opens an encrypted blob at the relative path.
+func (r *VaultRepo) newBlobReader(rel string) (*blobReader, error) {
+	file, err := os.Open(filepath.Join(r.root, rel))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, domain.ErrSecretNotInVault
+		}
+		return nil, fmt.Errorf("failed to open vault blob: %w", err)
+	}
+	return &blobReader{repo: r, rel: rel, file: file, in: bufio.NewReader(file)}, nil
+}
+
+// Read implements io.Reader interface returning decrypted blob content.
+func (br *blobReader) Read(p []byte) (int, error) {
+	for len(br.buf) == 0 {
+		if br.finished {
+			return 0, io.EOF
+		}
+		if err := br.readFrame(); err != nil {
+			_ = br.file.Close()
+			return 0, err
+		}
+	}
+
+	n := copy(p, br.buf)
+	br.buf = br.buf[n:]
+	return n, nil
+}
+
+// readFrame reads and decrypts the next frame.
+func (br *blobReader) readFrame() error {
+	var header [5]byte
+	if _, err := io.ReadFull(br.in, header[:]); err != nil {
+		return fmt.Errorf("%w: truncated vault blob", domain.ErrDecryptionFailed)
+	}
+
+	size := binary.BigEndian.Uint32(header[1:])
+	if size > vaultMaxSealed {
+		return fmt.Errorf("%w: corrupted vault blob (frame of %d bytes)", domain.ErrDecryptionFailed, size)
+	}
+
+	sealed := make([]byte, size)
+	if _, err := io.ReadFull(br.in, sealed); err != nil {
+		return fmt.Errorf("%w: truncated vault blob", domain.ErrDecryptionFailed)
+	}
+
+	data, err := br.repo.cipher.Open(sealed, blobFrameAAD(br.rel, br.index, header[0]))
+	if err != nil {
+		return fmt.Errorf("failed to decrypt vault blob: %w", err)
+	}
+
+	br.buf = data
+	br.index++
+	if header[0]&vaultFrameFinal != 0 {
+		br.finished = true
+		_ = br.file.Close()
+	}
+	return nil
+}
+
+// secretDirName returns a filesystem-safe directory name for a secret.
+func secretDirName(secretName string) string {
+	sum := sha256.Sum256([]byte(secretName))
+	return hex.EncodeToString(sum[:])
+}
+
+// vaultAAD binds an encrypted vault file to its location.
+func vaultAAD(rel string) []byte {
+	return []byte(vaultAADPrefix + "\x00" + filepath.ToSlash(rel))
+}
+
+// blobFrameAAD binds an encrypted blob frame to its location, position and flags.
+func blobFrameAAD(rel string, index uint64, flags byte) []byte {
+	aad := vaultAAD(rel)
+	aad = binary.BigEndian.AppendUint64(aad, index)
+	return append(aad, flags)
+}
+
+// writeFileAtomic writes data to a temporary file and renames it into place.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to store file: %w", err)
+	}
+	return nil
+}
+
+// getVaultDirPath returns the standardized path for the local vault.
+// Uses the profile directory inside the user's home directory.
+func getVaultDirPath(profile string) (string, error) {
+	dir, err := getProfileDir(profile)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "vault"), nil
+}
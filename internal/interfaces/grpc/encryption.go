@@ -0,0 +1,120 @@
+package grpc
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"strings"
+
+	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/domain"
+)
+
+const (
+	encryptedDataPrefix = "gk1:"        // prefix of encrypted Secret.Data values
+	encryptedStreamTag  = "GKS1"        // magic header of encrypted streams
+	frameHeaderSize     = 1 + 4         // flags byte + ciphertext length
+	frameFlagFinal      = byte(1 << 0)  // marks the last frame of a stream
+	maxFrameSize        = chunkSize * 2 // upper bound for a single sealed frame
+	dataAADPrefix       = "gk/data/v1"  // additional data domain for Secret.Data
+	chunkAADPrefix      = "gk/chunk/v1" // additional data domain for stream frames
+)
+
+// encryptData seals secret data and encodes it as a printable string,
+// since the protobuf Data field must contain valid UTF-8.
+func encryptData(c domain.SecretCipher, secretName, data string) (string, error) {
+	sealed, err := c.Seal([]byte(data), dataAAD(secretName))
+	if err != nil {
+		return "", fmt.Errorf("failed to encrypt secret data: %w", err)
+	}
+	return encryptedDataPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// decryptData reverses encryptData.
+// Data stored before encryption was introduced is only returned if plaintext accepts it.
+func decryptData(c domain.SecretCipher, plaintext plaintextPolicy, secretName, data string) (string, error) {
+	encoded, ok := strings.CutPrefix(data, encryptedDataPrefix)
+	if !ok {
+		if err := plaintext.accept(secretName); err != nil {
+			return "", err
+		}
+		return data, nil
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", fmt.Errorf("%w: malformed ciphertext", domain.ErrDecryptionFailed)
+	}
+
+	opened, err := c.Open(sealed, dataAAD(secretName))
+	if err != nil {
+		return "", err
+	}
+	return string(opened), nil
+}
+
+// plaintextPolicy decides about payloads without the encryption header.
+// A nil policy rejects them, since a server could substitute any secret with a plaintext payload.
+type plaintextPolicy func(secretName string)
+
+// accept returns an error unless unencrypted payloads are allowed, in which case they are reported.
+func (p plaintextPolicy) accept(secretName string) error {
+	if p == nil {
+		return fmt.Errorf("%w: '%s' (set allow_plaintext to read secrets stored before encryption)",
+			domain.ErrUnencryptedSecret, secretName)
+	}
+	p(secretName)
+	return nil
+}
+
+// sealFrame encrypts a stream chunk and prepends the frame header.
+// The frame index and flags are authenticated to prevent reordering and truncation.
+func sealFrame(c domain.SecretCipher, secretName string, index uint64, flags byte, chunk []byte) ([]byte, error) {
+	sealed, err := c.Seal(chunk, chunkAAD(secretName, index, flags))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt chunk %d: %w", index, err)
+	}
+
+	frame := make([]byte, frameHeaderSize, frameHeaderSize+len(sealed))
+	frame[0] = flags
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(sealed)))
+	return append(frame, sealed...), nil
+}
+
+// openFrame decodes the next frame from buf.
+// Returns ok=false if buf does not yet contain a complete frame.
+func openFrame(c domain.SecretCipher, secretName string, index uint64, buf []byte) (chunk []byte, flags byte, n int, ok bool, err error) {
+	if len(buf) < frameHeaderSize {
+		return nil, 0, 0, false, nil
+	}
+
+	flags = buf[0]
+	size := int(binary.BigEndian.Uint32(buf[1:frameHeaderSize]))
+	if size > maxFrameSize {
+		return nil, 0, 0, false, fmt.Errorf("%w: frame too large", domain.ErrDecryptionFailed)
+	}
+	if len(buf) < frameHeaderSize+size {
+		return nil, 0, 0, false, nil
+	}
+
+	chunk, err = c.Open(buf[frameHeaderSize:frameHeaderSize+size], chunkAAD(secretName, index, flags))
+	if err != nil {
+		return nil, 0, 0, false, err
+	}
+
+	return chunk, flags, frameHeaderSize + size, true, nil
+}
+
+// dataAAD binds encrypted Secret.Data to the secret name.
+func dataAAD(secretName string) []byte {
+	return []byte(dataAADPrefix + "\x00" + secretName)
+}
+
+// chunkAAD binds an encrypted frame to the secret name, its position and flags.
+func chunkAAD(secretName string, index uint64, flags byte) []byte {
+	var aad bytes.Buffer
+	aad.WriteString(chunkAADPrefix + "\x00" + secretName + "\x00")
+	_ = binary.Write(&aad, binary.BigEndian, index)
+	aad.WriteByte(flags)
+	return aad.Bytes()
+}
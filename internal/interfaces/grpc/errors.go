@@ -0,0 +1,30 @@
+package grpc
+
+import (
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/domain"
+)
+
+// mapError converts gRPC status errors into domain errors, so that the application
+// layer can detect when the server is unreachable, a secret does not exist or the session expired.
+func mapError(err error) error {
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+
+	switch st.Code() {
+	case codes.Unavailable:
+		return fmt.Errorf("%w: %s", domain.ErrServerUnavailable, st.Message())
+	case codes.NotFound:
+		return fmt.Errorf("%w: %s", domain.ErrSecretNotFound, st.Message())
+	case codes.Unauthenticated:
+		return fmt.Errorf("%w: %s", domain.ErrUnauthenticated, st.Message())
+	default:
+		return err
+	}
+}
@@ -3,26 +3,83 @@ package interceptors
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"sync"
+	"time"
 
+	"github.com/rs/zerolog/log"
 	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/domain"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/emptypb"
 )
 
+// ReauthFunc obtains and stores a new authentication token, e.g. by prompting the user for credentials.
+type ReauthFunc func(ctx context.Context) error
+
+// ExpiryWarningFunc is called when the authentication token is about to expire.
+type ExpiryWarningFunc func(expiresAt time.Time)
+
+// streamReplayLimit is the size of the sent messages a stream keeps to replay them after re-authentication.
+const streamReplayLimit = 2 * 1024 * 1024
+
 // AuthInterceptor is responsible for injecting authentication tokens into gRPC requests.
+// If configured, it re-authenticates once per process when the token is missing, expired or rejected by the server.
 type AuthInterceptor struct {
 	repo domain.TokenRepository
+
+	reauth         ReauthFunc
+	requestTimeout time.Duration
+	warnBefore     time.Duration
+	onExpiring     ExpiryWarningFunc
+	reauthOnce     sync.Once
+	reauthOK       bool
+	warnOnce       sync.Once
+}
+
+// AuthInterceptorOption configures optional AuthInterceptor behaviour.
+type AuthInterceptorOption func(*AuthInterceptor)
+
+// WithReauth sets the function used to obtain a new token when the current one is not accepted.
+// It is called with a context without the deadline of the request, as it may wait for the user.
+func WithReauth(fn ReauthFunc) AuthInterceptorOption {
+	return func(i *AuthInterceptor) {
+		i.reauth = fn
+	}
+}
+
+// WithRequestTimeout sets the timeout of a request continued after re-authentication,
+// so the time spent logging in does not count against its deadline. Zero keeps the deadline of the request.
+func WithRequestTimeout(timeout time.Duration) AuthInterceptorOption {
+	return func(i *AuthInterceptor) {
+		i.requestTimeout = timeout
+	}
+}
+
+// WithExpiryWarning sets the function called once when the token expires within the given duration.
+func WithExpiryWarning(before time.Duration, fn ExpiryWarningFunc) AuthInterceptorOption {
+	return func(i *AuthInterceptor) {
+		i.warnBefore = before
+		i.onExpiring = fn
+	}
 }
 
 // NewAuthInterceptor creates a new instance of AuthInterceptor with the given TokenRepository.
-func NewAuthInterceptor(repo domain.TokenRepository) *AuthInterceptor {
-	return &AuthInterceptor{
-		repo: repo,
+func NewAuthInterceptor(repo domain.TokenRepository, opts ...AuthInterceptorOption) *AuthInterceptor {
+	i := &AuthInterceptor{repo: repo}
+	for _, opt := range opts {
+		opt(i)
 	}
+	return i
 }
 
 // UnaryInterceptor intercepts unary gRPC calls to add authentication metadata.
+// A call rejected with codes.Unauthenticated is retried once after re-authentication.
 func (i *AuthInterceptor) UnaryInterceptor(
 	ctx context.Context,
 	method string,
@@ -32,14 +89,39 @@ func (i *AuthInterceptor) UnaryInterceptor(
 	invoker grpc.UnaryInvoker,
 	opts ...grpc.CallOption,
 ) error {
-	ctx, err := i.injectAuthMetadata(ctx)
+	token, prompted, err := i.token(ctx)
 	if err != nil {
 		return err
 	}
-	return invoker(ctx, method, req, reply, cc, opts...)
+	if prompted {
+		var cancel context.CancelFunc
+		ctx, cancel = i.afterReauth(ctx)
+		defer cancel()
+	}
+
+	err = invoker(withToken(ctx, token), method, req, reply, cc, opts...)
+	if status.Code(err) != codes.Unauthenticated {
+		return err
+	}
+	ok, prompted := i.reauthenticate(ctx)
+	if !ok {
+		return err
+	}
+	if prompted {
+		var cancel context.CancelFunc
+		ctx, cancel = i.afterReauth(ctx)
+		defer cancel()
+	}
+
+	if token, _, err = i.token(ctx); err != nil {
+		return err
+	}
+	return invoker(withToken(ctx, token), method, req, reply, cc, opts...)
 }
 
 // StreamInterceptor intercepts streaming gRPC calls to add authentication metadata.
+// A stream rejected with codes.Unauthenticated before any response is opened again once
+// after re-authentication, with the sent messages replayed if they fit into streamReplayLimit.
 func (i *AuthInterceptor) StreamInterceptor(
 	ctx context.Context,
 	desc *grpc.StreamDesc,
@@ -48,20 +130,244 @@ func (i *AuthInterceptor) StreamInterceptor(
 	streamer grpc.Streamer,
 	opts ...grpc.CallOption,
 ) (grpc.ClientStream, error) {
-	ctx, err := i.injectAuthMetadata(ctx)
+	s := &retryStream{
+		interceptor: i,
+		ctx:         ctx,
+		cancel:      func() {},
+		open: func(ctx context.Context, token string) (grpc.ClientStream, error) {
+			return streamer(withToken(ctx, token), desc, cc, method, opts...)
+		},
+	}
+
+	token, prompted, err := i.token(ctx)
 	if err != nil {
 		return nil, err
 	}
-	return streamer(ctx, desc, cc, method, opts...)
+	if prompted {
+		ctx, s.cancel = i.afterReauth(ctx)
+	}
+	if s.ClientStream, err = s.open(ctx, token); err != nil {
+		s.cancel()
+		return nil, err
+	}
+	return s, nil
 }
 
-// injectAuthMetadata retrieves the authentication token from the repository and injects it into the context metadata.
-func (i *AuthInterceptor) injectAuthMetadata(ctx context.Context) (context.Context, error) {
+// token retrieves the authentication token from the repository.
+// A missing or expired token is renewed first if re-authentication is configured,
+// which is reported as prompted.
+func (i *AuthInterceptor) token(ctx context.Context) (string, bool, error) {
+	var prompted bool
 	token, err := i.repo.GetToken()
+	if errors.Is(err, domain.ErrTokenNotFound) {
+		var ok bool
+		if ok, prompted = i.reauthenticate(ctx); ok {
+			token, err = i.repo.GetToken()
+		}
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("failed to get token: %w", err)
+	}
+
+	if claims, err := domain.ParseTokenClaims(token); err == nil {
+		var renewed bool
+		token, renewed, err = i.checkExpiry(ctx, token, claims)
+		if err != nil {
+			return "", false, fmt.Errorf("failed to get token: %w", err)
+		}
+		prompted = prompted || renewed
+	} else {
+		log.Debug().Err(err).Msg("failed to parse token claims")
+	}
+	return token, prompted, nil
+}
+
+// withToken adds the token to the context metadata as an authorization header.
+func withToken(ctx context.Context, token string) context.Context {
+	return metadata.NewOutgoingContext(ctx, metadata.Pairs("authorization", "Bearer "+token))
+}
+
+// checkExpiry renews an expired token and warns about a token that is about to expire.
+// Reports whether the token was renewed.
+func (i *AuthInterceptor) checkExpiry(ctx context.Context, token string, claims *domain.TokenClaims) (string, bool, error) {
+	now := time.Now()
+	if claims.Expired(now) {
+		ok, prompted := i.reauthenticate(ctx)
+		if !ok {
+			return token, false, nil
+		}
+		token, err := i.repo.GetToken()
+		return token, prompted, err
+	}
+
+	if i.onExpiring != nil && !claims.ExpiresAt.IsZero() && claims.ExpiresAt.Sub(now) <= i.warnBefore {
+		i.warnOnce.Do(func() { i.onExpiring(claims.ExpiresAt) })
+	}
+	return token, false, nil
+}
+
+// reauthenticate obtains a new token at most once per interceptor.
+// Reports whether a new token is available and whether it was obtained by this call.
+func (i *AuthInterceptor) reauthenticate(ctx context.Context) (bool, bool) {
+	if i.reauth == nil {
+		return false, false
+	}
+
+	var prompted bool
+	i.reauthOnce.Do(func() {
+		prompted = true
+		if err := i.reauth(withoutDeadline(ctx)); err != nil {
+			log.Error().Err(err).Msg("failed to re-authenticate")
+			return
+		}
+		i.reauthOK = true
+	})
+	return i.reauthOK, prompted && i.reauthOK
+}
+
+// afterReauth returns the context a request continues with after re-authentication:
+// canceled with ctx, with a new deadline of the request timeout if one is set.
+func (i *AuthInterceptor) afterReauth(ctx context.Context) (context.Context, context.CancelFunc) {
+	if i.requestTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(withoutDeadline(ctx), i.requestTimeout)
+}
+
+// withoutDeadline returns a context that is canceled with the parent but ignores its deadline.
+func withoutDeadline(parent context.Context) context.Context {
+	ctx, cancel := context.WithCancel(context.WithoutCancel(parent))
+	context.AfterFunc(parent, func() {
+		if errors.Is(parent.Err(), context.Canceled) {
+			cancel()
+		}
+	})
+	return ctx
+}
+
+// retryStream is a client stream that is opened again after re-authentication
+// if the server rejects it before any response was received.
+type retryStream struct {
+	grpc.ClientStream
+
+	interceptor *AuthInterceptor
+	ctx         context.Context
+	cancel      context.CancelFunc
+	open        func(ctx context.Context, token string) (grpc.ClientStream, error)
+
+	sent      []proto.Message // messages to replay, nil once they exceed streamReplayLimit
+	sentSize  int
+	overflow  bool
+	closeSent bool
+	received  bool
+	retried   bool
+	err       error // status consumed while sending, reported by RecvMsg
+}
+
+// SendMsg sends a message and keeps it for a replay.
+// A stream ended by the server is opened again if it rejected the token.
+func (s *retryStream) SendMsg(m any) error {
+	s.record(m)
+	err := s.ClientStream.SendMsg(m)
+	if !errors.Is(err, io.EOF) || !s.replayable() {
+		return err
+	}
+
+	// The server ended the stream, its status is only reported by RecvMsg
+	cause := s.ClientStream.RecvMsg(&emptypb.Empty{})
+	retried, rerr := s.retry(cause)
+	if !retried {
+		s.err = rerr
+		return err
+	}
+	return nil
+}
+
+// CloseSend closes the sending side and remembers to close it on a replay too.
+func (s *retryStream) CloseSend() error {
+	s.closeSent = true
+	return s.ClientStream.CloseSend()
+}
+
+// RecvMsg receives a message, opening the stream again if the server rejected the token.
+func (s *retryStream) RecvMsg(m any) error {
+	if s.err != nil {
+		return s.err
+	}
+
+	err := s.ClientStream.RecvMsg(m)
+	if retried, rerr := s.retry(err); retried {
+		err = s.ClientStream.RecvMsg(m)
+	} else {
+		err = rerr
+	}
+
+	if err != nil {
+		s.cancel()
+		return err
+	}
+	s.received = true
+	return nil
+}
+
+// record keeps a copy of a sent message while the kept messages fit into streamReplayLimit.
+func (s *retryStream) record(m any) {
+	if s.overflow {
+		return
+	}
+	msg, ok := m.(proto.Message)
+	if !ok || s.sentSize+proto.Size(msg) > streamReplayLimit {
+		s.overflow, s.sent = true, nil
+		return
+	}
+	s.sent = append(s.sent, proto.Clone(msg))
+	s.sentSize += proto.Size(msg)
+}
+
+// replayable reports whether the stream can still be opened again.
+func (s *retryStream) replayable() bool {
+	return !s.retried && !s.received && !s.overflow && s.interceptor.reauth != nil
+}
+
+// retry opens the stream again after re-authentication if cause is a rejected token,
+// and replays the sent messages. Reports whether the stream was opened again,
+// otherwise returns cause or the error of opening the stream again.
+func (s *retryStream) retry(cause error) (bool, error) {
+	if status.Code(cause) != codes.Unauthenticated || !s.replayable() {
+		return false, cause
+	}
+	s.retried = true
+
+	ctx := s.ctx
+	ok, prompted := s.interceptor.reauthenticate(ctx)
+	if !ok {
+		return false, cause
+	}
+	if prompted {
+		s.cancel()
+		ctx, s.cancel = s.interceptor.afterReauth(ctx)
+	}
+
+	token, _, err := s.interceptor.token(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get token: %w", err)
+		return false, err
+	}
+	stream, err := s.open(ctx, token)
+	if err != nil {
+		return false, err
+	}
+	s.ClientStream = stream
+
+	for _, m := range s.sent {
+		if err := stream.SendMsg(m); err != nil {
+			// The status of the new stream is reported by RecvMsg
+			return true, nil
+		}
+	}
+	if s.closeSent {
+		if err := stream.CloseSend(); err != nil {
+			return false, err
+		}
 	}
-	// Add the token to metadata as an authorization header.
-	md := metadata.Pairs("authorization", "Bearer "+token)
-	return metadata.NewOutgoingContext(ctx, md), nil
+	return true, nil
 }
@@ -0,0 +1,321 @@
+package interceptors_test
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/domain"
+	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/interfaces/grpc/interceptors"
+	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/mocks"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func tokenExpiringAt(exp time.Time) string {
+	payload := fmt.Sprintf(`{"login":"testuser","exp":%d}`, exp.Unix())
+	return "eyJhbGciOiJIUzI1NiJ9." + base64.RawURLEncoding.EncodeToString([]byte(payload)) + ".c2ln"
+}
+
+// recordingInvoker returns the given errors in order and records the tokens sent with each call.
+func recordingInvoker(tokens *[]string, errs ...error) grpc.UnaryInvoker {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		md, _ := metadata.FromOutgoingContext(ctx)
+		*tokens = append(*tokens, md.Get("authorization")[0])
+		err := errs[0]
+		errs = errs[1:]
+		return err
+	}
+}
+
+func TestAuthInterceptor_UnaryInterceptor(t *testing.T) {
+	ctx := context.Background()
+	oldToken := tokenExpiringAt(time.Now().Add(time.Hour))
+	newToken := tokenExpiringAt(time.Now().Add(2 * time.Hour))
+	unauthenticated := status.Error(codes.Unauthenticated, "token expired")
+
+	t.Run("rejected call is retried once after re-login", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := mocks.NewMockTokenRepository(ctrl)
+
+		reauthCalls := 0
+		interceptor := interceptors.NewAuthInterceptor(mockRepo,
+			interceptors.WithReauth(func(ctx context.Context) error {
+				reauthCalls++
+				return nil
+			}))
+
+		gomock.InOrder(
+			mockRepo.EXPECT().GetToken().Return(oldToken, nil),
+			mockRepo.EXPECT().GetToken().Return(newToken, nil),
+		)
+
+		var sent []string
+		err := interceptor.UnaryInterceptor(ctx, "/Method", nil, nil, nil,
+			recordingInvoker(&sent, unauthenticated, nil))
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, reauthCalls)
+		assert.Equal(t, []string{"Bearer " + oldToken, "Bearer " + newToken}, sent)
+	})
+
+	t.Run("re-login and retried call are not bound by the request deadline", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := mocks.NewMockTokenRepository(ctrl)
+
+		reqCtx, cancel := context.WithTimeout(ctx, time.Second)
+		defer cancel()
+		parentDeadline, _ := reqCtx.Deadline()
+
+		var reauthHasDeadline bool
+		interceptor := interceptors.NewAuthInterceptor(mockRepo,
+			interceptors.WithRequestTimeout(time.Hour),
+			interceptors.WithReauth(func(ctx context.Context) error {
+				_, reauthHasDeadline = ctx.Deadline()
+				return nil
+			}))
+
+		gomock.InOrder(
+			mockRepo.EXPECT().GetToken().Return(oldToken, nil),
+			mockRepo.EXPECT().GetToken().Return(newToken, nil),
+		)
+
+		var deadlines []time.Time
+		invoker := func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			deadline, _ := ctx.Deadline()
+			deadlines = append(deadlines, deadline)
+			if len(deadlines) == 1 {
+				return unauthenticated
+			}
+			return nil
+		}
+
+		assert.NoError(t, interceptor.UnaryInterceptor(reqCtx, "/Method", nil, nil, nil, invoker))
+		assert.False(t, reauthHasDeadline)
+		assert.Equal(t, parentDeadline, deadlines[0])
+		assert.True(t, deadlines[1].After(parentDeadline))
+	})
+
+	t.Run("failed re-login returns the original error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := mocks.NewMockTokenRepository(ctrl)
+
+		interceptor := interceptors.NewAuthInterceptor(mockRepo,
+			interceptors.WithReauth(func(ctx context.Context) error {
+				return domain.ErrInvalidCredentials
+			}))
+
+		mockRepo.EXPECT().GetToken().Return(oldToken, nil)
+
+		var sent []string
+		err := interceptor.UnaryInterceptor(ctx, "/Method", nil, nil, nil,
+			recordingInvoker(&sent, unauthenticated))
+
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+		assert.Len(t, sent, 1)
+	})
+
+	t.Run("expired token is renewed before the call", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := mocks.NewMockTokenRepository(ctrl)
+
+		interceptor := interceptors.NewAuthInterceptor(mockRepo,
+			interceptors.WithReauth(func(ctx context.Context) error { return nil }))
+
+		gomock.InOrder(
+			mockRepo.EXPECT().GetToken().Return(tokenExpiringAt(time.Now().Add(-time.Minute)), nil),
+			mockRepo.EXPECT().GetToken().Return(newToken, nil),
+		)
+
+		var sent []string
+		err := interceptor.UnaryInterceptor(ctx, "/Method", nil, nil, nil, recordingInvoker(&sent, nil))
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"Bearer " + newToken}, sent)
+	})
+
+	t.Run("missing token without re-login fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := mocks.NewMockTokenRepository(ctrl)
+
+		interceptor := interceptors.NewAuthInterceptor(mockRepo)
+		mockRepo.EXPECT().GetToken().Return("", domain.ErrTokenNotFound)
+
+		err := interceptor.UnaryInterceptor(ctx, "/Method", nil, nil, nil, nil)
+		assert.True(t, errors.Is(err, domain.ErrTokenNotFound))
+	})
+
+	t.Run("token about to expire triggers a single warning", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := mocks.NewMockTokenRepository(ctrl)
+
+		expiresAt := time.Now().Add(5 * time.Minute).Truncate(time.Second)
+		var warnings []time.Time
+		interceptor := interceptors.NewAuthInterceptor(mockRepo,
+			interceptors.WithExpiryWarning(10*time.Minute, func(t time.Time) {
+				warnings = append(warnings, t)
+			}))
+
+		mockRepo.EXPECT().GetToken().Return(tokenExpiringAt(expiresAt), nil).Times(2)
+
+		var sent []string
+		invoker := recordingInvoker(&sent, nil, nil)
+		assert.NoError(t, interceptor.UnaryInterceptor(ctx, "/Method", nil, nil, nil, invoker))
+		assert.NoError(t, interceptor.UnaryInterceptor(ctx, "/Method", nil, nil, nil, invoker))
+
+		assert.Len(t, warnings, 1)
+		assert.True(t, expiresAt.Equal(warnings[0]))
+	})
+}
+
+// fakeClientStream is a client stream of a server that rejects the token given in rejected.
+type fakeClientStream struct {
+	grpc.ClientStream
+
+	rejected bool
+	sent     []string
+	closed   bool
+	replies  []string
+}
+
+func (s *fakeClientStream) SendMsg(m any) error {
+	if s.rejected {
+		return io.EOF
+	}
+	s.sent = append(s.sent, m.(*wrapperspb.StringValue).GetValue())
+	return nil
+}
+
+func (s *fakeClientStream) CloseSend() error {
+	s.closed = true
+	return nil
+}
+
+func (s *fakeClientStream) RecvMsg(m any) error {
+	if s.rejected {
+		return status.Error(codes.Unauthenticated, "token expired")
+	}
+	if len(s.replies) == 0 {
+		return io.EOF
+	}
+	m.(*wrapperspb.StringValue).Value, s.replies = s.replies[0], s.replies[1:]
+	return nil
+}
+
+func TestAuthInterceptor_StreamInterceptor(t *testing.T) {
+	ctx := context.Background()
+	oldToken := tokenExpiringAt(time.Now().Add(time.Hour))
+	newToken := tokenExpiringAt(time.Now().Add(2 * time.Hour))
+
+	// streamer opens streams rejecting the old token and records the tokens sent
+	streamer := func(tokens *[]string, streams *[]*fakeClientStream) grpc.Streamer {
+		return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string,
+			opts ...grpc.CallOption) (grpc.ClientStream, error) {
+			md, _ := metadata.FromOutgoingContext(ctx)
+			token := md.Get("authorization")[0]
+			*tokens = append(*tokens, token)
+			stream := &fakeClientStream{rejected: token == "Bearer "+oldToken, replies: []string{"reply"}}
+			*streams = append(*streams, stream)
+			return stream, nil
+		}
+	}
+
+	t.Run("rejected stream is opened again after re-login", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := mocks.NewMockTokenRepository(ctrl)
+
+		reauthCalls := 0
+		interceptor := interceptors.NewAuthInterceptor(mockRepo,
+			interceptors.WithReauth(func(ctx context.Context) error {
+				reauthCalls++
+				return nil
+			}))
+
+		gomock.InOrder(
+			mockRepo.EXPECT().GetToken().Return(oldToken, nil),
+			mockRepo.EXPECT().GetToken().Return(newToken, nil),
+		)
+
+		var tokens []string
+		var streams []*fakeClientStream
+		stream, err := interceptor.StreamInterceptor(ctx, &grpc.StreamDesc{}, nil, "/Method",
+			streamer(&tokens, &streams))
+		assert.NoError(t, err)
+
+		// The server ends the stream on the first message, the rest is sent to the new stream
+		assert.NoError(t, stream.SendMsg(wrapperspb.String("first")))
+		assert.NoError(t, stream.SendMsg(wrapperspb.String("second")))
+		assert.NoError(t, stream.CloseSend())
+
+		reply := &wrapperspb.StringValue{}
+		assert.NoError(t, stream.RecvMsg(reply))
+		assert.Equal(t, "reply", reply.GetValue())
+
+		assert.Equal(t, 1, reauthCalls)
+		assert.Equal(t, []string{"Bearer " + oldToken, "Bearer " + newToken}, tokens)
+		assert.Equal(t, []string{"first", "second"}, streams[1].sent)
+		assert.True(t, streams[1].closed)
+	})
+
+	t.Run("server stream is opened again on a rejected first response", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := mocks.NewMockTokenRepository(ctrl)
+
+		interceptor := interceptors.NewAuthInterceptor(mockRepo,
+			interceptors.WithReauth(func(ctx context.Context) error { return nil }))
+
+		gomock.InOrder(
+			mockRepo.EXPECT().GetToken().Return(oldToken, nil),
+			mockRepo.EXPECT().GetToken().Return(newToken, nil),
+		)
+
+		var tokens []string
+		var streams []*fakeClientStream
+		stream, err := interceptor.StreamInterceptor(ctx, &grpc.StreamDesc{ServerStreams: true}, nil, "/Method",
+			streamer(&tokens, &streams))
+		assert.NoError(t, err)
+
+		// The request is accepted by the stream, the server only reports the status on receive
+		streams[0].rejected = false
+		assert.NoError(t, stream.SendMsg(wrapperspb.String("request")))
+		assert.NoError(t, stream.CloseSend())
+		streams[0].rejected = true
+
+		reply := &wrapperspb.StringValue{}
+		assert.NoError(t, stream.RecvMsg(reply))
+		assert.Equal(t, "reply", reply.GetValue())
+		assert.True(t, errors.Is(stream.RecvMsg(reply), io.EOF))
+		assert.Equal(t, []string{"request"}, streams[1].sent)
+	})
+
+	t.Run("failed re-login returns the original error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := mocks.NewMockTokenRepository(ctrl)
+
+		interceptor := interceptors.NewAuthInterceptor(mockRepo,
+			interceptors.WithReauth(func(ctx context.Context) error {
+				return domain.ErrInvalidCredentials
+			}))
+
+		mockRepo.EXPECT().GetToken().Return(oldToken, nil)
+
+		var tokens []string
+		var streams []*fakeClientStream
+		stream, err := interceptor.StreamInterceptor(ctx, &grpc.StreamDesc{}, nil, "/Method",
+			streamer(&tokens, &streams))
+		assert.NoError(t, err)
+
+		assert.True(t, errors.Is(stream.SendMsg(wrapperspb.String("first")), io.EOF))
+		assert.Equal(t, codes.Unauthenticated, status.Code(stream.RecvMsg(&wrapperspb.StringValue{})))
+		assert.Len(t, tokens, 1)
+	})
+}
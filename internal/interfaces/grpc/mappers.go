@@ -1,19 +1,31 @@
 package grpc
 
 import (
+	"strings"
+
 	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/domain"
 	pb "github.com/ulixes-bloom/ya-gophkeeper-cli/internal/infrastructure/proto/gen"
 )
 
+// sshKeyMetadataMarker prefixes the metadata of ssh keys, which the server stores as credentials,
+// as the wire protocol has no secret type for them.
+const sshKeyMetadataMarker = "gophkeeper-type: ssh_key\n"
+
 // mapProtoGetSecretInfoResponseToDomainSecretInfo converts protobuf SecretInfo to domain model
 func mapProtoGetSecretInfoResponseToDomainSecretInfo(info *pb.GetSecretInfoResponse) domain.SecretInfo {
-	return domain.SecretInfo{
+	secretInfo := domain.SecretInfo{
 		Name:      info.GetName(),
 		Metadata:  info.GetMetadata(),
 		Version:   info.GetVersion(),
 		Type:      mapProtoSecretTypeToDomain(info.GetType()),
 		CreatedAt: info.GetCreatedAt().AsTime(),
 	}
+	if secretInfo.Type == domain.CredentialsSecretType {
+		if metadata, ok := strings.CutPrefix(secretInfo.Metadata, sshKeyMetadataMarker); ok {
+			secretInfo.Type, secretInfo.Metadata = domain.SSHKeySecretType, metadata
+		}
+	}
+	return secretInfo
 }
 
 // mapProtoGetSecretResponseToDomainSecret converts protobuf Secret to domain model
@@ -24,12 +36,17 @@ func mapProtoGetSecretResponseToDomainSecret(resp *pb.GetSecretResponse) *domain
 	}
 }
 
-// mapDomainSecretInfoToProtoCreateSecretInfoRequest converts domain SecretInfo to protobuf request
+// mapDomainSecretInfoToProtoCreateSecretInfoRequest converts domain SecretInfo to protobuf request.
+// SSH keys are sent as credentials with sshKeyMetadataMarker in front of the metadata.
 func mapDomainSecretInfoToProtoCreateSecretInfoRequest(secretInfo domain.SecretInfo) *pb.CreateSecretInfoRequest {
+	metadata := secretInfo.Metadata
+	if secretInfo.Type == domain.SSHKeySecretType {
+		metadata = sshKeyMetadataMarker + metadata
+	}
 	return &pb.CreateSecretInfoRequest{
 		Name:     secretInfo.Name,
 		Type:     mapDomainSecretTypeToProto(secretInfo.Type),
-		Metadata: secretInfo.Metadata,
+		Metadata: metadata,
 	}
 }
 
@@ -52,7 +69,7 @@ func mapProtoSecretTypeToDomain(stype pb.SecretType) domain.SecretType {
 // mapDomainSecretTypeToProto converts domain SecretType to protobuf SecretType
 func mapDomainSecretTypeToProto(domainType domain.SecretType) pb.SecretType {
 	switch domainType {
-	case domain.CredentialsSecretType:
+	case domain.CredentialsSecretType, domain.SSHKeySecretType:
 		return pb.SecretType_CREDENTIALS
 	case domain.TextSecretType:
 		return pb.SecretType_TEXT
@@ -0,0 +1,34 @@
+package grpc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/domain"
+	pb "github.com/ulixes-bloom/ya-gophkeeper-cli/internal/infrastructure/proto/gen"
+)
+
+func TestMapSSHKeySecretInfo(t *testing.T) {
+	t.Run("ssh keys are sent as marked credentials", func(t *testing.T) {
+		req := mapDomainSecretInfoToProtoCreateSecretInfoRequest(domain.SecretInfo{
+			Name: "deploy", Type: domain.SSHKeySecretType, Metadata: "SHA256:abc",
+		})
+
+		assert.Equal(t, pb.SecretType_CREDENTIALS, req.GetType())
+		assert.Equal(t, sshKeyMetadataMarker+"SHA256:abc", req.GetMetadata())
+
+		info := mapProtoGetSecretInfoResponseToDomainSecretInfo(&pb.GetSecretInfoResponse{
+			Name: req.GetName(), Type: req.GetType(), Metadata: req.GetMetadata(),
+		})
+		assert.Equal(t, domain.SSHKeySecretType, info.Type)
+		assert.Equal(t, "SHA256:abc", info.Metadata)
+	})
+
+	t.Run("marker is only recognized on credentials", func(t *testing.T) {
+		info := mapProtoGetSecretInfoResponseToDomainSecretInfo(&pb.GetSecretInfoResponse{
+			Name: "notes", Type: pb.SecretType_TEXT, Metadata: sshKeyMetadataMarker,
+		})
+		assert.Equal(t, domain.TextSecretType, info.Type)
+		assert.Equal(t, sshKeyMetadataMarker, info.Metadata)
+	})
+}
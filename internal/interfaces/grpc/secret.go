@@ -5,9 +5,12 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"sync"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
 
 	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/domain"
@@ -17,18 +20,34 @@ import (
 
 const (
 	chunkSize = 1024 * 512 // 500B chunk size for streaming
+
+	fanOutConcurrency = 8 // parallel requests when listing secrets without server support
 )
 
 // SecretClient provides methods to interact with the gRPC secret service.
+// Secret payloads are encrypted with the given cipher before they are sent to the server.
 type SecretClient struct {
-	client pb.SecretServiceClient
-	conn   *grpc.ClientConn
+	client    pb.SecretServiceClient
+	conn      *grpc.ClientConn
+	cipher    domain.SecretCipher
+	plaintext plaintextPolicy
 }
 
-// NewSecretClient initializes a new SecretClient with authentication interceptors.
-func NewSecretClient(serverAddr string, repo domain.TokenRepository, creds credentials.TransportCredentials) (*SecretClient, error) {
-	authInterceptor := interceptors.NewAuthInterceptor(repo)
+// SecretClientOption configures optional SecretClient behaviour.
+type SecretClientOption func(*SecretClient)
+
+// WithPlaintextSecrets accepts payloads stored before client-side encryption was introduced.
+// warn is called for every such payload, as it may as well have been substituted by the server.
+// Without this option unencrypted payloads are rejected with domain.ErrUnencryptedSecret.
+func WithPlaintextSecrets(warn func(secretName string)) SecretClientOption {
+	return func(c *SecretClient) {
+		c.plaintext = warn
+	}
+}
 
+// NewSecretClient initializes a new SecretClient with the given authentication interceptor.
+func NewSecretClient(serverAddr string, authInterceptor *interceptors.AuthInterceptor, creds credentials.TransportCredentials,
+	cipher domain.SecretCipher, opts ...SecretClientOption) (*SecretClient, error) {
 	conn, err := grpc.NewClient(serverAddr,
 		grpc.WithTransportCredentials(creds),
 		grpc.WithUnaryInterceptor(authInterceptor.UnaryInterceptor),
@@ -37,8 +56,11 @@ func NewSecretClient(serverAddr string, repo domain.TokenRepository, creds crede
 		return nil, fmt.Errorf("grpc.NewSecretClient: failed to dial gRPC server: %w", err)
 	}
 
-	client := pb.NewSecretServiceClient(conn)
-	return &SecretClient{client: client, conn: conn}, nil
+	c := &SecretClient{client: pb.NewSecretServiceClient(conn), conn: conn, cipher: cipher}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
 }
 
 // Close terminates the gRPC connection.
@@ -51,12 +73,17 @@ func (c *SecretClient) Close() error {
 
 // CreateSecret sends a request to create a new secret.
 func (c *SecretClient) CreateSecret(ctx context.Context, secret domain.Secret) error {
-	_, err := c.client.CreateSecret(ctx, &pb.CreateSecretRequest{
+	data, err := encryptData(c.cipher, secret.Info.Name, secret.Data)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.client.CreateSecret(ctx, &pb.CreateSecretRequest{
 		Info: mapDomainSecretInfoToProtoCreateSecretInfoRequest(secret.Info),
-		Data: secret.Data,
+		Data: data,
 	})
 	if err != nil {
-		return err
+		return mapError(err)
 	}
 
 	return nil
@@ -66,19 +93,19 @@ func (c *SecretClient) CreateSecret(ctx context.Context, secret domain.Secret) e
 func (c *SecretClient) CreateSecretStream(ctx context.Context, secret domain.Secret, reader io.Reader) error {
 	stream, err := c.client.CreateSecretStream(ctx)
 	if err != nil {
-		return fmt.Errorf("client.CreateSecretStream: %w", err)
+		return fmt.Errorf("client.CreateSecretStream: %w", mapError(err))
 	}
 
 	if err := c.sendMetadataChunk(stream, secret); err != nil {
 		return fmt.Errorf("failed to send metadata chunk: %w", err)
 	}
 
-	if err := c.sendDataChunks(stream, reader); err != nil {
+	if err := c.sendDataChunks(stream, secret.Info.Name, reader); err != nil {
 		return fmt.Errorf("failed to send data chunks: %w", err)
 	}
 
 	if _, err = stream.CloseAndRecv(); err != nil {
-		return fmt.Errorf("failed to close stream and recieve server's response: %w", err)
+		return fmt.Errorf("failed to close stream and recieve server's response: %w", mapError(err))
 	}
 
 	return nil
@@ -93,48 +120,270 @@ func (c *SecretClient) sendMetadataChunk(stream pb.SecretService_CreateSecretStr
 	})
 }
 
-// sendDataChunks streams the secret data in chunks.
-func (c *SecretClient) sendDataChunks(stream pb.SecretService_CreateSecretStreamClient, reader io.Reader) error {
+// sendDataChunks streams the secret data in encrypted chunks.
+// The stream starts with a magic header and ends with an empty final frame,
+// so that truncation can be detected on download.
+func (c *SecretClient) sendDataChunks(stream pb.SecretService_CreateSecretStreamClient, secretName string, reader io.Reader) error {
+	if err := c.sendDataChunk(stream, []byte(encryptedStreamTag)); err != nil {
+		return err
+	}
+
+	var index uint64
 	buf := make([]byte, chunkSize)
 	for {
 		n, err := reader.Read(buf)
+		if n > 0 {
+			frame, err := sealFrame(c.cipher, secretName, index, 0, buf[:n])
+			if err != nil {
+				return err
+			}
+			if err := c.sendDataChunk(stream, frame); err != nil {
+				return err
+			}
+			index++
+		}
 		if errors.Is(err, io.EOF) {
 			break
 		}
 		if err != nil {
 			return err
 		}
-		if n > 0 {
-			if err := stream.Send(&pb.CreateSecretChunkRequest{
-				Chunk: &pb.CreateSecretChunkRequest_Data{
-					Data: buf[:n],
-				},
-			}); err != nil {
-				return err
-			}
-		}
 	}
-	return nil
+
+	frame, err := sealFrame(c.cipher, secretName, index, frameFlagFinal, nil)
+	if err != nil {
+		return err
+	}
+	return c.sendDataChunk(stream, frame)
+}
+
+// sendDataChunk sends a single data chunk.
+func (c *SecretClient) sendDataChunk(stream pb.SecretService_CreateSecretStreamClient, data []byte) error {
+	return stream.Send(&pb.CreateSecretChunkRequest{
+		Chunk: &pb.CreateSecretChunkRequest_Data{
+			Data: data,
+		},
+	})
 }
 
 // ListSecrets retrieves all secret names from the server.
 func (c *SecretClient) ListSecrets(ctx context.Context) ([]string, error) {
 	resp, err := c.client.ListSecrets(ctx, &emptypb.Empty{})
 	if err != nil {
-		return nil, fmt.Errorf("client.ListSecrets: %w", err)
+		return nil, fmt.Errorf("client.ListSecrets: %w", mapError(err))
 	}
 
 	return resp.Data, nil
 }
 
+// ListSecretsInfo retrieves the info of the latest version of every secret.
+// The server has no RPC listing infos, so secret names are listed and the latest version
+// of each of them is fetched concurrently. Secrets deleted in the meantime are skipped.
+// Payloads are not decrypted, only infos are used.
+func (c *SecretClient) ListSecretsInfo(ctx context.Context) ([]domain.SecretInfo, error) {
+	names, err := c.ListSecrets(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]*domain.SecretInfo, len(names))
+	err = fanOut(ctx, len(names), func(ctx context.Context, i int) error {
+		resp, err := c.client.GetLatestSecret(ctx, &pb.GetLatestSecretRequest{Name: names[i]})
+		if status.Code(err) == codes.NotFound {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("client.GetLatestSecret '%s': %w", names[i], mapError(err))
+		}
+
+		info := mapProtoGetSecretInfoResponseToDomainSecretInfo(resp.GetInfo())
+		infos[i] = &info
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]domain.SecretInfo, 0, len(infos))
+	for _, info := range infos {
+		if info != nil {
+			result = append(result, *info)
+		}
+	}
+	return result, nil
+}
+
+// ListSecretVersions retrieves the info and stored payload size of every version of a secret.
+// The server has no RPC listing versions, so every version up to the latest one is fetched
+// concurrently; use GetSecretInfo to look up a single version.
+// Payloads are not decrypted, their stored size is measured. Missing versions are skipped.
+func (c *SecretClient) ListSecretVersions(ctx context.Context, secretName string) ([]domain.SecretVersion, error) {
+	latest, err := c.client.GetLatestSecret(ctx, &pb.GetLatestSecretRequest{Name: secretName})
+	if err != nil {
+		return nil, fmt.Errorf("client.GetLatestSecret: %w", mapError(err))
+	}
+	streamable := mapProtoSecretTypeToDomain(latest.GetInfo().GetType()).Streamable()
+
+	versions := make([]*domain.SecretVersion, latest.GetInfo().GetVersion())
+	err = fanOut(ctx, len(versions), func(ctx context.Context, i int) error {
+		req := &pb.GetSecretByVersionRequest{Name: secretName, Version: int32(i + 1)}
+
+		var (
+			version *domain.SecretVersion
+			err     error
+		)
+		if streamable {
+			version, err = c.measureSecretStream(ctx, req)
+		} else {
+			var resp *pb.GetSecretResponse
+			if resp, err = c.client.GetSecretByVersion(ctx, req); err == nil {
+				version = &domain.SecretVersion{
+					Info: mapProtoGetSecretInfoResponseToDomainSecretInfo(resp.GetInfo()),
+					Size: int64(len(resp.GetData())),
+				}
+			}
+		}
+		if status.Code(err) == codes.NotFound {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("client.GetSecretByVersion '%s' version %d: %w", secretName, req.Version, mapError(err))
+		}
+
+		versions[i] = version
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]domain.SecretVersion, 0, len(versions))
+	for _, version := range versions {
+		if version != nil {
+			result = append(result, *version)
+		}
+	}
+	return result, nil
+}
+
+// measureSecretStream downloads a streamable secret version and counts its stored bytes.
+func (c *SecretClient) measureSecretStream(ctx context.Context, req *pb.GetSecretByVersionRequest) (*domain.SecretVersion, error) {
+	stream, err := c.client.GetSecretStreamByVersion(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	version := &domain.SecretVersion{}
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return version, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if info := chunk.GetInfo(); info != nil {
+			version.Info = mapProtoGetSecretInfoResponseToDomainSecretInfo(info)
+		}
+		version.Size += int64(len(chunk.GetData()))
+	}
+}
+
+// GetSecretInfo retrieves the info of a secret version, version 0 being the latest one.
+// The latest version is fetched and, if another version is requested, the info of that version
+// is read from the first chunk of its stream or from its unary response. Payloads are not decrypted.
+func (c *SecretClient) GetSecretInfo(ctx context.Context, secretName string, version int32) (*domain.SecretInfo, error) {
+	latest, err := c.client.GetLatestSecret(ctx, &pb.GetLatestSecretRequest{Name: secretName})
+	if err != nil {
+		return nil, fmt.Errorf("client.GetLatestSecret: %w", mapError(err))
+	}
+	info := mapProtoGetSecretInfoResponseToDomainSecretInfo(latest.GetInfo())
+	if version == 0 || version == info.Version {
+		return &info, nil
+	}
+	if version > info.Version {
+		return nil, fmt.Errorf("%w: '%s' version %d", domain.ErrSecretNotFound, secretName, version)
+	}
+
+	req := &pb.GetSecretByVersionRequest{Name: secretName, Version: version}
+	if !info.Type.Streamable() {
+		resp, err := c.client.GetSecretByVersion(ctx, req)
+		if err != nil {
+			return nil, fmt.Errorf("client.GetSecretByVersion: %w", mapError(err))
+		}
+		info = mapProtoGetSecretInfoResponseToDomainSecretInfo(resp.GetInfo())
+		return &info, nil
+	}
+
+	// Only the first chunk is read, the rest of the stream is dropped
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := c.client.GetSecretStreamByVersion(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("client.GetSecretStreamByVersion: %w", mapError(err))
+	}
+	chunk, err := stream.Recv()
+	if err != nil {
+		return nil, fmt.Errorf("failed to receive metadata: %w", mapError(err))
+	}
+	if chunk.GetInfo() == nil {
+		return nil, fmt.Errorf("first chunk must contain secret info")
+	}
+	info = mapProtoGetSecretInfoResponseToDomainSecretInfo(chunk.GetInfo())
+	return &info, nil
+}
+
+// fanOut calls fn for every index in [0, n) with at most fanOutConcurrency calls in flight.
+// The first error cancels the remaining calls and is returned.
+func fanOut(ctx context.Context, n int, fn func(ctx context.Context, i int) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+		sem      = make(chan struct{}, fanOutConcurrency)
+	)
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			if err := fn(ctx, i); err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = err
+					cancel()
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	return firstErr
+}
+
 // GetLatestSecret retrieves the latest version of a secret.
 func (c *SecretClient) GetLatestSecret(ctx context.Context, secretName string) (*domain.Secret, error) {
 	resp, err := c.client.GetLatestSecret(ctx, &pb.GetLatestSecretRequest{Name: secretName})
 	if err != nil {
-		return nil, fmt.Errorf("client.GetLatestSecret: %w", err)
+		return nil, fmt.Errorf("client.GetLatestSecret: %w", mapError(err))
 	}
 
 	secret := mapProtoGetSecretResponseToDomainSecret(resp)
+	if err := checkSecretName(secretName, secret.Info.Name); err != nil {
+		return nil, err
+	}
+	if secret.Data, err = decryptData(c.cipher, c.plaintext, secretName, secret.Data); err != nil {
+		return nil, err
+	}
+
 	return secret, nil
 }
 
@@ -142,13 +391,13 @@ func (c *SecretClient) GetLatestSecret(ctx context.Context, secretName string) (
 func (c *SecretClient) GetLatestSecretStream(ctx context.Context, secretName string) (io.Reader, *domain.SecretInfo, error) {
 	stream, err := c.client.GetLatestSecretStream(ctx, &pb.GetLatestSecretRequest{Name: secretName})
 	if err != nil {
-		return nil, nil, fmt.Errorf("client.GetLatestSecretStream: %w", err)
+		return nil, nil, fmt.Errorf("client.GetLatestSecretStream: %w", mapError(err))
 	}
 
 	// Read the first chunk (metadata)
 	firstChunk, err := stream.Recv()
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to receive first chunk with metadata: %w", err)
+		return nil, nil, fmt.Errorf("failed to receive first chunk with metadata: %w", mapError(err))
 	}
 	secretInfo := firstChunk.GetInfo()
 	if secretInfo == nil {
@@ -156,8 +405,11 @@ func (c *SecretClient) GetLatestSecretStream(ctx context.Context, secretName str
 	}
 
 	domainSecretInfo := mapProtoGetSecretInfoResponseToDomainSecretInfo(secretInfo)
+	if err := checkSecretName(secretName, domainSecretInfo.Name); err != nil {
+		return nil, nil, err
+	}
 
-	return NewSecretStreamReader(stream), &domainSecretInfo, nil
+	return NewSecretStreamReader(stream, c.cipher, c.plaintext, secretName), &domainSecretInfo, nil
 }
 
 // GetSecretByVersion retrieves a specific version of a secret.
@@ -167,10 +419,16 @@ func (c *SecretClient) GetSecretByVersion(ctx context.Context, secretName string
 		Version: version,
 	})
 	if err != nil {
-		return nil, fmt.Errorf("client.GetSecretByVersion: %w", err)
+		return nil, fmt.Errorf("client.GetSecretByVersion: %w", mapError(err))
 	}
 
 	secret := mapProtoGetSecretResponseToDomainSecret(resp)
+	if err := checkSecretName(secretName, secret.Info.Name); err != nil {
+		return nil, err
+	}
+	if secret.Data, err = decryptData(c.cipher, c.plaintext, secretName, secret.Data); err != nil {
+		return nil, err
+	}
 
 	return secret, nil
 }
@@ -182,12 +440,12 @@ func (c *SecretClient) GetSecretStreamByVersion(ctx context.Context, secretName
 		Version: version,
 	})
 	if err != nil {
-		return nil, nil, fmt.Errorf("client.GetSecretStreamByVersion: %w", err)
+		return nil, nil, fmt.Errorf("client.GetSecretStreamByVersion: %w", mapError(err))
 	}
 
 	firstChunk, err := stream.Recv()
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to recieve metadata: %w", err)
+		return nil, nil, fmt.Errorf("failed to recieve metadata: %w", mapError(err))
 	}
 	secretInfo := firstChunk.GetInfo()
 	if secretInfo == nil {
@@ -195,8 +453,21 @@ func (c *SecretClient) GetSecretStreamByVersion(ctx context.Context, secretName
 	}
 
 	domainSecretInfo := mapProtoGetSecretInfoResponseToDomainSecretInfo(secretInfo)
+	if err := checkSecretName(secretName, domainSecretInfo.Name); err != nil {
+		return nil, nil, err
+	}
+
+	return NewSecretStreamReader(stream, c.cipher, c.plaintext, secretName), &domainSecretInfo, nil
+}
 
-	return NewSecretStreamReader(stream), &domainSecretInfo, nil
+// checkSecretName rejects a response carrying another secret than the requested one.
+// Payloads are bound to the requested name, but a server answering with another secret
+// would otherwise send a name and a ciphertext that match each other.
+func checkSecretName(requested, returned string) error {
+	if returned != requested {
+		return fmt.Errorf("%w: requested '%s', got '%s'", domain.ErrSecretMismatch, requested, returned)
+	}
+	return nil
 }
 
 // DeleteSecret removes a secret by its name.
@@ -205,7 +476,7 @@ func (c *SecretClient) DeleteSecret(ctx context.Context, secretName string) erro
 		Name: secretName,
 	})
 	if err != nil {
-		return fmt.Errorf("client.DeleteSecret: %w", err)
+		return fmt.Errorf("client.DeleteSecret: %w", mapError(err))
 	}
 
 	return nil
@@ -0,0 +1,119 @@
+package grpc
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/domain"
+	pb "github.com/ulixes-bloom/ya-gophkeeper-cli/internal/infrastructure/proto/gen"
+)
+
+// testCipher seals with AES-GCM under a fixed key, prefixing the nonce.
+type testCipher struct {
+	aead cipher.AEAD
+}
+
+func newTestCipher(t *testing.T) testCipher {
+	block, err := aes.NewCipher(make([]byte, 32))
+	require.NoError(t, err)
+	aead, err := cipher.NewGCM(block)
+	require.NoError(t, err)
+	return testCipher{aead: aead}
+}
+
+func (c testCipher) Seal(plaintext, additionalData []byte) ([]byte, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return c.aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+func (c testCipher) Open(ciphertext, additionalData []byte) ([]byte, error) {
+	nonce, sealed := ciphertext[:c.aead.NonceSize()], ciphertext[c.aead.NonceSize():]
+	opened, err := c.aead.Open(nil, nonce, sealed, additionalData)
+	if err != nil {
+		return nil, domain.ErrDecryptionFailed
+	}
+	return opened, nil
+}
+
+// hostileServer answers every request with the same stored secret.
+type hostileServer struct {
+	pb.SecretServiceClient
+	resp *pb.GetSecretResponse
+}
+
+func (s hostileServer) GetLatestSecret(context.Context, *pb.GetLatestSecretRequest, ...grpc.CallOption) (*pb.GetSecretResponse, error) {
+	return s.resp, nil
+}
+
+func (s hostileServer) GetSecretByVersion(context.Context, *pb.GetSecretByVersionRequest, ...grpc.CallOption) (*pb.GetSecretResponse, error) {
+	return s.resp, nil
+}
+
+func (s hostileServer) GetLatestSecretStream(context.Context, *pb.GetLatestSecretRequest,
+	...grpc.CallOption) (grpc.ServerStreamingClient[pb.GetSecretChunkResponse], error) {
+	return &infoStream{info: s.resp.GetInfo()}, nil
+}
+
+func (s hostileServer) GetSecretStreamByVersion(context.Context, *pb.GetSecretByVersionRequest,
+	...grpc.CallOption) (grpc.ServerStreamingClient[pb.GetSecretChunkResponse], error) {
+	return &infoStream{info: s.resp.GetInfo()}, nil
+}
+
+// infoStream sends the metadata chunk of a secret stream.
+type infoStream struct {
+	grpc.ClientStream
+	info *pb.GetSecretInfoResponse
+}
+
+func (s *infoStream) Recv() (*pb.GetSecretChunkResponse, error) {
+	return &pb.GetSecretChunkResponse{Chunk: &pb.GetSecretChunkResponse_Info{Info: s.info}}, nil
+}
+
+func TestSecretClient_SubstitutedSecret(t *testing.T) {
+	ctx := context.Background()
+	c := newTestCipher(t)
+
+	// The server holds the user's secret "bank" and is asked for "notes"
+	sealed, err := encryptData(c, "bank", "hunter2")
+	require.NoError(t, err)
+	bank := &pb.GetSecretInfoResponse{Name: "bank", Type: pb.SecretType_CREDENTIALS, Version: 1}
+
+	t.Run("another secret under its own name", func(t *testing.T) {
+		client := &SecretClient{client: hostileServer{resp: &pb.GetSecretResponse{Info: bank, Data: sealed}}, cipher: c}
+
+		_, err := client.GetLatestSecret(ctx, "notes")
+		assert.ErrorIs(t, err, domain.ErrSecretMismatch)
+		_, err = client.GetSecretByVersion(ctx, "notes", 1)
+		assert.ErrorIs(t, err, domain.ErrSecretMismatch)
+		_, _, err = client.GetLatestSecretStream(ctx, "notes")
+		assert.ErrorIs(t, err, domain.ErrSecretMismatch)
+		_, _, err = client.GetSecretStreamByVersion(ctx, "notes", 1)
+		assert.ErrorIs(t, err, domain.ErrSecretMismatch)
+	})
+
+	t.Run("another secret under the requested name", func(t *testing.T) {
+		renamed := &pb.GetSecretInfoResponse{Name: "notes", Type: pb.SecretType_CREDENTIALS, Version: 1}
+		client := &SecretClient{client: hostileServer{resp: &pb.GetSecretResponse{Info: renamed, Data: sealed}}, cipher: c}
+
+		_, err := client.GetLatestSecret(ctx, "notes")
+		assert.ErrorIs(t, err, domain.ErrDecryptionFailed)
+	})
+
+	t.Run("requested secret", func(t *testing.T) {
+		client := &SecretClient{client: hostileServer{resp: &pb.GetSecretResponse{Info: bank, Data: sealed}}, cipher: c}
+
+		secret, err := client.GetLatestSecret(ctx, "bank")
+		require.NoError(t, err)
+		assert.Equal(t, "hunter2", secret.Data)
+	})
+}
@@ -1,59 +1,158 @@
 package grpc
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"io"
 
+	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/domain"
 	pb "github.com/ulixes-bloom/ya-gophkeeper-cli/internal/infrastructure/proto/gen"
 )
 
 // secretStreamReader implements io.Reader for streaming secret data from gRPC.
-// It buffers incoming chunks and provides a standard Read interface.
+// It buffers incoming chunks, decrypts them and provides a standard Read interface.
 type secretStreamReader struct {
-	stream pb.SecretService_GetLatestSecretStreamClient // gRPC stream
-	buffer []byte                                       // current data buffer
-	index  int                                          // current position in buffer
+	stream     pb.SecretService_GetLatestSecretStreamClient // gRPC stream
+	cipher     domain.SecretCipher                          // cipher used to open frames
+	plaintext  plaintextPolicy                              // decides about unencrypted streams
+	secretName string                                       // name bound into frame additional data
+	pending    []byte                                       // received bytes not yet decrypted
+	buffer     []byte                                       // current plaintext buffer
+	index      int                                          // current position in buffer
+	frameIndex uint64                                       // index of the next expected frame
+	started    bool                                         // whether the stream header was inspected
+	encrypted  bool                                         // whether the stream carries encrypted frames
+	finished   bool                                         // whether the last frame was received
 }
 
 // NewSecretStreamReader creates a new io.Reader that reads from a gRPC secret stream.
 // The reader handles chunked data from the stream and presents it as a continuous flow.
-func NewSecretStreamReader(stream pb.SecretService_GetLatestSecretStreamClient) io.Reader {
+// Streams stored before encryption was introduced are passed through unchanged if plaintext accepts them.
+func NewSecretStreamReader(stream pb.SecretService_GetLatestSecretStreamClient, cipher domain.SecretCipher,
+	plaintext plaintextPolicy, secretName string) io.Reader {
 	return &secretStreamReader{
-		stream: stream,
+		stream:     stream,
+		cipher:     cipher,
+		plaintext:  plaintext,
+		secretName: secretName,
 	}
 }
 
 // Read implements io.Reader interface to read data from the gRPC stream.
-// It fills the provided byte slice with data from the stream.
+// It fills the provided byte slice with decrypted data from the stream.
 // Returns number of bytes read and any error encountered.
 func (r *secretStreamReader) Read(p []byte) (int, error) {
-	// Check if we have buffered data to return
-	if r.index < len(r.buffer) {
-		n := copy(p, r.buffer[r.index:])
-		r.index += n
-		return n, nil
+	// Refill the plaintext buffer until there is something to return
+	for r.index >= len(r.buffer) {
+		if r.finished {
+			return 0, io.EOF
+		}
+		if err := r.fill(); err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, r.buffer[r.index:])
+	r.index += n
+	return n, nil
+}
+
+// fill decodes the next portion of plaintext into the buffer.
+func (r *secretStreamReader) fill() error {
+	if !r.started {
+		return r.readHeader()
+	}
+
+	if !r.encrypted {
+		return r.fillPlain()
 	}
 
+	chunk, flags, n, ok, err := openFrame(r.cipher, r.secretName, r.frameIndex, r.pending)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt chunk %d: %w", r.frameIndex, err)
+	}
+	if !ok {
+		eof, err := r.receive()
+		if err != nil {
+			return err
+		}
+		if eof {
+			return fmt.Errorf("%w: stream ended before final chunk", domain.ErrDecryptionFailed)
+		}
+		return nil
+	}
+
+	r.pending = r.pending[n:]
+	r.frameIndex++
+	r.buffer, r.index = chunk, 0
+
+	if flags&frameFlagFinal != 0 {
+		if len(r.pending) > 0 {
+			return fmt.Errorf("%w: unexpected data after final chunk", domain.ErrDecryptionFailed)
+		}
+		r.finished = true
+	}
+	return nil
+}
+
+// readHeader detects whether the stream is encrypted by its magic header.
+func (r *secretStreamReader) readHeader() error {
+	for len(r.pending) < len(encryptedStreamTag) {
+		eof, err := r.receive()
+		if err != nil {
+			return err
+		}
+		if eof {
+			break
+		}
+	}
+
+	r.started = true
+	if !bytes.HasPrefix(r.pending, []byte(encryptedStreamTag)) {
+		return r.plaintext.accept(r.secretName)
+	}
+	r.encrypted = true
+	r.pending = r.pending[len(encryptedStreamTag):]
+	return nil
+}
+
+// fillPlain moves received plaintext into the buffer.
+func (r *secretStreamReader) fillPlain() error {
+	if len(r.pending) == 0 {
+		eof, err := r.receive()
+		if err != nil {
+			return err
+		}
+		if eof {
+			r.finished = true
+			return nil
+		}
+	}
+
+	r.buffer, r.index = r.pending, 0
+	r.pending = nil
+	return nil
+}
+
+// receive appends the next data chunk from the stream to the pending bytes.
+// Returns eof=true when the server closed the stream.
+func (r *secretStreamReader) receive() (bool, error) {
 	// Get next chunk from stream
 	response, err := r.stream.Recv()
 	if err != nil {
 		if errors.Is(err, io.EOF) {
-			return 0, io.EOF
+			return true, nil
 		}
-		return 0, fmt.Errorf("stream read failed: %w", err)
+		return false, fmt.Errorf("stream read failed: %w", err)
 	}
 
 	// Verify we got data chunk (not metadata or other message type)
 	chunk := response.GetData()
 	if chunk == nil {
-		return 0, fmt.Errorf("expected data chunk, got %T", response.Chunk)
+		return false, fmt.Errorf("expected data chunk, got %T", response.Chunk)
 	}
 
-	// Store new data in buffer
-	r.buffer = chunk
-	r.index = 0
-
-	// Recursive call to read from the new buffer
-	return r.Read(p)
+	r.pending = append(r.pending, chunk...)
+	return false, nil
 }
@@ -9,6 +9,7 @@ import (
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
+	domain "github.com/ulixes-bloom/ya-gophkeeper-cli/internal/domain"
 )
 
 // MockAuthService is a mock of AuthService interface.
@@ -48,6 +49,20 @@ func (mr *MockAuthServiceMockRecorder) Login(ctx, login, passsword interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Login", reflect.TypeOf((*MockAuthService)(nil).Login), ctx, login, passsword)
 }
 
+// Logout mocks base method.
+func (m *MockAuthService) Logout(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Logout", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Logout indicates an expected call of Logout.
+func (mr *MockAuthServiceMockRecorder) Logout(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Logout", reflect.TypeOf((*MockAuthService)(nil).Logout), ctx)
+}
+
 // Register mocks base method.
 func (m *MockAuthService) Register(ctx context.Context, login, passsword string) error {
 	m.ctrl.T.Helper()
@@ -62,6 +77,21 @@ func (mr *MockAuthServiceMockRecorder) Register(ctx, login, passsword interface{
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Register", reflect.TypeOf((*MockAuthService)(nil).Register), ctx, login, passsword)
 }
 
+// WhoAmI mocks base method.
+func (m *MockAuthService) WhoAmI(ctx context.Context) (*domain.TokenClaims, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WhoAmI", ctx)
+	ret0, _ := ret[0].(*domain.TokenClaims)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WhoAmI indicates an expected call of WhoAmI.
+func (mr *MockAuthServiceMockRecorder) WhoAmI(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WhoAmI", reflect.TypeOf((*MockAuthService)(nil).WhoAmI), ctx)
+}
+
 // MockAuthClient is a mock of AuthClient interface.
 type MockAuthClient struct {
 	ctrl     *gomock.Controller
@@ -138,6 +168,20 @@ func (m *MockTokenRepository) EXPECT() *MockTokenRepositoryMockRecorder {
 	return m.recorder
 }
 
+// DeleteToken mocks base method.
+func (m *MockTokenRepository) DeleteToken() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteToken")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteToken indicates an expected call of DeleteToken.
+func (mr *MockTokenRepositoryMockRecorder) DeleteToken() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteToken", reflect.TypeOf((*MockTokenRepository)(nil).DeleteToken))
+}
+
 // GetToken mocks base method.
 func (m *MockTokenRepository) GetToken() (string, error) {
 	m.ctrl.T.Helper()
@@ -0,0 +1,116 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/domain/backup.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	io "io"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	domain "github.com/ulixes-bloom/ya-gophkeeper-cli/internal/domain"
+)
+
+// MockBackupWriter is a mock of BackupWriter interface.
+type MockBackupWriter struct {
+	ctrl     *gomock.Controller
+	recorder *MockBackupWriterMockRecorder
+}
+
+// MockBackupWriterMockRecorder is the mock recorder for MockBackupWriter.
+type MockBackupWriterMockRecorder struct {
+	mock *MockBackupWriter
+}
+
+// NewMockBackupWriter creates a new mock instance.
+func NewMockBackupWriter(ctrl *gomock.Controller) *MockBackupWriter {
+	mock := &MockBackupWriter{ctrl: ctrl}
+	mock.recorder = &MockBackupWriterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBackupWriter) EXPECT() *MockBackupWriterMockRecorder {
+	return m.recorder
+}
+
+// Add mocks base method.
+func (m *MockBackupWriter) Add(info domain.SecretInfo, content io.Reader) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Add", info, content)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Add indicates an expected call of Add.
+func (mr *MockBackupWriterMockRecorder) Add(info, content interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Add", reflect.TypeOf((*MockBackupWriter)(nil).Add), info, content)
+}
+
+// Close mocks base method.
+func (m *MockBackupWriter) Close() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Close")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Close indicates an expected call of Close.
+func (mr *MockBackupWriterMockRecorder) Close() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockBackupWriter)(nil).Close))
+}
+
+// MockBackupReader is a mock of BackupReader interface.
+type MockBackupReader struct {
+	ctrl     *gomock.Controller
+	recorder *MockBackupReaderMockRecorder
+}
+
+// MockBackupReaderMockRecorder is the mock recorder for MockBackupReader.
+type MockBackupReaderMockRecorder struct {
+	mock *MockBackupReader
+}
+
+// NewMockBackupReader creates a new mock instance.
+func NewMockBackupReader(ctrl *gomock.Controller) *MockBackupReader {
+	mock := &MockBackupReader{ctrl: ctrl}
+	mock.recorder = &MockBackupReaderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBackupReader) EXPECT() *MockBackupReaderMockRecorder {
+	return m.recorder
+}
+
+// Verify mocks base method.
+func (m *MockBackupReader) Verify() (*domain.BackupManifest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Verify")
+	ret0, _ := ret[0].(*domain.BackupManifest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Verify indicates an expected call of Verify.
+func (mr *MockBackupReaderMockRecorder) Verify() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Verify", reflect.TypeOf((*MockBackupReader)(nil).Verify))
+}
+
+// Walk mocks base method.
+func (m *MockBackupReader) Walk(fn func(domain.BackupEntry, io.Reader) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Walk", fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Walk indicates an expected call of Walk.
+func (mr *MockBackupReaderMockRecorder) Walk(fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Walk", reflect.TypeOf((*MockBackupReader)(nil).Walk), fn)
+}
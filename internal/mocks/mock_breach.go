@@ -0,0 +1,63 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/domain/breach.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockBreachCorpus is a mock of BreachCorpus interface.
+type MockBreachCorpus struct {
+	ctrl     *gomock.Controller
+	recorder *MockBreachCorpusMockRecorder
+}
+
+// MockBreachCorpusMockRecorder is the mock recorder for MockBreachCorpus.
+type MockBreachCorpusMockRecorder struct {
+	mock *MockBreachCorpus
+}
+
+// NewMockBreachCorpus creates a new mock instance.
+func NewMockBreachCorpus(ctrl *gomock.Controller) *MockBreachCorpus {
+	mock := &MockBreachCorpus{ctrl: ctrl}
+	mock.recorder = &MockBreachCorpusMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBreachCorpus) EXPECT() *MockBreachCorpusMockRecorder {
+	return m.recorder
+}
+
+// Close mocks base method.
+func (m *MockBreachCorpus) Close() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Close")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Close indicates an expected call of Close.
+func (mr *MockBreachCorpusMockRecorder) Close() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockBreachCorpus)(nil).Close))
+}
+
+// Count mocks base method.
+func (m *MockBreachCorpus) Count(password string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Count", password)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Count indicates an expected call of Count.
+func (mr *MockBreachCorpusMockRecorder) Count(password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Count", reflect.TypeOf((*MockBreachCorpus)(nil).Count), password)
+}
@@ -0,0 +1,63 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/domain/clipboard.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockClipboard is a mock of Clipboard interface.
+type MockClipboard struct {
+	ctrl     *gomock.Controller
+	recorder *MockClipboardMockRecorder
+}
+
+// MockClipboardMockRecorder is the mock recorder for MockClipboard.
+type MockClipboardMockRecorder struct {
+	mock *MockClipboard
+}
+
+// NewMockClipboard creates a new mock instance.
+func NewMockClipboard(ctrl *gomock.Controller) *MockClipboard {
+	mock := &MockClipboard{ctrl: ctrl}
+	mock.recorder = &MockClipboardMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockClipboard) EXPECT() *MockClipboardMockRecorder {
+	return m.recorder
+}
+
+// Clear mocks base method.
+func (m *MockClipboard) Clear(text string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Clear", text)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Clear indicates an expected call of Clear.
+func (mr *MockClipboardMockRecorder) Clear(text interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Clear", reflect.TypeOf((*MockClipboard)(nil).Clear), text)
+}
+
+// Copy mocks base method.
+func (m *MockClipboard) Copy(text string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Copy", text)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Copy indicates an expected call of Copy.
+func (mr *MockClipboardMockRecorder) Copy(text interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Copy", reflect.TypeOf((*MockClipboard)(nil).Copy), text)
+}
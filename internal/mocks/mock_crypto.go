@@ -0,0 +1,117 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/domain/crypto.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	domain "github.com/ulixes-bloom/ya-gophkeeper-cli/internal/domain"
+)
+
+// MockKeyRepository is a mock of KeyRepository interface.
+type MockKeyRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockKeyRepositoryMockRecorder
+}
+
+// MockKeyRepositoryMockRecorder is the mock recorder for MockKeyRepository.
+type MockKeyRepositoryMockRecorder struct {
+	mock *MockKeyRepository
+}
+
+// NewMockKeyRepository creates a new mock instance.
+func NewMockKeyRepository(ctrl *gomock.Controller) *MockKeyRepository {
+	mock := &MockKeyRepository{ctrl: ctrl}
+	mock.recorder = &MockKeyRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockKeyRepository) EXPECT() *MockKeyRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetKeyParams mocks base method.
+func (m *MockKeyRepository) GetKeyParams() (*domain.KeyParams, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetKeyParams")
+	ret0, _ := ret[0].(*domain.KeyParams)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetKeyParams indicates an expected call of GetKeyParams.
+func (mr *MockKeyRepositoryMockRecorder) GetKeyParams() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetKeyParams", reflect.TypeOf((*MockKeyRepository)(nil).GetKeyParams))
+}
+
+// SaveKeyParams mocks base method.
+func (m *MockKeyRepository) SaveKeyParams(params domain.KeyParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveKeyParams", params)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveKeyParams indicates an expected call of SaveKeyParams.
+func (mr *MockKeyRepositoryMockRecorder) SaveKeyParams(params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveKeyParams", reflect.TypeOf((*MockKeyRepository)(nil).SaveKeyParams), params)
+}
+
+// MockSecretCipher is a mock of SecretCipher interface.
+type MockSecretCipher struct {
+	ctrl     *gomock.Controller
+	recorder *MockSecretCipherMockRecorder
+}
+
+// MockSecretCipherMockRecorder is the mock recorder for MockSecretCipher.
+type MockSecretCipherMockRecorder struct {
+	mock *MockSecretCipher
+}
+
+// NewMockSecretCipher creates a new mock instance.
+func NewMockSecretCipher(ctrl *gomock.Controller) *MockSecretCipher {
+	mock := &MockSecretCipher{ctrl: ctrl}
+	mock.recorder = &MockSecretCipherMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSecretCipher) EXPECT() *MockSecretCipherMockRecorder {
+	return m.recorder
+}
+
+// Open mocks base method.
+func (m *MockSecretCipher) Open(ciphertext, additionalData []byte) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Open", ciphertext, additionalData)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Open indicates an expected call of Open.
+func (mr *MockSecretCipherMockRecorder) Open(ciphertext, additionalData interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Open", reflect.TypeOf((*MockSecretCipher)(nil).Open), ciphertext, additionalData)
+}
+
+// Seal mocks base method.
+func (m *MockSecretCipher) Seal(plaintext, additionalData []byte) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Seal", plaintext, additionalData)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Seal indicates an expected call of Seal.
+func (mr *MockSecretCipherMockRecorder) Seal(plaintext, additionalData interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Seal", reflect.TypeOf((*MockSecretCipher)(nil).Seal), plaintext, additionalData)
+}
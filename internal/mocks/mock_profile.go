@@ -0,0 +1,219 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/domain/profile.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	domain "github.com/ulixes-bloom/ya-gophkeeper-cli/internal/domain"
+)
+
+// MockProfileService is a mock of ProfileService interface.
+type MockProfileService struct {
+	ctrl     *gomock.Controller
+	recorder *MockProfileServiceMockRecorder
+}
+
+// MockProfileServiceMockRecorder is the mock recorder for MockProfileService.
+type MockProfileServiceMockRecorder struct {
+	mock *MockProfileService
+}
+
+// NewMockProfileService creates a new mock instance.
+func NewMockProfileService(ctrl *gomock.Controller) *MockProfileService {
+	mock := &MockProfileService{ctrl: ctrl}
+	mock.recorder = &MockProfileServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockProfileService) EXPECT() *MockProfileServiceMockRecorder {
+	return m.recorder
+}
+
+// AddProfile mocks base method.
+func (m *MockProfileService) AddProfile(ctx context.Context, profile domain.Profile) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddProfile", ctx, profile)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddProfile indicates an expected call of AddProfile.
+func (mr *MockProfileServiceMockRecorder) AddProfile(ctx, profile interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddProfile", reflect.TypeOf((*MockProfileService)(nil).AddProfile), ctx, profile)
+}
+
+// ListProfiles mocks base method.
+func (m *MockProfileService) ListProfiles(ctx context.Context) ([]domain.Profile, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListProfiles", ctx)
+	ret0, _ := ret[0].([]domain.Profile)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListProfiles indicates an expected call of ListProfiles.
+func (mr *MockProfileServiceMockRecorder) ListProfiles(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListProfiles", reflect.TypeOf((*MockProfileService)(nil).ListProfiles), ctx)
+}
+
+// RemoveProfile mocks base method.
+func (m *MockProfileService) RemoveProfile(ctx context.Context, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveProfile", ctx, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveProfile indicates an expected call of RemoveProfile.
+func (mr *MockProfileServiceMockRecorder) RemoveProfile(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveProfile", reflect.TypeOf((*MockProfileService)(nil).RemoveProfile), ctx, name)
+}
+
+// ResolveProfile mocks base method.
+func (m *MockProfileService) ResolveProfile(ctx context.Context, name string) (*domain.Profile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResolveProfile", ctx, name)
+	ret0, _ := ret[0].(*domain.Profile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResolveProfile indicates an expected call of ResolveProfile.
+func (mr *MockProfileServiceMockRecorder) ResolveProfile(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResolveProfile", reflect.TypeOf((*MockProfileService)(nil).ResolveProfile), ctx, name)
+}
+
+// UseProfile mocks base method.
+func (m *MockProfileService) UseProfile(ctx context.Context, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseProfile", ctx, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UseProfile indicates an expected call of UseProfile.
+func (mr *MockProfileServiceMockRecorder) UseProfile(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseProfile", reflect.TypeOf((*MockProfileService)(nil).UseProfile), ctx, name)
+}
+
+// MockProfileRepository is a mock of ProfileRepository interface.
+type MockProfileRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockProfileRepositoryMockRecorder
+}
+
+// MockProfileRepositoryMockRecorder is the mock recorder for MockProfileRepository.
+type MockProfileRepositoryMockRecorder struct {
+	mock *MockProfileRepository
+}
+
+// NewMockProfileRepository creates a new mock instance.
+func NewMockProfileRepository(ctrl *gomock.Controller) *MockProfileRepository {
+	mock := &MockProfileRepository{ctrl: ctrl}
+	mock.recorder = &MockProfileRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockProfileRepository) EXPECT() *MockProfileRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeleteProfile mocks base method.
+func (m *MockProfileRepository) DeleteProfile(name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteProfile", name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteProfile indicates an expected call of DeleteProfile.
+func (mr *MockProfileRepositoryMockRecorder) DeleteProfile(name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteProfile", reflect.TypeOf((*MockProfileRepository)(nil).DeleteProfile), name)
+}
+
+// GetCurrentProfile mocks base method.
+func (m *MockProfileRepository) GetCurrentProfile() (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCurrentProfile")
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCurrentProfile indicates an expected call of GetCurrentProfile.
+func (mr *MockProfileRepositoryMockRecorder) GetCurrentProfile() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCurrentProfile", reflect.TypeOf((*MockProfileRepository)(nil).GetCurrentProfile))
+}
+
+// GetProfile mocks base method.
+func (m *MockProfileRepository) GetProfile(name string) (*domain.Profile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProfile", name)
+	ret0, _ := ret[0].(*domain.Profile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProfile indicates an expected call of GetProfile.
+func (mr *MockProfileRepositoryMockRecorder) GetProfile(name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProfile", reflect.TypeOf((*MockProfileRepository)(nil).GetProfile), name)
+}
+
+// ListProfiles mocks base method.
+func (m *MockProfileRepository) ListProfiles() ([]domain.Profile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListProfiles")
+	ret0, _ := ret[0].([]domain.Profile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListProfiles indicates an expected call of ListProfiles.
+func (mr *MockProfileRepositoryMockRecorder) ListProfiles() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListProfiles", reflect.TypeOf((*MockProfileRepository)(nil).ListProfiles))
+}
+
+// SaveCurrentProfile mocks base method.
+func (m *MockProfileRepository) SaveCurrentProfile(name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveCurrentProfile", name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveCurrentProfile indicates an expected call of SaveCurrentProfile.
+func (mr *MockProfileRepositoryMockRecorder) SaveCurrentProfile(name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveCurrentProfile", reflect.TypeOf((*MockProfileRepository)(nil).SaveCurrentProfile), name)
+}
+
+// SaveProfile mocks base method.
+func (m *MockProfileRepository) SaveProfile(profile domain.Profile) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveProfile", profile)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveProfile indicates an expected call of SaveProfile.
+func (mr *MockProfileRepositoryMockRecorder) SaveProfile(profile interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveProfile", reflect.TypeOf((*MockProfileRepository)(nil).SaveProfile), profile)
+}
@@ -124,6 +124,21 @@ func (mr *MockSecretClientMockRecorder) GetSecretByVersion(ctx, secretName, vers
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSecretByVersion", reflect.TypeOf((*MockSecretClient)(nil).GetSecretByVersion), ctx, secretName, version)
 }
 
+// GetSecretInfo mocks base method.
+func (m *MockSecretClient) GetSecretInfo(ctx context.Context, secretName string, version int32) (*domain.SecretInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSecretInfo", ctx, secretName, version)
+	ret0, _ := ret[0].(*domain.SecretInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSecretInfo indicates an expected call of GetSecretInfo.
+func (mr *MockSecretClientMockRecorder) GetSecretInfo(ctx, secretName, version interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSecretInfo", reflect.TypeOf((*MockSecretClient)(nil).GetSecretInfo), ctx, secretName, version)
+}
+
 // GetSecretStreamByVersion mocks base method.
 func (m *MockSecretClient) GetSecretStreamByVersion(ctx context.Context, secretName string, version int32) (io.Reader, *domain.SecretInfo, error) {
 	m.ctrl.T.Helper()
@@ -140,6 +155,21 @@ func (mr *MockSecretClientMockRecorder) GetSecretStreamByVersion(ctx, secretName
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSecretStreamByVersion", reflect.TypeOf((*MockSecretClient)(nil).GetSecretStreamByVersion), ctx, secretName, version)
 }
 
+// ListSecretVersions mocks base method.
+func (m *MockSecretClient) ListSecretVersions(ctx context.Context, secretName string) ([]domain.SecretVersion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSecretVersions", ctx, secretName)
+	ret0, _ := ret[0].([]domain.SecretVersion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSecretVersions indicates an expected call of ListSecretVersions.
+func (mr *MockSecretClientMockRecorder) ListSecretVersions(ctx, secretName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSecretVersions", reflect.TypeOf((*MockSecretClient)(nil).ListSecretVersions), ctx, secretName)
+}
+
 // ListSecrets mocks base method.
 func (m *MockSecretClient) ListSecrets(ctx context.Context) ([]string, error) {
 	m.ctrl.T.Helper()
@@ -155,6 +185,21 @@ func (mr *MockSecretClientMockRecorder) ListSecrets(ctx interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSecrets", reflect.TypeOf((*MockSecretClient)(nil).ListSecrets), ctx)
 }
 
+// ListSecretsInfo mocks base method.
+func (m *MockSecretClient) ListSecretsInfo(ctx context.Context) ([]domain.SecretInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSecretsInfo", ctx)
+	ret0, _ := ret[0].([]domain.SecretInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSecretsInfo indicates an expected call of ListSecretsInfo.
+func (mr *MockSecretClientMockRecorder) ListSecretsInfo(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSecretsInfo", reflect.TypeOf((*MockSecretClient)(nil).ListSecretsInfo), ctx)
+}
+
 // MockSecretService is a mock of SecretService interface.
 type MockSecretService struct {
 	ctrl     *gomock.Controller
@@ -252,6 +297,21 @@ func (mr *MockSecretServiceMockRecorder) GetSecretByVersion(ctx, secretName, ver
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSecretByVersion", reflect.TypeOf((*MockSecretService)(nil).GetSecretByVersion), ctx, secretName, version)
 }
 
+// GetSecretInfo mocks base method.
+func (m *MockSecretService) GetSecretInfo(ctx context.Context, secretName string, version int32) (*domain.SecretInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSecretInfo", ctx, secretName, version)
+	ret0, _ := ret[0].(*domain.SecretInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSecretInfo indicates an expected call of GetSecretInfo.
+func (mr *MockSecretServiceMockRecorder) GetSecretInfo(ctx, secretName, version interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSecretInfo", reflect.TypeOf((*MockSecretService)(nil).GetSecretInfo), ctx, secretName, version)
+}
+
 // GetSecretStreamByVersion mocks base method.
 func (m *MockSecretService) GetSecretStreamByVersion(ctx context.Context, secretName string, version int32) (io.Reader, *domain.SecretInfo, error) {
 	m.ctrl.T.Helper()
@@ -268,6 +328,36 @@ func (mr *MockSecretServiceMockRecorder) GetSecretStreamByVersion(ctx, secretNam
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSecretStreamByVersion", reflect.TypeOf((*MockSecretService)(nil).GetSecretStreamByVersion), ctx, secretName, version)
 }
 
+// GetSyncStatus mocks base method.
+func (m *MockSecretService) GetSyncStatus(ctx context.Context) (*domain.SyncStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSyncStatus", ctx)
+	ret0, _ := ret[0].(*domain.SyncStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSyncStatus indicates an expected call of GetSyncStatus.
+func (mr *MockSecretServiceMockRecorder) GetSyncStatus(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSyncStatus", reflect.TypeOf((*MockSecretService)(nil).GetSyncStatus), ctx)
+}
+
+// ListSecretVersions mocks base method.
+func (m *MockSecretService) ListSecretVersions(ctx context.Context, secretName string) ([]domain.SecretVersion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSecretVersions", ctx, secretName)
+	ret0, _ := ret[0].([]domain.SecretVersion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSecretVersions indicates an expected call of ListSecretVersions.
+func (mr *MockSecretServiceMockRecorder) ListSecretVersions(ctx, secretName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSecretVersions", reflect.TypeOf((*MockSecretService)(nil).ListSecretVersions), ctx, secretName)
+}
+
 // ListSecrets mocks base method.
 func (m *MockSecretService) ListSecrets(ctx context.Context) ([]string, error) {
 	m.ctrl.T.Helper()
@@ -282,3 +372,32 @@ func (mr *MockSecretServiceMockRecorder) ListSecrets(ctx interface{}) *gomock.Ca
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSecrets", reflect.TypeOf((*MockSecretService)(nil).ListSecrets), ctx)
 }
+
+// ListSecretsInfo mocks base method.
+func (m *MockSecretService) ListSecretsInfo(ctx context.Context) ([]domain.SecretInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSecretsInfo", ctx)
+	ret0, _ := ret[0].([]domain.SecretInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSecretsInfo indicates an expected call of ListSecretsInfo.
+func (mr *MockSecretServiceMockRecorder) ListSecretsInfo(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSecretsInfo", reflect.TypeOf((*MockSecretService)(nil).ListSecretsInfo), ctx)
+}
+
+// Sync mocks base method.
+func (m *MockSecretService) Sync(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Sync", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Sync indicates an expected call of Sync.
+func (mr *MockSecretServiceMockRecorder) Sync(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Sync", reflect.TypeOf((*MockSecretService)(nil).Sync), ctx)
+}
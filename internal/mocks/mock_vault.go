@@ -0,0 +1,351 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/domain/vault.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	io "io"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+	domain "github.com/ulixes-bloom/ya-gophkeeper-cli/internal/domain"
+)
+
+// MockVaultWriter is a mock of VaultWriter interface.
+type MockVaultWriter struct {
+	ctrl     *gomock.Controller
+	recorder *MockVaultWriterMockRecorder
+}
+
+// MockVaultWriterMockRecorder is the mock recorder for MockVaultWriter.
+type MockVaultWriterMockRecorder struct {
+	mock *MockVaultWriter
+}
+
+// NewMockVaultWriter creates a new mock instance.
+func NewMockVaultWriter(ctrl *gomock.Controller) *MockVaultWriter {
+	mock := &MockVaultWriter{ctrl: ctrl}
+	mock.recorder = &MockVaultWriterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockVaultWriter) EXPECT() *MockVaultWriterMockRecorder {
+	return m.recorder
+}
+
+// Abort mocks base method.
+func (m *MockVaultWriter) Abort() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Abort")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Abort indicates an expected call of Abort.
+func (mr *MockVaultWriterMockRecorder) Abort() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Abort", reflect.TypeOf((*MockVaultWriter)(nil).Abort))
+}
+
+// Commit mocks base method.
+func (m *MockVaultWriter) Commit() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Commit")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Commit indicates an expected call of Commit.
+func (mr *MockVaultWriterMockRecorder) Commit() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Commit", reflect.TypeOf((*MockVaultWriter)(nil).Commit))
+}
+
+// Write mocks base method.
+func (m *MockVaultWriter) Write(p []byte) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Write", p)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Write indicates an expected call of Write.
+func (mr *MockVaultWriterMockRecorder) Write(p interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Write", reflect.TypeOf((*MockVaultWriter)(nil).Write), p)
+}
+
+// MockVaultRepository is a mock of VaultRepository interface.
+type MockVaultRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockVaultRepositoryMockRecorder
+}
+
+// MockVaultRepositoryMockRecorder is the mock recorder for MockVaultRepository.
+type MockVaultRepositoryMockRecorder struct {
+	mock *MockVaultRepository
+}
+
+// NewMockVaultRepository creates a new mock instance.
+func NewMockVaultRepository(ctrl *gomock.Controller) *MockVaultRepository {
+	mock := &MockVaultRepository{ctrl: ctrl}
+	mock.recorder = &MockVaultRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockVaultRepository) EXPECT() *MockVaultRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeleteSecret mocks base method.
+func (m *MockVaultRepository) DeleteSecret(secretName string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSecret", secretName)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSecret indicates an expected call of DeleteSecret.
+func (mr *MockVaultRepositoryMockRecorder) DeleteSecret(secretName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSecret", reflect.TypeOf((*MockVaultRepository)(nil).DeleteSecret), secretName)
+}
+
+// EnqueueOperation mocks base method.
+func (m *MockVaultRepository) EnqueueOperation(op domain.PendingOperation, content io.Reader) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnqueueOperation", op, content)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EnqueueOperation indicates an expected call of EnqueueOperation.
+func (mr *MockVaultRepositoryMockRecorder) EnqueueOperation(op, content interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnqueueOperation", reflect.TypeOf((*MockVaultRepository)(nil).EnqueueOperation), op, content)
+}
+
+// GetKnownVersion mocks base method.
+func (m *MockVaultRepository) GetKnownVersion(secretName string) (int32, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetKnownVersion", secretName)
+	ret0, _ := ret[0].(int32)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetKnownVersion indicates an expected call of GetKnownVersion.
+func (mr *MockVaultRepositoryMockRecorder) GetKnownVersion(secretName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetKnownVersion", reflect.TypeOf((*MockVaultRepository)(nil).GetKnownVersion), secretName)
+}
+
+// GetLastSync mocks base method.
+func (m *MockVaultRepository) GetLastSync() (time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLastSync")
+	ret0, _ := ret[0].(time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLastSync indicates an expected call of GetLastSync.
+func (mr *MockVaultRepositoryMockRecorder) GetLastSync() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLastSync", reflect.TypeOf((*MockVaultRepository)(nil).GetLastSync))
+}
+
+// GetPendingContent mocks base method.
+func (m *MockVaultRepository) GetPendingContent(id string) (io.Reader, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPendingContent", id)
+	ret0, _ := ret[0].(io.Reader)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPendingContent indicates an expected call of GetPendingContent.
+func (mr *MockVaultRepositoryMockRecorder) GetPendingContent(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPendingContent", reflect.TypeOf((*MockVaultRepository)(nil).GetPendingContent), id)
+}
+
+// GetSecret mocks base method.
+func (m *MockVaultRepository) GetSecret(secretName string, version int32) (*domain.Secret, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSecret", secretName, version)
+	ret0, _ := ret[0].(*domain.Secret)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSecret indicates an expected call of GetSecret.
+func (mr *MockVaultRepositoryMockRecorder) GetSecret(secretName, version interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSecret", reflect.TypeOf((*MockVaultRepository)(nil).GetSecret), secretName, version)
+}
+
+// GetSecretInfos mocks base method.
+func (m *MockVaultRepository) GetSecretInfos() ([]domain.SecretInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSecretInfos")
+	ret0, _ := ret[0].([]domain.SecretInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSecretInfos indicates an expected call of GetSecretInfos.
+func (mr *MockVaultRepositoryMockRecorder) GetSecretInfos() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSecretInfos", reflect.TypeOf((*MockVaultRepository)(nil).GetSecretInfos))
+}
+
+// GetSecretNames mocks base method.
+func (m *MockVaultRepository) GetSecretNames() ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSecretNames")
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSecretNames indicates an expected call of GetSecretNames.
+func (mr *MockVaultRepositoryMockRecorder) GetSecretNames() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSecretNames", reflect.TypeOf((*MockVaultRepository)(nil).GetSecretNames))
+}
+
+// GetSecretStream mocks base method.
+func (m *MockVaultRepository) GetSecretStream(secretName string, version int32) (io.Reader, *domain.SecretInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSecretStream", secretName, version)
+	ret0, _ := ret[0].(io.Reader)
+	ret1, _ := ret[1].(*domain.SecretInfo)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetSecretStream indicates an expected call of GetSecretStream.
+func (mr *MockVaultRepositoryMockRecorder) GetSecretStream(secretName, version interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSecretStream", reflect.TypeOf((*MockVaultRepository)(nil).GetSecretStream), secretName, version)
+}
+
+// ListPendingOperations mocks base method.
+func (m *MockVaultRepository) ListPendingOperations() ([]domain.PendingOperation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPendingOperations")
+	ret0, _ := ret[0].([]domain.PendingOperation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPendingOperations indicates an expected call of ListPendingOperations.
+func (mr *MockVaultRepositoryMockRecorder) ListPendingOperations() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPendingOperations", reflect.TypeOf((*MockVaultRepository)(nil).ListPendingOperations))
+}
+
+// RemovePendingOperation mocks base method.
+func (m *MockVaultRepository) RemovePendingOperation(id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemovePendingOperation", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemovePendingOperation indicates an expected call of RemovePendingOperation.
+func (mr *MockVaultRepositoryMockRecorder) RemovePendingOperation(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemovePendingOperation", reflect.TypeOf((*MockVaultRepository)(nil).RemovePendingOperation), id)
+}
+
+// SaveKnownVersion mocks base method.
+func (m *MockVaultRepository) SaveKnownVersion(secretName string, version int32) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveKnownVersion", secretName, version)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveKnownVersion indicates an expected call of SaveKnownVersion.
+func (mr *MockVaultRepositoryMockRecorder) SaveKnownVersion(secretName, version interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveKnownVersion", reflect.TypeOf((*MockVaultRepository)(nil).SaveKnownVersion), secretName, version)
+}
+
+// SaveLastSync mocks base method.
+func (m *MockVaultRepository) SaveLastSync(t time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveLastSync", t)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveLastSync indicates an expected call of SaveLastSync.
+func (mr *MockVaultRepositoryMockRecorder) SaveLastSync(t interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveLastSync", reflect.TypeOf((*MockVaultRepository)(nil).SaveLastSync), t)
+}
+
+// SaveSecret mocks base method.
+func (m *MockVaultRepository) SaveSecret(secret domain.Secret) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveSecret", secret)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveSecret indicates an expected call of SaveSecret.
+func (mr *MockVaultRepositoryMockRecorder) SaveSecret(secret interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveSecret", reflect.TypeOf((*MockVaultRepository)(nil).SaveSecret), secret)
+}
+
+// SaveSecretInfos mocks base method.
+func (m *MockVaultRepository) SaveSecretInfos(infos []domain.SecretInfo) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveSecretInfos", infos)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveSecretInfos indicates an expected call of SaveSecretInfos.
+func (mr *MockVaultRepositoryMockRecorder) SaveSecretInfos(infos interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveSecretInfos", reflect.TypeOf((*MockVaultRepository)(nil).SaveSecretInfos), infos)
+}
+
+// SaveSecretNames mocks base method.
+func (m *MockVaultRepository) SaveSecretNames(names []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveSecretNames", names)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveSecretNames indicates an expected call of SaveSecretNames.
+func (mr *MockVaultRepositoryMockRecorder) SaveSecretNames(names interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveSecretNames", reflect.TypeOf((*MockVaultRepository)(nil).SaveSecretNames), names)
+}
+
+// SaveSecretStream mocks base method.
+func (m *MockVaultRepository) SaveSecretStream(info domain.SecretInfo) (domain.VaultWriter, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveSecretStream", info)
+	ret0, _ := ret[0].(domain.VaultWriter)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveSecretStream indicates an expected call of SaveSecretStream.
+func (mr *MockVaultRepositoryMockRecorder) SaveSecretStream(info interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveSecretStream", reflect.TypeOf((*MockVaultRepository)(nil).SaveSecretStream), info)
+}
@@ -0,0 +1,196 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/domain"
+)
+
+// newAuditCmd creates a command that checks all secrets for security problems.
+func newAuditCmd(ctx context.Context, secretService domain.SecretService, breaches breachSource) *cobra.Command {
+	var (
+		minEntropy float64
+		maxAgeDays int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "audit",
+		Short: "Check stored secrets for security problems",
+		Long: `Checks the latest version of every secret for reused and weak passwords,
+secrets that were not changed for a long time and expired payment cards.
+Passwords are never printed. The command fails when a problem is found, so it can gate CI jobs.
+Use 'audit breaches' to check passwords against a local breach corpus.`,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			secrets, err := loadAuditSecrets(ctx, secretService)
+			if err != nil {
+				return err
+			}
+
+			policy := domain.AuditPolicy{
+				MinEntropy: minEntropy,
+				MaxAge:     time.Duration(maxAgeDays) * 24 * time.Hour,
+			}
+			findings, err := domain.AuditSecrets(secrets, policy, time.Now())
+			if err != nil {
+				log.Error().Err(err).Msg("Failed to audit secrets")
+				return fmt.Errorf("failed to audit secrets")
+			}
+
+			return renderAuditFindings(cmd, findings, len(secrets))
+		},
+	}
+
+	cmd.Flags().Float64Var(&minEntropy, "min-entropy", domain.DefaultAuditPolicy.MinEntropy,
+		"Report passwords with a lower estimated entropy in bits, 0 disables the check")
+	cmd.Flags().IntVar(&maxAgeDays, "max-age-days", int(domain.DefaultAuditPolicy.MaxAge.Hours()/24),
+		"Report secrets whose latest version is older, 0 disables the check")
+
+	cmd.AddCommand(newAuditBreachesCmd(ctx, secretService, breaches))
+
+	return cmd
+}
+
+// loadAuditSecrets loads the latest version of every secret.
+// Payloads are only retrieved for credentials and payment cards.
+func loadAuditSecrets(ctx context.Context, secretService domain.SecretService) ([]domain.Secret, error) {
+	infos, err := secretService.ListSecretsInfo(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list secrets")
+		return nil, fmt.Errorf("failed to list secrets")
+	}
+
+	secrets := make([]domain.Secret, len(infos))
+	for i, info := range infos {
+		secrets[i].Info = info
+		if info.Type != domain.CredentialsSecretType && info.Type != domain.PaymentCardSecretType {
+			continue
+		}
+
+		secret, err := secretService.GetLatestSecret(ctx, info.Name)
+		if err != nil {
+			log.Error().Err(err).Msgf("Failed to retrieve secret '%s'", info.Name)
+			return nil, fmt.Errorf("failed to retrieve secret '%s'", info.Name)
+		}
+		secrets[i].Data = secret.Data
+	}
+	return secrets, nil
+}
+
+// renderAuditFindings prints the findings and fails if there are any.
+func renderAuditFindings(cmd *cobra.Command, findings []domain.AuditFinding, checked int) error {
+	views := make([]auditFindingView, len(findings))
+	for i, f := range findings {
+		views[i] = newAuditFindingView(f)
+	}
+
+	err := renderList(cmd, auditReportOutputKind, views, func(w io.Writer) error {
+		if len(views) == 0 {
+			fmt.Fprintln(w, "No problems found")
+			return nil
+		}
+
+		tableViews := make([]view, len(views))
+		for i, v := range views {
+			tableViews[i] = v
+		}
+		return renderTable(w, tableViews)
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(findings) > 0 {
+		return fmt.Errorf("audit found %d problem(s) in %d secret(s)", len(findings), checked)
+	}
+	return nil
+}
+
+// breachSource opens the local breach corpus used to check passwords.
+type breachSource struct {
+	defaultPath string
+	open        func(path string) (domain.BreachCorpus, error)
+}
+
+// openCorpus opens the corpus at path, or at the configured path if path is empty.
+func (s breachSource) openCorpus(path string) (domain.BreachCorpus, error) {
+	if s.open == nil {
+		return nil, fmt.Errorf("breach checks are not available")
+	}
+	if path == "" {
+		path = s.defaultPath
+	}
+	if path == "" {
+		return nil, fmt.Errorf("no hash file, set --hash-file or the breach_file setting")
+	}
+
+	corpus, err := s.open(path)
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed to open hash file '%s'", path)
+		return nil, fmt.Errorf("failed to open hash file '%s': %w", path, err)
+	}
+	return corpus, nil
+}
+
+// warnIfBreached warns on stderr if the password was seen in data breaches.
+func (s breachSource) warnIfBreached(cmd *cobra.Command, path, password string) error {
+	corpus, err := s.openCorpus(path)
+	if err != nil {
+		return err
+	}
+	defer corpus.Close()
+
+	count, err := corpus.Count(password)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to check password against the hash file")
+		return fmt.Errorf("failed to check password against the hash file")
+	}
+	if count > 0 {
+		fmt.Fprintf(cmd.ErrOrStderr(), "Warning: this password was seen %d times in data breaches\n", count)
+	}
+	return nil
+}
+
+// newAuditBreachesCmd creates a command that checks stored passwords against a local breach corpus.
+func newAuditBreachesCmd(ctx context.Context, secretService domain.SecretService, breaches breachSource) *cobra.Command {
+	var hashFile string
+
+	cmd := &cobra.Command{
+		Use:   "breaches",
+		Short: "Check stored passwords against a local Pwned Passwords hash file",
+		Long: `Checks the password of every credentials secret against a downloaded
+Pwned Passwords SHA-1 file ordered by hash. Passwords never leave this device.
+The command fails when a breached password is found.`,
+		Annotations: map[string]string{vaultAnnotation: "true"},
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			corpus, err := breaches.openCorpus(hashFile)
+			if err != nil {
+				return err
+			}
+			defer corpus.Close()
+
+			secrets, err := loadAuditSecrets(ctx, secretService)
+			if err != nil {
+				return err
+			}
+
+			findings, err := domain.AuditBreaches(secrets, corpus)
+			if err != nil {
+				log.Error().Err(err).Msg("Failed to check passwords against the hash file")
+				return fmt.Errorf("failed to check passwords against the hash file")
+			}
+
+			return renderAuditFindings(cmd, findings, len(secrets))
+		},
+	}
+
+	cmd.Flags().StringVar(&hashFile, "hash-file", "", "Path to the SHA-1 hash file (default: the breach_file setting)")
+
+	return cmd
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"time"
 
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
@@ -56,9 +58,10 @@ func newLoginCmd(ctx context.Context, authService domain.AuthService) *cobra.Com
 	var login, password string
 
 	cmd := &cobra.Command{
-		Use:   "login",
-		Short: "Login to your account",
-		Long:  `Authenticate with your existing account credentials`,
+		Use:         "login",
+		Short:       "Login to your account",
+		Long:        `Authenticate with your existing account credentials`,
+		Annotations: map[string]string{secretNamesAnnotation: "true"},
 
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if err := authService.Login(ctx, login, password); err != nil {
@@ -87,3 +90,77 @@ func newLoginCmd(ctx context.Context, authService domain.AuthService) *cobra.Com
 
 	return cmd
 }
+
+// newLogoutCmd creates a cobra command that removes the stored session token
+// ctx: Context for request cancellation and timeouts
+// authService: Authentication service interface
+// Returns: Configured cobra.Command for logout
+func newLogoutCmd(ctx context.Context, authService domain.AuthService) *cobra.Command {
+	return &cobra.Command{
+		Use:         "logout",
+		Short:       "Logout from your account",
+		Long:        "Remove the stored session token from this device",
+		Annotations: map[string]string{secretNamesAnnotation: "true"},
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := authService.Logout(ctx); err != nil {
+				log.Error().Err(err).Msg("failed to log out")
+				return fmt.Errorf("failed to log out")
+			}
+
+			fmt.Fprintln(cmd.OutOrStdout(), "Successfully logged out")
+			return nil
+		},
+	}
+}
+
+// newWhoAmICmd creates a cobra command that shows the current session
+// ctx: Context for request cancellation and timeouts
+// authService: Authentication service interface
+// Returns: Configured cobra.Command for whoami
+func newWhoAmICmd(ctx context.Context, authService domain.AuthService) *cobra.Command {
+	return &cobra.Command{
+		Use:   "whoami",
+		Short: "Show the current session",
+		Long:  "Show the login and expiry time of the stored session token",
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			claims, err := authService.WhoAmI(ctx)
+			if err != nil {
+				log.Error().Err(err).Msg("failed to get session")
+
+				switch {
+				case errors.Is(err, domain.ErrTokenNotFound):
+					return fmt.Errorf("not logged in")
+				default:
+					return fmt.Errorf("failed to get session")
+				}
+			}
+
+			now := time.Now()
+			return renderOne(cmd, sessionOutputKind, newSessionView(claims, now), func(w io.Writer) error {
+				printSession(w, claims, now)
+				return nil
+			})
+		},
+	}
+}
+
+// printSession prints the login and expiry of a session token.
+func printSession(w io.Writer, claims *domain.TokenClaims, now time.Time) {
+	login := claims.Login
+	if login == "" {
+		login = "unknown"
+	}
+	fmt.Fprintf(w, "Login: %s\n", login)
+
+	switch {
+	case claims.ExpiresAt.IsZero():
+		fmt.Fprintln(w, "Expires: never")
+	case claims.Expired(now):
+		fmt.Fprintf(w, "Expired: %s\n", claims.ExpiresAt.Format(time.RFC3339))
+	default:
+		fmt.Fprintf(w, "Expires: %s (in %s)\n",
+			claims.ExpiresAt.Format(time.RFC3339), claims.ExpiresAt.Sub(now).Round(time.Second))
+	}
+}
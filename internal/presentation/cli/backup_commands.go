@@ -0,0 +1,329 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/domain"
+)
+
+// backupSource creates and opens the encrypted archives of the backup commands.
+type backupSource struct {
+	create func(w io.Writer, passphrase string, allVersions bool) (domain.BackupWriter, error)
+	open   func(path, passphrase string) (domain.BackupReader, error)
+}
+
+// newBackupCmd creates a command that writes every secret into an encrypted backup archive.
+// Every request gets its own requestTimeout, as a backup downloads every secret.
+func newBackupCmd(ctx context.Context, secretService domain.SecretService, backups backupSource,
+	requestTimeout time.Duration) *cobra.Command {
+	var (
+		filePath, passphraseFile string
+		allVersions              bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "backup",
+		Short: "Back up all secrets into an encrypted archive",
+		Long: `Writes the latest version of every secret, or every version with --all-versions,
+into a single archive encrypted with a passphrase. The archive holds a manifest with the
+size and SHA-256 checksum of every version and is only replaced once it is complete.
+The passphrase is asked for twice unless --passphrase-file is given.
+Use 'restore-backup' to verify and restore an archive.`,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if backups.create == nil {
+				return fmt.Errorf("backups are not available")
+			}
+			passphrase, err := readBackupPassphrase(passphraseFile, true)
+			if err != nil {
+				return err
+			}
+
+			ctx := untilCanceled(ctx)
+			infos, err := backupVersions(ctx, secretService, allVersions, requestTimeout)
+			if err != nil {
+				return err
+			}
+
+			infos, err = writeBackup(ctx, secretService, backups, filePath, passphrase, allVersions, infos, requestTimeout)
+			if err != nil {
+				return err
+			}
+
+			secrets := make(map[string]bool)
+			for _, info := range infos {
+				secrets[info.Name] = true
+			}
+			v := backupView{File: filePath, Secrets: len(secrets), Versions: len(infos), AllVersions: allVersions}
+			return renderOne(cmd, backupOutputKind, v, func(w io.Writer) error {
+				fmt.Fprintf(w, "Backed up %d versions of %d secrets to '%s'\n", v.Versions, v.Secrets, v.File)
+				return nil
+			})
+		},
+	}
+
+	cmd.Flags().StringVarP(&filePath, "file", "f", "", "Path of the backup archive (required)")
+	cmd.Flags().BoolVar(&allVersions, "all-versions", false, "Back up every version instead of the latest one")
+	cmd.Flags().StringVar(&passphraseFile, "passphrase-file", "", "Read the passphrase from the first line of this file")
+
+	_ = cmd.MarkFlagRequired("file")
+
+	return cmd
+}
+
+// backupVersions lists the secret versions to back up, ordered by name and version.
+// Older versions are listed by number with the name and type of the latest one,
+// their infos are taken from the server when they are downloaded.
+func backupVersions(ctx context.Context, secretService domain.SecretService, allVersions bool,
+	timeout time.Duration) ([]domain.SecretInfo, error) {
+	ctx, cancel := requestContext(ctx, timeout)
+	defer cancel()
+
+	latest, err := secretService.ListSecretsInfo(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list secrets")
+		return nil, fmt.Errorf("failed to list secrets")
+	}
+	sort.Slice(latest, func(i, j int) bool { return latest[i].Name < latest[j].Name })
+	if !allVersions {
+		return latest, nil
+	}
+
+	var infos []domain.SecretInfo
+	for _, info := range latest {
+		for version := int32(1); version < info.Version; version++ {
+			infos = append(infos, domain.SecretInfo{Name: info.Name, Type: info.Type, Version: version})
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+// writeBackup writes the secret versions into a temporary file next to the archive
+// and renames it over the archive once it is complete. Older versions that no longer exist are skipped.
+// Every version is downloaded with its own request timeout. Returns the infos of the versions written.
+func writeBackup(ctx context.Context, secretService domain.SecretService, backups backupSource,
+	path, passphrase string, allVersions bool, infos []domain.SecretInfo,
+	timeout time.Duration) (written []domain.SecretInfo, err error) {
+	file, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed to create backup file '%s'", path)
+		return nil, fmt.Errorf("failed to create backup file '%s'", path)
+	}
+	defer func() {
+		if err != nil {
+			file.Close()
+			os.Remove(file.Name())
+		}
+	}()
+
+	archive, err := backups.create(file, passphrase, allVersions)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to create backup archive")
+		return nil, fmt.Errorf("failed to create backup archive")
+	}
+
+	for i, info := range infos {
+		versionCtx, cancel := requestContext(ctx, timeout)
+		content, stored, err := secretVersionContent(versionCtx, secretService, info)
+		if errors.Is(err, domain.ErrSecretNotFound) && i+1 < len(infos) && infos[i+1].Name == info.Name {
+			cancel()
+			continue
+		}
+		if err != nil {
+			cancel()
+			log.Error().Err(err).Msgf("Failed to retrieve version %d of secret '%s'", info.Version, info.Name)
+			return nil, fmt.Errorf("failed to retrieve version %d of secret '%s'", info.Version, info.Name)
+		}
+		err = archive.Add(*stored, content)
+		cancel()
+		if err != nil {
+			log.Error().Err(err).Msgf("Failed to back up version %d of secret '%s'", info.Version, info.Name)
+			return nil, fmt.Errorf("failed to back up version %d of secret '%s'", info.Version, info.Name)
+		}
+		written = append(written, *stored)
+	}
+
+	if err := archive.Close(); err != nil {
+		log.Error().Err(err).Msg("Failed to write backup archive")
+		return nil, fmt.Errorf("failed to write backup archive")
+	}
+	if err := file.Sync(); err != nil {
+		log.Error().Err(err).Msg("Failed to write backup archive")
+		return nil, fmt.Errorf("failed to write backup archive")
+	}
+	if err := file.Close(); err != nil {
+		log.Error().Err(err).Msg("Failed to write backup archive")
+		return nil, fmt.Errorf("failed to write backup archive")
+	}
+	if err := os.Rename(file.Name(), path); err != nil {
+		log.Error().Err(err).Msgf("Failed to move backup archive to '%s'", path)
+		return nil, fmt.Errorf("failed to move backup archive to '%s'", path)
+	}
+	return written, nil
+}
+
+// newRestoreBackupCmd creates a command that verifies a backup archive and stores its secrets.
+// Every request gets its own requestTimeout, as a restore may store hundreds of versions.
+func newRestoreBackupCmd(ctx context.Context, secretService domain.SecretService, backups backupSource,
+	requestTimeout time.Duration) *cobra.Command {
+	var (
+		filePath, passphraseFile, collision string
+		dryRun                              bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "restore-backup",
+		Short: "Verify a backup archive and restore its secrets",
+		Long: `Verifies the checksum of every version in an archive written by 'backup' before anything is stored,
+then stores the versions of every secret oldest first. Names that are already taken are resolved
+with --on-collision: skip keeps the existing secret, overwrite stores the versions on top of an existing
+secret of the same type, rename stores them under the first free name with a numeric suffix
+and fail stops before anything is stored.
+The passphrase is asked for unless --passphrase-file is given.`,
+		Annotations: map[string]string{secretNamesAnnotation: "true"},
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			policy, err := domain.ParseImportCollisionPolicy(collision)
+			if err != nil {
+				return err
+			}
+			if backups.open == nil {
+				return fmt.Errorf("backups are not available")
+			}
+			passphrase, err := readBackupPassphrase(passphraseFile, false)
+			if err != nil {
+				return err
+			}
+
+			archive, err := backups.open(filePath, passphrase)
+			if err != nil {
+				log.Error().Err(err).Msgf("Failed to open backup '%s'", filePath)
+				return fmt.Errorf("failed to open backup '%s': %w", filePath, err)
+			}
+			manifest, err := archive.Verify()
+			if err != nil {
+				log.Error().Err(err).Msgf("Failed to verify backup '%s'", filePath)
+				return fmt.Errorf("failed to verify backup '%s': %w", filePath, err)
+			}
+
+			ctx := untilCanceled(ctx)
+			listCtx, cancel := requestContext(ctx, requestTimeout)
+			existing, err := secretService.ListSecretsInfo(listCtx)
+			cancel()
+			if err != nil {
+				log.Error().Err(err).Msg("Failed to list secrets")
+				return fmt.Errorf("failed to list secrets")
+			}
+
+			plan, err := domain.PlanRestore(manifest.Entries, existing, policy)
+			if err != nil {
+				return err
+			}
+			views := make([]importResultView, len(plan))
+			for i, p := range plan {
+				views[i] = newRestoreResultView(p)
+			}
+
+			if !dryRun {
+				if err := restoreBackup(ctx, secretService, archive, plan, views, requestTimeout); err != nil {
+					log.Error().Err(err).Msgf("Failed to read backup '%s'", filePath)
+					return fmt.Errorf("failed to read backup '%s': %w", filePath, err)
+				}
+			}
+
+			return renderImportResults(cmd, restoreReportOutputKind, views, dryRun, "restore", "Restored")
+		},
+	}
+
+	cmd.Flags().StringVarP(&filePath, "file", "f", "", "Path of the backup archive (required)")
+	cmd.Flags().StringVar(&collision, "on-collision", string(domain.SkipImportCollisionPolicy),
+		"What to do with secrets whose name is taken: skip, overwrite, rename or fail")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Verify the archive and show what would be restored without storing anything")
+	cmd.Flags().StringVar(&passphraseFile, "passphrase-file", "", "Read the passphrase from the first line of this file")
+
+	_ = cmd.MarkFlagRequired("file")
+
+	return cmd
+}
+
+// restoreBackup stores the planned versions while walking the archive, which is in plan order.
+// Every version is stored with its own request timeout.
+// Versions that cannot be stored are marked as failed in the views, the walk goes on.
+func restoreBackup(ctx context.Context, secretService domain.SecretService, archive domain.BackupReader,
+	plan []domain.PlannedRestore, views []importResultView, timeout time.Duration) error {
+	i := 0
+	return archive.Walk(func(entry domain.BackupEntry, content io.Reader) error {
+		if i >= len(plan) {
+			return fmt.Errorf("%w: more entries than listed in the manifest", domain.ErrInvalidBackup)
+		}
+		p := plan[i]
+		i++
+		if p.Action == domain.SkipImportAction {
+			return nil
+		}
+
+		secret := domain.Secret{
+			Info: domain.SecretInfo{
+				Name:     p.Name,
+				Type:     entry.Type,
+				Metadata: entry.Metadata,
+			},
+			BaseVersion: p.BaseVersion,
+		}
+		ctx, cancel := requestContext(ctx, timeout)
+		defer cancel()
+		if err := secretService.CreateSecret(ctx, secret, content); err != nil {
+			log.Error().Err(err).Msgf("Failed to restore version %d of '%s'", entry.Version, p.Name)
+			views[i-1].Action, views[i-1].Detail = failedImportAction, "failed to store secret"
+		}
+		return nil
+	})
+}
+
+// readBackupPassphrase reads the passphrase from the first line of the file,
+// or from the terminal if no file is given. A new passphrase is asked for twice.
+func readBackupPassphrase(path string, confirm bool) (string, error) {
+	var passphrase string
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			log.Error().Err(err).Msgf("Failed to read passphrase file '%s'", path)
+			return "", fmt.Errorf("failed to read passphrase file '%s'", path)
+		}
+		passphrase, _, _ = strings.Cut(string(data), "\n")
+		passphrase = strings.TrimSuffix(passphrase, "\r")
+	} else {
+		var err error
+		if passphrase, err = promptSecret("Backup passphrase: "); err != nil {
+			if errors.Is(err, ErrNoTerminal) {
+				return "", fmt.Errorf("%w, set --passphrase-file", err)
+			}
+			return "", err
+		}
+		if confirm && passphrase != "" {
+			repeated, err := promptSecret("Repeat backup passphrase: ")
+			if err != nil {
+				return "", err
+			}
+			if repeated != passphrase {
+				return "", ErrPasswordsMismatch
+			}
+		}
+	}
+
+	if passphrase == "" {
+		return "", fmt.Errorf("backup passphrase cannot be empty")
+	}
+	return passphrase, nil
+}
@@ -0,0 +1,106 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/domain"
+)
+
+// cardFlags holds payment card fields shared by the create and update commands.
+type cardFlags struct {
+	number, holder, expiry, cvv, pin, issuer, notes string
+}
+
+// addCardFlags registers payment card flags on the command.
+func addCardFlags(cmd *cobra.Command, f *cardFlags) {
+	cmd.Flags().StringVarP(&f.number, "number", "c", "", "Card number")
+	cmd.Flags().StringVar(&f.holder, "holder", "", "Cardholder name")
+	cmd.Flags().StringVar(&f.expiry, "expiry", "", "Expiry date as MM/YY or MM/YYYY")
+	cmd.Flags().StringVar(&f.cvv, "cvv", "", "Card verification value")
+	cmd.Flags().StringVar(&f.pin, "pin", "", "Card PIN")
+	cmd.Flags().StringVar(&f.issuer, "issuer", "", "Issuing bank")
+	cmd.Flags().StringVar(&f.notes, "notes", "", "Billing notes")
+}
+
+// cardFlagNames are the names of the flags registered by addCardFlags.
+var cardFlagNames = []string{"number", "holder", "expiry", "cvv", "pin", "issuer", "notes"}
+
+// validatedCardFlags are the flags of the card fields checked by Validate.
+var validatedCardFlags = []string{"number", "expiry", "cvv", "pin"}
+
+// apply sets the card fields given on the command line and validates the result
+// if any validated field was given, so cards stored before validation can still be
+// updated in other fields.
+func (f cardFlags) apply(cmd *cobra.Command, card *domain.PaymentCardSecret) error {
+	changed := cmd.Flags().Changed
+
+	if changed("number") {
+		card.Number = domain.NormalizeCardNumber(f.number)
+	}
+	if changed("holder") {
+		card.Holder = f.holder
+	}
+	if changed("expiry") {
+		card.ExpiryMonth, card.ExpiryYear = 0, 0
+		if f.expiry != "" {
+			month, year, err := domain.ParseCardExpiry(f.expiry)
+			if err != nil {
+				return err
+			}
+			card.ExpiryMonth, card.ExpiryYear = month, year
+		}
+	}
+	if changed("cvv") {
+		card.CVV = f.cvv
+	}
+	if changed("pin") {
+		card.PIN = f.pin
+	}
+	if changed("issuer") {
+		card.Issuer = f.issuer
+	}
+	if changed("notes") {
+		card.Notes = f.notes
+	}
+
+	for _, name := range validatedCardFlags {
+		if !changed(name) {
+			continue
+		}
+		if err := card.Validate(); err != nil {
+			return err
+		}
+		break
+	}
+	if card.Expired(time.Now()) {
+		fmt.Fprintf(cmd.ErrOrStderr(), "Warning: card expired in %s\n", formatCardExpiry(*card))
+	}
+	return nil
+}
+
+// formatCardExpiry formats the card expiry date as MM/YYYY.
+func formatCardExpiry(card domain.PaymentCardSecret) string {
+	if card.ExpiryYear == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%02d/%d", card.ExpiryMonth, card.ExpiryYear)
+}
+
+// maskCardNumber hides all but the last four digits of a card number.
+func maskCardNumber(number string) string {
+	if len(number) <= 4 {
+		return strings.Repeat("*", len(number))
+	}
+	return strings.Repeat("*", len(number)-4) + number[len(number)-4:]
+}
+
+// maskValue hides a non-empty value.
+func maskValue(value string) string {
+	if value == "" {
+		return ""
+	}
+	return maskedValue
+}
@@ -2,6 +2,10 @@ package cli
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/domain"
@@ -13,37 +17,230 @@ var (
 	GitCommit = "HEAD"
 )
 
-func NewCLI(ctx context.Context, secretService domain.SecretService, authService domain.AuthService) *cobra.Command {
+// vaultAnnotation marks commands that encrypt or decrypt secret payloads
+// and therefore require the vault to be unlocked before they run.
+const vaultAnnotation = "vault"
+
+// secretNamesAnnotation marks commands that may add or remove secrets or switch the account,
+// after which the shell lists the secret names again.
+const secretNamesAnnotation = "secret-names"
+
+// options holds optional dependencies of the root command.
+type options struct {
+	unlockVault    func() error
+	profileService domain.ProfileService
+	configService  domain.ConfigService
+	breaches       breachSource
+	clipboard      clipboardSource
+	requestTimeout time.Duration
+	shell          shellOptions
+	openImporter   func(format string) (domain.Importer, error)
+	backups        backupSource
+}
+
+// Option configures optional behaviour of the root command.
+type Option func(*options)
+
+// WithVaultUnlocker sets the function used to unlock the encryption key
+// before running commands that work with secret payloads.
+func WithVaultUnlocker(unlock func() error) Option {
+	return func(o *options) {
+		o.unlockVault = unlock
+	}
+}
+
+// WithProfileService enables the profile management commands.
+func WithProfileService(profileService domain.ProfileService) Option {
+	return func(o *options) {
+		o.profileService = profileService
+	}
+}
+
+// WithConfigService enables the configuration commands.
+func WithConfigService(configService domain.ConfigService) Option {
+	return func(o *options) {
+		o.configService = configService
+	}
+}
+
+// WithBreachCorpus enables password checks against a local breach corpus.
+// The corpus at defaultPath is used unless another one is given on the command line.
+func WithBreachCorpus(defaultPath string, open func(path string) (domain.BreachCorpus, error)) Option {
+	return func(o *options) {
+		o.breaches = breachSource{defaultPath: defaultPath, open: open}
+	}
+}
+
+// WithClipboard enables the --copy flag of the get commands.
+// The clipboard of defaultBackend is used and cleared after defaultClearAfter unless flags say otherwise.
+func WithClipboard(defaultBackend string, defaultClearAfter time.Duration, open func(backend string) (domain.Clipboard, error)) Option {
+	return func(o *options) {
+		o.clipboard = clipboardSource{defaultBackend: defaultBackend, defaultClearAfter: defaultClearAfter, open: open}
+	}
+}
+
+// WithImporter enables importing the exports of other password managers
+// with the importer that open returns for a format.
+func WithImporter(open func(format string) (domain.Importer, error)) Option {
+	return func(o *options) {
+		o.openImporter = open
+	}
+}
+
+// WithBackup enables the backup commands, which write archives with create and read them with open.
+func WithBackup(create func(w io.Writer, passphrase string, allVersions bool) (domain.BackupWriter, error),
+	open func(path, passphrase string) (domain.BackupReader, error)) Option {
+	return func(o *options) {
+		o.backups = backupSource{create: create, open: open}
+	}
+}
+
+// WithRequestTimeout limits every request of the commands that send many of them,
+// like import, backup and the commands run in the shell, instead of the command as a whole.
+func WithRequestTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.requestTimeout = timeout
+	}
+}
+
+// WithShell enables the interactive shell. lockVault discards the vault key
+// after inactivity and reports whether the vault was unlocked.
+func WithShell(lockVault func() bool) Option {
+	return func(o *options) {
+		o.shell = shellOptions{enabled: true, lockVault: lockVault}
+	}
+}
+
+func NewCLI(ctx context.Context, secretService domain.SecretService, authService domain.AuthService, opts ...Option) *cobra.Command {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
 	rootCmd := &cobra.Command{
 		Use:   "gophkeeper-cli",
 		Short: "GophKeeper - Secure secret management CLI",
 		Long: `GophKeeper is a command-line tool for securely storing and managing secrets.
 		
 Features:
-- Store credentials, payment cards, text notes and files
+- Store credentials, payment cards, SSH keys, text notes and files
 - Encrypted storage with versioning
 - Cross-platform secret synchronization
 
 Use 'gophkeeper <command> --help' for detailed usage of each command.`,
 		Version:      Version,
 		SilenceUsage: true,
+
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			// Reject an unknown output format before doing any work
+			if _, err := outputFormat(cmd); err != nil {
+				return err
+			}
+
+			if cmd.Annotations[vaultAnnotation] == "" || o.unlockVault == nil {
+				return nil
+			}
+			if err := o.unlockVault(); err != nil {
+				return fmt.Errorf("failed to unlock vault: %w", err)
+			}
+			return nil
+		},
 	}
 
-	// Add all secret management commands
-	rootCmd.AddCommand(newCreateCredentialsSecretCmd(ctx, secretService))
-	rootCmd.AddCommand(newCreatePaymentCardSecretCmd(ctx, secretService))
-	rootCmd.AddCommand(newCreateTextSecretCmd(ctx, secretService))
-	rootCmd.AddCommand(newCreateFileSecretCmd(ctx, secretService))
+	// Global flags are parsed before the commands are built, see ParseGlobalFlags;
+	// registering them here makes cobra accept and document them.
+	var globals GlobalFlags
+	addGlobalFlags(rootCmd.PersistentFlags(), &globals)
+	addOutputFlag(rootCmd.PersistentFlags())
+
+	// Add commands that work with encrypted secret payloads
+	vaultCmds := []*cobra.Command{
+		newCreateCredentialsSecretCmd(ctx, secretService, o.breaches),
+		newCreatePaymentCardSecretCmd(ctx, secretService),
+		newCreateTextSecretCmd(ctx, secretService),
+		newCreateFileSecretCmd(ctx, secretService),
+		newCreateSSHKeySecretCmd(ctx, secretService),
+		newUpdateCredentialsSecretCmd(ctx, secretService),
+		newUpdatePaymentCardSecretCmd(ctx, secretService),
+		newUpdateTextSecretCmd(ctx, secretService),
+		newUpdateFileSecretCmd(ctx, secretService),
+		newGetCredentialsSecretCmd(ctx, secretService, o.clipboard),
+		newGetPaymentCardSecretCmd(ctx, secretService, o.clipboard),
+		newGetTextSecretCmd(ctx, secretService, o.clipboard),
+		newGetFileSecretCmd(ctx, secretService, o.clipboard),
+		newGetSSHKeySecretCmd(ctx, secretService, o.clipboard),
+		newSSHAgentCmd(ctx, secretService),
+		newTOTPCmd(ctx, secretService),
+		newDiffCmd(ctx, secretService),
+		newRestoreCmd(ctx, secretService),
+		newAuditCmd(ctx, secretService, o.breaches),
+		newImportCmd(ctx, secretService, o.openImporter, o.requestTimeout),
+		newBackupCmd(ctx, secretService, o.backups, o.requestTimeout),
+		newRestoreBackupCmd(ctx, secretService, o.backups, o.requestTimeout),
+		newRunCmd(ctx, secretService),
+		newInjectCmd(ctx, secretService),
+		newGitCredentialCmd(ctx, secretService),
+		newSyncCmd(ctx, secretService),
+	}
+	for _, cmd := range vaultCmds {
+		if cmd.Annotations == nil {
+			cmd.Annotations = make(map[string]string)
+		}
+		cmd.Annotations[vaultAnnotation] = "true"
+		rootCmd.AddCommand(cmd)
+	}
+
+	// Add remaining secret management commands
 	rootCmd.AddCommand(newListSecretsCmd(ctx, secretService))
-	rootCmd.AddCommand(newGetCredentialsSecretCmd(ctx, secretService))
-	rootCmd.AddCommand(newGetPaymentCardSecretCmd(ctx, secretService))
-	rootCmd.AddCommand(newGetTextSecretCmd(ctx, secretService))
-	rootCmd.AddCommand(newGetFileSecretCmd(ctx, secretService))
+	rootCmd.AddCommand(newHistoryCmd(ctx, secretService))
 	rootCmd.AddCommand(newDeleteSecretCmd(ctx, secretService))
+	rootCmd.AddCommand(newGenerateCmd())
 
 	// Add authentication commands
 	rootCmd.AddCommand(newRegisterCmd(ctx, authService))
 	rootCmd.AddCommand(newLoginCmd(ctx, authService))
+	rootCmd.AddCommand(newLogoutCmd(ctx, authService))
+	rootCmd.AddCommand(newWhoAmICmd(ctx, authService))
+
+	// Add the interactive shell, which builds a new command tree for every line
+	if o.shell.enabled {
+		newRoot := func(ctx context.Context) *cobra.Command {
+			return NewCLI(ctx, secretService, authService, opts...)
+		}
+		shell := o.shell
+		shell.requestTimeout = o.requestTimeout
+		rootCmd.AddCommand(newShellCmd(ctx, secretService, newRoot, shell))
+	}
+
+	// Add profile management commands
+	if o.profileService != nil {
+		rootCmd.AddCommand(newProfileCmd(ctx, o.profileService))
+	}
+
+	// Add configuration commands
+	if o.configService != nil {
+		rootCmd.AddCommand(newConfigCmd(ctx, o.configService))
+	}
 
 	return rootCmd
 }
+
+// untilCanceled returns a context that is canceled with the parent but ignores its deadline.
+func untilCanceled(parent context.Context) context.Context {
+	ctx, cancel := context.WithCancel(context.WithoutCancel(parent))
+	context.AfterFunc(parent, func() {
+		if errors.Is(parent.Err(), context.Canceled) {
+			cancel()
+		}
+	})
+	return ctx
+}
+
+// requestContext returns the context of a single request of a command sending many of them,
+// limited by timeout. Pass a context from untilCanceled, so the command deadline does not apply.
+func requestContext(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout > 0 {
+		return context.WithTimeout(ctx, timeout)
+	}
+	return context.WithCancel(ctx)
+}
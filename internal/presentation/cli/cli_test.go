@@ -3,20 +3,32 @@ package cli_test
 import (
 	"bytes"
 	"context"
+	"crypto/ed25519"
+	"crypto/rand"
 	"encoding/json"
+	"encoding/pem"
 	"errors"
 	"fmt"
 	"io"
+	"net"
 	"os"
+	"path/filepath"
+	"slices"
 	"strings"
+	"sync/atomic"
+	"syscall"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/domain"
 	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/mocks"
 	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/presentation/cli"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
 )
 
 func TestCLI_RegisterCmd(t *testing.T) {
@@ -159,7 +171,58 @@ func TestCLI_LoginCmd(t *testing.T) {
 	}
 }
 
-func TestCLI_CreateCredentialsSecretCmd(t *testing.T) {
+func TestCLI_LogoutCmd(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuthService := mocks.NewMockAuthService(ctrl)
+	mockSecretService := mocks.NewMockSecretService(ctrl)
+
+	ctx := context.Background()
+	cmd := cli.NewCLI(ctx, mockSecretService, mockAuthService)
+
+	tests := []struct {
+		name           string
+		setupMock      func()
+		expectedOutput string
+		expectedError  error
+	}{
+		{
+			name: "successful logout",
+			setupMock: func() {
+				mockAuthService.EXPECT().Logout(ctx).Return(nil)
+			},
+			expectedOutput: "Successfully logged out\n",
+		},
+		{
+			name: "token can't be removed",
+			setupMock: func() {
+				mockAuthService.EXPECT().Logout(ctx).Return(errors.New("permission denied"))
+			},
+			expectedError: errors.New("failed to log out"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setupMock()
+
+			cmd.SetArgs([]string{"logout"})
+
+			output, err := executeCommand(cmd)
+
+			if tt.expectedError != nil {
+				assert.Error(t, err)
+				assert.Equal(t, tt.expectedError.Error(), err.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedOutput, output)
+			}
+		})
+	}
+}
+
+func TestCLI_WhoAmICmd(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
@@ -169,6 +232,67 @@ func TestCLI_CreateCredentialsSecretCmd(t *testing.T) {
 	ctx := context.Background()
 	cmd := cli.NewCLI(ctx, mockSecretService, mockAuthService)
 
+	expiredAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		setupMock      func()
+		expectedOutput string
+		expectedError  error
+	}{
+		{
+			name: "expired session",
+			setupMock: func() {
+				mockAuthService.EXPECT().WhoAmI(ctx).
+					Return(&domain.TokenClaims{Login: "testuser", ExpiresAt: expiredAt}, nil)
+			},
+			expectedOutput: "Login: testuser\nExpired: " + expiredAt.Format(time.RFC3339) + "\n",
+		},
+		{
+			name: "session without expiry",
+			setupMock: func() {
+				mockAuthService.EXPECT().WhoAmI(ctx).
+					Return(&domain.TokenClaims{Login: "testuser"}, nil)
+			},
+			expectedOutput: "Login: testuser\nExpires: never\n",
+		},
+		{
+			name: "not logged in",
+			setupMock: func() {
+				mockAuthService.EXPECT().WhoAmI(ctx).Return(nil, domain.ErrTokenNotFound)
+			},
+			expectedError: errors.New("not logged in"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setupMock()
+
+			cmd.SetArgs([]string{"whoami"})
+
+			output, err := executeCommand(cmd)
+
+			if tt.expectedError != nil {
+				assert.Error(t, err)
+				assert.Equal(t, tt.expectedError.Error(), err.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedOutput, output)
+			}
+		})
+	}
+}
+
+func TestCLI_CreateCredentialsSecretCmd(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuthService := mocks.NewMockAuthService(ctrl)
+	mockSecretService := mocks.NewMockSecretService(ctrl)
+
+	ctx := context.Background()
+
 	tests := []struct {
 		name           string
 		args           []string
@@ -210,6 +334,62 @@ func TestCLI_CreateCredentialsSecretCmd(t *testing.T) {
 			},
 			expectedError: errors.New("failed to create secret: storage error"),
 		},
+		{
+			name: "login and seed from otpauth uri",
+			args: []string{"create-credentials", "-n", "github", "-p", "pass", "--url", "https://github.com",
+				"--import-uri", "otpauth://totp/GitHub:octocat?secret=jbswy3dpehpk3pxp&issuer=GitHub"},
+			setupMock: func() {
+				mockSecretService.EXPECT().
+					CreateSecret(ctx, gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, secret domain.Secret, r io.Reader) error {
+						var credentials domain.CredentialsSecret
+						assert.NoError(t, json.NewDecoder(r).Decode(&credentials))
+						assert.Equal(t, domain.CredentialsSecret{
+							Login:    "octocat",
+							Password: "pass",
+							URL:      "https://github.com",
+							OTP:      "otpauth://totp/GitHub:octocat?algorithm=SHA1&digits=6&issuer=GitHub&period=30&secret=JBSWY3DPEHPK3PXP",
+						}, credentials)
+						return nil
+					})
+			},
+			expectedOutput: "Successfully stored credentials for 'github'\n",
+		},
+		{
+			name:          "invalid otpauth uri",
+			args:          []string{"create-credentials", "-n", "github", "-p", "pass", "--import-uri", "otpauth://totp/x?secret=!!"},
+			expectedError: errors.New("invalid one-time password seed: secret is not valid base32"),
+		},
+		{
+			name:          "missing login",
+			args:          []string{"create-credentials", "-n", "github", "-p", "pass"},
+			expectedError: errors.New("login is required, set --login or --import-uri with an account name"),
+		},
+		{
+			name: "generated passphrase",
+			args: []string{"create-credentials", "-n", "github", "-l", "octocat", "--generate", "--diceware", "--words", "4", "--separator", " "},
+			setupMock: func() {
+				mockSecretService.EXPECT().
+					CreateSecret(ctx, gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, secret domain.Secret, r io.Reader) error {
+						var credentials domain.CredentialsSecret
+						assert.NoError(t, json.NewDecoder(r).Decode(&credentials))
+						assert.Len(t, strings.Fields(credentials.Password), 4)
+						return nil
+					})
+			},
+			expectedOutput: "Successfully stored credentials for 'github'\nGenerated a password with an estimated entropy of 52 bits\n",
+		},
+		{
+			name:          "password and generate",
+			args:          []string{"create-credentials", "-n", "github", "-l", "octocat", "-p", "pass", "--generate"},
+			expectedError: errors.New("if any flags in the group [password generate] are set none of the others can be"),
+		},
+		{
+			name:          "neither password nor generate",
+			args:          []string{"create-credentials", "-n", "github", "-l", "octocat"},
+			expectedError: errors.New("at least one of the flags in the group [password generate] is required"),
+		},
 	}
 
 	for _, tt := range tests {
@@ -218,6 +398,7 @@ func TestCLI_CreateCredentialsSecretCmd(t *testing.T) {
 				tt.setupMock()
 			}
 
+			cmd := cli.NewCLI(ctx, mockSecretService, mockAuthService)
 			cmd.SetArgs(tt.args)
 			output, err := executeCommand(cmd)
 
@@ -232,6 +413,50 @@ func TestCLI_CreateCredentialsSecretCmd(t *testing.T) {
 	}
 }
 
+func TestCLI_GenerateCmd(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuthService := mocks.NewMockAuthService(ctrl)
+	mockSecretService := mocks.NewMockSecretService(ctrl)
+
+	ctx := context.Background()
+
+	t.Run("password with entropy", func(t *testing.T) {
+		cmd := cli.NewCLI(ctx, mockSecretService, mockAuthService)
+		cmd.SetArgs([]string{"generate", "--length", "12", "--no-symbols", "--exclude-ambiguous"})
+		output, err := executeCommand(cmd)
+		assert.NoError(t, err)
+		assert.Regexp(t, `^[a-km-np-zA-HJ-NP-Z2-9]{12}\nEstimated entropy: 70 bits\n$`, output)
+	})
+
+	t.Run("diceware json", func(t *testing.T) {
+		cmd := cli.NewCLI(ctx, mockSecretService, mockAuthService)
+		cmd.SetArgs([]string{"generate", "--diceware", "--words", "3", "-o", "json"})
+		output, err := executeCommand(cmd)
+		assert.NoError(t, err)
+
+		var doc struct {
+			Kind string `json:"kind"`
+			Data struct {
+				Password string  `json:"password"`
+				Entropy  float64 `json:"entropy_bits"`
+			} `json:"data"`
+		}
+		assert.NoError(t, json.Unmarshal([]byte(output), &doc))
+		assert.Equal(t, "generated_password", doc.Kind)
+		assert.Len(t, strings.SplitN(doc.Data.Password, "-", 3), 3)
+		assert.Equal(t, 38.8, doc.Data.Entropy)
+	})
+
+	t.Run("invalid policy", func(t *testing.T) {
+		cmd := cli.NewCLI(ctx, mockSecretService, mockAuthService)
+		cmd.SetArgs([]string{"generate", "--no-lower", "--no-upper", "--no-digits", "--no-symbols"})
+		_, err := executeCommand(cmd)
+		assert.EqualError(t, err, "invalid password policy: no character classes enabled")
+	})
+}
+
 func TestCLI_CreatePaymentCardSecretCmd(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -251,7 +476,7 @@ func TestCLI_CreatePaymentCardSecretCmd(t *testing.T) {
 	}{
 		{
 			name: "successful payment card creation",
-			args: []string{"create-paymentcard", "-n", "testcard", "-c", "1234567890123456"},
+			args: []string{"create-paymentcard", "-n", "testcard", "-c", "4111 1111 1111 1111"},
 			setupMock: func() {
 				expectedSecret := domain.Secret{
 					Info: domain.SecretInfo{
@@ -259,18 +484,63 @@ func TestCLI_CreatePaymentCardSecretCmd(t *testing.T) {
 						Type: domain.PaymentCardSecretType,
 					},
 				}
-				expectedData, _ := json.Marshal(domain.PaymentCardSecret{
-					Number: "1234567890123456",
-				})
 				mockSecretService.EXPECT().
 					CreateSecret(ctx, expectedSecret, gomock.Any()).
 					DoAndReturn(func(ctx context.Context, secret domain.Secret, r io.Reader) error {
 						data, _ := io.ReadAll(r)
-						assert.Equal(t, expectedData, data)
+						assert.Equal(t, `{"Number":"4111111111111111"}`, string(data))
 						return nil
 					})
 			},
 		},
+		{
+			name: "card with all details",
+			args: []string{"create-paymentcard", "-n", "amex", "-c", "378282246310005", "--holder", "J DOE",
+				"--expiry", "08/29", "--cvv", "1234", "--pin", "0000", "--issuer", "Bank", "--notes", "billing"},
+			setupMock: func() {
+				mockSecretService.EXPECT().
+					CreateSecret(ctx, gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, secret domain.Secret, r io.Reader) error {
+						var card domain.PaymentCardSecret
+						assert.NoError(t, json.NewDecoder(r).Decode(&card))
+						assert.Equal(t, domain.PaymentCardSecret{
+							Number: "378282246310005", Holder: "J DOE", ExpiryMonth: 8, ExpiryYear: 2029,
+							CVV: "1234", PIN: "0000", Issuer: "Bank", Notes: "billing",
+						}, card)
+						return nil
+					})
+			},
+		},
+		{
+			name:          "luhn checksum mismatch",
+			args:          []string{"create-paymentcard", "-n", "bad", "-c", "4111111111111112", "--holder", "", "--expiry", "", "--cvv", "", "--pin", ""},
+			expectedError: errors.New("invalid card number: checksum mismatch"),
+		},
+		{
+			name:          "wrong length for network",
+			args:          []string{"create-paymentcard", "-n", "bad", "-c", "5555555555554444000"},
+			expectedError: errors.New("invalid card number"),
+		},
+		{
+			name:          "mastercard length",
+			args:          []string{"create-paymentcard", "-n", "bad", "-c", "555555555555444"},
+			expectedError: errors.New("invalid card number"),
+		},
+		{
+			name:          "invalid expiry month",
+			args:          []string{"create-paymentcard", "-n", "bad", "-c", "2200000000000004", "--expiry", "13/30"},
+			expectedError: errors.New("invalid card expiry: month must be between 1 and 12"),
+		},
+		{
+			name:          "malformed expiry",
+			args:          []string{"create-paymentcard", "-n", "bad", "-c", "2200000000000004", "--expiry", "2030-01"},
+			expectedError: errors.New("invalid card expiry: '2030-01' must be MM/YY or MM/YYYY"),
+		},
+		{
+			name:          "amex needs four digit cvv",
+			args:          []string{"create-paymentcard", "-n", "bad", "-c", "378282246310005", "--expiry", "", "--cvv", "123"},
+			expectedError: errors.New("invalid card CVV: amex cards have a 4 digit CVV"),
+		},
 	}
 
 	for _, tt := range tests {
@@ -445,28 +715,105 @@ func TestCLI_ListSecretsCmd(t *testing.T) {
 	mockSecretService := mocks.NewMockSecretService(ctrl)
 
 	ctx := context.Background()
-	cmd := cli.NewCLI(ctx, mockSecretService, mockAuthService)
+
+	infos := []domain.SecretInfo{
+		{
+			Name:      "mail",
+			Type:      domain.CredentialsSecretType,
+			Version:   2,
+			Metadata:  "work",
+			CreatedAt: time.Date(2024, 5, 2, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			Name:      "card",
+			Type:      domain.PaymentCardSecretType,
+			Version:   5,
+			CreatedAt: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			Name:      "notes",
+			Type:      domain.TextSecretType,
+			Version:   1,
+			CreatedAt: time.Date(2024, 5, 3, 10, 0, 0, 0, time.UTC),
+		},
+	}
 
 	tests := []struct {
 		name           string
+		args           []string
 		setupMock      func()
 		expectedOutput string
 		expectedError  error
 	}{
 		{
 			name: "successful list with secrets",
+			args: []string{"list"},
+			setupMock: func() {
+				mockSecretService.EXPECT().
+					ListSecretsInfo(ctx).
+					Return(slices.Clone(infos), nil)
+			},
+			expectedOutput: "NAME   TYPE          VERSION  METADATA  CREATED_AT\n" +
+				"card   payment_card  5        -         2024-05-01T10:00:00Z\n" +
+				"mail   credentials   2        work      2024-05-02T10:00:00Z\n" +
+				"notes  text          1        -         2024-05-03T10:00:00Z\n",
+		},
+		{
+			name: "sort by version reversed with selected columns",
+			args: []string{"list", "--sort", "version", "--reverse", "--columns", "version,name"},
+			setupMock: func() {
+				mockSecretService.EXPECT().
+					ListSecretsInfo(ctx).
+					Return(slices.Clone(infos), nil)
+			},
+			expectedOutput: "VERSION  NAME\n" +
+				"5        card\n" +
+				"2        mail\n" +
+				"1        notes\n",
+		},
+		{
+			name: "filter by type sorted by creation time",
+			args: []string{"list", "--sort", "created", "--type", "text,credentials", "--columns", "name,created_at"},
 			setupMock: func() {
 				mockSecretService.EXPECT().
-					ListSecrets(ctx).
-					Return([]string{"secret1", "secret2"}, nil)
+					ListSecretsInfo(ctx).
+					Return(slices.Clone(infos), nil)
 			},
-			expectedOutput: "Stored secrets:\n  - secret1\n  - secret2\n",
+			expectedOutput: "NAME   CREATED_AT\n" +
+				"mail   2024-05-02T10:00:00Z\n" +
+				"notes  2024-05-03T10:00:00Z\n",
+		},
+		{
+			name: "no secrets",
+			args: []string{"list", "--type", "file"},
+			setupMock: func() {
+				mockSecretService.EXPECT().
+					ListSecretsInfo(ctx).
+					Return(slices.Clone(infos), nil)
+			},
+			expectedOutput: "No secrets found\n",
+		},
+		{
+			name:          "unknown sort key",
+			args:          []string{"list", "--sort", "size"},
+			expectedError: errors.New("unknown sort key 'size' (must be name, type, version or created)"),
+		},
+		{
+			name:          "unknown type",
+			args:          []string{"list", "--type", "photo"},
+			expectedError: errors.New("unknown secret type 'photo'"),
+		},
+		{
+			name:          "unknown column",
+			args:          []string{"list", "--columns", "size"},
+			expectedError: errors.New("unknown column 'size'"),
 		},
 		{
 			name: "list error",
+			args: []string{"list", "--columns", "name"},
 			setupMock: func() {
 				mockSecretService.EXPECT().
-					ListSecrets(ctx).
+					ListSecretsInfo(ctx).
 					Return(nil, fmt.Errorf("list error"))
 			},
 			expectedError: errors.New("failed to list secrets"),
@@ -479,7 +826,9 @@ func TestCLI_ListSecretsCmd(t *testing.T) {
 				tt.setupMock()
 			}
 
-			cmd.SetArgs([]string{"list"})
+			// slice flags accumulate values across executions, so every case gets a fresh root
+			cmd := cli.NewCLI(ctx, mockSecretService, mockAuthService)
+			cmd.SetArgs(tt.args)
 			output, err := executeCommand(cmd)
 
 			if tt.expectedError != nil {
@@ -488,13 +837,12 @@ func TestCLI_ListSecretsCmd(t *testing.T) {
 			} else {
 				assert.NoError(t, err)
 				assert.Equal(t, tt.expectedOutput, output)
-				fmt.Println(output)
 			}
 		})
 	}
 }
 
-func TestCLI_GetCredentialsSecretCmd(t *testing.T) {
+func TestCLI_HistoryCmd(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
@@ -504,11 +852,22 @@ func TestCLI_GetCredentialsSecretCmd(t *testing.T) {
 	ctx := context.Background()
 	cmd := cli.NewCLI(ctx, mockSecretService, mockAuthService)
 
-	testCreds := domain.CredentialsSecret{
-		Login:    "testuser",
-		Password: "testpass",
+	versions := []domain.SecretVersion{
+		{
+			Info: domain.SecretInfo{
+				Name: "github", Type: domain.CredentialsSecretType, Version: 1,
+				CreatedAt: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+			},
+			Size: 64,
+		},
+		{
+			Info: domain.SecretInfo{
+				Name: "github", Type: domain.CredentialsSecretType, Version: 2, Metadata: "rotated",
+				CreatedAt: time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC),
+			},
+			Size: 72,
+		},
 	}
-	credsData, _ := json.Marshal(testCreds)
 
 	tests := []struct {
 		name           string
@@ -518,62 +877,64 @@ func TestCLI_GetCredentialsSecretCmd(t *testing.T) {
 		expectedError  error
 	}{
 		{
-			name: "successful get latest credentials",
-			args: []string{"get-credentials", "-n", "testcreds"},
+			name: "list versions",
+			args: []string{"history", "--name", "github"},
 			setupMock: func() {
-				mockSecretService.EXPECT().
-					GetLatestSecret(ctx, "testcreds").
-					Return(&domain.Secret{
-						Info: domain.SecretInfo{
-							Name:    "testcreds",
-							Version: 1,
-						},
-						Data: string(credsData),
-					}, nil)
+				mockSecretService.EXPECT().ListSecretVersions(ctx, "github").Return(versions, nil)
 			},
-			expectedOutput: "Name: testcreds\nVersion: 1\nLogin: testuser\nPassword: testpass\n",
+			expectedOutput: "VERSION  CREATED_AT            SIZE  METADATA\n" +
+				"1        2024-05-01T10:00:00Z  64    -\n" +
+				"2        2024-06-01T10:00:00Z  72    rotated\n",
 		},
 		{
-			name: "successful get specific version",
-			args: []string{"get-credentials", "-n", "testcreds", "-v", "2"},
+			name: "versions as json",
+			args: []string{"history", "--name", "github", "-o", "json"},
 			setupMock: func() {
-				mockSecretService.EXPECT().
-					GetSecretByVersion(ctx, "testcreds", int32(2)).
-					Return(&domain.Secret{
-						Info: domain.SecretInfo{
-							Name:     "testcreds",
-							Version:  2,
-							Metadata: "test metadata",
-						},
-						Data: string(credsData),
-					}, nil)
+				mockSecretService.EXPECT().ListSecretVersions(ctx, "github").Return(versions[:1], nil)
 			},
-			expectedOutput: "Name: testcreds\nVersion: 2\nMetadata: test metadata\nLogin: testuser\nPassword: testpass\n",
+			expectedOutput: `{
+  "schema_version": 1,
+  "kind": "secret_history",
+  "data": [
+    {
+      "name": "github",
+      "type": "credentials",
+      "version": 1,
+      "created_at": "2024-05-01T10:00:00Z",
+      "size": 64
+    }
+  ]
+}
+`,
 		},
 		{
-			name: "secret not found",
-			args: []string{"get-credentials", "-n", "testcreds", "-v", "2"},
+			name: "unknown secret",
+			args: []string{"history", "--name", "gitlab", "-o", "text"},
 			setupMock: func() {
-				mockSecretService.EXPECT().
-					GetSecretByVersion(ctx, "testcreds", int32(2)).
-					Return(nil, fmt.Errorf("not found"))
+				mockSecretService.EXPECT().ListSecretVersions(ctx, "gitlab").
+					Return(nil, fmt.Errorf("client.ListSecretVersions: %w", domain.ErrSecretNotFound))
 			},
-			expectedError: errors.New("failed to retrieve credentials"),
+			expectedError: errors.New("secret 'gitlab' not found"),
+		},
+		{
+			name: "list error",
+			args: []string{"history", "--name", "github"},
+			setupMock: func() {
+				mockSecretService.EXPECT().ListSecretVersions(ctx, "github").Return(nil, domain.ErrServerUnavailable)
+			},
+			expectedError: errors.New("failed to list versions of secret 'github'"),
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if tt.setupMock != nil {
-				tt.setupMock()
-			}
+			tt.setupMock()
 
 			cmd.SetArgs(tt.args)
 			output, err := executeCommand(cmd)
 
 			if tt.expectedError != nil {
-				assert.Error(t, err)
-				assert.Contains(t, err.Error(), tt.expectedError.Error())
+				assert.EqualError(t, err, tt.expectedError.Error())
 			} else {
 				assert.NoError(t, err)
 				assert.Equal(t, tt.expectedOutput, output)
@@ -582,7 +943,7 @@ func TestCLI_GetCredentialsSecretCmd(t *testing.T) {
 	}
 }
 
-func TestCLI_GetPaymentCardSecretCmd(t *testing.T) {
+func TestCLI_DiffCmd(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
@@ -590,12 +951,21 @@ func TestCLI_GetPaymentCardSecretCmd(t *testing.T) {
 	mockSecretService := mocks.NewMockSecretService(ctrl)
 
 	ctx := context.Background()
-	cmd := cli.NewCLI(ctx, mockSecretService, mockAuthService)
 
-	testCard := domain.PaymentCardSecret{
-		Number: "1234567890123456",
+	// expectInfo expects the lookup of a version, 0 resolving to the latest one
+	expectInfo := func(name string, secretType domain.SecretType, version, resolved int32, metadata string) {
+		mockSecretService.EXPECT().GetSecretInfo(ctx, name, version).Return(&domain.SecretInfo{
+			Name: name, Type: secretType, Version: resolved, Metadata: metadata,
+		}, nil)
+	}
+	expectSecret := func(name string, version int32, data string) {
+		mockSecretService.EXPECT().GetSecretByVersion(ctx, name, version).
+			Return(&domain.Secret{Info: domain.SecretInfo{Name: name, Version: version}, Data: data}, nil)
+	}
+	expectStream := func(name string, version int32, content string) {
+		mockSecretService.EXPECT().GetSecretStreamByVersion(ctx, name, version).
+			Return(strings.NewReader(content), &domain.SecretInfo{Name: name, Version: version}, nil)
 	}
-	cardData, _ := json.Marshal(testCard)
 
 	tests := []struct {
 		name           string
@@ -605,30 +975,2468 @@ func TestCLI_GetPaymentCardSecretCmd(t *testing.T) {
 		expectedError  error
 	}{
 		{
-			name: "successful get payment card",
-			args: []string{"get-paymentcard", "-n", "testcard"},
+			name: "credentials are masked",
+			args: []string{"diff", "--name", "github", "--from", "1"},
 			setupMock: func() {
-				mockSecretService.EXPECT().
-					GetLatestSecret(ctx, "testcard").
-					Return(&domain.Secret{
-						Info: domain.SecretInfo{
-							Name:    "testcard",
-							Version: 1,
-						},
-						Data: string(cardData),
-					}, nil)
+				expectInfo("github", domain.CredentialsSecretType, 1, 1, "")
+				expectInfo("github", domain.CredentialsSecretType, 0, 3, "rotated")
+				expectSecret("github", 1, `{"Login":"octocat","Password":"old"}`)
+				expectSecret("github", 3, `{"Login":"octocat","Password":"new"}`)
 			},
-			expectedOutput: "Name: testcard\nVersion: 1\nCard Number: 1234567890123456\n",
+			expectedOutput: "--- github version 1\n+++ github version 3\n" +
+				"+ metadata: rotated\n" +
+				"~ Password: ******** -> ********\n",
 		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
+		{
+			name: "credentials are revealed",
+			args: []string{"diff", "--name", "github", "--from", "1", "--to", "2", "--reveal"},
+			setupMock: func() {
+				expectInfo("github", domain.CredentialsSecretType, 1, 1, "")
+				expectInfo("github", domain.CredentialsSecretType, 2, 2, "")
+				expectSecret("github", 1, `{"Login":"octocat","Password":"old"}`)
+				expectSecret("github", 2, `{"Login":"hubot","Password":"old"}`)
+			},
+			expectedOutput: "--- github version 1\n+++ github version 2\n" +
+				"~ Login: octocat -> hubot\n",
+		},
+		{
+			name: "unchanged payment card",
+			args: []string{"diff", "--name", "visa", "--from", "1", "--to", "2", "--reveal=false"},
+			setupMock: func() {
+				expectInfo("visa", domain.PaymentCardSecretType, 1, 1, "")
+				expectInfo("visa", domain.PaymentCardSecretType, 2, 2, "")
+				expectSecret("visa", 1, `{"Number":"4111111111111111"}`)
+				expectSecret("visa", 2, `{"Number":"4111111111111111"}`)
+			},
+			expectedOutput: "--- visa version 1\n+++ visa version 2\nNo differences\n",
+		},
+		{
+			name: "text line counts without reveal",
+			args: []string{"diff", "--name", "notes", "--from", "1", "--to", "2"},
+			setupMock: func() {
+				expectInfo("notes", domain.TextSecretType, 1, 1, "")
+				expectInfo("notes", domain.TextSecretType, 2, 2, "")
+				expectStream("notes", 1, "a\nb\nc\n")
+				expectStream("notes", 2, "a\nB\nc\nd\n")
+			},
+			expectedOutput: "--- notes version 1\n+++ notes version 2\n" +
+				"~ content: 2 lines added, 1 removed\n" +
+				"Use --reveal to show the changed lines\n",
+		},
+		{
+			name: "text unified diff",
+			args: []string{"diff", "--name", "notes", "--from", "1", "--to", "2", "--reveal"},
+			setupMock: func() {
+				expectInfo("notes", domain.TextSecretType, 1, 1, "")
+				expectInfo("notes", domain.TextSecretType, 2, 2, "")
+				expectStream("notes", 1, "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n")
+				expectStream("notes", 2, "1\n2\n3\n4\nfive\n6\n7\n8\n9\n10\n11\n12\n13\n")
+			},
+			expectedOutput: "--- notes version 1\n+++ notes version 2\n" +
+				"~ content: 2 lines added, 1 removed\n\n" +
+				"--- notes@1\n+++ notes@2\n" +
+				"@@ -2,7 +2,7 @@\n 2\n 3\n 4\n-5\n+five\n 6\n 7\n 8\n" +
+				"@@ -10,3 +10,4 @@\n 10\n 11\n 12\n+13\n",
+		},
+		{
+			name: "texts too large for a line diff",
+			args: []string{"diff", "--name", "notes", "--from", "1", "--to", "2", "--reveal"},
+			setupMock: func() {
+				expectInfo("notes", domain.TextSecretType, 1, 1, "")
+				expectInfo("notes", domain.TextSecretType, 2, 2, "")
+				expectStream("notes", 1, strings.Repeat("a\n", 2100))
+				expectStream("notes", 2, strings.Repeat("b\n", 2100))
+			},
+			expectedOutput: "--- notes version 1\n+++ notes version 2\n" +
+				"~ content: contents differ, too large to compare lines\n",
+		},
+		{
+			name: "file size and checksum",
+			args: []string{"diff", "--name", "doc", "--from", "1", "--to", "2", "-o", "json"},
+			setupMock: func() {
+				expectInfo("doc", domain.FileSecretType, 1, 1, "")
+				expectInfo("doc", domain.FileSecretType, 2, 2, "")
+				expectStream("doc", 1, "hello")
+				expectStream("doc", 2, "hello world")
+			},
+			expectedOutput: `{
+  "schema_version": 1,
+  "kind": "secret_diff",
+  "data": {
+    "name": "doc",
+    "type": "file",
+    "from": 1,
+    "to": 2,
+    "changes": [
+      {
+        "field": "size",
+        "change": "changed",
+        "from": "5",
+        "to": "11"
+      },
+      {
+        "field": "sha256",
+        "change": "changed",
+        "from": "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824",
+        "to": "b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9"
+      }
+    ]
+  }
+}
+`,
+		},
+		{
+			name: "unknown version",
+			args: []string{"diff", "--name", "github", "--from", "7", "-o", "text"},
+			setupMock: func() {
+				expectInfo("github", domain.CredentialsSecretType, 0, 1, "")
+				mockSecretService.EXPECT().GetSecretInfo(ctx, "github", int32(7)).
+					Return(nil, fmt.Errorf("client.GetSecretInfo: %w", domain.ErrSecretNotFound))
+			},
+			expectedError: errors.New("version 7 of secret 'github' not found"),
+		},
+		{
+			name: "unknown secret",
+			args: []string{"diff", "--name", "gitlab", "--from", "1"},
+			setupMock: func() {
+				mockSecretService.EXPECT().GetSecretInfo(ctx, "gitlab", int32(0)).
+					Return(nil, fmt.Errorf("client.GetSecretInfo: %w", domain.ErrSecretNotFound))
+			},
+			expectedError: errors.New("secret 'gitlab' not found"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setupMock()
+
+			cmd := cli.NewCLI(ctx, mockSecretService, mockAuthService)
+			cmd.SetArgs(tt.args)
+			output, err := executeCommand(cmd)
+
+			if tt.expectedError != nil {
+				assert.EqualError(t, err, tt.expectedError.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedOutput, output)
+			}
+		})
+	}
+}
+
+func TestCLI_RestoreCmd(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuthService := mocks.NewMockAuthService(ctrl)
+	mockSecretService := mocks.NewMockSecretService(ctrl)
+
+	ctx := context.Background()
+
+	// expectVersions expects the lookup of the restored version and of the latest version 2
+	expectVersions := func(secretType domain.SecretType, version int32) {
+		mockSecretService.EXPECT().GetSecretInfo(ctx, "s", version).
+			Return(&domain.SecretInfo{Name: "s", Type: secretType, Version: version, Metadata: "old"}, nil)
+		mockSecretService.EXPECT().GetSecretInfo(ctx, "s", int32(0)).
+			Return(&domain.SecretInfo{Name: "s", Type: secretType, Version: 2, Metadata: "new"}, nil)
+	}
+
+	tests := []struct {
+		name           string
+		args           []string
+		setupMock      func()
+		expectedOutput string
+		expectedError  error
+	}{
+		{
+			name: "restore credentials",
+			args: []string{"restore", "--name", "s", "--version", "1"},
+			setupMock: func() {
+				expectVersions(domain.CredentialsSecretType, 1)
+				mockSecretService.EXPECT().GetSecretByVersion(ctx, "s", int32(1)).
+					Return(&domain.Secret{Info: domain.SecretInfo{Name: "s", Version: 1}, Data: `{"Login":"a","Password":"b"}`}, nil)
+				mockSecretService.EXPECT().CreateSecret(ctx, domain.Secret{
+					Info:        domain.SecretInfo{Name: "s", Type: domain.CredentialsSecretType, Metadata: "old"},
+					BaseVersion: 2,
+				}, gomock.Any()).DoAndReturn(func(_ context.Context, _ domain.Secret, content io.Reader) error {
+					data, err := io.ReadAll(content)
+					assert.NoError(t, err)
+					assert.Equal(t, `{"Login":"a","Password":"b"}`, string(data))
+					return nil
+				})
+			},
+			expectedOutput: "Successfully restored version 1 of 's' as the latest version\n",
+		},
+		{
+			name: "file is streamed from download to upload",
+			args: []string{"restore", "--name", "s", "--version", "1"},
+			setupMock: func() {
+				download := strings.NewReader("file content")
+				expectVersions(domain.FileSecretType, 1)
+				mockSecretService.EXPECT().GetSecretStreamByVersion(ctx, "s", int32(1)).
+					Return(download, &domain.SecretInfo{Name: "s", Version: 1}, nil)
+				mockSecretService.EXPECT().CreateSecret(ctx, domain.Secret{
+					Info:        domain.SecretInfo{Name: "s", Type: domain.FileSecretType, Metadata: "old"},
+					BaseVersion: 2,
+				}, download).Return(nil)
+			},
+			expectedOutput: "Successfully restored version 1 of 's' as the latest version\n",
+		},
+		{
+			name: "latest version",
+			args: []string{"restore", "--name", "s", "--version", "2"},
+			setupMock: func() {
+				expectVersions(domain.TextSecretType, 2)
+			},
+			expectedOutput: "Version 2 is already the latest version of 's'\n",
+		},
+		{
+			name: "unknown version",
+			args: []string{"restore", "--name", "s", "--version", "5"},
+			setupMock: func() {
+				mockSecretService.EXPECT().GetSecretInfo(ctx, "s", int32(0)).
+					Return(&domain.SecretInfo{Name: "s", Type: domain.TextSecretType, Version: 2}, nil)
+				mockSecretService.EXPECT().GetSecretInfo(ctx, "s", int32(5)).
+					Return(nil, fmt.Errorf("client.GetSecretInfo: %w", domain.ErrSecretNotFound))
+			},
+			expectedError: errors.New("version 5 of secret 's' not found"),
+		},
+		{
+			name: "version conflict",
+			args: []string{"restore", "--name", "s", "--version", "1", "--on-conflict", "fail"},
+			setupMock: func() {
+				expectVersions(domain.PaymentCardSecretType, 1)
+				mockSecretService.EXPECT().GetSecretByVersion(ctx, "s", int32(1)).
+					Return(&domain.Secret{Info: domain.SecretInfo{Name: "s", Version: 1}, Data: `{"Number":"1"}`}, nil)
+				mockSecretService.EXPECT().CreateSecret(ctx, gomock.Any(), gomock.Any()).
+					Return(&domain.VersionConflictError{Name: "s", BaseVersion: 2, LatestVersion: 3})
+			},
+			expectedError: errors.New("failed to restore version 1 of secret 's': " +
+				"secret 's' was changed on the server: based on version 2, latest version is 3"),
+		},
+		{
+			name: "store error",
+			args: []string{"restore", "--name", "s", "--version", "1"},
+			setupMock: func() {
+				expectVersions(domain.PaymentCardSecretType, 1)
+				mockSecretService.EXPECT().GetSecretByVersion(ctx, "s", int32(1)).
+					Return(&domain.Secret{Info: domain.SecretInfo{Name: "s", Version: 1}, Data: `{"Number":"1"}`}, nil)
+				mockSecretService.EXPECT().CreateSecret(ctx, gomock.Any(), gomock.Any()).
+					Return(errors.New("connection refused"))
+			},
+			expectedError: errors.New("failed to restore version 1 of secret 's'"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setupMock()
+
+			cmd := cli.NewCLI(ctx, mockSecretService, mockAuthService)
+			cmd.SetArgs(tt.args)
+			output, err := executeCommand(cmd)
+
+			if tt.expectedError != nil {
+				assert.EqualError(t, err, tt.expectedError.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedOutput, output)
+			}
+		})
+	}
+}
+
+func TestCLI_UpdateSecretCmds(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuthService := mocks.NewMockAuthService(ctrl)
+	mockSecretService := mocks.NewMockSecretService(ctrl)
+
+	ctx := context.Background()
+
+	newFile := filepath.Join(t.TempDir(), "new.pdf")
+	require.NoError(t, os.WriteFile(newFile, []byte("new pdf"), 0600))
+	stored := &closeRecorder{Reader: strings.NewReader("old pdf")}
+
+	latest := func(name string, secretType domain.SecretType) domain.SecretInfo {
+		return domain.SecretInfo{Name: name, Type: secretType, Version: 3, Metadata: "work"}
+	}
+	expectCreate := func(secret domain.Secret, data string) {
+		mockSecretService.EXPECT().CreateSecret(ctx, secret, gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ domain.Secret, content io.Reader) error {
+				got, err := io.ReadAll(content)
+				assert.NoError(t, err)
+				assert.Equal(t, data, string(got))
+				return nil
+			})
+	}
+
+	tests := []struct {
+		name           string
+		args           []string
+		setupMock      func()
+		expectedOutput string
+		expectedError  error
+	}{
+		{
+			name: "update password only",
+			args: []string{"update-credentials", "--name", "github", "--password", "n3w"},
+			setupMock: func() {
+				mockSecretService.EXPECT().GetLatestSecret(ctx, "github").Return(&domain.Secret{
+					Info: latest("github", domain.CredentialsSecretType),
+					Data: `{"Login":"octocat","Password":"old"}`,
+				}, nil)
+				expectCreate(domain.Secret{
+					Info:        domain.SecretInfo{Name: "github", Type: domain.CredentialsSecretType, Metadata: "work"},
+					BaseVersion: 3,
+				}, `{"Login":"octocat","Password":"n3w"}`)
+			},
+			expectedOutput: "Successfully updated 'github'\n",
+		},
+		{
+			name: "update card number and metadata",
+			args: []string{"update-paymentcard", "--name", "visa", "--number", "5555555555554444", "--metadata", "new card"},
+			setupMock: func() {
+				mockSecretService.EXPECT().GetLatestSecret(ctx, "visa").Return(&domain.Secret{
+					Info: latest("visa", domain.PaymentCardSecretType),
+					Data: `{"Number":"4111111111111111","Holder":"J DOE"}`,
+				}, nil)
+				expectCreate(domain.Secret{
+					Info:        domain.SecretInfo{Name: "visa", Type: domain.PaymentCardSecretType, Metadata: "new card"},
+					BaseVersion: 3,
+				}, `{"Number":"5555555555554444","Holder":"J DOE"}`)
+			},
+			expectedOutput: "Successfully updated 'visa'\n",
+		},
+		{
+			name: "update holder of a card stored before validation",
+			args: []string{"update-paymentcard", "--name", "visa", "--holder", "J DOE"},
+			setupMock: func() {
+				mockSecretService.EXPECT().GetLatestSecret(ctx, "visa").Return(&domain.Secret{
+					Info: latest("visa", domain.PaymentCardSecretType),
+					Data: `{"Number":"1234 5678"}`,
+				}, nil)
+				expectCreate(domain.Secret{
+					Info:        domain.SecretInfo{Name: "visa", Type: domain.PaymentCardSecretType, Metadata: "work"},
+					BaseVersion: 3,
+				}, `{"Number":"1234 5678","Holder":"J DOE"}`)
+			},
+			expectedOutput: "Successfully updated 'visa'\n",
+		},
+		{
+			name: "update file metadata streams stored content",
+			args: []string{"update-file", "--name", "doc", "--metadata", "signed"},
+			setupMock: func() {
+				info := latest("doc", domain.FileSecretType)
+				mockSecretService.EXPECT().GetLatestSecretStream(gomock.Any(), "doc").Return(strings.NewReader("pdf"), &info, nil)
+				expectCreate(domain.Secret{
+					Info:        domain.SecretInfo{Name: "doc", Type: domain.FileSecretType, Metadata: "signed"},
+					BaseVersion: 3,
+				}, "pdf")
+			},
+			expectedOutput: "Successfully updated 'doc'\n",
+		},
+		{
+			name: "update text metadata streams stored content",
+			args: []string{"update-text", "--name", "notes", "--metadata", ""},
+			setupMock: func() {
+				info := latest("notes", domain.TextSecretType)
+				mockSecretService.EXPECT().GetLatestSecretStream(gomock.Any(), "notes").Return(strings.NewReader("hello\n"), &info, nil)
+				expectCreate(domain.Secret{
+					Info:        domain.SecretInfo{Name: "notes", Type: domain.TextSecretType},
+					BaseVersion: 3,
+				}, "hello\n")
+			},
+			expectedOutput: "Successfully updated 'notes'\n",
+		},
+		{
+			name: "update file replaces content and drops the download",
+			args: []string{"update-file", "--name", "doc", "--file", newFile},
+			setupMock: func() {
+				info := latest("doc", domain.FileSecretType)
+				var download context.Context
+				mockSecretService.EXPECT().GetLatestSecretStream(gomock.Any(), "doc").
+					DoAndReturn(func(ctx context.Context, _ string) (io.Reader, *domain.SecretInfo, error) {
+						download = ctx
+						return stored, &info, nil
+					})
+				mockSecretService.EXPECT().CreateSecret(ctx, domain.Secret{
+					Info:        domain.SecretInfo{Name: "doc", Type: domain.FileSecretType, Metadata: "work"},
+					BaseVersion: 3,
+				}, gomock.Any()).DoAndReturn(func(_ context.Context, _ domain.Secret, content io.Reader) error {
+					assert.ErrorIs(t, download.Err(), context.Canceled, "dropped download must be canceled")
+					got, err := io.ReadAll(content)
+					assert.NoError(t, err)
+					assert.Equal(t, "new pdf", string(got))
+					return nil
+				})
+			},
+			expectedOutput: "Successfully updated 'doc'\n",
+		},
+		{
+			name:          "nothing to update",
+			args:          []string{"update-credentials", "--name", "github"},
+			setupMock:     func() {},
+			expectedError: errors.New("nothing to update, specify at least one of --login, --password, --generate, --url, --notes, --import-uri, --metadata"),
+		},
+		{
+			name: "type mismatch",
+			args: []string{"update-credentials", "--name", "visa", "--login", "me"},
+			setupMock: func() {
+				mockSecretService.EXPECT().GetLatestSecret(ctx, "visa").
+					Return(&domain.Secret{Info: latest("visa", domain.PaymentCardSecretType)}, nil)
+			},
+			expectedError: errors.New("secret 'visa' is of type payment_card, not credentials"),
+		},
+		{
+			name: "missing secret",
+			args: []string{"update-file", "--name", "missing", "--file", "x"},
+			setupMock: func() {
+				mockSecretService.EXPECT().GetLatestSecretStream(gomock.Any(), "missing").
+					Return(nil, nil, fmt.Errorf("client.GetLatestSecretStream: %w", domain.ErrSecretNotFound))
+			},
+			expectedError: errors.New("secret 'missing' not found"),
+		},
+		{
+			name: "version conflict",
+			args: []string{"update-credentials", "--name", "github", "--login", "me", "--on-conflict", "fail"},
+			setupMock: func() {
+				mockSecretService.EXPECT().GetLatestSecret(ctx, "github").
+					Return(&domain.Secret{Info: latest("github", domain.CredentialsSecretType), Data: `{"Login":"user"}`}, nil)
+				mockSecretService.EXPECT().CreateSecret(ctx, gomock.Any(), gomock.Any()).
+					Return(&domain.VersionConflictError{Name: "github", BaseVersion: 3, LatestVersion: 4})
+			},
+			expectedError: errors.New("failed to update secret 'github': secret 'github' was changed on the server: " +
+				"based on version 3, latest version is 4"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setupMock()
+
+			cmd := cli.NewCLI(ctx, mockSecretService, mockAuthService)
+			cmd.SetArgs(tt.args)
+			output, err := executeCommand(cmd)
+
+			if tt.expectedError != nil {
+				assert.EqualError(t, err, tt.expectedError.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedOutput, output)
+			}
+		})
+	}
+	assert.True(t, stored.closed)
+}
+
+// closeRecorder is a reader that records whether it was closed.
+type closeRecorder struct {
+	io.Reader
+	closed bool
+}
+
+func (r *closeRecorder) Close() error {
+	r.closed = true
+	return nil
+}
+
+func TestCLI_TOTPCmd(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuthService := mocks.NewMockAuthService(ctrl)
+	mockSecretService := mocks.NewMockSecretService(ctrl)
+
+	ctx := context.Background()
+	cmd := cli.NewCLI(ctx, mockSecretService, mockAuthService)
+
+	credentials := func(otp string) *domain.Secret {
+		data, _ := json.Marshal(domain.CredentialsSecret{Login: "octocat", Password: "pass", OTP: otp})
+		return &domain.Secret{
+			Info: domain.SecretInfo{Name: "github", Type: domain.CredentialsSecretType, Version: 4, Metadata: "2fa"},
+			Data: string(data),
+		}
+	}
+	// RFC 4226 test secret "12345678901234567890"
+	const hotpSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+	t.Run("totp code", func(t *testing.T) {
+		mockSecretService.EXPECT().GetLatestSecret(ctx, "github").
+			Return(credentials("otpauth://totp/GitHub:octocat?secret=JBSWY3DPEHPK3PXP"), nil)
+
+		cmd.SetArgs([]string{"totp", "--name", "github"})
+		output, err := executeCommand(cmd)
+		assert.NoError(t, err)
+		assert.Regexp(t, `^Code: \d{6}\nValid for: \d+s\n$`, output)
+	})
+
+	t.Run("hotp code advances the counter", func(t *testing.T) {
+		mockSecretService.EXPECT().GetLatestSecret(ctx, "github").
+			Return(credentials("otpauth://hotp/octocat?secret="+hotpSecret+"&counter=1"), nil)
+		mockSecretService.EXPECT().CreateSecret(ctx, domain.Secret{
+			Info:        domain.SecretInfo{Name: "github", Type: domain.CredentialsSecretType, Metadata: "2fa"},
+			BaseVersion: 4,
+		}, gomock.Any()).DoAndReturn(func(_ context.Context, _ domain.Secret, r io.Reader) error {
+			var stored domain.CredentialsSecret
+			assert.NoError(t, json.NewDecoder(r).Decode(&stored))
+			assert.Equal(t, "otpauth://hotp/octocat?algorithm=SHA1&counter=2&digits=6&secret="+hotpSecret, stored.OTP)
+			return nil
+		})
+
+		cmd.SetArgs([]string{"totp", "--name", "github", "-o", "json"})
+		output, err := executeCommand(cmd)
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"schema_version":1,"kind":"otp_code","data":{"name":"github","type":"hotp","code":"287082","counter":1}}`, output)
+	})
+
+	t.Run("credentials without seed", func(t *testing.T) {
+		mockSecretService.EXPECT().GetLatestSecret(ctx, "github").Return(credentials(""), nil)
+
+		cmd.SetArgs([]string{"totp", "--name", "github", "-o", "text"})
+		_, err := executeCommand(cmd)
+		assert.EqualError(t, err, "credentials 'github' have no one-time password seed")
+	})
+}
+
+func TestCLI_AuditCmd(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuthService := mocks.NewMockAuthService(ctrl)
+	mockSecretService := mocks.NewMockSecretService(ctrl)
+
+	ctx := context.Background()
+	now := time.Now()
+
+	credentials := func(name, password string) *domain.Secret {
+		data, _ := json.Marshal(domain.CredentialsSecret{Login: "user", Password: password})
+		return &domain.Secret{
+			Info: domain.SecretInfo{Name: name, Type: domain.CredentialsSecretType, Version: 1, CreatedAt: now},
+			Data: string(data),
+		}
+	}
+	const strong = "x7#Kq9!vLm2@Zr5$Wt8&"
+
+	t.Run("problems fail the command", func(t *testing.T) {
+		github, gitlab := credentials("github", strong), credentials("gitlab", strong)
+		mockSecretService.EXPECT().ListSecretsInfo(ctx).Return([]domain.SecretInfo{
+			github.Info,
+			gitlab.Info,
+			{Name: "notes", Type: domain.TextSecretType, Version: 3, CreatedAt: now.AddDate(0, 0, -40)},
+		}, nil)
+		mockSecretService.EXPECT().GetLatestSecret(ctx, "github").Return(github, nil)
+		mockSecretService.EXPECT().GetLatestSecret(ctx, "gitlab").Return(gitlab, nil)
+
+		cmd := cli.NewCLI(ctx, mockSecretService, mockAuthService)
+		cmd.SetArgs([]string{"audit", "--max-age-days", "30", "-o", "json"})
+		output, err := executeCommand(cmd)
+		assert.EqualError(t, err, "audit found 3 problem(s) in 3 secret(s)")
+		assert.JSONEq(t, `{"schema_version":1,"kind":"audit_report","data":[
+			{"issue":"reused_password","secret":"github","detail":"password is also used by gitlab"},
+			{"issue":"reused_password","secret":"gitlab","detail":"password is also used by github"},
+			{"issue":"old_secret","secret":"notes","detail":"latest version is 40 days old"}
+		]}`, strings.SplitN(output, "\nError:", 2)[0])
+		assert.NotContains(t, output, strong)
+	})
+
+	t.Run("no problems", func(t *testing.T) {
+		github := credentials("github", strong)
+		mockSecretService.EXPECT().ListSecretsInfo(ctx).Return([]domain.SecretInfo{github.Info}, nil)
+		mockSecretService.EXPECT().GetLatestSecret(ctx, "github").Return(github, nil)
+
+		cmd := cli.NewCLI(ctx, mockSecretService, mockAuthService)
+		cmd.SetArgs([]string{"audit"})
+		output, err := executeCommand(cmd)
+		assert.NoError(t, err)
+		assert.Equal(t, "No problems found\n", output)
+	})
+
+	t.Run("weak password", func(t *testing.T) {
+		mail := credentials("mail", "password")
+		mockSecretService.EXPECT().ListSecretsInfo(ctx).Return([]domain.SecretInfo{mail.Info}, nil)
+		mockSecretService.EXPECT().GetLatestSecret(ctx, "mail").Return(mail, nil)
+
+		cmd := cli.NewCLI(ctx, mockSecretService, mockAuthService)
+		cmd.SetArgs([]string{"audit", "-o", "table"})
+		output, err := executeCommand(cmd)
+		assert.EqualError(t, err, "audit found 1 problem(s) in 1 secret(s)")
+		assert.Contains(t, output, "weak_password  mail    estimated entropy is 33 bits, below 60")
+	})
+}
+
+func TestCLI_AuditBreachesCmd(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuthService := mocks.NewMockAuthService(ctrl)
+	mockSecretService := mocks.NewMockSecretService(ctrl)
+	mockCorpus := mocks.NewMockBreachCorpus(ctrl)
+
+	ctx := context.Background()
+
+	var openedPath string
+	breaches := cli.WithBreachCorpus("/data/pwned.txt", func(path string) (domain.BreachCorpus, error) {
+		openedPath = path
+		return mockCorpus, nil
+	})
+
+	credentials := func(name, password string) *domain.Secret {
+		data, _ := json.Marshal(domain.CredentialsSecret{Login: "user", Password: password})
+		return &domain.Secret{
+			Info: domain.SecretInfo{Name: name, Type: domain.CredentialsSecretType, Version: 1, CreatedAt: time.Now()},
+			Data: string(data),
+		}
+	}
+
+	t.Run("breached password fails the command", func(t *testing.T) {
+		github, mail := credentials("github", "hunter2"), credentials("mail", "x7#Kq9!vLm2@Zr5$Wt8&")
+		mockSecretService.EXPECT().ListSecretsInfo(ctx).Return([]domain.SecretInfo{github.Info, mail.Info}, nil)
+		mockSecretService.EXPECT().GetLatestSecret(ctx, "github").Return(github, nil)
+		mockSecretService.EXPECT().GetLatestSecret(ctx, "mail").Return(mail, nil)
+		mockCorpus.EXPECT().Count("hunter2").Return(int64(17043), nil)
+		mockCorpus.EXPECT().Count("x7#Kq9!vLm2@Zr5$Wt8&").Return(int64(0), nil)
+		mockCorpus.EXPECT().Close().Return(nil)
+
+		cmd := cli.NewCLI(ctx, mockSecretService, mockAuthService, breaches)
+		cmd.SetArgs([]string{"audit", "breaches", "-o", "json"})
+		output, err := executeCommand(cmd)
+		assert.EqualError(t, err, "audit found 1 problem(s) in 2 secret(s)")
+		assert.Equal(t, "/data/pwned.txt", openedPath)
+		assert.JSONEq(t, `{"schema_version":1,"kind":"audit_report","data":[
+			{"issue":"breached_password","secret":"github","detail":"password was seen 17043 times in data breaches"}
+		]}`, strings.SplitN(output, "\nError:", 2)[0])
+	})
+
+	t.Run("hash file flag", func(t *testing.T) {
+		mockSecretService.EXPECT().ListSecretsInfo(ctx).Return(nil, nil)
+		mockCorpus.EXPECT().Close().Return(nil)
+
+		cmd := cli.NewCLI(ctx, mockSecretService, mockAuthService, breaches)
+		cmd.SetArgs([]string{"audit", "breaches", "--hash-file", "other.txt"})
+		output, err := executeCommand(cmd)
+		assert.NoError(t, err)
+		assert.Equal(t, "other.txt", openedPath)
+		assert.Equal(t, "No problems found\n", output)
+	})
+
+	t.Run("no hash file", func(t *testing.T) {
+		cmd := cli.NewCLI(ctx, mockSecretService, mockAuthService,
+			cli.WithBreachCorpus("", func(string) (domain.BreachCorpus, error) { return mockCorpus, nil }))
+		cmd.SetArgs([]string{"audit", "breaches"})
+		_, err := executeCommand(cmd)
+		assert.EqualError(t, err, "no hash file, set --hash-file or the breach_file setting")
+	})
+
+	t.Run("create credentials warns about breached password", func(t *testing.T) {
+		mockCorpus.EXPECT().Count("hunter2").Return(int64(17043), nil)
+		mockCorpus.EXPECT().Close().Return(nil)
+		mockSecretService.EXPECT().CreateSecret(ctx, gomock.Any(), gomock.Any()).Return(nil)
+
+		cmd := cli.NewCLI(ctx, mockSecretService, mockAuthService, breaches)
+		cmd.SetArgs([]string{"create-credentials", "-n", "github", "-l", "user", "-p", "hunter2", "--check-breaches"})
+		output, err := executeCommand(cmd)
+		assert.NoError(t, err)
+		assert.Equal(t, "Successfully stored credentials for 'github'\n"+
+			"Warning: this password was seen 17043 times in data breaches\n", output)
+	})
+}
+
+func TestCLI_GetCredentialsSecretCmd(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuthService := mocks.NewMockAuthService(ctrl)
+	mockSecretService := mocks.NewMockSecretService(ctrl)
+
+	ctx := context.Background()
+	cmd := cli.NewCLI(ctx, mockSecretService, mockAuthService)
+
+	testCreds := domain.CredentialsSecret{
+		Login:    "testuser",
+		Password: "testpass",
+	}
+	credsData, _ := json.Marshal(testCreds)
+
+	tests := []struct {
+		name           string
+		args           []string
+		setupMock      func()
+		expectedOutput string
+		expectedError  error
+	}{
+		{
+			name: "successful get latest credentials",
+			args: []string{"get-credentials", "-n", "testcreds"},
+			setupMock: func() {
+				mockSecretService.EXPECT().
+					GetLatestSecret(ctx, "testcreds").
+					Return(&domain.Secret{
+						Info: domain.SecretInfo{
+							Name:    "testcreds",
+							Version: 1,
+						},
+						Data: string(credsData),
+					}, nil)
+			},
+			expectedOutput: "Name: testcreds\nVersion: 1\nLogin: testuser\nPassword: testpass\n",
+		},
+		{
+			name: "successful get specific version",
+			args: []string{"get-credentials", "-n", "testcreds", "-v", "2"},
+			setupMock: func() {
+				mockSecretService.EXPECT().
+					GetSecretByVersion(ctx, "testcreds", int32(2)).
+					Return(&domain.Secret{
+						Info: domain.SecretInfo{
+							Name:     "testcreds",
+							Version:  2,
+							Metadata: "test metadata",
+						},
+						Data: string(credsData),
+					}, nil)
+			},
+			expectedOutput: "Name: testcreds\nVersion: 2\nMetadata: test metadata\nLogin: testuser\nPassword: testpass\n",
+		},
+		{
+			name: "secret not found",
+			args: []string{"get-credentials", "-n", "testcreds", "-v", "2"},
+			setupMock: func() {
+				mockSecretService.EXPECT().
+					GetSecretByVersion(ctx, "testcreds", int32(2)).
+					Return(nil, fmt.Errorf("not found"))
+			},
+			expectedError: errors.New("failed to retrieve credentials"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.setupMock != nil {
+				tt.setupMock()
+			}
+
+			cmd.SetArgs(tt.args)
+			output, err := executeCommand(cmd)
+
+			if tt.expectedError != nil {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedOutput, output)
+			}
+		})
+	}
+}
+
+func TestCLI_GetPaymentCardSecretCmd(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuthService := mocks.NewMockAuthService(ctrl)
+	mockSecretService := mocks.NewMockSecretService(ctrl)
+
+	ctx := context.Background()
+	cmd := cli.NewCLI(ctx, mockSecretService, mockAuthService)
+
+	testCard := domain.PaymentCardSecret{
+		Number: "1234567890123456",
+	}
+	cardData, _ := json.Marshal(testCard)
+
+	tests := []struct {
+		name           string
+		args           []string
+		setupMock      func()
+		expectedOutput string
+		expectedError  error
+	}{
+		{
+			name: "successful get payment card",
+			args: []string{"get-paymentcard", "-n", "testcard"},
+			setupMock: func() {
+				mockSecretService.EXPECT().
+					GetLatestSecret(ctx, "testcard").
+					Return(&domain.Secret{
+						Info: domain.SecretInfo{
+							Name:    "testcard",
+							Version: 1,
+						},
+						Data: string(cardData),
+					}, nil)
+			},
+			expectedOutput: "Name: testcard\nVersion: 1\nCard Number: ************3456\nNetwork: unknown\n",
+		},
+		{
+			name: "card details are masked",
+			args: []string{"get-paymentcard", "-n", "visa"},
+			setupMock: func() {
+				mockSecretService.EXPECT().
+					GetLatestSecret(ctx, "visa").
+					Return(&domain.Secret{
+						Info: domain.SecretInfo{Name: "visa", Version: 2},
+						Data: `{"Number":"4111111111111111","Holder":"J DOE","ExpiryMonth":8,"ExpiryYear":2029,"CVV":"123","PIN":"0000"}`,
+					}, nil)
+			},
+			expectedOutput: "Name: visa\nVersion: 2\nCard Number: ************1111\nNetwork: visa\n" +
+				"Holder: J DOE\nExpiry: 08/2029\nCVV: ********\nPIN: ********\n",
+		},
+		{
+			name: "card details are revealed",
+			args: []string{"get-paymentcard", "-n", "visa", "--reveal"},
+			setupMock: func() {
+				mockSecretService.EXPECT().
+					GetLatestSecret(ctx, "visa").
+					Return(&domain.Secret{
+						Info: domain.SecretInfo{Name: "visa", Version: 2},
+						Data: `{"Number":"4111111111111111","CVV":"123"}`,
+					}, nil)
+			},
+			expectedOutput: "Name: visa\nVersion: 2\nCard Number: 4111111111111111\nNetwork: visa\nCVV: 123\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.setupMock != nil {
+				tt.setupMock()
+			}
+
+			cmd.SetArgs(tt.args)
+			output, err := executeCommand(cmd)
+
+			if tt.expectedError != nil {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedOutput, output)
+			}
+		})
+	}
+}
+
+func TestCLI_GetTextSecretCmd(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuthService := mocks.NewMockAuthService(ctrl)
+	mockSecretService := mocks.NewMockSecretService(ctrl)
+
+	ctx := context.Background()
+	cmd := cli.NewCLI(ctx, mockSecretService, mockAuthService)
+
+	tests := []struct {
+		name           string
+		args           []string
+		setupMock      func()
+		expectedOutput string
+		expectedError  error
+	}{
+		{
+			name: "successful get text",
+			args: []string{"get-text", "-n", "testtext"},
+			setupMock: func() {
+				mockSecretService.EXPECT().
+					GetLatestSecretStream(ctx, "testtext").
+					Return(
+						io.NopCloser(strings.NewReader("text content")),
+						&domain.SecretInfo{
+							Name:    "testtext",
+							Version: 1,
+						},
+						nil,
+					)
+			},
+			expectedOutput: "Name: testtext\nVersion: 1\n\nContent:\ntext content",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.setupMock != nil {
+				tt.setupMock()
+			}
+
+			cmd.SetArgs(tt.args)
+			output, err := executeCommand(cmd)
+
+			if tt.expectedError != nil {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedOutput, output)
+			}
+		})
+	}
+}
+
+func TestCLI_GetFileSecretCmd(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuthService := mocks.NewMockAuthService(ctrl)
+	mockSecretService := mocks.NewMockSecretService(ctrl)
+
+	ctx := context.Background()
+	cmd := cli.NewCLI(ctx, mockSecretService, mockAuthService)
+
+	// Clean up test files after
+	defer os.Remove("testfile")
+
+	tests := []struct {
+		name           string
+		args           []string
+		setupMock      func()
+		expectedOutput string
+		expectedError  error
+	}{
+		{
+			name: "successful get file",
+			args: []string{"get-file-secret", "-n", "testfile"},
+			setupMock: func() {
+				mockSecretService.EXPECT().
+					GetLatestSecretStream(ctx, "testfile").
+					Return(
+						io.NopCloser(strings.NewReader("file content")),
+						&domain.SecretInfo{
+							Name:    "testfile",
+							Version: 1,
+						},
+						nil,
+					)
+			},
+			expectedOutput: "Successfully downloaded 'testfile' version 1\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.setupMock != nil {
+				tt.setupMock()
+			}
+
+			cmd.SetArgs(tt.args)
+			output, err := executeCommand(cmd)
+
+			if tt.expectedError != nil {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedOutput, output)
+				if tt.name == "successful get file" {
+					content, _ := os.ReadFile("testfile")
+					assert.Equal(t, "file content", string(content))
+				}
+			}
+		})
+	}
+}
+
+func TestCLI_CopyFlag(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuthService := mocks.NewMockAuthService(ctrl)
+	mockSecretService := mocks.NewMockSecretService(ctrl)
+	mockClipboard := mocks.NewMockClipboard(ctrl)
+
+	ctx := context.Background()
+
+	var openedBackend string
+	clipboard := cli.WithClipboard("osc52", time.Millisecond, func(backend string) (domain.Clipboard, error) {
+		openedBackend = backend
+		return mockClipboard, nil
+	})
+
+	credentialsData, _ := json.Marshal(domain.CredentialsSecret{Login: "user", Password: "hunter2"})
+	credentials := &domain.Secret{
+		Info: domain.SecretInfo{Name: "github", Type: domain.CredentialsSecretType, Version: 1},
+		Data: string(credentialsData),
+	}
+	cardData, _ := json.Marshal(domain.PaymentCardSecret{Number: "4111111111111111", CVV: "123"})
+	card := &domain.Secret{
+		Info: domain.SecretInfo{Name: "visa", Type: domain.PaymentCardSecretType, Version: 1},
+		Data: string(cardData),
+	}
+
+	t.Run("copies the password and clears it", func(t *testing.T) {
+		mockSecretService.EXPECT().GetLatestSecret(ctx, "github").Return(credentials, nil)
+		gomock.InOrder(
+			mockClipboard.EXPECT().Copy("hunter2").Return(nil),
+			mockClipboard.EXPECT().Clear("hunter2").Return(true, nil),
+		)
+
+		cmd := cli.NewCLI(ctx, mockSecretService, mockAuthService, clipboard)
+		cmd.SetArgs([]string{"get-credentials", "-n", "github", "--copy"})
+		output, err := executeCommand(cmd)
+		assert.NoError(t, err)
+		assert.Equal(t, "osc52", openedBackend)
+		assert.Equal(t, "Copied password of 'github' to the clipboard, clearing in 1ms\nClipboard cleared\n", output)
+	})
+
+	t.Run("field, backend and no clearing", func(t *testing.T) {
+		mockSecretService.EXPECT().GetLatestSecret(ctx, "github").Return(credentials, nil)
+		mockClipboard.EXPECT().Copy("user").Return(nil)
+
+		cmd := cli.NewCLI(ctx, mockSecretService, mockAuthService, clipboard)
+		cmd.SetArgs([]string{"get-credentials", "-n", "github", "--copy", "--field", "login",
+			"--clipboard", "xclip", "--clear-after", "0"})
+		output, err := executeCommand(cmd)
+		assert.NoError(t, err)
+		assert.Equal(t, "xclip", openedBackend)
+		assert.Equal(t, "Copied login of 'github' to the clipboard\n", output)
+	})
+
+	t.Run("keeps content copied since", func(t *testing.T) {
+		mockSecretService.EXPECT().GetLatestSecret(ctx, "github").Return(credentials, nil)
+		mockClipboard.EXPECT().Copy("hunter2").Return(nil)
+		mockClipboard.EXPECT().Clear("hunter2").Return(false, nil)
+
+		cmd := cli.NewCLI(ctx, mockSecretService, mockAuthService, clipboard)
+		cmd.SetArgs([]string{"get-credentials", "-n", "github", "--copy"})
+		output, err := executeCommand(cmd)
+		assert.NoError(t, err)
+		assert.Equal(t, "Copied password of 'github' to the clipboard, clearing in 1ms\n"+
+			"Clipboard not cleared, it was changed since\n", output)
+	})
+
+	t.Run("copies masked card fields unmasked", func(t *testing.T) {
+		mockSecretService.EXPECT().GetLatestSecret(ctx, "visa").Return(card, nil)
+		mockClipboard.EXPECT().Copy("123").Return(nil)
+		mockClipboard.EXPECT().Clear("123").Return(true, nil)
+
+		cmd := cli.NewCLI(ctx, mockSecretService, mockAuthService, clipboard)
+		cmd.SetArgs([]string{"get-paymentcard", "-n", "visa", "--copy", "--field", "cvv", "-o", "json"})
+		output, err := executeCommand(cmd)
+		assert.NoError(t, err)
+		assert.NotContains(t, output, "4111")
+		assert.Equal(t, "Copied cvv of 'visa' to the clipboard, clearing in 1ms\nClipboard cleared\n", output)
+	})
+
+	t.Run("unknown field", func(t *testing.T) {
+		mockSecretService.EXPECT().GetLatestSecret(ctx, "github").Return(credentials, nil)
+
+		cmd := cli.NewCLI(ctx, mockSecretService, mockAuthService, clipboard)
+		cmd.SetArgs([]string{"get-credentials", "-n", "github", "--copy", "--field", "pin"})
+		_, err := executeCommand(cmd)
+		assert.EqualError(t, err, "unknown field 'pin' (must be one of login, password)")
+	})
+
+	t.Run("copies text content", func(t *testing.T) {
+		mockSecretService.EXPECT().GetLatestSecretStream(ctx, "note").
+			Return(io.NopCloser(strings.NewReader("text content")), &domain.SecretInfo{Name: "note", Version: 1}, nil)
+		mockClipboard.EXPECT().Copy("text content").Return(nil)
+		mockClipboard.EXPECT().Clear("text content").Return(true, nil)
+
+		cmd := cli.NewCLI(ctx, mockSecretService, mockAuthService, clipboard)
+		cmd.SetArgs([]string{"get-text", "-n", "note", "--copy"})
+		output, err := executeCommand(cmd)
+		assert.NoError(t, err)
+		assert.Equal(t, "Copied content of 'note' to the clipboard, clearing in 1ms\nClipboard cleared\n", output)
+	})
+
+	t.Run("binary file is not copied", func(t *testing.T) {
+		mockSecretService.EXPECT().GetLatestSecretStream(ctx, "image.png").
+			Return(io.NopCloser(bytes.NewReader([]byte{0x89, 'P', 'N', 'G', 0xff})), &domain.SecretInfo{Name: "image.png", Version: 1}, nil)
+
+		cmd := cli.NewCLI(ctx, mockSecretService, mockAuthService, clipboard)
+		cmd.SetArgs([]string{"get-file-secret", "-n", "image.png", "--copy"})
+		_, err := executeCommand(cmd)
+		assert.EqualError(t, err, "'image.png' is not text and cannot be copied to the clipboard")
+		assert.NoFileExists(t, "image.png")
+	})
+}
+
+func TestCLI_SSHKeyCmds(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuthService := mocks.NewMockAuthService(ctrl)
+	mockSecretService := mocks.NewMockSecretService(ctrl)
+
+	ctx := context.Background()
+
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	publicKey, err := ssh.NewPublicKey(privateKey.Public())
+	require.NoError(t, err)
+	authorizedKey := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(publicKey)))
+	fingerprint := ssh.FingerprintSHA256(publicKey)
+
+	block, err := ssh.MarshalPrivateKeyWithPassphrase(privateKey, "", []byte("secret"))
+	require.NoError(t, err)
+	pemKey := string(pem.EncodeToMemory(block))
+
+	keyFile := filepath.Join(t.TempDir(), "id_ed25519")
+	require.NoError(t, os.WriteFile(keyFile, []byte(pemKey), 0600))
+	invalidFile := filepath.Join(t.TempDir(), "invalid")
+	require.NoError(t, os.WriteFile(invalidFile, []byte("not a key"), 0600))
+
+	keyData, _ := json.Marshal(domain.SSHKeySecret{PrivateKey: pemKey, Passphrase: "secret"})
+	keySecret := &domain.Secret{
+		Info: domain.SecretInfo{Name: "deploy", Type: domain.SSHKeySecretType, Version: 1},
+		Data: string(keyData),
+	}
+
+	tests := []struct {
+		name           string
+		args           []string
+		setupMock      func()
+		expectedOutput string
+		expectedError  string
+	}{
+		{
+			name: "create with public key metadata",
+			args: []string{"create-sshkey", "-n", "deploy", "-f", keyFile, "--passphrase", "secret", "--comment", "ci"},
+			setupMock: func() {
+				mockSecretService.EXPECT().CreateSecret(ctx, domain.Secret{
+					Info: domain.SecretInfo{
+						Name:     "deploy",
+						Type:     domain.SSHKeySecretType,
+						Metadata: fingerprint + " " + authorizedKey + " ci",
+					},
+				}, gomock.Any()).DoAndReturn(func(_ context.Context, _ domain.Secret, r io.Reader) error {
+					var stored domain.SSHKeySecret
+					assert.NoError(t, json.NewDecoder(r).Decode(&stored))
+					assert.Equal(t, domain.SSHKeySecret{PrivateKey: pemKey, Passphrase: "secret"}, stored)
+					return nil
+				})
+			},
+			expectedOutput: "Successfully stored SSH key 'deploy'\n",
+		},
+		{
+			name:          "create with wrong passphrase",
+			args:          []string{"create-sshkey", "-n", "deploy", "-f", keyFile, "--passphrase", "wrong"},
+			expectedError: "missing or incorrect SSH key passphrase: the passphrase does not match",
+		},
+		{
+			name:          "create with invalid key",
+			args:          []string{"create-sshkey", "-n", "deploy", "-f", invalidFile},
+			expectedError: "invalid SSH key: ssh: no key found",
+		},
+		{
+			name: "get shows public key",
+			args: []string{"get-sshkey", "-n", "deploy"},
+			setupMock: func() {
+				mockSecretService.EXPECT().GetLatestSecret(ctx, "deploy").Return(keySecret, nil)
+			},
+			expectedOutput: "Name: deploy\nVersion: 1\nAlgorithm: ssh-ed25519\n" +
+				"Public key: " + authorizedKey + "\nFingerprint: " + fingerprint + "\n",
+		},
+		{
+			name: "get reveals private key",
+			args: []string{"get-sshkey", "-n", "deploy", "--reveal"},
+			setupMock: func() {
+				mockSecretService.EXPECT().GetLatestSecret(ctx, "deploy").Return(keySecret, nil)
+			},
+			expectedOutput: "Name: deploy\nVersion: 1\nAlgorithm: ssh-ed25519\n" +
+				"Public key: " + authorizedKey + "\nFingerprint: " + fingerprint + "\n" +
+				"Passphrase: secret\nPrivate key:\n" + pemKey,
+		},
+		{
+			name: "get other secret type",
+			args: []string{"get-sshkey", "-n", "github"},
+			setupMock: func() {
+				mockSecretService.EXPECT().GetLatestSecret(ctx, "github").Return(&domain.Secret{
+					Info: domain.SecretInfo{Name: "github", Type: domain.CredentialsSecretType, Version: 1},
+				}, nil)
+			},
+			expectedError: "secret 'github' is of type credentials, not ssh_key",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.setupMock != nil {
+				tt.setupMock()
+			}
+
+			cmd := cli.NewCLI(ctx, mockSecretService, mockAuthService)
+			cmd.SetArgs(tt.args)
+			output, err := executeCommand(cmd)
+
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedOutput, output)
+			}
+		})
+	}
+}
+
+func TestCLI_SSHAgentCmd(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuthService := mocks.NewMockAuthService(ctrl)
+	mockSecretService := mocks.NewMockSecretService(ctrl)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	block, err := ssh.MarshalPrivateKey(privateKey, "")
+	require.NoError(t, err)
+	keyData, _ := json.Marshal(domain.SSHKeySecret{PrivateKey: string(pem.EncodeToMemory(block))})
+
+	mockSecretService.EXPECT().GetLatestSecret(ctx, "deploy").Return(&domain.Secret{
+		Info: domain.SecretInfo{Name: "deploy", Type: domain.SSHKeySecretType, Version: 1},
+		Data: string(keyData),
+	}, nil)
+
+	// Unix socket paths are limited in length, so the socket is not placed in t.TempDir
+	dir, err := os.MkdirTemp("", "gk-agent-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	socket := filepath.Join(dir, "agent.sock")
+
+	var output bytes.Buffer
+	cmd := cli.NewCLI(ctx, mockSecretService, mockAuthService)
+	cmd.SetOut(&output)
+	cmd.SetErr(io.Discard)
+	cmd.SetArgs([]string{"ssh-agent", "-n", "deploy", "-s", socket})
+
+	done := make(chan error, 1)
+	go func() { done <- cmd.Execute() }()
+
+	var conn net.Conn
+	require.Eventually(t, func() bool {
+		conn, err = net.Dial("unix", socket)
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+	defer conn.Close()
+
+	info, err := os.Stat(socket)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	client := agent.NewClient(conn)
+	keys, err := client.List()
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	assert.Equal(t, "deploy", keys[0].Comment)
+
+	publicKey, err := ssh.NewPublicKey(privateKey.Public())
+	require.NoError(t, err)
+	signature, err := client.Sign(publicKey, []byte("challenge"))
+	require.NoError(t, err)
+	assert.NoError(t, publicKey.Verify([]byte("challenge"), signature))
+
+	cancel()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("agent did not stop")
+	}
+	assert.Equal(t, "SSH_AUTH_SOCK='"+socket+"'; export SSH_AUTH_SOCK;\n", output.String())
+	assert.NoFileExists(t, socket)
+}
+
+func TestCLI_DeleteSecretCmd(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuthService := mocks.NewMockAuthService(ctrl)
+	mockSecretService := mocks.NewMockSecretService(ctrl)
+
+	ctx := context.Background()
+	cmd := cli.NewCLI(ctx, mockSecretService, mockAuthService)
+
+	// Mock stdin for confirmation
+	oldStdin := os.Stdin
+	defer func() { os.Stdin = oldStdin }()
+
+	tests := []struct {
+		name           string
+		args           []string
+		input          string
+		setupMock      func()
+		expectedOutput string
+		expectedError  error
+	}{
+		{
+			name:  "successful delete with confirmation",
+			args:  []string{"delete", "-n", "testsecret"},
+			input: "y\n",
+			setupMock: func() {
+				mockSecretService.EXPECT().
+					DeleteSecret(ctx, "testsecret").
+					Return(nil)
+			},
+			expectedOutput: "Are you sure you want to delete 'testsecret'? (y/n): Successfully deleted 'testsecret'\n",
+		},
+		{
+			name:           "delete cancelled",
+			args:           []string{"delete", "-n", "testsecret"},
+			input:          "n\n",
+			expectedOutput: "Are you sure you want to delete 'testsecret'? (y/n): Deletion cancelled\n",
+		},
+		{
+			name:  "delete error",
+			args:  []string{"delete", "-n", "testsecret"},
+			input: "y\n",
+			setupMock: func() {
+				mockSecretService.EXPECT().
+					DeleteSecret(ctx, "testsecret").
+					Return(fmt.Errorf("delete error"))
+			},
+			expectedError: errors.New("failed to delete secret 'testsecret'"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.input != "" {
+				r, w, _ := os.Pipe()
+				os.Stdin = r
+				go func() {
+					w.Write([]byte(tt.input))
+					w.Close()
+				}()
+			}
+
+			if tt.setupMock != nil {
+				tt.setupMock()
+			}
+
+			cmd.SetArgs(tt.args)
+			output, err := executeCommand(cmd)
+
+			if tt.expectedError != nil {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedOutput, output)
+			}
+		})
+	}
+}
+
+// executeCommand executes the command and returns the output
+func executeCommand(cmd *cobra.Command) (string, error) {
+	// Backup the original stdout and stderr
+	oldStdout := os.Stdout
+	oldStderr := os.Stderr
+	defer func() {
+		os.Stdout = oldStdout
+		os.Stderr = oldStderr
+	}()
+
+	// Create pipes to capture output
+	rOut, wOut, _ := os.Pipe()
+	rErr, wErr, _ := os.Pipe()
+	os.Stdout = wOut
+	os.Stderr = wErr
+	cmd.SetOut(wOut)
+	cmd.SetErr(wErr)
+
+	// Channel to collect the output
+	outChan := make(chan string)
+	go func() {
+		var buf bytes.Buffer
+		_, _ = io.Copy(&buf, rOut)
+		_, _ = io.Copy(&buf, rErr)
+		outChan <- buf.String()
+	}()
+
+	// Execute the command
+	err := cmd.Execute()
+
+	// Close the writers and wait for output
+	wOut.Close()
+	wErr.Close()
+	output := <-outChan
+
+	return output, err
+}
+
+func TestCLI_ShellCmd(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuthService := mocks.NewMockAuthService(ctrl)
+	mockSecretService := mocks.NewMockSecretService(ctrl)
+
+	ctx := context.Background()
+
+	var locks atomic.Int32
+	shell := cli.WithShell(func() bool {
+		return locks.Add(1) == 1
+	})
+
+	t.Run("runs commands until exit", func(t *testing.T) {
+		locks.Store(0)
+		credentialsData, _ := json.Marshal(domain.CredentialsSecret{Login: "user", Password: "hunter2"})
+		// Names are listed once at start, as none of the commands adds or removes secrets
+		mockSecretService.EXPECT().ListSecrets(gomock.Any()).Return([]string{"my github"}, nil)
+		mockSecretService.EXPECT().ListSecretsInfo(gomock.Any()).Return([]domain.SecretInfo{
+			{Name: "my github", Type: domain.CredentialsSecretType, Version: 1, CreatedAt: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)},
+		}, nil)
+		mockSecretService.EXPECT().GetLatestSecret(gomock.Any(), "my github").Return(&domain.Secret{
+			Info: domain.SecretInfo{Name: "my github", Type: domain.CredentialsSecretType, Version: 1},
+			Data: string(credentialsData),
+		}, nil)
+
+		cmd := cli.NewCLI(ctx, mockSecretService, mockAuthService, shell)
+		cmd.SetArgs([]string{"shell"})
+		cmd.SetIn(strings.NewReader("list --columns name\n\nget-credentials -n 'my github'\n" +
+			"get-credentials\nshell\necho \"open\nlist --server other:8097\nlock\nlock\nexit\nlist\n"))
+		output, err := executeCommand(cmd)
+		assert.NoError(t, err)
+		assert.Equal(t, "NAME\nmy github\n"+
+			"Name: my github\nVersion: 1\nLogin: user\nPassword: hunter2\n"+
+			"Error: required flag(s) \"name\" not set\n"+
+			"Error: already in the shell\n"+
+			"Error: unterminated \" quote\n"+
+			"Error: --server only applies when the shell starts\n"+
+			"Vault locked\n", output)
+		assert.Equal(t, int32(2), locks.Load())
+	})
+
+	t.Run("lists the names again after commands that change secrets", func(t *testing.T) {
+		gomock.InOrder(
+			mockSecretService.EXPECT().ListSecrets(gomock.Any()).Return([]string{"my github"}, nil),
+			mockAuthService.EXPECT().Logout(gomock.Any()).Return(nil),
+			mockSecretService.EXPECT().ListSecrets(gomock.Any()).Return(nil, domain.ErrUnauthenticated),
+		)
+
+		cmd := cli.NewCLI(ctx, mockSecretService, mockAuthService, shell)
+		cmd.SetArgs([]string{"shell"})
+		cmd.SetIn(strings.NewReader("logout\nexit\n"))
+		output, err := executeCommand(cmd)
+		assert.NoError(t, err)
+		assert.Equal(t, "Successfully logged out\n", output)
+	})
+
+	t.Run("locks the vault when idle", func(t *testing.T) {
+		locks.Store(0)
+		mockSecretService.EXPECT().ListSecrets(gomock.Any()).Return(nil, nil)
+		input, writer := io.Pipe()
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			_, _ = io.WriteString(writer, "exit\n")
+			_ = writer.Close()
+		}()
+
+		cmd := cli.NewCLI(ctx, mockSecretService, mockAuthService, shell)
+		cmd.SetArgs([]string{"shell", "--lock-after", "1ms"})
+		cmd.SetIn(input)
+		output, err := executeCommand(cmd)
+		assert.NoError(t, err)
+		assert.Equal(t, "Vault locked after 1ms of inactivity\n", output)
+	})
+
+	t.Run("shell is only available when enabled", func(t *testing.T) {
+		cmd := cli.NewCLI(ctx, mockSecretService, mockAuthService)
+		cmd.SetArgs([]string{"shell"})
+		_, err := executeCommand(cmd)
+		assert.EqualError(t, err, `unknown command "shell" for "gophkeeper-cli"`)
+	})
+}
+
+func TestCLI_SyncCmd(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuthService := mocks.NewMockAuthService(ctrl)
+	mockSecretService := mocks.NewMockSecretService(ctrl)
+
+	ctx := context.Background()
+	cmd := cli.NewCLI(ctx, mockSecretService, mockAuthService)
+
+	lastSync := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	queuedAt := time.Date(2025, 3, 2, 8, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		args           []string
+		setupMock      func()
+		expectedOutput string
+		expectedError  error
+	}{
+		{
+			name: "successful sync",
+			args: []string{"sync", "--status=false"},
+			setupMock: func() {
+				mockSecretService.EXPECT().Sync(ctx).Return(nil)
+				mockSecretService.EXPECT().GetSyncStatus(ctx).
+					Return(&domain.SyncStatus{LastSync: lastSync}, nil)
+			},
+			expectedOutput: "Last sync: 2025-03-01T12:00:00Z\nNo pending operations\n",
+		},
+		{
+			name: "status only with pending operations",
+			args: []string{"sync", "--status"},
+			setupMock: func() {
+				mockSecretService.EXPECT().GetSyncStatus(ctx).
+					Return(&domain.SyncStatus{
+						Pending: []domain.PendingOperation{
+							{
+								Type:     domain.CreateOperationType,
+								Secret:   domain.Secret{Info: domain.SecretInfo{Name: "github"}},
+								QueuedAt: queuedAt,
+							},
+						},
+					}, nil)
+			},
+			expectedOutput: "Last sync: never\nPending operations: 1\n  - create 'github' queued at 2025-03-02T08:30:00Z\n",
+		},
+		{
+			name: "server still unavailable",
+			args: []string{"sync", "--status=false"},
+			setupMock: func() {
+				mockSecretService.EXPECT().Sync(ctx).Return(domain.ErrServerUnavailable)
+				mockSecretService.EXPECT().GetSyncStatus(ctx).
+					Return(&domain.SyncStatus{}, nil)
+			},
+			expectedError: errors.New("failed to sync pending operations"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.setupMock != nil {
+				tt.setupMock()
+			}
+
+			cmd.SetArgs(tt.args)
+			output, err := executeCommand(cmd)
+
+			if tt.expectedError != nil {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedOutput, output)
+			}
+		})
+	}
+}
+
+func TestCLI_ImportCmd(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuthService := mocks.NewMockAuthService(ctrl)
+	mockSecretService := mocks.NewMockSecretService(ctrl)
+	mockImporter := mocks.NewMockImporter(ctrl)
+
+	ctx := context.Background()
+
+	importer := cli.WithImporter(func(format string) (domain.Importer, error) {
+		if format != "keepass" {
+			return nil, fmt.Errorf("unknown import format '%s'", format)
+		}
+		return mockImporter, nil
+	})
+
+	entries := []domain.ImportEntry{
+		domain.NewCredentialsImportEntry("web/github", "", domain.CredentialsSecret{Login: "user", Password: "hunter2"}),
+		{Name: "notes", Type: domain.TextSecretType, Content: []byte("remember")},
+	}
+	skipped := []domain.SkippedImportEntry{{Name: "empty", Reason: "entry has no login, password or notes"}}
+	existing := []domain.SecretInfo{{Name: "notes", Type: domain.TextSecretType, Version: 4}}
+
+	t.Run("dry run stores nothing", func(t *testing.T) {
+		mockImporter.EXPECT().Parse("export.xml").Return(entries, skipped, nil)
+		mockSecretService.EXPECT().ListSecretsInfo(gomock.Any()).Return(existing, nil)
+
+		cmd := cli.NewCLI(ctx, mockSecretService, mockAuthService, importer)
+		cmd.SetArgs([]string{"import", "--format", "keepass", "-f", "export.xml", "--dry-run"})
+		output, err := executeCommand(cmd)
+		assert.NoError(t, err)
+		assert.Equal(t, "NAME        TYPE         ACTION  DETAIL\n"+
+			"web/github  credentials  create  -\n"+
+			"notes       text         skip    a secret with this name exists\n"+
+			"empty       -            skip    entry has no login, password or notes\n"+
+			"\nWould import 1 of 3 entries, nothing was stored\n", output)
+	})
+
+	t.Run("overwrite", func(t *testing.T) {
+		mockImporter.EXPECT().Parse("export.xml").Return(entries, nil, nil)
+		mockSecretService.EXPECT().ListSecretsInfo(gomock.Any()).Return(existing, nil)
+		mockSecretService.EXPECT().CreateSecret(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, secret domain.Secret, r io.Reader) error {
+				content, _ := io.ReadAll(r)
+				assert.Equal(t, "web/github", secret.Info.Name)
+				assert.Equal(t, domain.CredentialsSecretType, secret.Info.Type)
+				assert.JSONEq(t, `{"Login":"user","Password":"hunter2"}`, string(content))
+				return nil
+			})
+		mockSecretService.EXPECT().CreateSecret(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, secret domain.Secret, r io.Reader) error {
+				content, _ := io.ReadAll(r)
+				assert.Equal(t, "notes", secret.Info.Name)
+				assert.Equal(t, int32(4), secret.BaseVersion)
+				assert.Equal(t, "remember", string(content))
+				return nil
+			})
+
+		cmd := cli.NewCLI(ctx, mockSecretService, mockAuthService, importer)
+		cmd.SetArgs([]string{"import", "--format", "keepass", "-f", "export.xml", "--on-collision", "overwrite"})
+		output, err := executeCommand(cmd)
+		assert.NoError(t, err)
+		assert.Equal(t, "Imported 2 of 2 entries\n", output)
+	})
+
+	t.Run("rename with json output", func(t *testing.T) {
+		mockImporter.EXPECT().Parse("export.xml").Return(entries[1:], skipped, nil)
+		mockSecretService.EXPECT().ListSecretsInfo(gomock.Any()).Return(existing, nil)
+		mockSecretService.EXPECT().CreateSecret(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, secret domain.Secret, _ io.Reader) error {
+				assert.Equal(t, "notes-2", secret.Info.Name)
+				assert.Zero(t, secret.BaseVersion)
+				return nil
+			})
+
+		cmd := cli.NewCLI(ctx, mockSecretService, mockAuthService, importer)
+		cmd.SetArgs([]string{"import", "--format", "keepass", "-f", "export.xml", "--on-collision", "rename", "-o", "json"})
+		output, err := executeCommand(cmd)
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"schema_version":1,"kind":"import_report","data":[
+			{"name":"notes-2","type":"text","action":"create","detail":"renamed from 'notes'"},
+			{"name":"empty","action":"skip","detail":"entry has no login, password or notes"}
+		]}`, output)
+	})
+
+	t.Run("failed store fails the command", func(t *testing.T) {
+		mockImporter.EXPECT().Parse("export.xml").Return(entries, nil, nil)
+		mockSecretService.EXPECT().ListSecretsInfo(gomock.Any()).Return(nil, nil)
+		mockSecretService.EXPECT().CreateSecret(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+		mockSecretService.EXPECT().CreateSecret(gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("connection refused"))
+
+		cmd := cli.NewCLI(ctx, mockSecretService, mockAuthService, importer)
+		cmd.SetArgs([]string{"import", "--format", "keepass", "-f", "export.xml"})
+		output, err := executeCommand(cmd)
+		assert.EqualError(t, err, "failed to import 1 entries")
+		assert.Equal(t, "NAME   TYPE  ACTION  DETAIL\n"+
+			"notes  text  failed  failed to store secret\n"+
+			"\nImported 1 of 2 entries\n"+
+			"Error: failed to import 1 entries\n", output)
+	})
+
+	t.Run("collision with fail policy", func(t *testing.T) {
+		mockImporter.EXPECT().Parse("export.xml").Return(entries, nil, nil)
+		mockSecretService.EXPECT().ListSecretsInfo(gomock.Any()).Return(existing, nil)
+
+		cmd := cli.NewCLI(ctx, mockSecretService, mockAuthService, importer)
+		cmd.SetArgs([]string{"import", "--format", "keepass", "-f", "export.xml", "--on-collision", "fail"})
+		_, err := executeCommand(cmd)
+		assert.ErrorIs(t, err, domain.ErrImportCollision)
+	})
+
+	t.Run("every request gets its own timeout", func(t *testing.T) {
+		expired, cancel := context.WithDeadline(ctx, time.Now().Add(-time.Second))
+		defer cancel()
+
+		checkDeadline := func(ctx context.Context) {
+			assert.NoError(t, ctx.Err())
+			deadline, ok := ctx.Deadline()
+			assert.True(t, ok)
+			assert.WithinDuration(t, time.Now().Add(time.Minute), deadline, 10*time.Second)
+		}
+		mockImporter.EXPECT().Parse("export.xml").Return(entries[:1], nil, nil)
+		mockSecretService.EXPECT().ListSecretsInfo(gomock.Any()).
+			DoAndReturn(func(ctx context.Context) ([]domain.SecretInfo, error) {
+				checkDeadline(ctx)
+				return nil, nil
+			})
+		mockSecretService.EXPECT().CreateSecret(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, _ domain.Secret, _ io.Reader) error {
+				checkDeadline(ctx)
+				return nil
+			})
+
+		cmd := cli.NewCLI(expired, mockSecretService, mockAuthService, importer, cli.WithRequestTimeout(time.Minute))
+		cmd.SetArgs([]string{"import", "--format", "keepass", "-f", "export.xml"})
+		output, err := executeCommand(cmd)
+		assert.NoError(t, err)
+		assert.Equal(t, "Imported 1 of 1 entries\n", output)
+	})
+
+	t.Run("invalid arguments", func(t *testing.T) {
+		cmd := cli.NewCLI(ctx, mockSecretService, mockAuthService, importer)
+		cmd.SetArgs([]string{"import", "--format", "lastpass", "-f", "export.csv"})
+		_, err := executeCommand(cmd)
+		assert.EqualError(t, err, "unknown import format 'lastpass'")
+
+		cmd = cli.NewCLI(ctx, mockSecretService, mockAuthService, importer)
+		cmd.SetArgs([]string{"import", "--format", "keepass", "-f", "export.xml", "--on-collision", "merge"})
+		_, err = executeCommand(cmd)
+		assert.EqualError(t, err, "unknown collision policy 'merge' (must be skip, overwrite, rename or fail)")
+	})
+}
+
+func TestCLI_BackupCmd(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuthService := mocks.NewMockAuthService(ctrl)
+	mockSecretService := mocks.NewMockSecretService(ctrl)
+	mockWriter := mocks.NewMockBackupWriter(ctrl)
+
+	ctx := context.Background()
+	dir := t.TempDir()
+	passphraseFile := filepath.Join(dir, "passphrase")
+	require.NoError(t, os.WriteFile(passphraseFile, []byte("correct horse\n"), 0600))
+
+	var created struct {
+		passphrase  string
+		allVersions bool
+	}
+	backups := cli.WithBackup(func(w io.Writer, passphrase string, allVersions bool) (domain.BackupWriter, error) {
+		created.passphrase, created.allVersions = passphrase, allVersions
+		_, err := w.Write([]byte("archive"))
+		return mockWriter, err
+	}, nil)
+
+	github := domain.SecretInfo{Name: "github", Type: domain.CredentialsSecretType, Version: 2}
+	notes := domain.SecretInfo{Name: "notes", Type: domain.TextSecretType, Version: 1}
+	addContent := func(want string) func(domain.SecretInfo, io.Reader) error {
+		return func(_ domain.SecretInfo, r io.Reader) error {
+			content, err := io.ReadAll(r)
+			assert.Equal(t, want, string(content))
+			return err
+		}
+	}
+
+	t.Run("all versions", func(t *testing.T) {
+		path := filepath.Join(dir, "all.gkb")
+		// Older versions are listed by number, their infos come with the content
+		githubV1 := github
+		githubV1.Version, githubV1.Metadata = 1, "old"
+		mockSecretService.EXPECT().ListSecretsInfo(gomock.Any()).Return([]domain.SecretInfo{notes, github}, nil)
+		gomock.InOrder(
+			mockSecretService.EXPECT().GetSecretByVersion(gomock.Any(), "github", int32(1)).Return(&domain.Secret{Info: githubV1, Data: "v1"}, nil),
+			mockWriter.EXPECT().Add(githubV1, gomock.Any()).DoAndReturn(addContent("v1")),
+			mockSecretService.EXPECT().GetSecretByVersion(gomock.Any(), "github", int32(2)).Return(&domain.Secret{Info: github, Data: "v2"}, nil),
+			mockWriter.EXPECT().Add(github, gomock.Any()).DoAndReturn(addContent("v2")),
+			mockSecretService.EXPECT().GetSecretStreamByVersion(gomock.Any(), "notes", int32(1)).Return(strings.NewReader("remember"), &notes, nil),
+			mockWriter.EXPECT().Add(notes, gomock.Any()).DoAndReturn(addContent("remember")),
+			mockWriter.EXPECT().Close().Return(nil),
+		)
+
+		cmd := cli.NewCLI(ctx, mockSecretService, mockAuthService, backups)
+		cmd.SetArgs([]string{"backup", "-f", path, "--all-versions", "--passphrase-file", passphraseFile})
+		output, err := executeCommand(cmd)
+		require.NoError(t, err)
+		assert.Equal(t, "Backed up 3 versions of 2 secrets to '"+path+"'\n", output)
+		assert.Equal(t, "correct horse", created.passphrase)
+		assert.True(t, created.allVersions)
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, "archive", string(data))
+	})
+
+	t.Run("latest versions with json output", func(t *testing.T) {
+		path := filepath.Join(dir, "latest.gkb")
+		mockSecretService.EXPECT().ListSecretsInfo(gomock.Any()).Return([]domain.SecretInfo{github}, nil)
+		mockSecretService.EXPECT().GetSecretByVersion(gomock.Any(), "github", int32(2)).Return(&domain.Secret{Info: github, Data: "v2"}, nil)
+		mockWriter.EXPECT().Add(github, gomock.Any()).DoAndReturn(addContent("v2"))
+		mockWriter.EXPECT().Close().Return(nil)
+
+		cmd := cli.NewCLI(ctx, mockSecretService, mockAuthService, backups)
+		cmd.SetArgs([]string{"backup", "-f", path, "--passphrase-file", passphraseFile, "-o", "json"})
+		output, err := executeCommand(cmd)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"schema_version":1,"kind":"backup","data":
+			{"file":"`+path+`","secrets":1,"versions":1,"all_versions":false}}`, output)
+		assert.False(t, created.allVersions)
+	})
+
+	t.Run("failed backup leaves no file", func(t *testing.T) {
+		path := filepath.Join(dir, "failed.gkb")
+		mockSecretService.EXPECT().ListSecretsInfo(gomock.Any()).Return([]domain.SecretInfo{github}, nil)
+		mockSecretService.EXPECT().GetSecretByVersion(gomock.Any(), "github", int32(2)).Return(nil, errors.New("connection refused"))
+
+		cmd := cli.NewCLI(ctx, mockSecretService, mockAuthService, backups)
+		cmd.SetArgs([]string{"backup", "-f", path, "--passphrase-file", passphraseFile})
+		_, err := executeCommand(cmd)
+		assert.EqualError(t, err, "failed to retrieve version 2 of secret 'github'")
+
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		for _, e := range entries {
+			assert.NotContains(t, e.Name(), "failed.gkb")
+		}
+	})
+
+	t.Run("every version gets its own timeout", func(t *testing.T) {
+		path := filepath.Join(dir, "timeout.gkb")
+		expired, cancel := context.WithDeadline(ctx, time.Now().Add(-time.Second))
+		defer cancel()
+
+		checkDeadline := func(ctx context.Context) {
+			assert.NoError(t, ctx.Err())
+			deadline, ok := ctx.Deadline()
+			assert.True(t, ok)
+			assert.WithinDuration(t, time.Now().Add(time.Minute), deadline, 10*time.Second)
+		}
+		mockSecretService.EXPECT().ListSecretsInfo(gomock.Any()).
+			DoAndReturn(func(ctx context.Context) ([]domain.SecretInfo, error) {
+				checkDeadline(ctx)
+				return []domain.SecretInfo{github}, nil
+			})
+		mockSecretService.EXPECT().GetSecretByVersion(gomock.Any(), "github", int32(2)).
+			DoAndReturn(func(ctx context.Context, _ string, _ int32) (*domain.Secret, error) {
+				checkDeadline(ctx)
+				return &domain.Secret{Info: github, Data: "v2"}, nil
+			})
+		mockWriter.EXPECT().Add(github, gomock.Any()).DoAndReturn(addContent("v2"))
+		mockWriter.EXPECT().Close().Return(nil)
+
+		cmd := cli.NewCLI(expired, mockSecretService, mockAuthService, backups, cli.WithRequestTimeout(time.Minute))
+		cmd.SetArgs([]string{"backup", "-f", path, "--passphrase-file", passphraseFile})
+		output, err := executeCommand(cmd)
+		require.NoError(t, err)
+		assert.Equal(t, "Backed up 1 versions of 1 secrets to '"+path+"'\n", output)
+	})
+
+	t.Run("empty passphrase", func(t *testing.T) {
+		empty := filepath.Join(dir, "empty")
+		require.NoError(t, os.WriteFile(empty, []byte("\n"), 0600))
+
+		cmd := cli.NewCLI(ctx, mockSecretService, mockAuthService, backups)
+		cmd.SetArgs([]string{"backup", "-f", filepath.Join(dir, "x.gkb"), "--passphrase-file", empty})
+		_, err := executeCommand(cmd)
+		assert.EqualError(t, err, "backup passphrase cannot be empty")
+	})
+}
+
+func TestCLI_RestoreBackupCmd(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuthService := mocks.NewMockAuthService(ctrl)
+	mockSecretService := mocks.NewMockSecretService(ctrl)
+	mockReader := mocks.NewMockBackupReader(ctrl)
+
+	ctx := context.Background()
+	passphraseFile := filepath.Join(t.TempDir(), "passphrase")
+	require.NoError(t, os.WriteFile(passphraseFile, []byte("correct horse"), 0600))
+
+	backups := cli.WithBackup(nil, func(path, passphrase string) (domain.BackupReader, error) {
+		if passphrase != "correct horse" {
+			return nil, domain.ErrBackupPassphrase
+		}
+		return mockReader, nil
+	})
+
+	manifest := &domain.BackupManifest{
+		FormatVersion: domain.BackupFormatVersion,
+		AllVersions:   true,
+		Entries: []domain.BackupEntry{
+			{Name: "github", Type: domain.CredentialsSecretType, Version: 1},
+			{Name: "github", Type: domain.CredentialsSecretType, Version: 2},
+			{Name: "notes", Type: domain.TextSecretType, Metadata: "todo", Version: 1},
+		},
+	}
+	contents := []string{"v1", "v2", "remember"}
+	walk := func(fn func(domain.BackupEntry, io.Reader) error) error {
+		for i, entry := range manifest.Entries {
+			if err := fn(entry, strings.NewReader(contents[i])); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	existing := []domain.SecretInfo{{Name: "github", Type: domain.CredentialsSecretType, Version: 5}}
+
+	t.Run("dry run", func(t *testing.T) {
+		mockReader.EXPECT().Verify().Return(manifest, nil)
+		mockSecretService.EXPECT().ListSecretsInfo(gomock.Any()).Return(existing, nil)
+
+		cmd := cli.NewCLI(ctx, mockSecretService, mockAuthService, backups)
+		cmd.SetArgs([]string{"restore-backup", "-f", "vault.gkb", "--passphrase-file", passphraseFile, "--dry-run"})
+		output, err := executeCommand(cmd)
+		require.NoError(t, err)
+		assert.Equal(t, "NAME    VERSION  TYPE         ACTION  DETAIL\n"+
+			"github  1        credentials  skip    a secret with this name exists\n"+
+			"github  2        credentials  skip    a secret with this name exists\n"+
+			"notes   1        text         create  -\n"+
+			"\nWould restore 1 of 3 entries, nothing was stored\n", output)
+	})
+
+	t.Run("overwrite", func(t *testing.T) {
+		mockReader.EXPECT().Verify().Return(manifest, nil)
+		mockSecretService.EXPECT().ListSecretsInfo(gomock.Any()).Return(existing, nil)
+		mockReader.EXPECT().Walk(gomock.Any()).DoAndReturn(walk)
+
+		var stored []string
+		mockSecretService.EXPECT().CreateSecret(gomock.Any(), gomock.Any(), gomock.Any()).Times(3).
+			DoAndReturn(func(_ context.Context, secret domain.Secret, r io.Reader) error {
+				content, _ := io.ReadAll(r)
+				stored = append(stored, fmt.Sprintf("%s %s %s %d %s",
+					secret.Info.Name, secret.Info.Type, secret.Info.Metadata, secret.BaseVersion, content))
+				return nil
+			})
+
+		cmd := cli.NewCLI(ctx, mockSecretService, mockAuthService, backups)
+		cmd.SetArgs([]string{"restore-backup", "-f", "vault.gkb", "--passphrase-file", passphraseFile, "--on-collision", "overwrite"})
+		output, err := executeCommand(cmd)
+		require.NoError(t, err)
+		assert.Equal(t, "Restored 3 of 3 entries\n", output)
+		assert.Equal(t, []string{
+			"github credentials  5 v1",
+			"github credentials  0 v2",
+			"notes text todo 0 remember",
+		}, stored)
+	})
+
+	t.Run("rename with a failed version", func(t *testing.T) {
+		mockReader.EXPECT().Verify().Return(manifest, nil)
+		mockSecretService.EXPECT().ListSecretsInfo(gomock.Any()).Return(existing, nil)
+		mockReader.EXPECT().Walk(gomock.Any()).DoAndReturn(walk)
+		mockSecretService.EXPECT().CreateSecret(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+		mockSecretService.EXPECT().CreateSecret(gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("connection refused"))
+		mockSecretService.EXPECT().CreateSecret(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+
+		cmd := cli.NewCLI(ctx, mockSecretService, mockAuthService, backups)
+		cmd.SetArgs([]string{"restore-backup", "-f", "vault.gkb", "--passphrase-file", passphraseFile, "--on-collision", "rename", "-o", "json"})
+		output, err := executeCommand(cmd)
+		assert.EqualError(t, err, "failed to restore 1 entries")
+		assert.JSONEq(t, `{"schema_version":1,"kind":"restore_report","data":[
+			{"name":"github-2","version":1,"type":"credentials","action":"create","detail":"renamed from 'github'"},
+			{"name":"github-2","version":2,"type":"credentials","action":"failed","detail":"failed to store secret"},
+			{"name":"notes","version":1,"type":"text","action":"create"}
+		]}`, strings.SplitN(output, "\nError:", 2)[0])
+	})
+
+	t.Run("damaged archive", func(t *testing.T) {
+		mockReader.EXPECT().Verify().Return(nil, fmt.Errorf("%w: version 2 of 'github'", domain.ErrBackupChecksum))
+
+		cmd := cli.NewCLI(ctx, mockSecretService, mockAuthService, backups)
+		cmd.SetArgs([]string{"restore-backup", "-f", "vault.gkb", "--passphrase-file", passphraseFile})
+		_, err := executeCommand(cmd)
+		assert.ErrorIs(t, err, domain.ErrBackupChecksum)
+		assert.EqualError(t, err, "failed to verify backup 'vault.gkb': backup entry does not match its checksum: version 2 of 'github'")
+	})
+
+	t.Run("wrong passphrase", func(t *testing.T) {
+		wrong := filepath.Join(t.TempDir(), "wrong")
+		require.NoError(t, os.WriteFile(wrong, []byte("wrong\n"), 0600))
+
+		cmd := cli.NewCLI(ctx, mockSecretService, mockAuthService, backups)
+		cmd.SetArgs([]string{"restore-backup", "-f", "vault.gkb", "--passphrase-file", wrong})
+		_, err := executeCommand(cmd)
+		assert.EqualError(t, err, "failed to open backup 'vault.gkb': wrong passphrase or damaged backup archive")
+	})
+}
+
+func TestCLI_RunCmd(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuthService := mocks.NewMockAuthService(ctrl)
+	mockSecretService := mocks.NewMockSecretService(ctrl)
+
+	ctx := context.Background()
+
+	dbProd := domain.SecretInfo{Name: "db-prod", Type: domain.CredentialsSecretType, Version: 2}
+	config := domain.SecretInfo{Name: "app.env", Type: domain.TextSecretType, Version: 1}
+	credentialsData, _ := json.Marshal(domain.CredentialsSecret{Login: "admin", Password: "hunter2"})
+	expectSecrets := func() {
+		mockSecretService.EXPECT().GetSecretInfo(ctx, "db-prod", int32(0)).Return(&dbProd, nil)
+		mockSecretService.EXPECT().GetSecretByVersion(ctx, "db-prod", int32(2)).
+			Return(&domain.Secret{Info: dbProd, Data: string(credentialsData)}, nil)
+	}
+
+	run := func(args ...string) (string, error) {
+		cmd := cli.NewCLI(ctx, mockSecretService, mockAuthService)
+		cmd.SetArgs(append([]string{"run"}, args...))
+		cmd.SetIn(strings.NewReader(""))
+		return executeCommand(cmd)
+	}
+
+	t.Run("masks injected values", func(t *testing.T) {
+		expectSecrets()
+		output, err := run("--env", "DB_USER=db-prod.login", "--env", "DB_PASS=db-prod.password", "--",
+			"sh", "-c", `echo "$DB_USER:$DB_PASS"; printf hun >&2; sleep 0.1; printf 'ter2 hunt\n' >&2`)
+		require.NoError(t, err)
+		assert.Equal(t, "********:********\n******** hunt\n", output)
+	})
+
+	t.Run("propagates the exit status", func(t *testing.T) {
+		expectSecrets()
+		output, err := run("--env", "DB_PASS=db-prod.password", "--no-mask", "sh", "-c", `echo "$DB_PASS"; exit 3`)
+		var exitErr *cli.ExitError
+		require.ErrorAs(t, err, &exitErr)
+		assert.Equal(t, 3, exitErr.Code)
+		assert.Equal(t, "hunter2\n", output)
+	})
+
+	t.Run("env file", func(t *testing.T) {
+		mapping := filepath.Join(t.TempDir(), "mapping.yaml")
+		require.NoError(t, os.WriteFile(mapping, []byte("DB_PASS: db-prod.login\nAPP_ENV: app.env.content\n"), 0600))
+		expectSecrets()
+		mockSecretService.EXPECT().GetSecretInfo(ctx, "app.env", int32(0)).Return(&config, nil)
+		mockSecretService.EXPECT().GetSecretStreamByVersion(ctx, "app.env", int32(1)).
+			Return(strings.NewReader("PORT=8080"), &config, nil)
+
+		output, err := run("--env-file", mapping, "--env", "DB_PASS=db-prod.password", "--no-mask",
+			"sh", "-c", `echo "$DB_PASS $APP_ENV"`)
+		require.NoError(t, err)
+		assert.Equal(t, "hunter2 PORT=8080\n", output)
+	})
+
+	t.Run("missing references fail before the command starts", func(t *testing.T) {
+		marker := filepath.Join(t.TempDir(), "started")
+		touch := []string{"--", "sh", "-c", "touch " + marker}
+
+		expectSecrets()
+		_, err := run(append([]string{"--env", "DB_OTP=db-prod.otp"}, touch...)...)
+		assert.EqualError(t, err, "failed to resolve DB_OTP: field 'otp' of 'db-prod' is empty")
+
+		mockSecretService.EXPECT().GetSecretInfo(ctx, "api", int32(0)).
+			Return(nil, fmt.Errorf("client.GetSecretInfo: %w", domain.ErrSecretNotFound))
+		_, err = run(append([]string{"--env", "API_KEY=api.password"}, touch...)...)
+		assert.EqualError(t, err, "failed to resolve API_KEY: secret 'api' not found")
+
+		expectSecrets()
+		_, err = run(append([]string{"--env", "DB_PIN=db-prod.pin"}, touch...)...)
+		assert.EqualError(t, err, "failed to resolve DB_PIN: unknown field 'pin' of credentials secret 'db-prod' "+
+			"(must be one of login, password, url, notes, otp)")
+
+		assert.NoFileExists(t, marker)
+	})
+
+	t.Run("invalid mappings", func(t *testing.T) {
+		_, err := run("--env", "DB_PASS", "--", "true")
+		assert.EqualError(t, err, "invalid --env 'DB_PASS' (must be NAME=<secret>.<field>)")
+
+		_, err = run("--env", "DB-PASS=db-prod.password", "--", "true")
+		assert.EqualError(t, err, "invalid environment variable name 'DB-PASS'")
+
+		_, err = run("--env", "DB_PASS=db-prod", "--", "true")
+		assert.EqualError(t, err, "invalid reference of DB_PASS: invalid reference 'db-prod' (must be <secret>.<field>)")
+
+		_, err = run("--", "true")
+		assert.EqualError(t, err, "no variables to set, use --env or --env-file")
+	})
+
+	t.Run("unknown command", func(t *testing.T) {
+		expectSecrets()
+		_, err := run("--env", "DB_PASS=db-prod.password", "--", "gophkeeper-no-such-command")
+		assert.ErrorContains(t, err, "failed to run 'gophkeeper-no-such-command'")
+	})
+
+	t.Run("terminates the command when interrupted", func(t *testing.T) {
+		runCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		mockSecretService.EXPECT().GetSecretInfo(runCtx, "db-prod", int32(0)).Return(&dbProd, nil)
+		mockSecretService.EXPECT().GetSecretByVersion(runCtx, "db-prod", int32(2)).
+			DoAndReturn(func(context.Context, string, int32) (*domain.Secret, error) {
+				time.AfterFunc(100*time.Millisecond, cancel)
+				return &domain.Secret{Info: dbProd, Data: string(credentialsData)}, nil
+			})
+
+		cmd := cli.NewCLI(runCtx, mockSecretService, mockAuthService)
+		cmd.SetArgs([]string{"run", "--env", "DB_PASS=db-prod.password", "--", "sleep", "10"})
+		cmd.SetIn(strings.NewReader(""))
+		start := time.Now()
+		_, err := executeCommand(cmd)
+		var exitErr *cli.ExitError
+		require.ErrorAs(t, err, &exitErr)
+		assert.Equal(t, 128+int(syscall.SIGTERM), exitErr.Code)
+		assert.Less(t, time.Since(start), 5*time.Second)
+	})
+
+	t.Run("passes received signals to the command", func(t *testing.T) {
+		expectSecrets()
+		// The command interrupts this process, and exits with 42 only if the interrupt reaches it
+		start := time.Now()
+		_, err := run("--env", "DB_PASS=db-prod.password", "--", "sh", "-c",
+			`trap 'exit 42' INT; kill -INT $PPID; sleep 10 >/dev/null 2>&1 & wait`)
+		var exitErr *cli.ExitError
+		require.ErrorAs(t, err, &exitErr)
+		assert.Equal(t, 42, exitErr.Code)
+		assert.Less(t, time.Since(start), 5*time.Second)
+	})
+}
+
+func TestCLI_InjectCmd(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuthService := mocks.NewMockAuthService(ctrl)
+	mockSecretService := mocks.NewMockSecretService(ctrl)
+
+	ctx := context.Background()
+
+	dbProd := domain.SecretInfo{Name: "db-prod", Type: domain.CredentialsSecretType, Version: 2}
+	dbProdV1 := domain.SecretInfo{Name: "db-prod", Type: domain.CredentialsSecretType, Version: 1}
+	credentialsData, _ := json.Marshal(domain.CredentialsSecret{Login: "admin", Password: "hunter2"})
+	oldCredentialsData, _ := json.Marshal(domain.CredentialsSecret{Login: "admin", Password: "letmein"})
+	expectSecrets := func() {
+		mockSecretService.EXPECT().GetSecretInfo(ctx, "db-prod", int32(0)).Return(&dbProd, nil)
+		mockSecretService.EXPECT().GetSecretByVersion(ctx, "db-prod", int32(2)).
+			Return(&domain.Secret{Info: dbProd, Data: string(credentialsData)}, nil)
+	}
+
+	const template = `user={{ gk "db-prod" "login" }} pass={{ gk "db-prod" "password" }}` + "\n"
+
+	inject := func(stdin string, args ...string) (string, error) {
+		cmd := cli.NewCLI(ctx, mockSecretService, mockAuthService)
+		cmd.SetArgs(append([]string{"inject"}, args...))
+		cmd.SetIn(strings.NewReader(stdin))
+		return executeCommand(cmd)
+	}
+
+	t.Run("renders stdin to stdout", func(t *testing.T) {
+		expectSecrets()
+		output, err := inject(template)
+		require.NoError(t, err)
+		assert.Equal(t, "user=admin pass=hunter2\n", output)
+	})
+
+	t.Run("renders a file into a private file", func(t *testing.T) {
+		dir := t.TempDir()
+		in, out := filepath.Join(dir, "config.tmpl"), filepath.Join(dir, "config")
+		require.NoError(t, os.WriteFile(in, []byte(template), 0644))
+		expectSecrets()
+
+		output, err := inject("", "--file", in, "--out", out)
+		require.NoError(t, err)
+		assert.Equal(t, fmt.Sprintf("Rendered 'config.tmpl' into '%s'\n", out), output)
+
+		data, err := os.ReadFile(out)
+		require.NoError(t, err)
+		assert.Equal(t, "user=admin pass=hunter2\n", string(data))
+		stat, err := os.Stat(out)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0600), stat.Mode().Perm())
+	})
+
+	t.Run("pinned version", func(t *testing.T) {
+		mockSecretService.EXPECT().GetSecretInfo(ctx, "db-prod", int32(1)).Return(&dbProdV1, nil)
+		mockSecretService.EXPECT().GetSecretByVersion(ctx, "db-prod", int32(1)).
+			Return(&domain.Secret{Info: dbProdV1, Data: string(oldCredentialsData)}, nil)
+
+		output, err := inject(`{{ gk "db-prod" "password" 1 }}`)
+		require.NoError(t, err)
+		assert.Equal(t, "letmein", output)
+
+		mockSecretService.EXPECT().GetSecretInfo(ctx, "db-prod", int32(3)).
+			Return(nil, fmt.Errorf("client.GetSecretInfo: %w", domain.ErrSecretNotFound))
+		_, err = inject(`{{ gk "db-prod" "password" 3 }}`)
+		assert.ErrorContains(t, err, "version 3 of secret 'db-prod' not found")
+
+		_, err = inject(`{{ gk "db-prod" "password" 0 }}`)
+		assert.ErrorContains(t, err, "invalid version 0 of 'db-prod' (must be positive)")
+	})
+
+	t.Run("pinned version of another type", func(t *testing.T) {
+		// Version 1 of the secret held credentials, the latest one is a text
+		apiV1 := domain.SecretInfo{Name: "api", Type: domain.CredentialsSecretType, Version: 1}
+		mockSecretService.EXPECT().GetSecretInfo(ctx, "api", int32(1)).Return(&apiV1, nil)
+		mockSecretService.EXPECT().GetSecretByVersion(ctx, "api", int32(1)).
+			Return(&domain.Secret{Info: apiV1, Data: string(oldCredentialsData)}, nil)
+
+		output, err := inject(`{{ gk "api" "password" 1 }}`)
+		require.NoError(t, err)
+		assert.Equal(t, "letmein", output)
+	})
+
+	t.Run("missing references write nothing", func(t *testing.T) {
+		out := filepath.Join(t.TempDir(), "config")
+
+		mockSecretService.EXPECT().GetSecretInfo(ctx, "api", int32(0)).
+			Return(nil, fmt.Errorf("client.GetSecretInfo: %w", domain.ErrSecretNotFound))
+		_, err := inject(`{{ gk "api" "password" }}`, "--out", out)
+		assert.ErrorContains(t, err, "secret 'api' not found")
+
+		expectSecrets()
+		_, err = inject(template+`{{ gk "db-prod" "otp" }}`, "--out", out)
+		assert.ErrorContains(t, err, "field 'otp' of 'db-prod' is empty")
+
+		assert.NoFileExists(t, out)
+	})
+
+	t.Run("invalid template", func(t *testing.T) {
+		_, err := inject(`{{ gk "db-prod" }`)
+		assert.ErrorContains(t, err, "invalid template")
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		output, err := inject(template+`{{ gk "db-prod" "password" }}{{ gk "db-prod" "password" 1 }}`, "--dry-run")
+		require.NoError(t, err)
+		assert.Equal(t, "SECRET   FIELD     VERSION\n"+
+			"db-prod  login     latest\n"+
+			"db-prod  password  latest\n"+
+			"db-prod  password  1\n", output)
+
+		output, err = inject("plain text", "--dry-run")
+		require.NoError(t, err)
+		assert.Equal(t, "No secret references\n", output)
+	})
+}
+
+func TestCLI_GitCredentialCmd(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuthService := mocks.NewMockAuthService(ctrl)
+	mockSecretService := mocks.NewMockSecretService(ctrl)
+
+	ctx := context.Background()
+
+	github := domain.SecretInfo{Name: "git:https://github.com", Type: domain.CredentialsSecretType, Version: 3}
+	gitlab := domain.SecretInfo{Name: "work-gitlab", Type: domain.CredentialsSecretType, Metadata: "https://gitlab.example.com", Version: 1}
+	githubData, _ := json.Marshal(domain.CredentialsSecret{Login: "octo", Password: "ghp_token", Notes: "personal"})
+	gitlabData, _ := json.Marshal(domain.CredentialsSecret{Login: "dev", Password: "glpat_token"})
+	expectMissing := func(names ...string) {
+		for _, name := range names {
+			mockSecretService.EXPECT().GetSecretInfo(ctx, name, int32(0)).
+				Return(nil, fmt.Errorf("client.GetSecretInfo: %w", domain.ErrSecretNotFound))
+		}
+	}
+	expectNamed := func(info domain.SecretInfo, data []byte) {
+		mockSecretService.EXPECT().GetSecretInfo(ctx, info.Name, int32(0)).Return(&info, nil)
+		mockSecretService.EXPECT().GetLatestSecret(ctx, info.Name).Return(&domain.Secret{Info: info, Data: string(data)}, nil)
+	}
+	expectListed := func(info domain.SecretInfo, data []byte) {
+		mockSecretService.EXPECT().ListSecretsInfo(ctx).Return([]domain.SecretInfo{github, gitlab}, nil)
+		mockSecretService.EXPECT().GetLatestSecret(ctx, info.Name).Return(&domain.Secret{Info: info, Data: string(data)}, nil)
+	}
+
+	credential := func(operation, stdin string) (string, error) {
+		cmd := cli.NewCLI(ctx, mockSecretService, mockAuthService)
+		cmd.SetArgs([]string{"git-credential", operation})
+		cmd.SetIn(strings.NewReader(stdin))
+		return executeCommand(cmd)
+	}
+
+	t.Run("get by name", func(t *testing.T) {
+		expectMissing("git:https://github.com/org/repo.git")
+		expectNamed(github, githubData)
+		output, err := credential("get", "protocol=https\nhost=github.com\npath=org/repo.git\n\n")
+		require.NoError(t, err)
+		assert.Equal(t, "username=octo\npassword=ghp_token\n", output)
+	})
+
+	t.Run("get by metadata", func(t *testing.T) {
+		expectMissing("git:https://gitlab.example.com/team/app.git", "git:https://gitlab.example.com")
+		expectListed(gitlab, gitlabData)
+		output, err := credential("get", "url=https://gitlab.example.com/team/app.git\n")
+		require.NoError(t, err)
+		assert.Equal(t, "username=dev\npassword=glpat_token\n", output)
+	})
+
+	t.Run("get ignores other remotes and users", func(t *testing.T) {
+		expectMissing("git:https://bitbucket.org")
+		mockSecretService.EXPECT().ListSecretsInfo(ctx).Return([]domain.SecretInfo{github, gitlab}, nil)
+		output, err := credential("get", "protocol=https\nhost=bitbucket.org\n")
+		require.NoError(t, err)
+		assert.Empty(t, output)
+
+		expectNamed(github, githubData)
+		output, err = credential("get", "protocol=https\nhost=github.com\nusername=someone\n")
+		require.NoError(t, err)
+		assert.Empty(t, output)
+	})
+
+	t.Run("store creates a version of the matching secret", func(t *testing.T) {
+		expectNamed(github, githubData)
+		mockSecretService.EXPECT().CreateSecret(ctx, domain.Secret{
+			Info:        domain.SecretInfo{Name: github.Name, Type: domain.CredentialsSecretType},
+			BaseVersion: 3,
+		}, gomock.Any()).DoAndReturn(func(_ context.Context, _ domain.Secret, content io.Reader) error {
+			data, _ := io.ReadAll(content)
+			assert.JSONEq(t, `{"Login":"octo","Password":"ghp_rotated","Notes":"personal"}`, string(data))
+			return nil
+		})
+
+		output, err := credential("store", "protocol=https\nhost=github.com\nusername=octo\npassword=ghp_rotated\n")
+		require.NoError(t, err)
+		assert.Empty(t, output)
+	})
+
+	t.Run("store skips unchanged credentials", func(t *testing.T) {
+		expectNamed(github, githubData)
+		_, err := credential("store", "protocol=https\nhost=github.com\nusername=octo\npassword=ghp_token\n")
+		require.NoError(t, err)
+	})
+
+	t.Run("store creates a secret for a new remote", func(t *testing.T) {
+		expectMissing("git:https://bitbucket.org/team/app.git", "git:https://bitbucket.org")
+		mockSecretService.EXPECT().ListSecretsInfo(ctx).Return([]domain.SecretInfo{github, gitlab}, nil)
+		mockSecretService.EXPECT().CreateSecret(ctx, domain.Secret{
+			Info: domain.SecretInfo{Name: "git:https://bitbucket.org/team/app.git", Type: domain.CredentialsSecretType},
+		}, gomock.Any()).DoAndReturn(func(_ context.Context, _ domain.Secret, content io.Reader) error {
+			data, _ := io.ReadAll(content)
+			assert.JSONEq(t, `{"Login":"dev","Password":"app_password","URL":"https://bitbucket.org/team/app.git"}`, string(data))
+			return nil
+		})
+
+		_, err := credential("store", "protocol=https\nhost=bitbucket.org\npath=team/app.git\nusername=dev\npassword=app_password\n")
+		require.NoError(t, err)
+	})
+
+	t.Run("store keeps the secret of another user", func(t *testing.T) {
+		expectMissing("git:https://github.com/work/repo.git")
+		expectNamed(github, githubData)
+		mockSecretService.EXPECT().CreateSecret(ctx, domain.Secret{
+			Info: domain.SecretInfo{Name: "git:https://github.com/work/repo.git", Type: domain.CredentialsSecretType},
+		}, gomock.Any()).Return(nil)
+
+		_, err := credential("store", "protocol=https\nhost=github.com\npath=work/repo.git\nusername=bot\npassword=ghp_bot\n")
+		require.NoError(t, err)
+	})
+
+	t.Run("erase clears the rejected password in a new version", func(t *testing.T) {
+		expectNamed(github, githubData)
+		mockSecretService.EXPECT().CreateSecret(ctx, domain.Secret{
+			Info:        domain.SecretInfo{Name: github.Name, Type: domain.CredentialsSecretType},
+			BaseVersion: 3,
+		}, gomock.Any()).DoAndReturn(func(_ context.Context, _ domain.Secret, content io.Reader) error {
+			data, _ := io.ReadAll(content)
+			assert.JSONEq(t, `{"Login":"octo","Password":"","Notes":"personal"}`, string(data))
+			return nil
+		})
+		_, err := credential("erase", "protocol=https\nhost=github.com\nusername=octo\npassword=ghp_token\n")
+		require.NoError(t, err)
+
+		expectNamed(github, githubData)
+		_, err = credential("erase", "protocol=https\nhost=github.com\nusername=octo\npassword=ghp_old\n")
+		require.NoError(t, err)
+	})
+
+	t.Run("erase keeps secrets matched by metadata", func(t *testing.T) {
+		expectMissing("git:https://gitlab.example.com")
+		_, err := credential("erase", "protocol=https\nhost=gitlab.example.com\nusername=dev\npassword=glpat_token\n")
+		require.NoError(t, err)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := credential("get", "protocol https\n")
+		assert.ErrorIs(t, err, domain.ErrInvalidGitCredential)
+
+		mockSecretService.EXPECT().GetSecretInfo(ctx, "git:https://github.com", int32(0)).
+			Return(nil, errors.New("connection refused"))
+		_, err = credential("get", "protocol=https\nhost=github.com\n")
+		assert.EqualError(t, err, "failed to retrieve secret 'git:https://github.com'")
+
+		expectMissing("git:https://github.com")
+		mockSecretService.EXPECT().ListSecretsInfo(ctx).Return(nil, errors.New("connection refused"))
+		_, err = credential("get", "protocol=https\nhost=github.com\n")
+		assert.EqualError(t, err, "failed to list secrets")
+
+		output, err := credential("approve", "protocol=https\nhost=github.com\n")
+		require.NoError(t, err)
+		assert.Empty(t, output)
+	})
+}
+
+func TestCLI_CreateSecretConflictCmd(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuthService := mocks.NewMockAuthService(ctrl)
+	mockSecretService := mocks.NewMockSecretService(ctrl)
+
+	ctx := context.Background()
+	cmd := cli.NewCLI(ctx, mockSecretService, mockAuthService)
+
+	conflict := &domain.VersionConflictError{
+		Name:          "testcreds",
+		BaseVersion:   1,
+		LatestVersion: 2,
+		Base:          &domain.Secret{Data: `{"Login":"testuser","Password":"oldpass"}`},
+		Theirs:        &domain.Secret{Data: `{"Login":"otheruser","Password":"oldpass"}`},
+	}
+	args := []string{"create-credentials", "-n", "testcreds", "-l", "testuser", "-p", "testpass", "--base-version", "1"}
+
+	tests := []struct {
+		name           string
+		policy         string
+		setupMock      func()
+		expectedOutput string
+		expectedError  error
+	}{
+		{
+			name:   "keep mine",
+			policy: "mine",
+			setupMock: func() {
+				gomock.InOrder(
+					mockSecretService.EXPECT().
+						CreateSecret(ctx, gomock.Any(), gomock.Any()).
+						DoAndReturn(func(ctx context.Context, secret domain.Secret, r io.Reader) error {
+							assert.Equal(t, int32(1), secret.BaseVersion)
+							return conflict
+						}),
+					mockSecretService.EXPECT().
+						CreateSecret(ctx, gomock.Any(), gomock.Any()).
+						DoAndReturn(func(ctx context.Context, secret domain.Secret, r io.Reader) error {
+							assert.Equal(t, int32(2), secret.BaseVersion)
+							return nil
+						}),
+				)
+			},
+			expectedOutput: "Successfully stored credentials for 'testcreds'\n",
+		},
+		{
+			name:   "keep theirs",
+			policy: "theirs",
+			setupMock: func() {
+				mockSecretService.EXPECT().
+					CreateSecret(ctx, gomock.Any(), gomock.Any()).
+					Return(conflict)
+			},
+			expectedOutput: "Kept server version 2 of 'testcreds'\n",
+		},
+		{
+			name:   "merge fields",
+			policy: "merge",
+			setupMock: func() {
+				gomock.InOrder(
+					mockSecretService.EXPECT().
+						CreateSecret(ctx, gomock.Any(), gomock.Any()).
+						Return(conflict),
+					mockSecretService.EXPECT().
+						CreateSecret(ctx, gomock.Any(), gomock.Any()).
+						DoAndReturn(func(ctx context.Context, secret domain.Secret, r io.Reader) error {
+							data, _ := io.ReadAll(r)
+							assert.JSONEq(t, `{"Login":"otheruser","Password":"testpass"}`, string(data))
+							return nil
+						}),
+				)
+			},
+			expectedOutput: "Successfully stored credentials for 'testcreds'\n",
+		},
+		{
+			name:   "fail on conflict",
+			policy: "fail",
+			setupMock: func() {
+				mockSecretService.EXPECT().
+					CreateSecret(ctx, gomock.Any(), gomock.Any()).
+					Return(conflict)
+			},
+			expectedError: errors.New("secret 'testcreds' was changed on the server"),
+		},
+		{
+			name:          "unknown policy",
+			policy:        "ours",
+			expectedError: errors.New("unknown conflict policy 'ours'"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
 			if tt.setupMock != nil {
 				tt.setupMock()
 			}
 
-			cmd.SetArgs(tt.args)
+			cmd.SetArgs(append(args, "--on-conflict", tt.policy))
 			output, err := executeCommand(cmd)
 
 			if tt.expectedError != nil {
@@ -642,7 +3450,7 @@ func TestCLI_GetPaymentCardSecretCmd(t *testing.T) {
 	}
 }
 
-func TestCLI_GetTextSecretCmd(t *testing.T) {
+func TestCLI_CreateSecretConflictErrors(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
@@ -650,7 +3458,63 @@ func TestCLI_GetTextSecretCmd(t *testing.T) {
 	mockSecretService := mocks.NewMockSecretService(ctrl)
 
 	ctx := context.Background()
-	cmd := cli.NewCLI(ctx, mockSecretService, mockAuthService)
+	filePath := filepath.Join(t.TempDir(), "report.pdf")
+	require.NoError(t, os.WriteFile(filePath, []byte("report"), 0600))
+
+	conflict := &domain.VersionConflictError{Name: "s", BaseVersion: 1, LatestVersion: 2}
+
+	tests := []struct {
+		name          string
+		args          []string
+		expectedError string
+	}{
+		{
+			name:          "payment card",
+			args:          []string{"create-paymentcard", "-n", "s", "--number", "4111111111111111"},
+			expectedError: "failed to create secret: secret 's' was changed on the server",
+		},
+		{
+			name:          "text",
+			args:          []string{"create-text", "-n", "s"},
+			expectedError: "failed to store text: secret 's' was changed on the server",
+		},
+		{
+			name:          "file",
+			args:          []string{"create-file", "-n", "s", "-f", filePath},
+			expectedError: "failed to store file '" + filePath + "' in secret storage: secret 's' was changed on the server",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSecretService.EXPECT().CreateSecret(ctx, gomock.Any(), gomock.Any()).Return(conflict)
+
+			cmd := cli.NewCLI(ctx, mockSecretService, mockAuthService)
+			cmd.SetArgs(append(tt.args, "--base-version", "1", "--on-conflict", "fail"))
+			_, err := executeCommand(cmd)
+			assert.ErrorIs(t, err, domain.ErrVersionConflict)
+			assert.ErrorContains(t, err, tt.expectedError)
+		})
+	}
+
+	t.Run("unknown policy", func(t *testing.T) {
+		cmd := cli.NewCLI(ctx, mockSecretService, mockAuthService)
+		cmd.SetArgs([]string{"create-text", "-n", "s", "--on-conflict", "ours"})
+		_, err := executeCommand(cmd)
+		assert.ErrorIs(t, err, domain.ErrUnknownConflictPolicy)
+	})
+}
+
+func TestCLI_ProfileCmd(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuthService := mocks.NewMockAuthService(ctrl)
+	mockSecretService := mocks.NewMockSecretService(ctrl)
+	mockProfileService := mocks.NewMockProfileService(ctrl)
+
+	ctx := context.Background()
+	cmd := cli.NewCLI(ctx, mockSecretService, mockAuthService, cli.WithProfileService(mockProfileService))
 
 	tests := []struct {
 		name           string
@@ -660,36 +3524,71 @@ func TestCLI_GetTextSecretCmd(t *testing.T) {
 		expectedError  error
 	}{
 		{
-			name: "successful get text",
-			args: []string{"get-text", "-n", "testtext"},
+			name: "list profiles",
+			args: []string{"profile", "list"},
 			setupMock: func() {
-				mockSecretService.EXPECT().
-					GetLatestSecretStream(ctx, "testtext").
-					Return(
-						io.NopCloser(strings.NewReader("text content")),
-						&domain.SecretInfo{
-							Name:    "testtext",
-							Version: 1,
-						},
-						nil,
-					)
+				mockProfileService.EXPECT().ListProfiles(ctx).Return([]domain.Profile{
+					{Name: "prod", ServerAddr: "prod.example.com:443"},
+					{Name: "staging", ServerAddr: "staging.example.com:443"},
+				}, "staging", nil)
 			},
-			expectedOutput: "Name: testtext\nVersion: 1\n\nContent:\ntext content",
+			expectedOutput: "  default  (from configuration)\n" +
+				"  prod     prod.example.com:443\n" +
+				"* staging  staging.example.com:443\n",
+		},
+		{
+			name: "add and use profile",
+			args: []string{"profile", "add", "staging", "--address", "staging.example.com:443",
+				"--request-timeout", "5s", "--use"},
+			setupMock: func() {
+				gomock.InOrder(
+					mockProfileService.EXPECT().AddProfile(ctx, domain.Profile{
+						Name: "staging", ServerAddr: "staging.example.com:443", Timeout: 5 * time.Second,
+					}).Return(nil),
+					mockProfileService.EXPECT().UseProfile(ctx, "staging").Return(nil),
+				)
+			},
+			expectedOutput: "Profile 'staging' added\nSwitched to profile 'staging'\n",
+		},
+		{
+			name: "add existing profile",
+			args: []string{"profile", "add", "prod", "--address", "prod.example.com:443", "--request-timeout", "0", "--use=false"},
+			setupMock: func() {
+				mockProfileService.EXPECT().AddProfile(ctx, domain.Profile{Name: "prod", ServerAddr: "prod.example.com:443"}).
+					Return(fmt.Errorf("repo.GetProfile: %w", domain.ErrProfileAlreadyExists))
+			},
+			expectedError: errors.New("profile already exists: 'prod'"),
+		},
+		{
+			name: "use unknown profile",
+			args: []string{"profile", "use", "unknown"},
+			setupMock: func() {
+				mockProfileService.EXPECT().UseProfile(ctx, "unknown").
+					Return(fmt.Errorf("repo.GetProfile: %w", domain.ErrProfileNotFound))
+			},
+			expectedError: errors.New("profile not found: 'unknown'"),
+		},
+		{
+			name: "remove profile",
+			args: []string{"profile", "remove", "staging"},
+			setupMock: func() {
+				mockProfileService.EXPECT().RemoveProfile(ctx, "staging").Return(nil)
+			},
+			expectedOutput: "Profile 'staging' removed\n",
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if tt.setupMock != nil {
-				tt.setupMock()
-			}
+			tt.setupMock()
 
 			cmd.SetArgs(tt.args)
+
 			output, err := executeCommand(cmd)
 
 			if tt.expectedError != nil {
 				assert.Error(t, err)
-				assert.Contains(t, err.Error(), tt.expectedError.Error())
+				assert.Equal(t, tt.expectedError.Error(), err.Error())
 			} else {
 				assert.NoError(t, err)
 				assert.Equal(t, tt.expectedOutput, output)
@@ -698,18 +3597,70 @@ func TestCLI_GetTextSecretCmd(t *testing.T) {
 	}
 }
 
-func TestCLI_GetFileSecretCmd(t *testing.T) {
+func TestParseGlobalFlags(t *testing.T) {
+	tests := []struct {
+		name            string
+		args            []string
+		expectedProfile string
+		expectedServer  string
+		expectedCommand string
+	}{
+		{
+			name:            "profile before command",
+			args:            []string{"--profile", "staging", "get-text", "-n", "note"},
+			expectedProfile: "staging",
+			expectedCommand: "get-text",
+		},
+		{
+			name:            "profile after command flags",
+			args:            []string{"get-text", "--name", "note", "-v", "2", "--profile=prod"},
+			expectedProfile: "prod",
+			expectedCommand: "get-text",
+		},
+		{
+			name:            "no profile",
+			args:            []string{"list", "--help"},
+			expectedCommand: "list",
+		},
+		{
+			name:            "global overrides",
+			args:            []string{"--server", "example.com:443", "--timeout", "5s", "config", "view", "--profile", "prod"},
+			expectedProfile: "prod",
+			expectedServer:  "example.com:443",
+			expectedCommand: "config",
+		},
+		{
+			name:            "profile add settings are not overrides",
+			args:            []string{"profile", "add", "staging", "--address", "staging.example.com:443", "--cert", "ca.crt"},
+			expectedCommand: "profile",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("GOPHKEEPER_PROFILE", "")
+
+			globals, err := cli.ParseGlobalFlags(tt.args)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedProfile, globals.Profile)
+			assert.Equal(t, tt.expectedServer, globals.Server)
+			assert.Equal(t, tt.expectedCommand, globals.Command())
+		})
+	}
+}
+
+func TestCLI_ConfigCmd(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	mockAuthService := mocks.NewMockAuthService(ctrl)
 	mockSecretService := mocks.NewMockSecretService(ctrl)
+	mockConfigService := mocks.NewMockConfigService(ctrl)
 
 	ctx := context.Background()
-	cmd := cli.NewCLI(ctx, mockSecretService, mockAuthService)
+	cmd := cli.NewCLI(ctx, mockSecretService, mockAuthService, cli.WithConfigService(mockConfigService))
 
-	// Clean up test files after
-	defer os.Remove("testfile")
+	mockConfigService.EXPECT().Path().Return("/home/user/.config/gophkeeper-cli/config.yaml").AnyTimes()
 
 	tests := []struct {
 		name           string
@@ -719,49 +3670,70 @@ func TestCLI_GetFileSecretCmd(t *testing.T) {
 		expectedError  error
 	}{
 		{
-			name: "successful get file",
-			args: []string{"get-file-secret", "-n", "testfile"},
+			name: "view",
+			args: []string{"config", "view"},
 			setupMock: func() {
-				mockSecretService.EXPECT().
-					GetLatestSecretStream(ctx, "testfile").
-					Return(
-						io.NopCloser(strings.NewReader("file content")),
-						&domain.SecretInfo{
-							Name:    "testfile",
-							Version: 1,
-						},
-						nil,
-					)
+				mockConfigService.EXPECT().ListSettings(ctx).Return([]domain.ConfigSetting{
+					{Key: "server", Value: "example.com:443"},
+					{Key: "timeout", Value: "30s"},
+				}, nil)
 			},
-			expectedOutput: "Successfully downloaded 'testfile' version 1\n",
+			expectedOutput: "# /home/user/.config/gophkeeper-cli/config.yaml\nserver: example.com:443\ntimeout: 30s\n",
+		},
+		{
+			name: "get",
+			args: []string{"config", "get", "server"},
+			setupMock: func() {
+				mockConfigService.EXPECT().GetSetting(ctx, "server").Return("example.com:443", nil)
+			},
+			expectedOutput: "example.com:443\n",
+		},
+		{
+			name: "set",
+			args: []string{"config", "set", "timeout", "10s"},
+			setupMock: func() {
+				mockConfigService.EXPECT().SetSetting(ctx, "timeout", "10s").Return(nil)
+			},
+			expectedOutput: "Set timeout in /home/user/.config/gophkeeper-cli/config.yaml\n",
+		},
+		{
+			name: "set unknown key",
+			args: []string{"config", "set", "color", "blue"},
+			setupMock: func() {
+				mockConfigService.EXPECT().SetSetting(ctx, "color", "blue").Return(domain.ErrUnknownConfigKey)
+			},
+			expectedError: domain.ErrUnknownConfigKey,
+		},
+		{
+			name: "validate",
+			args: []string{"config", "validate"},
+			setupMock: func() {
+				mockConfigService.EXPECT().Validate(ctx).Return(nil)
+			},
+			expectedOutput: "Configuration is valid\n",
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if tt.setupMock != nil {
-				tt.setupMock()
-			}
+			tt.setupMock()
 
 			cmd.SetArgs(tt.args)
+
 			output, err := executeCommand(cmd)
 
 			if tt.expectedError != nil {
 				assert.Error(t, err)
-				assert.Contains(t, err.Error(), tt.expectedError.Error())
+				assert.Equal(t, tt.expectedError.Error(), err.Error())
 			} else {
 				assert.NoError(t, err)
 				assert.Equal(t, tt.expectedOutput, output)
-				if tt.name == "successful get file" {
-					content, _ := os.ReadFile("testfile")
-					assert.Equal(t, "file content", string(content))
-				}
 			}
 		})
 	}
 }
 
-func TestCLI_DeleteSecretCmd(t *testing.T) {
+func TestCLI_OutputFormats(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
@@ -771,69 +3743,122 @@ func TestCLI_DeleteSecretCmd(t *testing.T) {
 	ctx := context.Background()
 	cmd := cli.NewCLI(ctx, mockSecretService, mockAuthService)
 
-	// Mock stdin for confirmation
-	oldStdin := os.Stdin
-	defer func() { os.Stdin = oldStdin }()
+	credentials := &domain.Secret{
+		Info: domain.SecretInfo{
+			Name:      "github",
+			Type:      domain.CredentialsSecretType,
+			Version:   3,
+			Metadata:  "work",
+			CreatedAt: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+		},
+		Data: `{"Login":"octocat","Password":"it's secret"}`,
+	}
 
 	tests := []struct {
 		name           string
 		args           []string
-		input          string
 		setupMock      func()
 		expectedOutput string
 		expectedError  error
 	}{
 		{
-			name:  "successful delete with confirmation",
-			args:  []string{"delete", "-n", "testsecret"},
-			input: "y\n",
+			name: "credentials as json",
+			args: []string{"get-credentials", "-n", "github", "-o", "json"},
 			setupMock: func() {
-				mockSecretService.EXPECT().
-					DeleteSecret(ctx, "testsecret").
-					Return(nil)
+				mockSecretService.EXPECT().GetLatestSecret(ctx, "github").Return(credentials, nil)
 			},
-			expectedOutput: "Are you sure you want to delete 'testsecret'? (y/n): Successfully deleted 'testsecret'\n",
+			expectedOutput: `{
+  "schema_version": 1,
+  "kind": "secret",
+  "data": {
+    "name": "github",
+    "type": "credentials",
+    "version": 3,
+    "metadata": "work",
+    "created_at": "2024-05-01T10:00:00Z",
+    "credentials": {
+      "login": "octocat",
+      "password": "it's secret"
+    }
+  }
+}
+`,
 		},
 		{
-			name:           "delete cancelled",
-			args:           []string{"delete", "-n", "testsecret"},
-			input:          "n\n",
-			expectedOutput: "Are you sure you want to delete 'testsecret'? (y/n): Deletion cancelled\n",
+			name: "credentials as yaml",
+			args: []string{"get-credentials", "-n", "github", "-o", "yaml"},
+			setupMock: func() {
+				mockSecretService.EXPECT().GetLatestSecret(ctx, "github").Return(credentials, nil)
+			},
+			expectedOutput: `schema_version: 1
+kind: secret
+data:
+  name: github
+  type: credentials
+  version: 3
+  metadata: work
+  created_at: 2024-05-01T10:00:00Z
+  credentials:
+    login: octocat
+    password: it's secret
+`,
 		},
 		{
-			name:  "delete error",
-			args:  []string{"delete", "-n", "testsecret"},
-			input: "y\n",
+			name: "credentials as env",
+			args: []string{"get-credentials", "-n", "github", "-o", "env"},
 			setupMock: func() {
-				mockSecretService.EXPECT().
-					DeleteSecret(ctx, "testsecret").
-					Return(fmt.Errorf("delete error"))
+				mockSecretService.EXPECT().GetLatestSecret(ctx, "github").Return(credentials, nil)
 			},
-			expectedError: errors.New("failed to delete secret 'testsecret'"),
+			expectedOutput: "GK_NAME='github'\nGK_TYPE='credentials'\nGK_VERSION='3'\nGK_METADATA='work'\n" +
+				"GK_CREATED_AT='2024-05-01T10:00:00Z'\nGK_LOGIN='octocat'\nGK_PASSWORD='it'\\''s secret'\n",
+		},
+		{
+			name: "credentials as table",
+			args: []string{"get-credentials", "-n", "github", "-o", "table"},
+			setupMock: func() {
+				mockSecretService.EXPECT().GetLatestSecret(ctx, "github").Return(credentials, nil)
+			},
+			expectedOutput: "NAME    TYPE         VERSION  METADATA  CREATED_AT            LOGIN    PASSWORD\n" +
+				"github  credentials  3        work      2024-05-01T10:00:00Z  octocat  it's secret\n",
+		},
+		{
+			name: "empty list as json",
+			args: []string{"list", "-o", "json"},
+			setupMock: func() {
+				mockSecretService.EXPECT().ListSecretsInfo(ctx).Return(nil, nil)
+			},
+			expectedOutput: "{\n  \"schema_version\": 1,\n  \"kind\": \"secret_list\",\n  \"data\": []\n}\n",
+		},
+		{
+			name: "list as env",
+			args: []string{"list", "-o", "env", "--columns", "name,version"},
+			setupMock: func() {
+				mockSecretService.EXPECT().ListSecretsInfo(ctx).Return([]domain.SecretInfo{
+					{Name: "b", Type: domain.TextSecretType, Version: 1},
+					{Name: "a", Type: domain.TextSecretType, Version: 4},
+				}, nil)
+			},
+			expectedOutput: "GK_COUNT='2'\nGK_0_NAME='a'\nGK_0_VERSION='4'\nGK_1_NAME='b'\nGK_1_VERSION='1'\n",
+		},
+		{
+			name:          "unknown format",
+			args:          []string{"get-credentials", "-n", "github", "-o", "xml"},
+			setupMock:     func() {},
+			expectedError: errors.New("unknown output format 'xml' (must be text, json, yaml, table or env)"),
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if tt.input != "" {
-				r, w, _ := os.Pipe()
-				os.Stdin = r
-				go func() {
-					w.Write([]byte(tt.input))
-					w.Close()
-				}()
-			}
-
-			if tt.setupMock != nil {
-				tt.setupMock()
-			}
+			tt.setupMock()
 
 			cmd.SetArgs(tt.args)
+
 			output, err := executeCommand(cmd)
 
 			if tt.expectedError != nil {
 				assert.Error(t, err)
-				assert.Contains(t, err.Error(), tt.expectedError.Error())
+				assert.Equal(t, tt.expectedError.Error(), err.Error())
 			} else {
 				assert.NoError(t, err)
 				assert.Equal(t, tt.expectedOutput, output)
@@ -841,41 +3866,3 @@ func TestCLI_DeleteSecretCmd(t *testing.T) {
 		})
 	}
 }
-
-// executeCommand executes the command and returns the output
-func executeCommand(cmd *cobra.Command) (string, error) {
-	// Backup the original stdout and stderr
-	oldStdout := os.Stdout
-	oldStderr := os.Stderr
-	defer func() {
-		os.Stdout = oldStdout
-		os.Stderr = oldStderr
-	}()
-
-	// Create pipes to capture output
-	rOut, wOut, _ := os.Pipe()
-	rErr, wErr, _ := os.Pipe()
-	os.Stdout = wOut
-	os.Stderr = wErr
-	cmd.SetOut(wOut)
-	cmd.SetErr(wErr)
-
-	// Channel to collect the output
-	outChan := make(chan string)
-	go func() {
-		var buf bytes.Buffer
-		_, _ = io.Copy(&buf, rOut)
-		_, _ = io.Copy(&buf, rErr)
-		outChan <- buf.String()
-	}()
-
-	// Execute the command
-	err := cmd.Execute()
-
-	// Close the writers and wait for output
-	wOut.Close()
-	wErr.Close()
-	output := <-outChan
-
-	return output, err
-}
@@ -0,0 +1,136 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+	"unicode/utf8"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/domain"
+)
+
+// maxClipboardSize is the largest content that is copied, terminals drop longer OSC 52 sequences.
+const maxClipboardSize = 64 * 1024
+
+// clipboardSource opens the clipboard used by the --copy flag of the get commands.
+type clipboardSource struct {
+	defaultBackend    string
+	defaultClearAfter time.Duration
+	open              func(backend string) (domain.Clipboard, error)
+}
+
+// copyFlags holds the clipboard options of a get command.
+type copyFlags struct {
+	copy       bool
+	field      string
+	clearAfter time.Duration
+	backend    string
+}
+
+// addCopyFlags registers the clipboard flags on the command. The --field flag is only
+// registered when fields to choose from are given, otherwise defaultField is always copied.
+func addCopyFlags(cmd *cobra.Command, f *copyFlags, clipboard clipboardSource, defaultField string, fields ...string) {
+	cmd.Flags().BoolVar(&f.copy, "copy", false, "Copy a field to the clipboard instead of printing the secret")
+	if len(fields) > 0 {
+		cmd.Flags().StringVar(&f.field, "field", defaultField,
+			fmt.Sprintf("Field to copy with --copy (%s)", strings.Join(fields, ", ")))
+	} else {
+		f.field = defaultField
+	}
+	cmd.Flags().DurationVar(&f.clearAfter, "clear-after", clipboard.defaultClearAfter,
+		"Clear the clipboard after this delay, 0 leaves the value on the clipboard")
+	cmd.Flags().StringVar(&f.backend, "clipboard", "",
+		"Clipboard backend: osc52, xclip, wl-copy or auto (default: the clipboard setting)")
+}
+
+// copyColumn copies the value of the selected field among the columns of a secret view.
+func (s clipboardSource) copyColumn(ctx context.Context, cmd *cobra.Command, f copyFlags, name string, columns []column) error {
+	keys := make([]string, len(columns))
+	for i, c := range columns {
+		if c.key == f.field {
+			if c.value == "" {
+				return fmt.Errorf("field '%s' of '%s' is empty", f.field, name)
+			}
+			return s.copy(ctx, cmd, f, name, c.value)
+		}
+		keys[i] = c.key
+	}
+	return fmt.Errorf("unknown field '%s' (must be one of %s)", f.field, strings.Join(keys, ", "))
+}
+
+// copyContent copies the content of a text or file secret.
+// Only UTF-8 text up to maxClipboardSize can be copied.
+func (s clipboardSource) copyContent(ctx context.Context, cmd *cobra.Command, f copyFlags, name string, r io.Reader) error {
+	content, err := io.ReadAll(io.LimitReader(r, maxClipboardSize+1))
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to read secret data")
+		return fmt.Errorf("failed to read secret data")
+	}
+	if len(content) > maxClipboardSize {
+		return fmt.Errorf("'%s' is larger than %d bytes and cannot be copied to the clipboard", name, maxClipboardSize)
+	}
+	if !utf8.Valid(content) {
+		return fmt.Errorf("'%s' is not text and cannot be copied to the clipboard", name)
+	}
+	return s.copy(ctx, cmd, f, name, string(content))
+}
+
+// copy puts the value on the clipboard and clears it after the delay, unless something else was copied since.
+// The value is never written to stdout; progress is reported on stderr.
+// Interrupting the command while it waits clears the clipboard right away.
+func (s clipboardSource) copy(ctx context.Context, cmd *cobra.Command, f copyFlags, name, value string) error {
+	if s.open == nil {
+		return fmt.Errorf("clipboard is not available")
+	}
+	backend := f.backend
+	if backend == "" {
+		backend = s.defaultBackend
+	}
+	if f.clearAfter < 0 {
+		return fmt.Errorf("invalid --clear-after %s (must not be negative)", f.clearAfter)
+	}
+
+	clipboard, err := s.open(backend)
+	if err != nil {
+		return err
+	}
+	if err := clipboard.Copy(value); err != nil {
+		log.Error().Err(err).Msg("Failed to copy to the clipboard")
+		return fmt.Errorf("failed to copy to the clipboard")
+	}
+
+	stderr := cmd.ErrOrStderr()
+	if f.clearAfter == 0 {
+		fmt.Fprintf(stderr, "Copied %s of '%s' to the clipboard\n", f.field, name)
+		return nil
+	}
+	fmt.Fprintf(stderr, "Copied %s of '%s' to the clipboard, clearing in %s\n", f.field, name, f.clearAfter)
+
+	waitCtx, stop := signal.NotifyContext(untilCanceled(ctx), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	timer := time.NewTimer(f.clearAfter)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-waitCtx.Done():
+	}
+
+	cleared, err := clipboard.Clear(value)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to clear the clipboard")
+		return fmt.Errorf("failed to clear the clipboard")
+	}
+	if !cleared {
+		fmt.Fprintln(stderr, "Clipboard not cleared, it was changed since")
+		return nil
+	}
+	fmt.Fprintln(stderr, "Clipboard cleared")
+	return nil
+}
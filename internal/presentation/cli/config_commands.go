@@ -0,0 +1,126 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/domain"
+)
+
+// newConfigCmd creates a cobra command grouping configuration commands
+// ctx: Context for request cancellation and timeouts
+// configService: Configuration service interface
+// Returns: Configured cobra.Command with config subcommands
+func newConfigCmd(ctx context.Context, configService domain.ConfigService) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "View and edit the configuration",
+		Long: `View and edit the configuration file.
+
+Settings are applied in order: config file, environment variables, profile
+and the global --server, --timeout, --tls-cert and --log-level flags.`,
+	}
+
+	cmd.AddCommand(
+		newConfigViewCmd(ctx, configService),
+		newConfigGetCmd(ctx, configService),
+		newConfigSetCmd(ctx, configService),
+		newConfigValidateCmd(ctx, configService),
+	)
+	return cmd
+}
+
+// newConfigViewCmd creates a cobra command that prints the effective configuration
+func newConfigViewCmd(ctx context.Context, configService domain.ConfigService) *cobra.Command {
+	return &cobra.Command{
+		Use:   "view",
+		Short: "Show the effective configuration",
+		Args:  cobra.NoArgs,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			settings, err := configService.ListSettings(ctx)
+			if err != nil {
+				log.Error().Err(err).Msg("failed to list settings")
+				return fmt.Errorf("failed to list settings")
+			}
+
+			return renderOne(cmd, configOutputKind, configView(settings), func(w io.Writer) error {
+				fmt.Fprintf(w, "# %s\n", configService.Path())
+				for _, s := range settings {
+					fmt.Fprintf(w, "%s: %s\n", s.Key, s.Value)
+				}
+				return nil
+			})
+		},
+	}
+}
+
+// newConfigGetCmd creates a cobra command that prints a single effective setting
+func newConfigGetCmd(ctx context.Context, configService domain.ConfigService) *cobra.Command {
+	return &cobra.Command{
+		Use:   "get <key>",
+		Short: "Show the effective value of a setting",
+		Args:  cobra.ExactArgs(1),
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			value, err := configService.GetSetting(ctx, args[0])
+			if err != nil {
+				log.Error().Err(err).Msg("failed to get setting")
+				return configError(err, "failed to get setting")
+			}
+
+			fmt.Fprintln(cmd.OutOrStdout(), value)
+			return nil
+		},
+	}
+}
+
+// newConfigSetCmd creates a cobra command that stores a setting in the config file
+func newConfigSetCmd(ctx context.Context, configService domain.ConfigService) *cobra.Command {
+	return &cobra.Command{
+		Use:   "set <key> <value>",
+		Short: "Store a setting in the config file",
+		Args:  cobra.ExactArgs(2),
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := configService.SetSetting(ctx, args[0], args[1]); err != nil {
+				log.Error().Err(err).Msg("failed to set setting")
+				return configError(err, "failed to set setting")
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Set %s in %s\n", args[0], configService.Path())
+			return nil
+		},
+	}
+}
+
+// newConfigValidateCmd creates a cobra command that validates the configuration
+func newConfigValidateCmd(ctx context.Context, configService domain.ConfigService) *cobra.Command {
+	return &cobra.Command{
+		Use:   "validate",
+		Short: "Validate the config file and the effective configuration",
+		Args:  cobra.NoArgs,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := configService.Validate(ctx); err != nil {
+				log.Error().Err(err).Msg("invalid configuration")
+				return configError(err, "failed to validate configuration")
+			}
+
+			fmt.Fprintln(cmd.OutOrStdout(), "Configuration is valid")
+			return nil
+		},
+	}
+}
+
+// configError converts configuration service errors into user facing errors.
+func configError(err error, msg string) error {
+	if errors.Is(err, domain.ErrUnknownConfigKey) || errors.Is(err, domain.ErrInvalidConfig) {
+		return err
+	}
+	return errors.New(msg)
+}
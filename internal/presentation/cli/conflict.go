@@ -0,0 +1,133 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/domain"
+)
+
+// conflictFlags holds flags shared by commands that create new secret versions.
+type conflictFlags struct {
+	policy      string
+	baseVersion int32
+}
+
+// addConflictFlags registers version conflict flags on the command.
+func addConflictFlags(cmd *cobra.Command, f *conflictFlags) {
+	cmd.Flags().StringVar(&f.policy, "on-conflict", string(domain.PromptConflictPolicy),
+		"How to resolve version conflicts: prompt, mine, theirs, merge or fail")
+	cmd.Flags().Int32Var(&f.baseVersion, "base-version", 0,
+		"Version the change is based on (default: last version seen on this device)")
+}
+
+// baseOn sets the version the change is based on unless it was given with --base-version.
+func (f *conflictFlags) baseOn(cmd *cobra.Command, version int32) {
+	if !cmd.Flags().Changed("base-version") {
+		f.baseVersion = version
+	}
+}
+
+// storeSecret creates a new secret version and resolves version conflicts according to the policy.
+// Returns false if the server version was kept and nothing was stored.
+func storeSecret(ctx context.Context, cmd *cobra.Command, secretService domain.SecretService,
+	secret domain.Secret, content io.Reader, flags conflictFlags) (bool, error) {
+	policy, err := domain.ParseConflictPolicy(flags.policy)
+	if err != nil {
+		return false, err
+	}
+
+	secret.BaseVersion = flags.baseVersion
+	// Secrets never seen on this device only conflict if a policy was asked for
+	secret.CheckUnseen = cmd.Flags().Changed("on-conflict")
+	err = secretService.CreateSecret(ctx, secret, content)
+
+	var conflict *domain.VersionConflictError
+	if !errors.As(err, &conflict) {
+		return err == nil, err
+	}
+
+	if policy == domain.PromptConflictPolicy {
+		if policy, err = promptConflictPolicy(conflict); err != nil {
+			return false, err
+		}
+	}
+
+	secret.BaseVersion = conflict.LatestVersion
+
+	switch policy {
+	case domain.KeepMineConflictPolicy:
+		return true, secretService.CreateSecret(ctx, secret, content)
+	case domain.KeepTheirsConflictPolicy:
+		fmt.Fprintf(cmd.OutOrStdout(), "Kept server version %d of '%s'\n", conflict.LatestVersion, conflict.Name)
+		return false, nil
+	case domain.MergeConflictPolicy:
+		if conflict.Theirs == nil {
+			return false, fmt.Errorf("%w: merge is only supported for credentials and payment cards", conflict)
+		}
+
+		mine, err := io.ReadAll(content)
+		if err != nil {
+			return false, fmt.Errorf("failed to read secret content: %w", err)
+		}
+
+		var base string
+		if conflict.Base != nil {
+			base = conflict.Base.Data
+		}
+		merged, conflictingFields, err := domain.MergeSecretData(base, string(mine), conflict.Theirs.Data)
+		if err != nil {
+			return false, fmt.Errorf("failed to merge versions: %w", err)
+		}
+		if len(conflictingFields) > 0 {
+			fmt.Fprintf(cmd.OutOrStdout(), "Fields changed on both sides, kept local values: %s\n",
+				strings.Join(conflictingFields, ", "))
+		}
+
+		return true, secretService.CreateSecret(ctx, secret, bytes.NewReader([]byte(merged)))
+	default:
+		return false, conflict
+	}
+}
+
+// storeSecretError returns the error of a command whose storeSecret call failed.
+// Version conflicts and unknown policies are kept, so the user can tell them from transport
+// failures and resolve them; other failures are reported with msg only.
+func storeSecretError(err error, msg string) error {
+	if errors.Is(err, domain.ErrUnknownConflictPolicy) {
+		return err
+	}
+	if errors.Is(err, domain.ErrVersionConflict) {
+		return fmt.Errorf("%s: %w", msg, err)
+	}
+	return errors.New(msg)
+}
+
+// promptConflictPolicy asks the user how to resolve a version conflict.
+func promptConflictPolicy(conflict *domain.VersionConflictError) (domain.ConflictPolicy, error) {
+	question := "Keep [m]ine, keep [t]heirs, me[r]ge or [a]bort? "
+	if conflict.Theirs == nil {
+		question = "Keep [m]ine, keep [t]heirs or [a]bort? "
+	}
+
+	answer, err := promptLine(conflict.Error() + "\n" + question)
+	if err != nil {
+		return "", fmt.Errorf("%w: use --on-conflict to resolve it non-interactively", conflict)
+	}
+
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "m", "mine":
+		return domain.KeepMineConflictPolicy, nil
+	case "t", "theirs":
+		return domain.KeepTheirsConflictPolicy, nil
+	case "r", "merge":
+		return domain.MergeConflictPolicy, nil
+	default:
+		return domain.FailConflictPolicy, nil
+	}
+}
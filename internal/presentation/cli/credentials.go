@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/domain"
+)
+
+// credentialsFlags holds credentials fields shared by the create and update commands.
+type credentialsFlags struct {
+	login, password, url, notes, importURI string
+	generate                               bool
+	generator                              generatorFlags
+}
+
+// addCredentialsFlags registers credentials flags on the command.
+func addCredentialsFlags(cmd *cobra.Command, f *credentialsFlags) {
+	cmd.Flags().StringVarP(&f.login, "login", "l", "", "Username/login")
+	cmd.Flags().StringVarP(&f.password, "password", "p", "", "Password")
+	cmd.Flags().StringVar(&f.url, "url", "", "Website or service URL")
+	cmd.Flags().StringVar(&f.notes, "notes", "", "Notes")
+	cmd.Flags().StringVar(&f.importURI, "import-uri", "",
+		"otpauth:// URI with a TOTP or HOTP seed; its account name is used as login if none is given")
+	cmd.Flags().BoolVar(&f.generate, "generate", false, "Generate a random password instead of --password")
+	addGeneratorFlags(cmd, &f.generator)
+
+	cmd.MarkFlagsMutuallyExclusive("password", "generate")
+}
+
+// credentialsFlagNames are the names of the flags registered by addCredentialsFlags.
+var credentialsFlagNames = []string{"login", "password", "generate", "url", "notes", "import-uri"}
+
+// apply sets the credentials fields given on the command line.
+// An empty --import-uri removes the one-time password seed.
+// The estimated entropy of a generated password is reported on stderr.
+func (f credentialsFlags) apply(cmd *cobra.Command, credentials *domain.CredentialsSecret) error {
+	changed := cmd.Flags().Changed
+
+	if changed("import-uri") {
+		credentials.OTP = ""
+		if f.importURI != "" {
+			key, err := domain.ParseOTPURI(f.importURI)
+			if err != nil {
+				return err
+			}
+			credentials.OTP = key.URI()
+			if !changed("login") && credentials.Login == "" {
+				credentials.Login = key.Account
+			}
+		}
+	}
+	if changed("login") {
+		credentials.Login = f.login
+	}
+	if changed("password") {
+		credentials.Password = f.password
+	}
+	if f.generate {
+		password, entropy, err := f.generator.generate()
+		if err != nil {
+			return err
+		}
+		credentials.Password = password
+		fmt.Fprintf(cmd.ErrOrStderr(), "Generated a password with an estimated entropy of %.0f bits\n", entropy)
+	}
+	if changed("url") {
+		credentials.URL = f.url
+	}
+	if changed("notes") {
+		credentials.Notes = f.notes
+	}
+	return nil
+}
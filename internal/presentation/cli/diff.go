@@ -0,0 +1,344 @@
+package cli
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// maskedValue replaces sensitive values in diffs unless they are revealed.
+const maskedValue = "********"
+
+// diffContextLines is the number of unchanged lines around changes in unified diffs.
+const diffContextLines = 3
+
+// Limits of line diffs: texts larger than maxDiffTextSize or with more than maxDiffCells
+// line pairs are compared by checksum only, as the diff table grows with both line counts.
+const (
+	maxDiffTextSize = 1 << 20
+	maxDiffCells    = 4 << 20
+)
+
+// sensitiveFields are secret payload fields that are masked unless revealed.
+var sensitiveFields = map[string]bool{
+	"Password":   true,
+	"Number":     true,
+	"CVV":        true,
+	"PIN":        true,
+	"OTP":        true,
+	"PrivateKey": true,
+	"Passphrase": true,
+}
+
+// Field change kinds.
+const (
+	fieldAdded   = "added"
+	fieldRemoved = "removed"
+	fieldChanged = "changed"
+)
+
+// fieldChange describes a change of a single field between two secret versions.
+// From is nil for added fields and To is nil for removed ones.
+type fieldChange struct {
+	Field  string  `json:"field" yaml:"field"`
+	Change string  `json:"change" yaml:"change"`
+	From   *string `json:"from,omitempty" yaml:"from,omitempty"`
+	To     *string `json:"to,omitempty" yaml:"to,omitempty"`
+}
+
+// newFieldChange compares a field value in both versions. Returns nil if the value is unchanged.
+func newFieldChange(field string, from, to *string, reveal bool) *fieldChange {
+	switch {
+	case from == nil && to == nil:
+		return nil
+	case from != nil && to != nil && *from == *to:
+		return nil
+	}
+
+	c := &fieldChange{Field: field, Change: fieldChanged, From: from, To: to}
+	switch {
+	case from == nil:
+		c.Change = fieldAdded
+	case to == nil:
+		c.Change = fieldRemoved
+	}
+
+	if sensitiveFields[field] && !reveal {
+		if c.From != nil {
+			c.From = ptr(maskedValue)
+		}
+		if c.To != nil {
+			c.To = ptr(maskedValue)
+		}
+	}
+	return c
+}
+
+// diffJSONFields compares the top-level fields of two JSON objects, ordered by field name.
+func diffJSONFields(from, to string, reveal bool) ([]fieldChange, error) {
+	fromFields, err := jsonFields(from)
+	if err != nil {
+		return nil, err
+	}
+	toFields, err := jsonFields(to)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(fromFields)+len(toFields))
+	for name := range fromFields {
+		names = append(names, name)
+	}
+	for name := range toFields {
+		if _, ok := fromFields[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var changes []fieldChange
+	for _, name := range names {
+		if c := newFieldChange(name, fromFields[name], toFields[name], reveal); c != nil {
+			changes = append(changes, *c)
+		}
+	}
+	return changes, nil
+}
+
+// jsonFields decodes a JSON object into its fields. Empty values are treated as absent.
+func jsonFields(data string) (map[string]*string, error) {
+	var raw map[string]any
+	if err := json.Unmarshal([]byte(data), &raw); err != nil {
+		return nil, fmt.Errorf("failed to decode secret: %w", err)
+	}
+
+	fields := make(map[string]*string, len(raw))
+	for name, value := range raw {
+		var s string
+		switch v := value.(type) {
+		case nil:
+			continue
+		case string:
+			s = v
+		default:
+			encoded, err := json.Marshal(v)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode field '%s': %w", name, err)
+			}
+			s = string(encoded)
+		}
+		if s != "" {
+			fields[name] = &s
+		}
+	}
+	return fields, nil
+}
+
+// fileDigest describes streamed content by its size and SHA-256 checksum.
+type fileDigest struct {
+	Size   int64
+	SHA256 string
+}
+
+// digestContent reads the content and computes its digest.
+func digestContent(r io.Reader) (fileDigest, error) {
+	h := sha256.New()
+	n, err := io.Copy(h, r)
+	if err != nil {
+		return fileDigest{}, err
+	}
+	return fileDigest{Size: n, SHA256: hex.EncodeToString(h.Sum(nil))}, nil
+}
+
+// diffText is streamed text content, kept in memory only up to maxDiffTextSize bytes.
+type diffText struct {
+	text      string
+	digest    fileDigest
+	truncated bool
+}
+
+// readDiffText reads the content for a line diff and computes its digest.
+// Content beyond maxDiffTextSize is only hashed and the text is marked as truncated.
+func readDiffText(r io.Reader) (diffText, error) {
+	h := sha256.New()
+	head, err := io.ReadAll(io.LimitReader(io.TeeReader(r, h), maxDiffTextSize+1))
+	if err != nil {
+		return diffText{}, err
+	}
+	rest, err := io.Copy(h, r)
+	if err != nil {
+		return diffText{}, err
+	}
+
+	t := diffText{
+		digest:    fileDigest{Size: int64(len(head)) + rest, SHA256: hex.EncodeToString(h.Sum(nil))},
+		truncated: len(head) > maxDiffTextSize,
+	}
+	if !t.truncated {
+		t.text = string(head)
+	}
+	return t, nil
+}
+
+// diffable reports whether a line diff of a and b stays within maxDiffCells.
+func diffable(a, b []string) bool {
+	return (len(a)+1)*(len(b)+1) <= maxDiffCells
+}
+
+// diffOp is a line of a line diff: ' ' for unchanged, '-' for removed and '+' for added lines.
+type diffOp struct {
+	kind byte
+	line string
+}
+
+// diffLines computes a minimal line diff of a and b using their longest common subsequence.
+// The table takes (len(a)+1)*(len(b)+1) cells, check diffable first.
+func diffLines(a, b []string) []diffOp {
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	ops := make([]diffOp, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, diffOp{kind: ' ', line: a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, diffOp{kind: '-', line: a[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{kind: '+', line: b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		ops = append(ops, diffOp{kind: '-', line: a[i]})
+	}
+	for ; j < len(b); j++ {
+		ops = append(ops, diffOp{kind: '+', line: b[j]})
+	}
+	return ops
+}
+
+// countChangedLines returns the number of added and removed lines.
+func countChangedLines(ops []diffOp) (added, removed int) {
+	for _, op := range ops {
+		switch op.kind {
+		case '+':
+			added++
+		case '-':
+			removed++
+		}
+	}
+	return added, removed
+}
+
+// unifiedDiff formats a line diff in the unified format with diffContextLines lines of context.
+func unifiedDiff(fromLabel, toLabel string, ops []diffOp) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", fromLabel, toLabel)
+
+	for start := 0; start < len(ops); {
+		// Find the next change and extend the hunk while changes are close enough
+		first := start
+		for first < len(ops) && ops[first].kind == ' ' {
+			first++
+		}
+		if first == len(ops) {
+			break
+		}
+
+		begin := max(first-diffContextLines, start)
+		end := first
+		for unchanged := 0; end < len(ops) && unchanged <= 2*diffContextLines; end++ {
+			if ops[end].kind == ' ' {
+				unchanged++
+			} else {
+				unchanged = 0
+			}
+		}
+		// Trim trailing context to diffContextLines lines
+		for end > first && ops[end-1].kind == ' ' && trailingContext(ops[:end]) > diffContextLines {
+			end--
+		}
+
+		writeHunk(&sb, ops, begin, end)
+		start = end
+	}
+	return sb.String()
+}
+
+// trailingContext returns the number of unchanged lines at the end of ops.
+func trailingContext(ops []diffOp) int {
+	n := 0
+	for i := len(ops) - 1; i >= 0 && ops[i].kind == ' '; i-- {
+		n++
+	}
+	return n
+}
+
+// writeHunk writes ops[begin:end] as a unified diff hunk.
+func writeHunk(sb *strings.Builder, ops []diffOp, begin, end int) {
+	fromLine, toLine := 1, 1
+	for _, op := range ops[:begin] {
+		if op.kind != '+' {
+			fromLine++
+		}
+		if op.kind != '-' {
+			toLine++
+		}
+	}
+
+	fromCount, toCount := 0, 0
+	for _, op := range ops[begin:end] {
+		if op.kind != '+' {
+			fromCount++
+		}
+		if op.kind != '-' {
+			toCount++
+		}
+	}
+	// Empty ranges start at the line before the hunk
+	if fromCount == 0 {
+		fromLine--
+	}
+	if toCount == 0 {
+		toLine--
+	}
+
+	fmt.Fprintf(sb, "@@ -%d,%d +%d,%d @@\n", fromLine, fromCount, toLine, toCount)
+	for _, op := range ops[begin:end] {
+		fmt.Fprintf(sb, "%c%s\n", op.kind, op.line)
+	}
+}
+
+// splitLines splits text into lines without the trailing line break.
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// ptr returns a pointer to the value.
+func ptr[T any](v T) *T {
+	return &v
+}
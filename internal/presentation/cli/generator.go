@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/domain"
+)
+
+// generatorFlags holds password generator options shared by the generate and credentials commands.
+type generatorFlags struct {
+	length                                int
+	noLower, noUpper, noDigits, noSymbols bool
+	excludeAmbiguous, diceware            bool
+	words                                 int
+	separator                             string
+}
+
+// addGeneratorFlags registers password generator flags on the command.
+func addGeneratorFlags(cmd *cobra.Command, f *generatorFlags) {
+	cmd.Flags().IntVar(&f.length, "length", domain.DefaultPasswordPolicy.Length, "Length of the generated password")
+	cmd.Flags().BoolVar(&f.noLower, "no-lower", false, "Do not use lowercase letters")
+	cmd.Flags().BoolVar(&f.noUpper, "no-upper", false, "Do not use uppercase letters")
+	cmd.Flags().BoolVar(&f.noDigits, "no-digits", false, "Do not use digits")
+	cmd.Flags().BoolVar(&f.noSymbols, "no-symbols", false, "Do not use symbols")
+	cmd.Flags().BoolVar(&f.excludeAmbiguous, "exclude-ambiguous", false, "Do not use easily confused characters like l, 1, O and 0")
+	cmd.Flags().BoolVar(&f.diceware, "diceware", false, "Generate a passphrase of words from the EFF wordlist")
+	cmd.Flags().IntVar(&f.words, "words", domain.DefaultPassphrasePolicy.Words, "Number of words of a diceware passphrase")
+	cmd.Flags().StringVar(&f.separator, "separator", domain.DefaultPassphrasePolicy.Separator, "Separator between diceware words")
+}
+
+// generate generates a password or passphrase and returns it with its estimated entropy in bits.
+func (f generatorFlags) generate() (string, float64, error) {
+	if f.diceware {
+		return domain.GeneratePassphrase(domain.PassphrasePolicy{Words: f.words, Separator: f.separator})
+	}
+	return domain.GeneratePassword(domain.PasswordPolicy{
+		Length:           f.length,
+		Lower:            !f.noLower,
+		Upper:            !f.noUpper,
+		Digits:           !f.noDigits,
+		Symbols:          !f.noSymbols,
+		ExcludeAmbiguous: f.excludeAmbiguous,
+	})
+}
+
+// newGenerateCmd creates a command that prints a random password or passphrase.
+func newGenerateCmd() *cobra.Command {
+	var flags generatorFlags
+
+	cmd := &cobra.Command{
+		Use:   "generate",
+		Short: "Generate a random password or passphrase",
+		Long: `Generates a password from the enabled character classes, or a diceware passphrase
+with --diceware. The password is printed on stdout and its estimated entropy on stderr.`,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			password, entropy, err := flags.generate()
+			if err != nil {
+				return err
+			}
+
+			v := newGeneratedPasswordView(password, entropy)
+			return renderOne(cmd, generatedPasswordOutputKind, v, func(w io.Writer) error {
+				fmt.Fprintln(w, password)
+				fmt.Fprintf(cmd.ErrOrStderr(), "Estimated entropy: %.0f bits\n", entropy)
+				return nil
+			})
+		},
+	}
+
+	addGeneratorFlags(cmd, &flags)
+
+	return cmd
+}
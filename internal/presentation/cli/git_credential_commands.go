@@ -0,0 +1,209 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/domain"
+)
+
+// newGitCredentialCmd creates a command that serves git as a credential helper.
+func newGitCredentialCmd(ctx context.Context, secretService domain.SecretService) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "git-credential get|store|erase",
+		Short: "Provide credentials to git as a credential helper",
+		Long: `Speaks the git credential helper protocol on stdin and stdout:
+  git config --global credential.helper '!gophkeeper-cli git-credential'
+A remote is looked up in the credentials secret named git:<protocol>://<host>/<path>, then
+git:<protocol>://<host>, then in a credentials secret whose metadata is one of these URLs.
+The path is only sent by git if credential.useHttpPath is set.
+get prints the login and password of the secret, store creates a new version of it or a new
+git:<url> secret. erase clears the password in a new version of a git:<url> secret if it still
+holds the rejected credentials, so earlier versions stay in its history. Secrets matched by their
+metadata are never erased.
+Remotes without a secret and unknown operations are ignored, so git falls back to other helpers.`,
+		Args:        cobra.ExactArgs(1),
+		ValidArgs:   []string{"get", "store", "erase"},
+		Annotations: map[string]string{secretNamesAnnotation: "true"},
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			credential, err := domain.ParseGitCredential(cmd.InOrStdin())
+			if err != nil {
+				return err
+			}
+			if credential.Protocol == "" || (credential.Host == "" && credential.Path == "") {
+				return nil
+			}
+
+			switch args[0] {
+			case "get":
+				return getGitCredential(ctx, cmd, secretService, credential)
+			case "store":
+				return storeGitCredential(ctx, secretService, credential)
+			case "erase":
+				return eraseGitCredential(ctx, secretService, credential)
+			}
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+// getGitCredential prints the credentials of the remote unless they belong to another user.
+func getGitCredential(ctx context.Context, cmd *cobra.Command, secretService domain.SecretService,
+	credential domain.GitCredential) error {
+	info, credentials, err := findGitCredential(ctx, secretService, credential)
+	if err != nil || credentials == nil {
+		return err
+	}
+	if credential.Username != "" && credentials.Login != credential.Username {
+		return nil
+	}
+	if credentials.Password == "" {
+		return nil
+	}
+
+	found := domain.GitCredential{Username: credentials.Login, Password: credentials.Password}
+	if _, err := found.WriteTo(cmd.OutOrStdout()); err != nil {
+		log.Error().Err(err).Msgf("Failed to write credentials of '%s'", info.Name)
+		return fmt.Errorf("failed to write credentials of '%s'", info.Name)
+	}
+	return nil
+}
+
+// storeGitCredential stores credentials git has used successfully. The matching secret gets a new
+// version if it belongs to the same user or to exactly this remote, otherwise a git:<url> secret is created.
+// Nothing is stored if the credentials did not change.
+func storeGitCredential(ctx context.Context, secretService domain.SecretService, credential domain.GitCredential) error {
+	if credential.Username == "" || credential.Password == "" {
+		return nil
+	}
+
+	info, credentials, err := findGitCredential(ctx, secretService, credential)
+	if err != nil {
+		return err
+	}
+
+	secret := domain.Secret{
+		Info: domain.SecretInfo{
+			Name: credential.SecretName(),
+			Type: domain.CredentialsSecretType,
+		},
+	}
+	updated := domain.CredentialsSecret{URL: credential.URL()}
+	if credentials != nil && (credentials.Login == credential.Username || info.Name == secret.Info.Name) {
+		if credentials.Login == credential.Username && credentials.Password == credential.Password {
+			return nil
+		}
+		secret.Info.Name, secret.Info.Metadata, secret.BaseVersion = info.Name, info.Metadata, info.Version
+		updated = *credentials
+	}
+	updated.Login, updated.Password = credential.Username, credential.Password
+
+	marshaled, err := json.Marshal(updated)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to marshal credentials")
+		return fmt.Errorf("failed to marshal credentials")
+	}
+	if err := secretService.CreateSecret(ctx, secret, bytes.NewReader(marshaled)); err != nil {
+		log.Error().Err(err).Msgf("Failed to store secret '%s'", secret.Info.Name)
+		return fmt.Errorf("failed to store secret '%s'", secret.Info.Name)
+	}
+	return nil
+}
+
+// eraseGitCredential clears the password of the git:<url> secret of the remote if it holds the credentials
+// git rejected. The password is cleared in a new version, so the secret keeps its history.
+func eraseGitCredential(ctx context.Context, secretService domain.SecretService, credential domain.GitCredential) error {
+	info, credentials, err := lookupGitCredential(ctx, secretService, credential)
+	if err != nil || credentials == nil || credentials.Password == "" {
+		return err
+	}
+	if credential.Username != "" && credentials.Login != credential.Username {
+		return nil
+	}
+	if credential.Password != "" && credentials.Password != credential.Password {
+		return nil
+	}
+
+	erased := *credentials
+	erased.Password = ""
+	marshaled, err := json.Marshal(erased)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to marshal credentials")
+		return fmt.Errorf("failed to marshal credentials")
+	}
+	secret := domain.Secret{
+		Info:        domain.SecretInfo{Name: info.Name, Type: info.Type, Metadata: info.Metadata},
+		BaseVersion: info.Version,
+	}
+	if err := secretService.CreateSecret(ctx, secret, bytes.NewReader(marshaled)); err != nil {
+		log.Error().Err(err).Msgf("Failed to erase credentials of '%s'", info.Name)
+		return fmt.Errorf("failed to erase credentials of '%s'", info.Name)
+	}
+	return nil
+}
+
+// findGitCredential retrieves the latest version of the credentials secret of the remote,
+// looking it up by its git:<url> names before listing the secrets to match their metadata.
+// Returns nil credentials if no secret matches.
+func findGitCredential(ctx context.Context, secretService domain.SecretService,
+	credential domain.GitCredential) (domain.SecretInfo, *domain.CredentialsSecret, error) {
+	info, credentials, err := lookupGitCredential(ctx, secretService, credential)
+	if err != nil || credentials != nil {
+		return info, credentials, err
+	}
+
+	infos, err := secretService.ListSecretsInfo(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list secrets")
+		return domain.SecretInfo{}, nil, fmt.Errorf("failed to list secrets")
+	}
+	info, ok := domain.MatchGitCredential(infos, credential)
+	if !ok {
+		return domain.SecretInfo{}, nil, nil
+	}
+	return loadGitCredential(ctx, secretService, info)
+}
+
+// lookupGitCredential retrieves the latest version of the credentials secret named git:<url> after the remote,
+// or after its host. Returns nil credentials if there is none.
+func lookupGitCredential(ctx context.Context, secretService domain.SecretService,
+	credential domain.GitCredential) (domain.SecretInfo, *domain.CredentialsSecret, error) {
+	for _, name := range credential.SecretNames() {
+		info, err := secretService.GetSecretInfo(ctx, name, 0)
+		if errors.Is(err, domain.ErrSecretNotFound) {
+			continue
+		}
+		if err != nil {
+			log.Error().Err(err).Msgf("Failed to retrieve secret '%s'", name)
+			return domain.SecretInfo{}, nil, fmt.Errorf("failed to retrieve secret '%s'", name)
+		}
+		if info.Type == domain.CredentialsSecretType {
+			return loadGitCredential(ctx, secretService, *info)
+		}
+	}
+	return domain.SecretInfo{}, nil, nil
+}
+
+// loadGitCredential retrieves the latest version of a credentials secret.
+func loadGitCredential(ctx context.Context, secretService domain.SecretService,
+	info domain.SecretInfo) (domain.SecretInfo, *domain.CredentialsSecret, error) {
+	secret, err := secretService.GetLatestSecret(ctx, info.Name)
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed to retrieve secret '%s'", info.Name)
+		return domain.SecretInfo{}, nil, fmt.Errorf("failed to retrieve secret '%s'", info.Name)
+	}
+	var credentials domain.CredentialsSecret
+	if err := json.Unmarshal([]byte(secret.Data), &credentials); err != nil {
+		log.Error().Err(err).Msg("Failed to decode credentials")
+		return domain.SecretInfo{}, nil, fmt.Errorf("failed to decode credentials")
+	}
+	return info, &credentials, nil
+}
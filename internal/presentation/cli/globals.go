@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"io"
+	"os"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+// GlobalFlags holds flags that affect how the application is wired
+// and therefore have to be known before the commands are built.
+type GlobalFlags struct {
+	Profile    string
+	ConfigPath string
+	Server     string
+	Timeout    time.Duration
+	TLSCert    string
+	LogLevel   string
+
+	command string
+}
+
+// Command returns the name of the command given on the command line, if any.
+func (g GlobalFlags) Command() string {
+	return g.command
+}
+
+// addGlobalFlags registers the global flags on the flag set.
+func addGlobalFlags(fs *pflag.FlagSet, g *GlobalFlags) {
+	fs.StringVar(&g.Profile, "profile", os.Getenv("GOPHKEEPER_PROFILE"),
+		"Profile to use (default: current profile, env GOPHKEEPER_PROFILE)")
+	fs.StringVar(&g.ConfigPath, "config", "", "Config file path (default: $XDG_CONFIG_HOME/gophkeeper-cli/config.yaml)")
+	fs.StringVar(&g.Server, "server", "", "gRPC server address, overrides config, env and profile")
+	fs.DurationVar(&g.Timeout, "timeout", 0, "Request timeout, overrides config, env and profile")
+	fs.StringVar(&g.TLSCert, "tls-cert", "", "Path to the server TLS certificate, overrides config, env and profile")
+	fs.StringVar(&g.LogLevel, "log-level", "", "Logging level (debug, info, warn, error), overrides config and env")
+}
+
+// ParseGlobalFlags extracts the global flags from the command line arguments.
+// Unknown flags are ignored, they are parsed later by the commands themselves.
+func ParseGlobalFlags(args []string) (GlobalFlags, error) {
+	var g GlobalFlags
+
+	fs := pflag.NewFlagSet("global", pflag.ContinueOnError)
+	fs.ParseErrorsWhitelist.UnknownFlags = true
+	fs.Usage = func() {}
+	fs.SetOutput(io.Discard)
+	addGlobalFlags(fs, &g)
+
+	// Help flags are handled by cobra
+	fs.BoolP("help", "h", false, "")
+
+	if err := fs.Parse(args); err != nil {
+		return g, err
+	}
+	if fs.NArg() > 0 {
+		g.command = fs.Arg(0)
+	}
+	return g, nil
+}
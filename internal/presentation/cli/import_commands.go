@@ -0,0 +1,169 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/domain"
+)
+
+// failedImportAction marks entries that could not be stored.
+const failedImportAction = "failed"
+
+// newImportCmd creates a command that imports the export of another password manager.
+// Every request gets its own requestTimeout, as an import may store hundreds of entries.
+func newImportCmd(ctx context.Context, secretService domain.SecretService,
+	openImporter func(format string) (domain.Importer, error), requestTimeout time.Duration) *cobra.Command {
+	var (
+		format, filePath, collision string
+		dryRun                      bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "import",
+		Short: "Import secrets from another password manager",
+		Long: `Imports logins, notes, cards and attachments exported by another password manager:
+  keepass    KeePass 2 or KeePassXC XML export
+  bitwarden  Bitwarden unencrypted JSON export
+  1password  1Password CSV export
+  chrome     Chrome CSV export
+  firefox    Firefox CSV export
+  pass       pass password store directory, decrypted with gpg
+Entries are named after their folder and title. Names that are already taken are resolved
+with --on-collision. Entries that cannot be imported are listed with the reason.`,
+		Annotations: map[string]string{secretNamesAnnotation: "true"},
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			policy, err := domain.ParseImportCollisionPolicy(collision)
+			if err != nil {
+				return err
+			}
+			if openImporter == nil {
+				return fmt.Errorf("import is not available")
+			}
+			importer, err := openImporter(format)
+			if err != nil {
+				return err
+			}
+
+			entries, skipped, err := importer.Parse(filePath)
+			if err != nil {
+				log.Error().Err(err).Msgf("Failed to parse '%s'", filePath)
+				return fmt.Errorf("failed to parse '%s': %w", filePath, err)
+			}
+
+			ctx := untilCanceled(ctx)
+			listCtx, cancel := requestContext(ctx, requestTimeout)
+			existing, err := secretService.ListSecretsInfo(listCtx)
+			cancel()
+			if err != nil {
+				log.Error().Err(err).Msg("Failed to list secrets")
+				return fmt.Errorf("failed to list secrets")
+			}
+
+			plan, err := domain.PlanImport(entries, existing, policy)
+			if err != nil {
+				return err
+			}
+
+			views := make([]importResultView, 0, len(plan)+len(skipped))
+			for _, p := range plan {
+				v := newImportResultView(p)
+				if !dryRun && p.Action != domain.SkipImportAction {
+					if err := storeImportEntry(ctx, secretService, p, requestTimeout); err != nil {
+						log.Error().Err(err).Msgf("Failed to import '%s'", p.Name)
+						v.Action, v.Detail = failedImportAction, "failed to store secret"
+					}
+				}
+				views = append(views, v)
+			}
+			for _, s := range skipped {
+				views = append(views, importResultView{
+					Name:   s.Name,
+					Action: string(domain.SkipImportAction),
+					Detail: s.Reason,
+				})
+			}
+
+			return renderImportResults(cmd, importReportOutputKind, views, dryRun, "import", "Imported")
+		},
+	}
+
+	cmd.Flags().StringVar(&format, "format", "", "Export format: keepass, bitwarden, 1password, chrome, firefox or pass (required)")
+	cmd.Flags().StringVarP(&filePath, "file", "f", "", "Path to the export file, or the password store directory for pass (required)")
+	cmd.Flags().StringVar(&collision, "on-collision", string(domain.SkipImportCollisionPolicy),
+		"What to do with entries whose name is taken: skip, overwrite, rename or fail")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would be imported without storing anything")
+
+	_ = cmd.MarkFlagRequired("format")
+	_ = cmd.MarkFlagRequired("file")
+
+	return cmd
+}
+
+// storeImportEntry stores a planned entry as a new secret or a new version of an existing one.
+func storeImportEntry(ctx context.Context, secretService domain.SecretService, p domain.PlannedImport,
+	timeout time.Duration) error {
+	ctx, cancel := requestContext(ctx, timeout)
+	defer cancel()
+
+	secret := domain.Secret{
+		Info: domain.SecretInfo{
+			Name:     p.Name,
+			Type:     p.Entry.Type,
+			Metadata: p.Entry.Metadata,
+		},
+		BaseVersion: p.BaseVersion,
+	}
+	return secretService.CreateSecret(ctx, secret, bytes.NewReader(p.Entry.Content))
+}
+
+// renderImportResults prints the results of an import or a backup restore followed by a summary.
+// Text output lists every entry of a dry run, otherwise only the entries that were not stored.
+// The command fails if an entry could not be stored.
+func renderImportResults(cmd *cobra.Command, kind string, views []importResultView, dryRun bool, verb, done string) error {
+	var (
+		imported, failed int
+		listed           []view
+	)
+	for _, v := range views {
+		switch v.Action {
+		case string(domain.CreateImportAction), string(domain.OverwriteImportAction):
+			imported++
+		case failedImportAction:
+			failed++
+		}
+		if dryRun || (v.Action != string(domain.CreateImportAction) && v.Action != string(domain.OverwriteImportAction)) {
+			listed = append(listed, v)
+		}
+	}
+
+	err := renderList(cmd, kind, views, func(w io.Writer) error {
+		if len(listed) > 0 {
+			if err := renderTable(w, listed); err != nil {
+				return err
+			}
+			fmt.Fprintln(w)
+		}
+
+		if dryRun {
+			fmt.Fprintf(w, "Would %s %d of %d entries, nothing was stored\n", verb, imported, len(views))
+		} else {
+			fmt.Fprintf(w, "%s %d of %d entries\n", done, imported, len(views))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to %s %d entries", verb, failed)
+	}
+	return nil
+}
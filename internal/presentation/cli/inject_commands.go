@@ -0,0 +1,185 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"text/template"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/domain"
+)
+
+// newInjectCmd creates a command that renders a template with secret references.
+func newInjectCmd(ctx context.Context, secretService domain.SecretService) *cobra.Command {
+	var (
+		templatePath, outPath string
+		dryRun                bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "inject",
+		Short: "Render a template with secret references",
+		Long: `Renders a Go text/template read from --file or stdin. Secret fields are referenced with
+  {{ gk "db-prod" "password" }}      the latest version
+  {{ gk "db-prod" "password" 3 }}    version 3
+using the fields of 'run'. A missing secret, version or field fails the command and nothing is written.
+The result is written to --out, created with 0600 permissions, or to stdout.
+--dry-run lists the references without retrieving any secret.`,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name, text, err := readTemplate(cmd, templatePath)
+			if err != nil {
+				return err
+			}
+
+			var resolve func(ref secretRef) (string, error)
+			tmpl, err := template.New(name).
+				Option("missingkey=error").
+				Funcs(template.FuncMap{"gk": func(name, field string, version ...int) (string, error) {
+					ref, err := newTemplateRef(name, field, version)
+					if err != nil {
+						return "", err
+					}
+					return resolve(ref)
+				}}).
+				Parse(text)
+			if err != nil {
+				return fmt.Errorf("invalid template: %w", err)
+			}
+
+			if dryRun {
+				var refs []secretRef
+				seen := make(map[secretRef]bool)
+				resolve = func(ref secretRef) (string, error) {
+					if !seen[ref] {
+						seen[ref] = true
+						refs = append(refs, ref)
+					}
+					return "", nil
+				}
+				if err := tmpl.Execute(io.Discard, nil); err != nil {
+					return fmt.Errorf("failed to render template: %w", err)
+				}
+				return renderTemplateRefs(cmd, refs)
+			}
+
+			resolve = newSecretResolver(ctx, secretService).resolve
+			var rendered bytes.Buffer
+			if err := tmpl.Execute(&rendered, nil); err != nil {
+				return fmt.Errorf("failed to render template: %w", err)
+			}
+
+			if outPath == "" {
+				_, err := cmd.OutOrStdout().Write(rendered.Bytes())
+				return err
+			}
+			if err := writePrivateFile(outPath, rendered.Bytes()); err != nil {
+				log.Error().Err(err).Msgf("Failed to write '%s'", outPath)
+				return fmt.Errorf("failed to write '%s'", outPath)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Rendered '%s' into '%s'\n", name, outPath)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&templatePath, "file", "f", "", "Template file (default: stdin)")
+	cmd.Flags().StringVar(&outPath, "out", "", "Write the result to this file with 0600 permissions (default: stdout)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "List the secret references without retrieving them")
+
+	return cmd
+}
+
+// readTemplate reads the template at path, or from stdin if path is empty or "-".
+// The returned name identifies the template in error messages.
+func readTemplate(cmd *cobra.Command, path string) (string, string, error) {
+	if path == "" || path == "-" {
+		data, err := io.ReadAll(cmd.InOrStdin())
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to read template from stdin")
+			return "", "", fmt.Errorf("failed to read template from stdin")
+		}
+		return "stdin", string(data), nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed to read template '%s'", path)
+		return "", "", fmt.Errorf("failed to read template '%s'", path)
+	}
+	return filepath.Base(path), string(data), nil
+}
+
+// newTemplateRef builds the reference of a gk call from its arguments.
+func newTemplateRef(name, field string, version []int) (secretRef, error) {
+	ref := secretRef{name: name, field: field}
+	switch len(version) {
+	case 0:
+	case 1:
+		if version[0] < 1 || version[0] > math.MaxInt32 {
+			return secretRef{}, fmt.Errorf("invalid version %d of '%s' (must be positive)", version[0], name)
+		}
+		ref.version = int32(version[0])
+	default:
+		return secretRef{}, fmt.Errorf("gk takes a secret name, a field and an optional version")
+	}
+	if name == "" || field == "" {
+		return secretRef{}, fmt.Errorf("gk needs a secret name and a field")
+	}
+	return ref, nil
+}
+
+// renderTemplateRefs prints the references found in a template.
+func renderTemplateRefs(cmd *cobra.Command, refs []secretRef) error {
+	views := make([]templateRefView, len(refs))
+	for i, ref := range refs {
+		views[i] = templateRefView{Secret: ref.name, Field: ref.field, Version: ref.version}
+	}
+
+	return renderList(cmd, templateRefsOutputKind, views, func(w io.Writer) error {
+		if len(views) == 0 {
+			fmt.Fprintln(w, "No secret references")
+			return nil
+		}
+
+		tableViews := make([]view, len(views))
+		for i, v := range views {
+			tableViews[i] = v
+		}
+		return renderTable(w, tableViews)
+	})
+}
+
+// writePrivateFile writes data into a temporary file readable by the owner only
+// and renames it over path, so path never holds partial content.
+func writePrivateFile(path string, data []byte) (err error) {
+	file, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			file.Close()
+			os.Remove(file.Name())
+		}
+	}()
+
+	if err = file.Chmod(0600); err != nil {
+		return err
+	}
+	if _, err = file.Write(data); err != nil {
+		return err
+	}
+	if err = file.Sync(); err != nil {
+		return err
+	}
+	if err = file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), path)
+}
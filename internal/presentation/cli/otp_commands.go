@@ -0,0 +1,108 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/domain"
+)
+
+// newTOTPCmd creates a command to compute the current one-time password of stored credentials.
+func newTOTPCmd(ctx context.Context, secretService domain.SecretService) *cobra.Command {
+	var name string
+
+	cmd := &cobra.Command{
+		Use:   "totp",
+		Short: "Show the current one-time password of credentials",
+		Long: `Computes the current RFC 6238 code from the TOTP seed of credentials and shows how long it stays valid.
+For HOTP seeds the code of the stored counter is shown and the counter is advanced in a new version.`,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			secret, err := secretService.GetLatestSecret(ctx, name)
+			if err != nil {
+				log.Error().Err(err).Msg("Failed to retrieve credentials")
+				return fmt.Errorf("failed to retrieve credentials")
+			}
+			if secret.Info.Type != domain.CredentialsSecretType {
+				return fmt.Errorf("secret '%s' is of type %s, not %s", name, secret.Info.Type, domain.CredentialsSecretType)
+			}
+
+			var credentials domain.CredentialsSecret
+			if err := json.Unmarshal([]byte(secret.Data), &credentials); err != nil {
+				log.Error().Err(err).Msg("Failed to decode credentials")
+				return fmt.Errorf("failed to decode credentials")
+			}
+			if credentials.OTP == "" {
+				return fmt.Errorf("credentials '%s' have no one-time password seed", name)
+			}
+
+			key, err := domain.ParseOTPURI(credentials.OTP)
+			if err != nil {
+				return err
+			}
+
+			v := otpCodeView{Name: name, Type: string(key.Type)}
+			if key.Type == domain.TOTPType {
+				code, remaining, err := key.TOTP(time.Now())
+				if err != nil {
+					return err
+				}
+				v.Code, v.ValidFor = code, int(remaining.Round(time.Second)/time.Second)
+			} else {
+				if v.Code, err = key.HOTP(key.Counter); err != nil {
+					return err
+				}
+				v.Counter = ptr(key.Counter)
+				if err := advanceHOTPCounter(ctx, cmd, secretService, *secret, credentials, *key); err != nil {
+					log.Error().Err(err).Msgf("Failed to advance HOTP counter of '%s'", name)
+					return fmt.Errorf("failed to advance HOTP counter of '%s'", name)
+				}
+			}
+
+			return renderOne(cmd, otpCodeOutputKind, v, func(w io.Writer) error {
+				fmt.Fprintf(w, "Code: %s\n", v.Code)
+				if v.Counter != nil {
+					fmt.Fprintf(w, "Counter: %d\n", *v.Counter)
+				} else {
+					fmt.Fprintf(w, "Valid for: %ds\n", v.ValidFor)
+				}
+				return nil
+			})
+		},
+	}
+
+	cmd.Flags().StringVarP(&name, "name", "n", "", "Name of the credentials (required)")
+	_ = cmd.MarkFlagRequired("name")
+
+	return cmd
+}
+
+// advanceHOTPCounter stores a new version of the credentials with the next HOTP counter,
+// so that a code is never shown twice.
+func advanceHOTPCounter(ctx context.Context, cmd *cobra.Command, secretService domain.SecretService,
+	secret domain.Secret, credentials domain.CredentialsSecret, key domain.OTPKey) error {
+	key.Counter++
+	credentials.OTP = key.URI()
+
+	marshaled, err := json.Marshal(credentials)
+	if err != nil {
+		return fmt.Errorf("failed to marshal credentials: %w", err)
+	}
+
+	next := domain.Secret{
+		Info: domain.SecretInfo{
+			Name:     secret.Info.Name,
+			Metadata: secret.Info.Metadata,
+			Type:     domain.CredentialsSecretType,
+		},
+	}
+	flags := conflictFlags{policy: string(domain.FailConflictPolicy), baseVersion: secret.Info.Version}
+	_, err = storeSecret(ctx, cmd, secretService, next, bytes.NewReader(marshaled), flags)
+	return err
+}
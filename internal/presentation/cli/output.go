@@ -0,0 +1,757 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/domain"
+	"golang.org/x/crypto/ssh"
+	"gopkg.in/yaml.v3"
+)
+
+// outputSchemaVersion is the version of the machine-readable output schema.
+// It is increased whenever a field is renamed or removed; adding fields keeps the version.
+const outputSchemaVersion = 1
+
+// OutputFormat defines how command results are printed.
+type OutputFormat string
+
+const (
+	TextOutputFormat  OutputFormat = "text"
+	JSONOutputFormat  OutputFormat = "json"
+	YAMLOutputFormat  OutputFormat = "yaml"
+	TableOutputFormat OutputFormat = "table"
+	EnvOutputFormat   OutputFormat = "env"
+)
+
+// envPrefix prefixes variable names in env output.
+const envPrefix = "GK_"
+
+// Output kinds identify the type of data in machine-readable output.
+const (
+	secretOutputKind            = "secret"
+	secretListOutputKind        = "secret_list"
+	secretHistoryOutputKind     = "secret_history"
+	secretDiffOutputKind        = "secret_diff"
+	otpCodeOutputKind           = "otp_code"
+	generatedPasswordOutputKind = "generated_password"
+	auditReportOutputKind       = "audit_report"
+	importReportOutputKind      = "import_report"
+	backupOutputKind            = "backup"
+	restoreReportOutputKind     = "restore_report"
+	templateRefsOutputKind      = "template_references"
+	sessionOutputKind           = "session"
+	syncStatusOutputKind        = "sync_status"
+	profileListOutputKind       = "profile_list"
+	configOutputKind            = "config"
+)
+
+// addOutputFlag registers the global output format flag on the flag set.
+func addOutputFlag(fs *pflag.FlagSet) {
+	fs.StringP("output", "o", string(TextOutputFormat), "Output format: text, json, yaml, table or env")
+}
+
+// parseOutputFormat converts a string into an OutputFormat.
+func parseOutputFormat(s string) (OutputFormat, error) {
+	switch f := OutputFormat(strings.ToLower(s)); f {
+	case TextOutputFormat, JSONOutputFormat, YAMLOutputFormat, TableOutputFormat, EnvOutputFormat:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unknown output format '%s' (must be text, json, yaml, table or env)", s)
+	}
+}
+
+// outputFormat returns the output format selected for the command.
+func outputFormat(cmd *cobra.Command) (OutputFormat, error) {
+	flag := cmd.Flag("output")
+	if flag == nil {
+		return TextOutputFormat, nil
+	}
+	return parseOutputFormat(flag.Value.String())
+}
+
+// column is a named value of a rendered view, used by table and env output.
+type column struct {
+	key   string
+	value string
+}
+
+// view is a serializable representation of command results with stable field names.
+type view interface {
+	columns() []column
+}
+
+// envelope wraps machine-readable output with its schema version and kind.
+type envelope struct {
+	SchemaVersion int    `json:"schema_version" yaml:"schema_version"`
+	Kind          string `json:"kind" yaml:"kind"`
+	Data          any    `json:"data" yaml:"data"`
+}
+
+// renderOne prints a single view in the selected format. Text output is produced by the text function.
+func renderOne(cmd *cobra.Command, kind string, v view, text func(w io.Writer) error) error {
+	format, err := outputFormat(cmd)
+	if err != nil {
+		return err
+	}
+
+	w := cmd.OutOrStdout()
+	switch format {
+	case JSONOutputFormat, YAMLOutputFormat:
+		return renderDocument(w, format, envelope{SchemaVersion: outputSchemaVersion, Kind: kind, Data: v})
+	case TableOutputFormat:
+		return renderTable(w, []view{v})
+	case EnvOutputFormat:
+		return renderEnv(w, "", v.columns())
+	default:
+		return text(w)
+	}
+}
+
+// renderList prints a list of views in the selected format. Text output is produced by the text function.
+func renderList[V view](cmd *cobra.Command, kind string, vs []V, text func(w io.Writer) error) error {
+	format, err := outputFormat(cmd)
+	if err != nil {
+		return err
+	}
+
+	views := make([]view, len(vs))
+	for i, v := range vs {
+		views[i] = v
+	}
+
+	w := cmd.OutOrStdout()
+	switch format {
+	case JSONOutputFormat, YAMLOutputFormat:
+		// Always render a list, never null, so consumers can iterate unconditionally
+		data := make([]V, 0, len(vs))
+		data = append(data, vs...)
+		return renderDocument(w, format, envelope{SchemaVersion: outputSchemaVersion, Kind: kind, Data: data})
+	case TableOutputFormat:
+		return renderTable(w, views)
+	case EnvOutputFormat:
+		if err := renderEnv(w, "", []column{{key: "count", value: strconv.Itoa(len(views))}}); err != nil {
+			return err
+		}
+		for i, v := range views {
+			if err := renderEnv(w, strconv.Itoa(i)+"_", v.columns()); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return text(w)
+	}
+}
+
+// renderDocument prints the envelope as JSON or YAML.
+func renderDocument(w io.Writer, format OutputFormat, doc envelope) error {
+	if format == YAMLOutputFormat {
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(doc); err != nil {
+			return fmt.Errorf("failed to encode output: %w", err)
+		}
+		return enc.Close()
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return fmt.Errorf("failed to encode output: %w", err)
+	}
+	return nil
+}
+
+// renderTable prints views as a table. The header is the union of columns in order of appearance.
+func renderTable(w io.Writer, views []view) error {
+	var header []string
+	seen := make(map[string]bool)
+	rows := make([]map[string]string, len(views))
+	for i, v := range views {
+		rows[i] = make(map[string]string)
+		for _, c := range v.columns() {
+			if !seen[c.key] {
+				seen[c.key] = true
+				header = append(header, c.key)
+			}
+			rows[i][c.key] = c.value
+		}
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	cells := make([]string, len(header))
+	for i, key := range header {
+		cells[i] = strings.ToUpper(key)
+	}
+	fmt.Fprintln(tw, strings.Join(cells, "\t"))
+
+	for _, row := range rows {
+		for i, key := range header {
+			cells[i] = tableCell(row[key])
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+	return tw.Flush()
+}
+
+// tableCell keeps table rows on a single line.
+func tableCell(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return strings.NewReplacer("\n", `\n`, "\t", " ").Replace(value)
+}
+
+// renderEnv prints columns as shell-compatible variable assignments.
+func renderEnv(w io.Writer, prefix string, columns []column) error {
+	for _, c := range columns {
+		name := envPrefix + strings.ToUpper(prefix+c.key)
+		if _, err := fmt.Fprintf(w, "%s=%s\n", name, shellQuote(c.value)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// shellQuote quotes a value for POSIX shells.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// secretInfoView is the stable representation of domain.SecretInfo.
+type secretInfoView struct {
+	Name      string     `json:"name" yaml:"name"`
+	Type      string     `json:"type" yaml:"type"`
+	Version   int32      `json:"version" yaml:"version"`
+	Metadata  string     `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+	CreatedAt *time.Time `json:"created_at,omitempty" yaml:"created_at,omitempty"`
+}
+
+// newSecretInfoView converts domain.SecretInfo into its view.
+func newSecretInfoView(info domain.SecretInfo) secretInfoView {
+	v := secretInfoView{
+		Name:     info.Name,
+		Type:     string(info.Type),
+		Version:  info.Version,
+		Metadata: info.Metadata,
+	}
+	if !info.CreatedAt.IsZero() {
+		createdAt := info.CreatedAt.UTC()
+		v.CreatedAt = &createdAt
+	}
+	return v
+}
+
+func (v secretInfoView) columns() []column {
+	columns := []column{
+		{key: "name", value: v.Name},
+		{key: "type", value: v.Type},
+		{key: "version", value: strconv.Itoa(int(v.Version))},
+		{key: "metadata", value: v.Metadata},
+	}
+	if v.CreatedAt != nil {
+		columns = append(columns, column{key: "created_at", value: v.CreatedAt.Format(time.RFC3339)})
+	}
+	return columns
+}
+
+// secretVersionView is the stable representation of domain.SecretVersion.
+type secretVersionView struct {
+	secretInfoView `yaml:",inline"`
+
+	Size int64 `json:"size" yaml:"size"`
+}
+
+// newSecretVersionView converts domain.SecretVersion into its view.
+func newSecretVersionView(version domain.SecretVersion) secretVersionView {
+	return secretVersionView{secretInfoView: newSecretInfoView(version.Info), Size: version.Size}
+}
+
+func (v secretVersionView) columns() []column {
+	return append(v.secretInfoView.columns(), column{key: "size", value: strconv.FormatInt(v.Size, 10)})
+}
+
+// secretDiffView is the stable representation of the changes between two secret versions.
+// Unified holds the line diff of text secrets when it is revealed.
+type secretDiffView struct {
+	Name    string        `json:"name" yaml:"name"`
+	Type    string        `json:"type" yaml:"type"`
+	From    int32         `json:"from" yaml:"from"`
+	To      int32         `json:"to" yaml:"to"`
+	Changes []fieldChange `json:"changes" yaml:"changes"`
+	Unified string        `json:"unified,omitempty" yaml:"unified,omitempty"`
+}
+
+func (v secretDiffView) columns() []column {
+	columns := []column{
+		{key: "name", value: v.Name},
+		{key: "type", value: v.Type},
+		{key: "from", value: strconv.Itoa(int(v.From))},
+		{key: "to", value: strconv.Itoa(int(v.To))},
+	}
+	for _, c := range v.Changes {
+		value := c.Change
+		if c.To != nil {
+			value = *c.To
+		}
+		columns = append(columns, column{key: c.Field, value: value})
+	}
+	return columns
+}
+
+// otpCodeView is the stable representation of a one-time password.
+// ValidFor is set for TOTP codes and Counter for HOTP codes.
+type otpCodeView struct {
+	Name     string  `json:"name" yaml:"name"`
+	Type     string  `json:"type" yaml:"type"`
+	Code     string  `json:"code" yaml:"code"`
+	ValidFor int     `json:"valid_for_seconds,omitempty" yaml:"valid_for_seconds,omitempty"`
+	Counter  *uint64 `json:"counter,omitempty" yaml:"counter,omitempty"`
+}
+
+func (v otpCodeView) columns() []column {
+	columns := []column{{key: "name", value: v.Name}, {key: "type", value: v.Type}, {key: "code", value: v.Code}}
+	if v.Counter != nil {
+		return append(columns, column{key: "counter", value: strconv.FormatUint(*v.Counter, 10)})
+	}
+	return append(columns, column{key: "valid_for_seconds", value: strconv.Itoa(v.ValidFor)})
+}
+
+// generatedPasswordView is the stable representation of a generated password
+// with its estimated entropy in bits, rounded to one decimal.
+type generatedPasswordView struct {
+	Password string  `json:"password" yaml:"password"`
+	Entropy  float64 `json:"entropy_bits" yaml:"entropy_bits"`
+}
+
+// newGeneratedPasswordView converts a generated password into its view.
+func newGeneratedPasswordView(password string, entropy float64) generatedPasswordView {
+	return generatedPasswordView{Password: password, Entropy: math.Round(entropy*10) / 10}
+}
+
+func (v generatedPasswordView) columns() []column {
+	return []column{
+		{key: "password", value: v.Password},
+		{key: "entropy_bits", value: strconv.FormatFloat(v.Entropy, 'f', -1, 64)},
+	}
+}
+
+// auditFindingView is the stable representation of domain.AuditFinding.
+type auditFindingView struct {
+	Issue  string `json:"issue" yaml:"issue"`
+	Secret string `json:"secret" yaml:"secret"`
+	Detail string `json:"detail" yaml:"detail"`
+}
+
+// newAuditFindingView converts an audit finding into its view.
+func newAuditFindingView(f domain.AuditFinding) auditFindingView {
+	return auditFindingView{Issue: string(f.Issue), Secret: f.Secret, Detail: f.Detail}
+}
+
+func (v auditFindingView) columns() []column {
+	return []column{{key: "issue", value: v.Issue}, {key: "secret", value: v.Secret}, {key: "detail", value: v.Detail}}
+}
+
+// importResultView is the stable representation of what an import or a backup restore did with an entry.
+// Version is only set for the secret versions of a backup.
+type importResultView struct {
+	Name    string `json:"name" yaml:"name"`
+	Version int32  `json:"version,omitempty" yaml:"version,omitempty"`
+	Type    string `json:"type,omitempty" yaml:"type,omitempty"`
+	Action  string `json:"action" yaml:"action"`
+	Detail  string `json:"detail,omitempty" yaml:"detail,omitempty"`
+}
+
+// newImportResultView converts a planned import into its view.
+func newImportResultView(p domain.PlannedImport) importResultView {
+	return importResultView{
+		Name:   p.Name,
+		Type:   string(p.Entry.Type),
+		Action: string(p.Action),
+		Detail: importDetail(p.Action, p.Name, p.Entry.Name, p.BaseVersion, p.Reason),
+	}
+}
+
+// newRestoreResultView converts a planned restore of a secret version into its view.
+func newRestoreResultView(p domain.PlannedRestore) importResultView {
+	return importResultView{
+		Name:    p.Name,
+		Version: p.Entry.Version,
+		Type:    string(p.Entry.Type),
+		Action:  string(p.Action),
+		Detail:  importDetail(p.Action, p.Name, p.Entry.Name, p.BaseVersion, p.Reason),
+	}
+}
+
+// importDetail explains a planned action: the replaced version, the original name or the reason of a skip.
+func importDetail(action domain.ImportAction, name, original string, baseVersion int32, reason string) string {
+	switch {
+	case action == domain.OverwriteImportAction && baseVersion > 0:
+		return fmt.Sprintf("replaces version %d", baseVersion)
+	case action != domain.SkipImportAction && name != original:
+		return fmt.Sprintf("renamed from '%s'", original)
+	default:
+		return reason
+	}
+}
+
+func (v importResultView) columns() []column {
+	columns := []column{{key: "name", value: v.Name}}
+	if v.Version > 0 {
+		columns = append(columns, column{key: "version", value: strconv.Itoa(int(v.Version))})
+	}
+	return append(columns,
+		column{key: "type", value: v.Type},
+		column{key: "action", value: v.Action},
+		column{key: "detail", value: v.Detail},
+	)
+}
+
+// backupView is the stable representation of a written backup archive.
+type backupView struct {
+	File        string `json:"file" yaml:"file"`
+	Secrets     int    `json:"secrets" yaml:"secrets"`
+	Versions    int    `json:"versions" yaml:"versions"`
+	AllVersions bool   `json:"all_versions" yaml:"all_versions"`
+}
+
+func (v backupView) columns() []column {
+	return []column{
+		{key: "file", value: v.File},
+		{key: "secrets", value: strconv.Itoa(v.Secrets)},
+		{key: "versions", value: strconv.Itoa(v.Versions)},
+		{key: "all_versions", value: strconv.FormatBool(v.AllVersions)},
+	}
+}
+
+// templateRefView is the stable representation of a secret reference in a template.
+type templateRefView struct {
+	Secret  string `json:"secret" yaml:"secret"`
+	Field   string `json:"field" yaml:"field"`
+	Version int32  `json:"version,omitempty" yaml:"version,omitempty"`
+}
+
+func (v templateRefView) columns() []column {
+	version := "latest"
+	if v.Version > 0 {
+		version = strconv.Itoa(int(v.Version))
+	}
+	return []column{
+		{key: "secret", value: v.Secret},
+		{key: "field", value: v.Field},
+		{key: "version", value: version},
+	}
+}
+
+// credentialsView is the stable representation of domain.CredentialsSecret.
+type credentialsView struct {
+	Login    string `json:"login" yaml:"login"`
+	Password string `json:"password" yaml:"password"`
+	URL      string `json:"url,omitempty" yaml:"url,omitempty"`
+	Notes    string `json:"notes,omitempty" yaml:"notes,omitempty"`
+	OTP      string `json:"otp,omitempty" yaml:"otp,omitempty"`
+}
+
+// newCredentialsView converts credentials into their view.
+func newCredentialsView(credentials domain.CredentialsSecret) credentialsView {
+	return credentialsView{
+		Login:    credentials.Login,
+		Password: credentials.Password,
+		URL:      credentials.URL,
+		Notes:    credentials.Notes,
+		OTP:      credentials.OTP,
+	}
+}
+
+func (v credentialsView) columns() []column {
+	columns := []column{{key: "login", value: v.Login}, {key: "password", value: v.Password}}
+	for _, c := range []column{{key: "url", value: v.URL}, {key: "notes", value: v.Notes}, {key: "otp", value: v.OTP}} {
+		if c.value != "" {
+			columns = append(columns, c)
+		}
+	}
+	return columns
+}
+
+// paymentCardView is the stable representation of domain.PaymentCardSecret.
+// Number, CVV and PIN are masked unless they are revealed.
+type paymentCardView struct {
+	Number  string `json:"number" yaml:"number"`
+	Network string `json:"network" yaml:"network"`
+	Holder  string `json:"holder,omitempty" yaml:"holder,omitempty"`
+	Expiry  string `json:"expiry,omitempty" yaml:"expiry,omitempty"`
+	CVV     string `json:"cvv,omitempty" yaml:"cvv,omitempty"`
+	PIN     string `json:"pin,omitempty" yaml:"pin,omitempty"`
+	Issuer  string `json:"issuer,omitempty" yaml:"issuer,omitempty"`
+	Notes   string `json:"notes,omitempty" yaml:"notes,omitempty"`
+}
+
+// newPaymentCardView converts a payment card into its view.
+func newPaymentCardView(card domain.PaymentCardSecret, reveal bool) paymentCardView {
+	v := paymentCardView{
+		Number:  card.Number,
+		Network: string(card.Network()),
+		Holder:  card.Holder,
+		Expiry:  formatCardExpiry(card),
+		CVV:     card.CVV,
+		PIN:     card.PIN,
+		Issuer:  card.Issuer,
+		Notes:   card.Notes,
+	}
+	if !reveal {
+		v.Number = maskCardNumber(v.Number)
+		v.CVV = maskValue(v.CVV)
+		v.PIN = maskValue(v.PIN)
+	}
+	return v
+}
+
+func (v paymentCardView) columns() []column {
+	return []column{
+		{key: "number", value: v.Number},
+		{key: "network", value: v.Network},
+		{key: "holder", value: v.Holder},
+		{key: "expiry", value: v.Expiry},
+		{key: "cvv", value: v.CVV},
+		{key: "pin", value: v.PIN},
+		{key: "issuer", value: v.Issuer},
+		{key: "notes", value: v.Notes},
+	}
+}
+
+// sshKeyView is the stable representation of domain.SSHKeySecret.
+// The private key and its passphrase are only set when they are revealed.
+type sshKeyView struct {
+	Algorithm   string `json:"algorithm" yaml:"algorithm"`
+	PublicKey   string `json:"public_key" yaml:"public_key"`
+	Fingerprint string `json:"fingerprint" yaml:"fingerprint"`
+	PrivateKey  string `json:"private_key,omitempty" yaml:"private_key,omitempty"`
+	Passphrase  string `json:"passphrase,omitempty" yaml:"passphrase,omitempty"`
+}
+
+// newSSHKeyView converts an SSH key into its view.
+func newSSHKeyView(key domain.SSHKeySecret, publicKey ssh.PublicKey, reveal bool) sshKeyView {
+	v := sshKeyView{
+		Algorithm:   publicKey.Type(),
+		PublicKey:   strings.TrimSpace(string(ssh.MarshalAuthorizedKey(publicKey))),
+		Fingerprint: ssh.FingerprintSHA256(publicKey),
+	}
+	if reveal {
+		v.PrivateKey = key.PrivateKey
+		v.Passphrase = key.Passphrase
+	}
+	return v
+}
+
+func (v sshKeyView) columns() []column {
+	columns := []column{
+		{key: "algorithm", value: v.Algorithm},
+		{key: "public_key", value: v.PublicKey},
+		{key: "fingerprint", value: v.Fingerprint},
+	}
+	for _, c := range []column{{key: "private_key", value: v.PrivateKey}, {key: "passphrase", value: v.Passphrase}} {
+		if c.value != "" {
+			columns = append(columns, c)
+		}
+	}
+	return columns
+}
+
+// secretView is the stable representation of domain.Secret.
+// Exactly one of the payload fields is set, matching the secret type.
+type secretView struct {
+	secretInfoView `yaml:",inline"`
+
+	Credentials *credentialsView `json:"credentials,omitempty" yaml:"credentials,omitempty"`
+	PaymentCard *paymentCardView `json:"payment_card,omitempty" yaml:"payment_card,omitempty"`
+	SSHKey      *sshKeyView      `json:"ssh_key,omitempty" yaml:"ssh_key,omitempty"`
+	Content     *string          `json:"content,omitempty" yaml:"content,omitempty"`
+	Path        string           `json:"path,omitempty" yaml:"path,omitempty"`
+}
+
+func (v secretView) columns() []column {
+	columns := v.secretInfoView.columns()
+	switch {
+	case v.Credentials != nil:
+		columns = append(columns, v.Credentials.columns()...)
+	case v.PaymentCard != nil:
+		columns = append(columns, v.PaymentCard.columns()...)
+	case v.SSHKey != nil:
+		columns = append(columns, v.SSHKey.columns()...)
+	case v.Content != nil:
+		columns = append(columns, column{key: "content", value: *v.Content})
+	}
+	if v.Path != "" {
+		columns = append(columns, column{key: "path", value: v.Path})
+	}
+	return columns
+}
+
+// secretInfoColumns are the column keys of secretInfoView.
+var secretInfoColumns = []string{"name", "type", "version", "metadata", "created_at"}
+
+// selectedColumnsView limits the columns of a view to the selected keys in the given order.
+// JSON and YAML documents are not affected.
+type selectedColumnsView[V view] struct {
+	view V
+	keys []string
+}
+
+func (v selectedColumnsView[V]) columns() []column {
+	values := make(map[string]string)
+	for _, c := range v.view.columns() {
+		values[c.key] = c.value
+	}
+
+	columns := make([]column, len(v.keys))
+	for i, key := range v.keys {
+		columns[i] = column{key: key, value: values[key]}
+	}
+	return columns
+}
+
+func (v selectedColumnsView[V]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.view)
+}
+
+func (v selectedColumnsView[V]) MarshalYAML() (any, error) {
+	return v.view, nil
+}
+
+// sessionView is the stable representation of domain.TokenClaims.
+type sessionView struct {
+	Login     string     `json:"login" yaml:"login"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty" yaml:"expires_at,omitempty"`
+	Expired   bool       `json:"expired" yaml:"expired"`
+}
+
+// newSessionView converts token claims into their view.
+func newSessionView(claims *domain.TokenClaims, now time.Time) sessionView {
+	v := sessionView{Login: claims.Login, Expired: claims.Expired(now)}
+	if !claims.ExpiresAt.IsZero() {
+		expiresAt := claims.ExpiresAt.UTC()
+		v.ExpiresAt = &expiresAt
+	}
+	return v
+}
+
+func (v sessionView) columns() []column {
+	return []column{
+		{key: "login", value: v.Login},
+		{key: "expires_at", value: formatOptionalTime(v.ExpiresAt)},
+		{key: "expired", value: strconv.FormatBool(v.Expired)},
+	}
+}
+
+// pendingOperationView is the stable representation of domain.PendingOperation.
+type pendingOperationView struct {
+	ID       string    `json:"id" yaml:"id"`
+	Type     string    `json:"type" yaml:"type"`
+	Name     string    `json:"name" yaml:"name"`
+	QueuedAt time.Time `json:"queued_at" yaml:"queued_at"`
+}
+
+// syncStatusView is the stable representation of domain.SyncStatus.
+type syncStatusView struct {
+	LastSync *time.Time             `json:"last_sync,omitempty" yaml:"last_sync,omitempty"`
+	Pending  []pendingOperationView `json:"pending" yaml:"pending"`
+}
+
+// newSyncStatusView converts domain.SyncStatus into its view.
+func newSyncStatusView(status *domain.SyncStatus) syncStatusView {
+	v := syncStatusView{Pending: make([]pendingOperationView, 0, len(status.Pending))}
+	if !status.LastSync.IsZero() {
+		lastSync := status.LastSync.UTC()
+		v.LastSync = &lastSync
+	}
+	for _, op := range status.Pending {
+		v.Pending = append(v.Pending, pendingOperationView{
+			ID:       op.ID,
+			Type:     string(op.Type),
+			Name:     op.Secret.Info.Name,
+			QueuedAt: op.QueuedAt.UTC(),
+		})
+	}
+	return v
+}
+
+func (v syncStatusView) columns() []column {
+	return []column{
+		{key: "last_sync", value: formatOptionalTime(v.LastSync)},
+		{key: "pending", value: strconv.Itoa(len(v.Pending))},
+	}
+}
+
+// profileView is the stable representation of domain.Profile.
+type profileView struct {
+	Name    string `json:"name" yaml:"name"`
+	Server  string `json:"server,omitempty" yaml:"server,omitempty"`
+	TLSCert string `json:"tls_cert,omitempty" yaml:"tls_cert,omitempty"`
+	Timeout string `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	Current bool   `json:"current" yaml:"current"`
+}
+
+// newProfileView converts domain.Profile into its view.
+func newProfileView(p domain.Profile, current bool) profileView {
+	v := profileView{Name: p.Name, Server: p.ServerAddr, TLSCert: p.TLSCertPath, Current: current}
+	if p.Timeout > 0 {
+		v.Timeout = p.Timeout.String()
+	}
+	return v
+}
+
+func (v profileView) columns() []column {
+	return []column{
+		{key: "name", value: v.Name},
+		{key: "server", value: v.Server},
+		{key: "tls_cert", value: v.TLSCert},
+		{key: "timeout", value: v.Timeout},
+		{key: "current", value: strconv.FormatBool(v.Current)},
+	}
+}
+
+// configView is the representation of configuration settings, serialized as a key-value object.
+type configView []domain.ConfigSetting
+
+func (v configView) toMap() map[string]string {
+	m := make(map[string]string, len(v))
+	for _, s := range v {
+		m[s.Key] = s.Value
+	}
+	return m
+}
+
+// MarshalJSON implements json.Marshaler.
+func (v configView) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.toMap())
+}
+
+// MarshalYAML implements yaml.Marshaler.
+func (v configView) MarshalYAML() (any, error) {
+	return v.toMap(), nil
+}
+
+func (v configView) columns() []column {
+	columns := make([]column, len(v))
+	for i, s := range v {
+		columns[i] = column{key: s.Key, value: s.Value}
+	}
+	return columns
+}
+
+// formatOptionalTime formats a time in RFC 3339, or returns an empty string if it is not set.
+func formatOptionalTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,173 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/domain"
+)
+
+// newProfileCmd creates a cobra command grouping profile management commands
+// ctx: Context for request cancellation and timeouts
+// profileService: Profile service interface
+// Returns: Configured cobra.Command with profile subcommands
+func newProfileCmd(ctx context.Context, profileService domain.ProfileService) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "profile",
+		Short: "Manage server and account profiles",
+		Long: `Manage named profiles with their own server address, TLS settings and session token.
+Select a profile for a single command with the global --profile flag.`,
+	}
+
+	cmd.AddCommand(
+		newProfileListCmd(ctx, profileService),
+		newProfileUseCmd(ctx, profileService),
+		newProfileAddCmd(ctx, profileService),
+		newProfileRemoveCmd(ctx, profileService),
+	)
+	return cmd
+}
+
+// newProfileListCmd creates a cobra command that lists profiles
+func newProfileListCmd(ctx context.Context, profileService domain.ProfileService) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List profiles",
+		Long:  "List all profiles, marking the current one with '*'",
+		Args:  cobra.NoArgs,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			profiles, current, err := profileService.ListProfiles(ctx)
+			if err != nil {
+				log.Error().Err(err).Msg("failed to list profiles")
+				return fmt.Errorf("failed to list profiles")
+			}
+
+			views := make([]profileView, 0, len(profiles)+1)
+			views = append(views, profileView{Name: domain.DefaultProfileName, Current: current == domain.DefaultProfileName})
+			for _, p := range profiles {
+				views = append(views, newProfileView(p, current == p.Name))
+			}
+
+			return renderList(cmd, profileListOutputKind, views, func(out io.Writer) error {
+				w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+				fmt.Fprintf(w, "%s %s\t%s\n", currentMark(current == domain.DefaultProfileName),
+					domain.DefaultProfileName, "(from configuration)")
+				for _, p := range profiles {
+					fmt.Fprintf(w, "%s %s\t%s\n", currentMark(current == p.Name), p.Name, p.ServerAddr)
+				}
+				return w.Flush()
+			})
+		},
+	}
+}
+
+// newProfileUseCmd creates a cobra command that selects the current profile
+func newProfileUseCmd(ctx context.Context, profileService domain.ProfileService) *cobra.Command {
+	return &cobra.Command{
+		Use:   "use <name>",
+		Short: "Select the current profile",
+		Long:  "Select the profile used by subsequent commands; 'default' selects the configuration settings",
+		Args:  cobra.ExactArgs(1),
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := profileService.UseProfile(ctx, args[0]); err != nil {
+				log.Error().Err(err).Msg("failed to select profile")
+				return profileError(err, args[0], "failed to select profile")
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Switched to profile '%s'\n", args[0])
+			return nil
+		},
+	}
+}
+
+// newProfileAddCmd creates a cobra command that adds a profile
+func newProfileAddCmd(ctx context.Context, profileService domain.ProfileService) *cobra.Command {
+	var (
+		profile domain.Profile
+		use     bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "add <name>",
+		Short: "Add a profile",
+		Long:  "Add a profile with its own server address, TLS certificate and request timeout",
+		Args:  cobra.ExactArgs(1),
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			profile.Name = args[0]
+			if err := profileService.AddProfile(ctx, profile); err != nil {
+				log.Error().Err(err).Msg("failed to add profile")
+				return profileError(err, profile.Name, "failed to add profile")
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Profile '%s' added\n", profile.Name)
+
+			if use {
+				if err := profileService.UseProfile(ctx, profile.Name); err != nil {
+					log.Error().Err(err).Msg("failed to select profile")
+					return profileError(err, profile.Name, "failed to select profile")
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "Switched to profile '%s'\n", profile.Name)
+			}
+			return nil
+		},
+	}
+
+	// Named apart from the global --server, --tls-cert and --timeout, which override the active connection
+	cmd.Flags().StringVar(&profile.ServerAddr, "address", "", "gRPC server address (required)")
+	cmd.Flags().StringVar(&profile.TLSCertPath, "cert", "", "Path to the server TLS certificate")
+	cmd.Flags().DurationVar(&profile.Timeout, "request-timeout", 0, "Request timeout (default: from configuration)")
+	cmd.Flags().BoolVar(&use, "use", false, "Select the profile after adding it")
+
+	_ = cmd.MarkFlagRequired("address")
+
+	return cmd
+}
+
+// newProfileRemoveCmd creates a cobra command that removes a profile
+func newProfileRemoveCmd(ctx context.Context, profileService domain.ProfileService) *cobra.Command {
+	return &cobra.Command{
+		Use:   "remove <name>",
+		Short: "Remove a profile",
+		Long:  "Remove a profile and its session token; the local vault of the profile is kept",
+		Args:  cobra.ExactArgs(1),
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := profileService.RemoveProfile(ctx, args[0]); err != nil {
+				log.Error().Err(err).Msg("failed to remove profile")
+				return profileError(err, args[0], "failed to remove profile")
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Profile '%s' removed\n", args[0])
+			return nil
+		},
+	}
+}
+
+// profileError converts profile service errors into user facing errors.
+func profileError(err error, name, msg string) error {
+	switch {
+	case errors.Is(err, domain.ErrProfileNotFound):
+		return fmt.Errorf("%w: '%s'", domain.ErrProfileNotFound, name)
+	case errors.Is(err, domain.ErrProfileAlreadyExists):
+		return fmt.Errorf("%w: '%s'", domain.ErrProfileAlreadyExists, name)
+	case errors.Is(err, domain.ErrInvalidProfile):
+		return err
+	default:
+		return errors.New(msg)
+	}
+}
+
+// currentMark returns the marker used for the current profile in listings.
+func currentMark(current bool) string {
+	if current {
+		return "*"
+	}
+	return " "
+}
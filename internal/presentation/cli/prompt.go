@@ -0,0 +1,146 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/domain"
+	"golang.org/x/term"
+)
+
+var (
+	ErrNoTerminal        = errors.New("no terminal available to prompt for input")
+	ErrPasswordsMismatch = errors.New("passwords do not match")
+	ErrEmptyPassword     = errors.New("master password cannot be empty")
+)
+
+// PromptMasterPassword reads the master password from the controlling terminal without echo.
+// When confirm is true the user has to type the password twice.
+func PromptMasterPassword(confirm bool) (string, error) {
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return "", fmt.Errorf("%w, set MASTER_PASSWORD", ErrNoTerminal)
+	}
+	defer tty.Close()
+
+	password, err := readPassword(tty, "Master password: ")
+	if err != nil {
+		return "", err
+	}
+	if password == "" {
+		return "", ErrEmptyPassword
+	}
+
+	if confirm {
+		repeated, err := readPassword(tty, "Repeat master password: ")
+		if err != nil {
+			return "", err
+		}
+		if repeated != password {
+			return "", ErrPasswordsMismatch
+		}
+	}
+
+	return password, nil
+}
+
+// PromptCredentials reads the account login and password from the controlling terminal.
+func PromptCredentials() (login, password string, err error) {
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return "", "", ErrNoTerminal
+	}
+	defer tty.Close()
+
+	fmt.Fprint(tty, "Login: ")
+	login, err = bufio.NewReader(tty).ReadString('\n')
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read login: %w", err)
+	}
+
+	password, err = readPassword(tty, "Password: ")
+	if err != nil {
+		return "", "", err
+	}
+	return strings.TrimSpace(login), password, nil
+}
+
+// promptSecret reads a secret value from the controlling terminal without echo.
+func promptSecret(prompt string) (string, error) {
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return "", ErrNoTerminal
+	}
+	defer tty.Close()
+
+	return readPassword(tty, prompt)
+}
+
+// ReauthPrompt returns a function that asks the user to log in again when the session is no longer valid.
+// The login request is limited by timeout, which starts after the credentials are entered.
+func ReauthPrompt(authService domain.AuthService, timeout time.Duration) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		if tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0); err == nil {
+			fmt.Fprintln(tty, "Session expired or missing, please log in")
+			tty.Close()
+		}
+
+		login, password, err := PromptCredentials()
+		if err != nil {
+			return err
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		return authService.Login(ctx, login, password)
+	}
+}
+
+// WarnSessionExpiry returns a function that warns the user that the session is about to expire.
+func WarnSessionExpiry(w io.Writer) func(expiresAt time.Time) {
+	return func(expiresAt time.Time) {
+		fmt.Fprintf(w, "Warning: session expires in %s, run 'login' to renew it\n",
+			time.Until(expiresAt).Round(time.Second))
+	}
+}
+
+// WarnPlaintextSecret returns a function warning on w that a secret was received unencrypted.
+func WarnPlaintextSecret(w io.Writer) func(secretName string) {
+	return func(secretName string) {
+		fmt.Fprintf(w, "Warning: '%s' is stored unencrypted on the server, store it again to encrypt it\n", secretName)
+	}
+}
+
+// readPassword prints the prompt and reads a line from the terminal without echo.
+func readPassword(tty *os.File, prompt string) (string, error) {
+	fmt.Fprint(tty, prompt)
+	password, err := term.ReadPassword(int(tty.Fd()))
+	fmt.Fprintln(tty)
+	if err != nil {
+		return "", fmt.Errorf("failed to read password: %w", err)
+	}
+	return string(password), nil
+}
+
+// promptLine prints the prompt and reads a line from the controlling terminal.
+// The terminal is used instead of stdin, which may carry secret content.
+func promptLine(prompt string) (string, error) {
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return "", ErrNoTerminal
+	}
+	defer tty.Close()
+
+	fmt.Fprint(tty, prompt)
+	line, err := bufio.NewReader(tty).ReadString('\n')
+	if err != nil {
+		return "", fmt.Errorf("failed to read answer: %w", err)
+	}
+	return line, nil
+}
@@ -0,0 +1,167 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/rs/zerolog/log"
+	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/domain"
+)
+
+// maxReferenceSize is the largest text or file content a reference resolves to.
+const maxReferenceSize = 1024 * 1024
+
+// referenceFields lists the fields a reference can select, per secret type.
+var referenceFields = map[domain.SecretType][]string{
+	domain.CredentialsSecretType: {"login", "password", "url", "notes", "otp"},
+	domain.PaymentCardSecretType: {"number", "network", "holder", "expiry", "cvv", "pin", "issuer", "notes"},
+	domain.SSHKeySecretType:      {"algorithm", "public_key", "fingerprint", "private_key", "passphrase"},
+	domain.TextSecretType:        {"content"},
+	domain.FileSecretType:        {"content"},
+}
+
+// secretRef references a field of a secret version. Version 0 stands for the latest one.
+type secretRef struct {
+	name    string
+	field   string
+	version int32
+}
+
+// parseSecretRef parses a reference written as <secret>.<field>.
+// The field follows the last dot, so secret names may contain dots.
+func parseSecretRef(s string) (secretRef, error) {
+	i := strings.LastIndex(s, ".")
+	if i <= 0 || i == len(s)-1 {
+		return secretRef{}, fmt.Errorf("invalid reference '%s' (must be <secret>.<field>)", s)
+	}
+	return secretRef{name: s[:i], field: s[i+1:]}, nil
+}
+
+// secretVersionKey identifies a retrieved secret version.
+type secretVersionKey struct {
+	name    string
+	version int32
+}
+
+// resolvedSecret holds the type and fields of a retrieved secret version.
+type resolvedSecret struct {
+	secretType domain.SecretType
+	fields     map[string]string
+}
+
+// secretResolver resolves references to secret fields, retrieving every secret version once.
+type secretResolver struct {
+	ctx           context.Context
+	secretService domain.SecretService
+
+	secrets map[secretVersionKey]resolvedSecret
+}
+
+// newSecretResolver creates a resolver for the secrets of the service.
+func newSecretResolver(ctx context.Context, secretService domain.SecretService) *secretResolver {
+	return &secretResolver{
+		ctx:           ctx,
+		secretService: secretService,
+		secrets:       make(map[secretVersionKey]resolvedSecret),
+	}
+}
+
+// resolve returns the value of the referenced field. Empty fields are an error,
+// so a missing value is never injected silently.
+func (r *secretResolver) resolve(ref secretRef) (string, error) {
+	key := secretVersionKey{name: ref.name, version: ref.version}
+	secret, ok := r.secrets[key]
+	if !ok {
+		var err error
+		if secret, err = r.load(ref.name, ref.version); err != nil {
+			return "", err
+		}
+		r.secrets[key] = secret
+	}
+
+	secretType := secret.secretType
+	if !slices.Contains(referenceFields[secretType], ref.field) {
+		return "", fmt.Errorf("unknown field '%s' of %s secret '%s' (must be one of %s)",
+			ref.field, secretType, ref.name, strings.Join(referenceFields[secretType], ", "))
+	}
+	value := secret.fields[ref.field]
+	if value == "" {
+		return "", fmt.Errorf("field '%s' of '%s' is empty", ref.field, ref.name)
+	}
+	return value, nil
+}
+
+// load retrieves a secret version, the latest one if version is 0, and returns its type and fields.
+// The type is looked up for that version, since a secret may change type between versions.
+func (r *secretResolver) load(name string, version int32) (resolvedSecret, error) {
+	info, err := r.secretService.GetSecretInfo(r.ctx, name, version)
+	if errors.Is(err, domain.ErrSecretNotFound) {
+		if version == 0 {
+			return resolvedSecret{}, fmt.Errorf("secret '%s' not found", name)
+		}
+		return resolvedSecret{}, fmt.Errorf("version %d of secret '%s' not found", version, name)
+	}
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed to retrieve secret '%s'", name)
+		return resolvedSecret{}, fmt.Errorf("failed to retrieve secret '%s'", name)
+	}
+
+	content, _, err := secretVersionContent(r.ctx, r.secretService, *info)
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed to retrieve secret '%s'", name)
+		return resolvedSecret{}, fmt.Errorf("failed to retrieve secret '%s'", name)
+	}
+	data, err := io.ReadAll(io.LimitReader(content, maxReferenceSize+1))
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed to read secret '%s'", name)
+		return resolvedSecret{}, fmt.Errorf("failed to read secret '%s'", name)
+	}
+
+	var columns []column
+	switch info.Type {
+	case domain.CredentialsSecretType:
+		var creds domain.CredentialsSecret
+		if err := json.Unmarshal(data, &creds); err != nil {
+			log.Error().Err(err).Msg("Failed to decode credentials")
+			return resolvedSecret{}, fmt.Errorf("failed to decode credentials")
+		}
+		columns = newCredentialsView(creds).columns()
+	case domain.PaymentCardSecretType:
+		var card domain.PaymentCardSecret
+		if err := json.Unmarshal(data, &card); err != nil {
+			log.Error().Err(err).Msg("Failed to decode card data")
+			return resolvedSecret{}, fmt.Errorf("failed to decode card data")
+		}
+		columns = newPaymentCardView(card, true).columns()
+	case domain.SSHKeySecretType:
+		key, err := decodeSSHKey(string(data))
+		if err != nil {
+			return resolvedSecret{}, err
+		}
+		publicKey, err := key.PublicKey()
+		if err != nil {
+			return resolvedSecret{}, err
+		}
+		columns = newSSHKeyView(key, publicKey, true).columns()
+	default:
+		if len(data) > maxReferenceSize {
+			return resolvedSecret{}, fmt.Errorf("'%s' is larger than %d bytes and cannot be referenced", name, maxReferenceSize)
+		}
+		if !utf8.Valid(data) {
+			return resolvedSecret{}, fmt.Errorf("'%s' is not text and cannot be referenced", name)
+		}
+		columns = []column{{key: "content", value: string(data)}}
+	}
+
+	fields := make(map[string]string, len(columns))
+	for _, c := range columns {
+		fields[c.key] = c.value
+	}
+	return resolvedSecret{secretType: info.Type, fields: fields}, nil
+}
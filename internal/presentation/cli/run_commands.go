@@ -0,0 +1,286 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"regexp"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/domain"
+)
+
+// runKillDelay is how long a child has to exit after it was asked to terminate before it is killed.
+const runKillDelay = 10 * time.Second
+
+// envNamePattern matches valid environment variable names.
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ExitError reports the exit status of a child process, which the program should exit with.
+type ExitError struct {
+	Code int
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("command exited with status %d", e.Code)
+}
+
+// envRef maps an environment variable to a secret field.
+type envRef struct {
+	name string
+	ref  secretRef
+}
+
+// newRunCmd creates a command that runs a child process with secrets in its environment.
+func newRunCmd(ctx context.Context, secretService domain.SecretService) *cobra.Command {
+	var (
+		envs    []string
+		envFile string
+		noMask  bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "run [flags] -- command [args...]",
+		Short: "Run a command with secrets in its environment",
+		Long: `Runs a command with environment variables set to secret fields, referenced as <secret>.<field>:
+  gophkeeper-cli run --env DB_PASS=db-prod.password -- ./deploy.sh
+--env-file reads a YAML mapping of variable names to references; --env takes precedence.
+Every reference is resolved before the command starts, a missing secret or empty field is an error.
+Injected values are masked in the output of the command unless --no-mask is given,
+which also connects the command to the terminal directly.
+SIGINT, SIGTERM and SIGHUP received by this command are passed on to the child,
+and the exit status of the child is returned.`,
+		Args: cobra.MinimumNArgs(1),
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			refs, err := loadEnvRefs(envFile, envs)
+			if err != nil {
+				return err
+			}
+
+			resolver := newSecretResolver(ctx, secretService)
+			env := os.Environ()
+			values := make([]string, 0, len(refs))
+			for _, r := range refs {
+				value, err := resolver.resolve(r.ref)
+				if err != nil {
+					return fmt.Errorf("failed to resolve %s: %w", r.name, err)
+				}
+				env = append(env, r.name+"="+value)
+				values = append(values, value)
+			}
+
+			signals := make(chan os.Signal, 1)
+			signal.Notify(signals, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+			defer signal.Stop(signals)
+
+			runCtx, cancel := context.WithCancel(untilCanceled(ctx))
+			defer cancel()
+
+			// The child gets the signal this command received, or SIGTERM if the context was canceled
+			var received atomic.Value
+			child := exec.CommandContext(runCtx, args[0], args[1:]...)
+			child.Cancel = func() error {
+				sig, ok := received.Load().(os.Signal)
+				if !ok {
+					sig = syscall.SIGTERM
+				}
+				return child.Process.Signal(sig)
+			}
+			child.WaitDelay = runKillDelay
+			child.Env = env
+			child.Stdin = cmd.InOrStdin()
+
+			stdout, stderr := cmd.OutOrStdout(), cmd.ErrOrStderr()
+			if !noMask {
+				maskedOut, maskedErr := newMaskingWriter(stdout, values), newMaskingWriter(stderr, values)
+				defer maskedOut.Close()
+				defer maskedErr.Close()
+				stdout, stderr = maskedOut, maskedErr
+			}
+			child.Stdout, child.Stderr = stdout, stderr
+
+			if err := child.Start(); err != nil {
+				return exitStatus(cmd, err, args[0])
+			}
+			done := make(chan struct{})
+			defer close(done)
+			go forwardSignals(signals, done, child.Process, func(sig os.Signal) bool {
+				if runCtx.Err() != nil {
+					return false
+				}
+				received.Store(sig)
+				cancel()
+				return true
+			})
+
+			return exitStatus(cmd, child.Wait(), args[0])
+		},
+	}
+
+	cmd.Flags().StringArrayVar(&envs, "env", nil, "Set a variable to a secret field, as NAME=<secret>.<field> (repeatable)")
+	cmd.Flags().StringVar(&envFile, "env-file", "", "YAML file mapping variable names to <secret>.<field> references")
+	cmd.Flags().BoolVar(&noMask, "no-mask", false, "Do not mask injected values in the output of the command")
+	// Flags after the command name belong to the command
+	cmd.Flags().SetInterspersed(false)
+
+	return cmd
+}
+
+// forwardSignals passes the signals received until done to the child process. A signal is passed
+// through terminate while it cancels the child, so the child is killed if it does not exit in time;
+// once the child is being terminated, signals are sent to it directly.
+func forwardSignals(signals <-chan os.Signal, done <-chan struct{}, process *os.Process, terminate func(os.Signal) bool) {
+	for {
+		select {
+		case sig := <-signals:
+			if terminate(sig) {
+				continue
+			}
+			if err := process.Signal(sig); err != nil {
+				log.Debug().Err(err).Msgf("Failed to forward %s", sig)
+			}
+		case <-done:
+			return
+		}
+	}
+}
+
+// loadEnvRefs merges the references of the env file and the --env flags, sorted by variable name.
+func loadEnvRefs(envFile string, envs []string) ([]envRef, error) {
+	mapping := make(map[string]string)
+	if envFile != "" {
+		data, err := os.ReadFile(envFile)
+		if err != nil {
+			log.Error().Err(err).Msgf("Failed to read env file '%s'", envFile)
+			return nil, fmt.Errorf("failed to read env file '%s'", envFile)
+		}
+		if err := yaml.Unmarshal(data, &mapping); err != nil {
+			return nil, fmt.Errorf("invalid env file '%s': %w", envFile, err)
+		}
+	}
+	for _, e := range envs {
+		name, ref, ok := strings.Cut(e, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid --env '%s' (must be NAME=<secret>.<field>)", e)
+		}
+		mapping[name] = ref
+	}
+	if len(mapping) == 0 {
+		return nil, fmt.Errorf("no variables to set, use --env or --env-file")
+	}
+
+	refs := make([]envRef, 0, len(mapping))
+	for name, value := range mapping {
+		if !envNamePattern.MatchString(name) {
+			return nil, fmt.Errorf("invalid environment variable name '%s'", name)
+		}
+		ref, err := parseSecretRef(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid reference of %s: %w", name, err)
+		}
+		refs = append(refs, envRef{name: name, ref: ref})
+	}
+	sort.Slice(refs, func(i, j int) bool { return refs[i].name < refs[j].name })
+	return refs, nil
+}
+
+// exitStatus converts the result of the child into the result of the command.
+// A non-zero exit status is returned as an ExitError without printing an error;
+// a child killed by a signal exits with 128 plus the signal number, like in a shell.
+func exitStatus(cmd *cobra.Command, err error, name string) error {
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		if err != nil {
+			return fmt.Errorf("failed to run '%s': %w", name, err)
+		}
+		return nil
+	}
+
+	code := exitErr.ExitCode()
+	if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+		code = 128 + int(status.Signal())
+	}
+	cmd.SilenceErrors = true
+	return &ExitError{Code: code}
+}
+
+// maskingWriter replaces secret values in the output written through it.
+// A tail that may be the start of a value split across writes is held back until the next write or Close.
+type maskingWriter struct {
+	w       io.Writer
+	values  [][]byte
+	pending []byte
+}
+
+// newMaskingWriter creates a writer masking the non-empty values, longest first.
+func newMaskingWriter(w io.Writer, values []string) *maskingWriter {
+	m := &maskingWriter{w: w}
+	for _, v := range values {
+		if v != "" {
+			m.values = append(m.values, []byte(v))
+		}
+	}
+	sort.Slice(m.values, func(i, j int) bool { return len(m.values[i]) > len(m.values[j]) })
+	return m
+}
+
+// Write masks p and writes everything but a possible partial value.
+func (m *maskingWriter) Write(p []byte) (int, error) {
+	m.pending = append(m.pending, p...)
+	if err := m.flush(false); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close writes the held back tail.
+func (m *maskingWriter) Close() error {
+	return m.flush(true)
+}
+
+// flush writes the pending output with the values masked.
+func (m *maskingWriter) flush(final bool) error {
+	var out bytes.Buffer
+	i := 0
+scan:
+	for i < len(m.pending) {
+		rest := m.pending[i:]
+		for _, v := range m.values {
+			if bytes.HasPrefix(rest, v) {
+				out.WriteString(maskedValue)
+				i += len(v)
+				continue scan
+			}
+		}
+		if !final {
+			for _, v := range m.values {
+				if len(rest) < len(v) && bytes.HasPrefix(v, rest) {
+					break scan
+				}
+			}
+		}
+		out.WriteByte(rest[0])
+		i++
+	}
+
+	m.pending = append(m.pending[:0], m.pending[i:]...)
+	if out.Len() == 0 {
+		return nil
+	}
+	_, err := m.w.Write(out.Bytes())
+	return err
+}
@@ -8,6 +8,8 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"slices"
+	"sort"
 	"strings"
 
 	"github.com/rs/zerolog/log"
@@ -16,13 +18,23 @@ import (
 )
 
 // newCreateCredentialsSecretCmd creates a command for storing credential secret.
-func newCreateCredentialsSecretCmd(ctx context.Context, secretService domain.SecretService) *cobra.Command {
-	var name, login, password, metadata string
+func newCreateCredentialsSecretCmd(ctx context.Context, secretService domain.SecretService,
+	breaches breachSource) *cobra.Command {
+	var (
+		name, metadata, hashFile string
+		checkBreaches            bool
+		flags                    credentialsFlags
+		conflict                 conflictFlags
+	)
 
 	cmd := &cobra.Command{
 		Use:   "create-credentials",
 		Short: "Store login/password credentials",
-		Long:  "Securely stores username/password combinations with optional metadata",
+		Long: `Securely stores username/password combinations with optional metadata.
+A TOTP or HOTP seed can be imported from an otpauth:// URI with --import-uri.
+With --generate a random password is stored instead, see 'generate --help' for its options.
+With --check-breaches the password is checked against a local Pwned Passwords hash file first.`,
+		Annotations: map[string]string{secretNamesAnnotation: "true"},
 
 		RunE: func(cmd *cobra.Command, args []string) error {
 			secret := domain.Secret{
@@ -32,9 +44,17 @@ func newCreateCredentialsSecretCmd(ctx context.Context, secretService domain.Sec
 					Type:     domain.CredentialsSecretType,
 				},
 			}
-			credentials := domain.CredentialsSecret{
-				Login:    login,
-				Password: password,
+			var credentials domain.CredentialsSecret
+			if err := flags.apply(cmd, &credentials); err != nil {
+				return err
+			}
+			if credentials.Login == "" {
+				return fmt.Errorf("login is required, set --login or --import-uri with an account name")
+			}
+			if checkBreaches || cmd.Flags().Changed("hash-file") {
+				if err := breaches.warnIfBreached(cmd, hashFile, credentials.Password); err != nil {
+					return err
+				}
 			}
 			marshaled, err := json.Marshal(credentials)
 			if err != nil {
@@ -42,37 +62,48 @@ func newCreateCredentialsSecretCmd(ctx context.Context, secretService domain.Sec
 				return fmt.Errorf("failed to marshal credentials")
 			}
 
-			if err = secretService.CreateSecret(ctx, secret, bytes.NewReader(marshaled)); err != nil {
+			stored, err := storeSecret(ctx, cmd, secretService, secret, bytes.NewReader(marshaled), conflict)
+			if err != nil {
 				log.Error().Err(err).Msg("failed to create secret")
 				return fmt.Errorf("failed to create secret: %w", err)
 			}
 
-			fmt.Fprintf(cmd.OutOrStdout(), "Successfully stored credentials for '%s'\n", name)
+			if stored {
+				fmt.Fprintf(cmd.OutOrStdout(), "Successfully stored credentials for '%s'\n", name)
+			}
 
 			return nil
 		},
 	}
 
 	cmd.Flags().StringVarP(&name, "name", "n", "", "Unique name for the credentials (required)")
-	cmd.Flags().StringVarP(&login, "login", "l", "", "Username/login (required)")
-	cmd.Flags().StringVarP(&password, "password", "p", "", "Password (required)")
 	cmd.Flags().StringVarP(&metadata, "metadata", "m", "", "Optional metadata")
+	addCredentialsFlags(cmd, &flags)
+	cmd.Flags().BoolVar(&checkBreaches, "check-breaches", false, "Warn if the password was seen in data breaches")
+	cmd.Flags().StringVar(&hashFile, "hash-file", "",
+		"Path to the SHA-1 hash file for --check-breaches (default: the breach_file setting)")
+	addConflictFlags(cmd, &conflict)
 
 	_ = cmd.MarkFlagRequired("name")
-	_ = cmd.MarkFlagRequired("login")
-	_ = cmd.MarkFlagRequired("password")
+	cmd.MarkFlagsOneRequired("password", "generate")
 
 	return cmd
 }
 
 // newCreatePaymentCardSecretCmd creates a command for storing payment card information.
 func newCreatePaymentCardSecretCmd(ctx context.Context, secretService domain.SecretService) *cobra.Command {
-	var name, number, metadata string
+	var (
+		name, metadata string
+		card           cardFlags
+		conflict       conflictFlags
+	)
 
 	cmd := &cobra.Command{
 		Use:   "create-paymentcard",
 		Short: "Store payment card information",
-		Long:  `Securely stores payment card details with optional metadata`,
+		Long: `Securely stores payment card details with optional metadata.
+The number is checked against the Luhn checksum and the lengths of its network (Visa, Mastercard, MIR, Amex).`,
+		Annotations: map[string]string{secretNamesAnnotation: "true"},
 
 		RunE: func(cmd *cobra.Command, args []string) error {
 			secret := domain.Secret{
@@ -82,10 +113,11 @@ func newCreatePaymentCardSecretCmd(ctx context.Context, secretService domain.Sec
 					Type:     domain.PaymentCardSecretType,
 				},
 			}
-			credentials := domain.PaymentCardSecret{
-				Number: number,
+			var paymentCard domain.PaymentCardSecret
+			if err := card.apply(cmd, &paymentCard); err != nil {
+				return err
 			}
-			marshaled, err := json.Marshal(credentials)
+			marshaled, err := json.Marshal(paymentCard)
 			if err != nil {
 				log.Error().Err(err).Msg("failed to marshal card data")
 				return fmt.Errorf("failed to marshal card data")
@@ -93,9 +125,9 @@ func newCreatePaymentCardSecretCmd(ctx context.Context, secretService domain.Sec
 
 			reader := bytes.NewReader(marshaled)
 
-			if err = secretService.CreateSecret(ctx, secret, reader); err != nil {
+			if _, err = storeSecret(ctx, cmd, secretService, secret, reader, conflict); err != nil {
 				log.Error().Err(err).Msg("failed to create secret")
-				return fmt.Errorf("failed to marshal card data")
+				return storeSecretError(err, "failed to create secret")
 			}
 
 			return nil
@@ -103,8 +135,9 @@ func newCreatePaymentCardSecretCmd(ctx context.Context, secretService domain.Sec
 	}
 
 	cmd.Flags().StringVarP(&name, "name", "n", "", "Unique name for the card (required)")
-	cmd.Flags().StringVarP(&number, "number", "c", "", "Card number (required)")
 	cmd.Flags().StringVarP(&metadata, "metadata", "m", "", "Optional metadata")
+	addCardFlags(cmd, &card)
+	addConflictFlags(cmd, &conflict)
 
 	_ = cmd.MarkFlagRequired("name")
 	_ = cmd.MarkFlagRequired("number")
@@ -114,12 +147,16 @@ func newCreatePaymentCardSecretCmd(ctx context.Context, secretService domain.Sec
 
 // newCreateTextSecretCmd creates a command for storing text secrets with interactive input.
 func newCreateTextSecretCmd(ctx context.Context, secretService domain.SecretService) *cobra.Command {
-	var name, metadata string
+	var (
+		name, metadata string
+		conflict       conflictFlags
+	)
 
 	cmd := &cobra.Command{
-		Use:   "create-text",
-		Short: "Store text content interactively",
-		Long:  `Stream text content to be stored securely. Type 'end' on a new line to finish input.`,
+		Use:         "create-text",
+		Short:       "Store text content interactively",
+		Long:        `Stream text content to be stored securely. Type 'end' on a new line to finish input.`,
+		Annotations: map[string]string{secretNamesAnnotation: "true"},
 
 		RunE: func(cmd *cobra.Command, args []string) error {
 			secret := domain.Secret{
@@ -130,27 +167,9 @@ func newCreateTextSecretCmd(ctx context.Context, secretService domain.SecretServ
 				},
 			}
 
-			pr, pw := io.Pipe()
-
-			go func() {
-				defer pw.Close()
-				scanner := bufio.NewScanner(os.Stdin)
-
-				for scanner.Scan() {
-					line := scanner.Text()
-					if strings.TrimSpace(line) == "end" {
-						break
-					}
-					if _, err := pw.Write([]byte(line + "\n")); err != nil {
-						log.Error().Err(err).Msg("Error writing text")
-						return
-					}
-				}
-			}()
-
-			if err := secretService.CreateSecret(ctx, secret, pr); err != nil {
+			if _, err := storeSecret(ctx, cmd, secretService, secret, readTextInput(os.Stdin), conflict); err != nil {
 				log.Error().Err(err).Msg("Failed to store text")
-				return fmt.Errorf("failed to store text")
+				return storeSecretError(err, "failed to store text")
 			}
 
 			return nil
@@ -159,6 +178,7 @@ func newCreateTextSecretCmd(ctx context.Context, secretService domain.SecretServ
 
 	cmd.Flags().StringVarP(&name, "name", "n", "", "Name for the text content (required)")
 	cmd.Flags().StringVarP(&metadata, "metadata", "m", "", "Optional metadata")
+	addConflictFlags(cmd, &conflict)
 
 	_ = cmd.MarkFlagRequired("name")
 
@@ -167,12 +187,16 @@ func newCreateTextSecretCmd(ctx context.Context, secretService domain.SecretServ
 
 // newCreateFileSecretCmd creates a command for storing file secrets.
 func newCreateFileSecretCmd(ctx context.Context, secretService domain.SecretService) *cobra.Command {
-	var name, metadata, filePath string
+	var (
+		name, metadata, filePath string
+		conflict                 conflictFlags
+	)
 
 	cmd := &cobra.Command{
-		Use:   "create-file",
-		Short: "Store a file securely",
-		Long:  `Uploads and securely stores a file from the local filesystem`,
+		Use:         "create-file",
+		Short:       "Store a file securely",
+		Long:        `Uploads and securely stores a file from the local filesystem`,
+		Annotations: map[string]string{secretNamesAnnotation: "true"},
 
 		RunE: func(cmd *cobra.Command, args []string) error {
 			file, err := os.Open(filePath)
@@ -190,12 +214,15 @@ func newCreateFileSecretCmd(ctx context.Context, secretService domain.SecretServ
 				},
 			}
 
-			if err = secretService.CreateSecret(ctx, secret, file); err != nil {
+			stored, err := storeSecret(ctx, cmd, secretService, secret, file, conflict)
+			if err != nil {
 				log.Error().Err(err).Msgf("Failed to store file '%s' in secret storage", filePath)
-				return fmt.Errorf("failed to store file '%s' in secret storage", filePath)
+				return storeSecretError(err, fmt.Sprintf("failed to store file '%s' in secret storage", filePath))
 			}
 
-			fmt.Fprintf(cmd.OutOrStdout(), "Successfully stored file '%s' as '%s'\n", filePath, name)
+			if stored {
+				fmt.Fprintf(cmd.OutOrStdout(), "Successfully stored file '%s' as '%s'\n", filePath, name)
+			}
 			return nil
 		},
 	}
@@ -203,6 +230,7 @@ func newCreateFileSecretCmd(ctx context.Context, secretService domain.SecretServ
 	cmd.Flags().StringVarP(&name, "name", "n", "", "Unique name for the file (required)")
 	cmd.Flags().StringVarP(&filePath, "file", "f", "", "Path to file (required)")
 	cmd.Flags().StringVarP(&metadata, "metadata", "m", "", "Optional metadata")
+	addConflictFlags(cmd, &conflict)
 
 	_ = cmd.MarkFlagRequired("name")
 	_ = cmd.MarkFlagRequired("file")
@@ -212,37 +240,152 @@ func newCreateFileSecretCmd(ctx context.Context, secretService domain.SecretServ
 
 // newListSecretsCmd creates a command to list all stored secrets
 func newListSecretsCmd(ctx context.Context, secretService domain.SecretService) *cobra.Command {
-	return &cobra.Command{
+	var (
+		sortBy  string
+		reverse bool
+		types   []string
+		columns []string
+	)
+
+	cmd := &cobra.Command{
 		Use:   "list",
 		Short: "List all stored secrets",
-		Long:  `Displays names and types of all stored secrets`,
+		Long:  `Displays name, type, latest version, metadata and creation time of all stored secrets`,
 
 		RunE: func(cmd *cobra.Command, args []string) error {
-			secrets, err := secretService.ListSecrets(ctx)
+			less, err := secretInfoOrder(sortBy)
+			if err != nil {
+				return err
+			}
+			filter, err := parseSecretTypes(types)
+			if err != nil {
+				return err
+			}
+			if err := validateColumns(columns, secretInfoColumns); err != nil {
+				return err
+			}
+
+			infos, err := secretService.ListSecretsInfo(ctx)
 			if err != nil {
 				log.Error().Err(err).Msg("Failed to list secrets")
 				return fmt.Errorf("failed to list secrets")
 			}
 
-			if len(secrets) == 0 {
-				fmt.Fprintln(os.Stdout, "No secrets found")
-				return nil
-			}
+			infos = filterSecretInfos(infos, filter)
+			sort.SliceStable(infos, func(i, j int) bool {
+				if reverse {
+					return less(infos[j], infos[i])
+				}
+				return less(infos[i], infos[j])
+			})
 
-			fmt.Fprintln(os.Stdout, "Stored secrets:")
-			for _, name := range secrets {
-				fmt.Fprintf(cmd.OutOrStdout(), "  - %s\n", name)
+			views := make([]selectedColumnsView[secretInfoView], len(infos))
+			for i, info := range infos {
+				views[i] = selectedColumnsView[secretInfoView]{view: newSecretInfoView(info), keys: columns}
 			}
-			return nil
+
+			return renderList(cmd, secretListOutputKind, views, func(w io.Writer) error {
+				if len(views) == 0 {
+					fmt.Fprintln(w, "No secrets found")
+					return nil
+				}
+
+				tableViews := make([]view, len(views))
+				for i, v := range views {
+					tableViews[i] = v
+				}
+				return renderTable(w, tableViews)
+			})
 		},
 	}
+
+	cmd.Flags().StringVar(&sortBy, "sort", "name", "Sort secrets by name, type, version or created")
+	cmd.Flags().BoolVarP(&reverse, "reverse", "r", false, "Reverse the sort order")
+	cmd.Flags().StringSliceVarP(&types, "type", "t", nil, "Only list secrets of these types")
+	cmd.Flags().StringSliceVar(&columns, "columns", secretInfoColumns,
+		"Columns to show in text, table and env output")
+
+	return cmd
+}
+
+// secretInfoOrder returns the ordering of secret infos for the sort key. Ties are ordered by name.
+func secretInfoOrder(sortBy string) (func(a, b domain.SecretInfo) bool, error) {
+	switch sortBy {
+	case "name":
+		return func(a, b domain.SecretInfo) bool { return a.Name < b.Name }, nil
+	case "type":
+		return func(a, b domain.SecretInfo) bool {
+			if a.Type != b.Type {
+				return a.Type < b.Type
+			}
+			return a.Name < b.Name
+		}, nil
+	case "version":
+		return func(a, b domain.SecretInfo) bool {
+			if a.Version != b.Version {
+				return a.Version < b.Version
+			}
+			return a.Name < b.Name
+		}, nil
+	case "created":
+		return func(a, b domain.SecretInfo) bool {
+			if !a.CreatedAt.Equal(b.CreatedAt) {
+				return a.CreatedAt.Before(b.CreatedAt)
+			}
+			return a.Name < b.Name
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown sort key '%s' (must be name, type, version or created)", sortBy)
+	}
+}
+
+// parseSecretTypes converts type flag values into a set of secret types.
+func parseSecretTypes(types []string) (map[domain.SecretType]bool, error) {
+	set := make(map[domain.SecretType]bool, len(types))
+	for _, t := range types {
+		secretType, err := domain.ParseSecretType(t)
+		if err != nil {
+			return nil, err
+		}
+		set[secretType] = true
+	}
+	return set, nil
+}
+
+// filterSecretInfos keeps the secrets of the given types. An empty set keeps all secrets.
+func filterSecretInfos(infos []domain.SecretInfo, types map[domain.SecretType]bool) []domain.SecretInfo {
+	if len(types) == 0 {
+		return infos
+	}
+
+	filtered := make([]domain.SecretInfo, 0, len(infos))
+	for _, info := range infos {
+		if types[info.Type] {
+			filtered = append(filtered, info)
+		}
+	}
+	return filtered
+}
+
+// validateColumns checks that every selected column is known.
+func validateColumns(selected, known []string) error {
+	if len(selected) == 0 {
+		return fmt.Errorf("at least one column must be selected")
+	}
+	for _, c := range selected {
+		if !slices.Contains(known, c) {
+			return fmt.Errorf("unknown column '%s' (must be one of %s)", c, strings.Join(known, ", "))
+		}
+	}
+	return nil
 }
 
 // newGetCredentialsSecretCmd creates a command to retrieve credentials
-func newGetCredentialsSecretCmd(ctx context.Context, secretService domain.SecretService) *cobra.Command {
+func newGetCredentialsSecretCmd(ctx context.Context, secretService domain.SecretService, clipboard clipboardSource) *cobra.Command {
 	var (
 		name    string
 		version int32
+		clip    copyFlags
 	)
 
 	cmd := &cobra.Command{
@@ -272,20 +415,35 @@ func newGetCredentialsSecretCmd(ctx context.Context, secretService domain.Secret
 				return fmt.Errorf("failed to decode credentials")
 			}
 
-			fmt.Fprintf(cmd.OutOrStdout(), "Name: %s\n", secret.Info.Name)
-			fmt.Fprintf(cmd.OutOrStdout(), "Version: %d\n", secret.Info.Version)
-			if secret.Info.Metadata != "" {
-				fmt.Fprintf(cmd.OutOrStdout(), "Metadata: %s\n", secret.Info.Metadata)
+			if clip.copy {
+				return clipboard.copyColumn(ctx, cmd, clip, name, newCredentialsView(creds).columns())
 			}
-			fmt.Fprintf(cmd.OutOrStdout(), "Login: %s\n", creds.Login)
-			fmt.Fprintf(cmd.OutOrStdout(), "Password: %s\n", creds.Password)
 
-			return nil
+			v := secretView{
+				secretInfoView: newSecretInfoView(secret.Info),
+				Credentials:    ptr(newCredentialsView(creds)),
+			}
+			return renderOne(cmd, secretOutputKind, v, func(w io.Writer) error {
+				printSecretInfo(w, secret.Info)
+				fmt.Fprintf(w, "Login: %s\n", creds.Login)
+				fmt.Fprintf(w, "Password: %s\n", creds.Password)
+				if creds.URL != "" {
+					fmt.Fprintf(w, "URL: %s\n", creds.URL)
+				}
+				if creds.Notes != "" {
+					fmt.Fprintf(w, "Notes: %s\n", creds.Notes)
+				}
+				if creds.OTP != "" {
+					fmt.Fprintf(w, "OTP: %s\n", creds.OTP)
+				}
+				return nil
+			})
 		},
 	}
 
 	cmd.Flags().StringVarP(&name, "name", "n", "", "Name of credentials to retrieve (required)")
 	cmd.Flags().Int32VarP(&version, "version", "v", 0, "Specific version to retrieve (default: latest)")
+	addCopyFlags(cmd, &clip, clipboard, "password", "login", "password", "url", "notes", "otp")
 
 	_ = cmd.MarkFlagRequired("name")
 
@@ -293,10 +451,12 @@ func newGetCredentialsSecretCmd(ctx context.Context, secretService domain.Secret
 }
 
 // newGetPaymentCardSecretCmd creates a command to retrieve payment card details
-func newGetPaymentCardSecretCmd(ctx context.Context, secretService domain.SecretService) *cobra.Command {
+func newGetPaymentCardSecretCmd(ctx context.Context, secretService domain.SecretService, clipboard clipboardSource) *cobra.Command {
 	var (
 		name    string
 		version int32
+		reveal  bool
+		clip    copyFlags
 	)
 
 	cmd := &cobra.Command{
@@ -326,19 +486,27 @@ func newGetPaymentCardSecretCmd(ctx context.Context, secretService domain.Secret
 				return fmt.Errorf("failed to decode card data")
 			}
 
-			fmt.Fprintf(cmd.OutOrStdout(), "Name: %s\n", secret.Info.Name)
-			fmt.Fprintf(cmd.OutOrStdout(), "Version: %d\n", secret.Info.Version)
-			if secret.Info.Metadata != "" {
-				fmt.Fprintf(cmd.OutOrStdout(), "Metadata: %s\n", secret.Info.Metadata)
+			if clip.copy {
+				return clipboard.copyColumn(ctx, cmd, clip, name, newPaymentCardView(card, true).columns())
 			}
-			fmt.Fprintf(cmd.OutOrStdout(), "Card Number: %s\n", card.Number)
 
-			return nil
+			cardView := newPaymentCardView(card, reveal)
+			v := secretView{
+				secretInfoView: newSecretInfoView(secret.Info),
+				PaymentCard:    &cardView,
+			}
+			return renderOne(cmd, secretOutputKind, v, func(w io.Writer) error {
+				printSecretInfo(w, secret.Info)
+				printPaymentCard(w, cardView)
+				return nil
+			})
 		},
 	}
 
 	cmd.Flags().StringVarP(&name, "name", "n", "", "Name of card to retrieve (required)")
 	cmd.Flags().Int32VarP(&version, "version", "v", 0, "Specific version to retrieve (default: latest)")
+	cmd.Flags().BoolVar(&reveal, "reveal", false, "Show card number, CVV and PIN")
+	addCopyFlags(cmd, &clip, clipboard, "number", "number", "holder", "expiry", "cvv", "pin", "issuer", "notes")
 
 	_ = cmd.MarkFlagRequired("name")
 
@@ -346,10 +514,11 @@ func newGetPaymentCardSecretCmd(ctx context.Context, secretService domain.Secret
 }
 
 // newGetTextSecretCmd retrieves and displays a text secret
-func newGetTextSecretCmd(ctx context.Context, secretService domain.SecretService) *cobra.Command {
+func newGetTextSecretCmd(ctx context.Context, secretService domain.SecretService, clipboard clipboardSource) *cobra.Command {
 	var (
 		name    string
 		version int32
+		clip    copyFlags
 	)
 
 	cmd := &cobra.Command{
@@ -373,24 +542,42 @@ func newGetTextSecretCmd(ctx context.Context, secretService domain.SecretService
 				return fmt.Errorf("failed to retrieve text")
 			}
 
-			fmt.Fprintf(cmd.OutOrStdout(), "Name: %s\n", secretInfo.Name)
-			fmt.Fprintf(cmd.OutOrStdout(), "Version: %d\n", secretInfo.Version)
-			if secretInfo.Metadata != "" {
-				fmt.Fprintf(cmd.OutOrStdout(), "Metadata: %s\n", secretInfo.Metadata)
+			if clip.copy {
+				return clipboard.copyContent(ctx, cmd, clip, secretInfo.Name, reader)
+			}
+
+			format, err := outputFormat(cmd)
+			if err != nil {
+				return err
+			}
+
+			if format == TextOutputFormat {
+				printSecretInfo(cmd.OutOrStdout(), *secretInfo)
+				fmt.Fprintln(os.Stdout, "\nContent:")
+
+				if _, err = io.Copy(os.Stdout, reader); err != nil {
+					log.Error().Err(err).Msg("Failed to read secret data")
+					return fmt.Errorf("failed to read secret data")
+				}
+				return nil
 			}
-			fmt.Fprintln(os.Stdout, "\nContent:")
 
-			if _, err = io.Copy(os.Stdout, reader); err != nil {
+			// Structured formats need the whole content to build the document
+			content, err := io.ReadAll(reader)
+			if err != nil {
 				log.Error().Err(err).Msg("Failed to read secret data")
 				return fmt.Errorf("failed to read secret data")
 			}
+			text := string(content)
 
-			return nil
+			v := secretView{secretInfoView: newSecretInfoView(*secretInfo), Content: &text}
+			return renderOne(cmd, secretOutputKind, v, nil)
 		},
 	}
 
 	cmd.Flags().StringVarP(&name, "name", "n", "", "Name of the text to retrieve (required)")
 	cmd.Flags().Int32VarP(&version, "version", "v", 0, "Specific version to retrieve (default: latest)")
+	addCopyFlags(cmd, &clip, clipboard, "content")
 
 	_ = cmd.MarkFlagRequired(name)
 
@@ -398,10 +585,11 @@ func newGetTextSecretCmd(ctx context.Context, secretService domain.SecretService
 }
 
 // newGetFileSecretCmd creates a command to download stored files
-func newGetFileSecretCmd(ctx context.Context, secretService domain.SecretService) *cobra.Command {
+func newGetFileSecretCmd(ctx context.Context, secretService domain.SecretService, clipboard clipboardSource) *cobra.Command {
 	var (
 		name    string
 		version int32
+		clip    copyFlags
 	)
 
 	cmd := &cobra.Command{
@@ -425,6 +613,10 @@ func newGetFileSecretCmd(ctx context.Context, secretService domain.SecretService
 				return fmt.Errorf("failed to retrieve file")
 			}
 
+			if clip.copy {
+				return clipboard.copyContent(ctx, cmd, clip, secretInfo.Name, reader)
+			}
+
 			outputFile, err := os.Create(secretInfo.Name)
 			if err != nil {
 				log.Error().Err(err).Msg("Failed to create output file")
@@ -437,18 +629,20 @@ func newGetFileSecretCmd(ctx context.Context, secretService domain.SecretService
 				return fmt.Errorf("failed to write secret data into output file '%s'", outputFile.Name())
 			}
 
-			fmt.Fprintf(cmd.OutOrStdout(), "Successfully downloaded '%s' version %d\n",
-				secretInfo.Name, secretInfo.Version)
-			if secretInfo.Metadata != "" {
-				fmt.Fprintf(cmd.OutOrStdout(), "Metadata: %s\n", secretInfo.Metadata)
-			}
-
-			return nil
+			v := secretView{secretInfoView: newSecretInfoView(*secretInfo), Path: outputFile.Name()}
+			return renderOne(cmd, secretOutputKind, v, func(w io.Writer) error {
+				fmt.Fprintf(w, "Successfully downloaded '%s' version %d\n", secretInfo.Name, secretInfo.Version)
+				if secretInfo.Metadata != "" {
+					fmt.Fprintf(w, "Metadata: %s\n", secretInfo.Metadata)
+				}
+				return nil
+			})
 		},
 	}
 
 	cmd.Flags().StringVarP(&name, "name", "n", "", "Name of file to retrieve (required)")
 	cmd.Flags().Int32VarP(&version, "version", "v", 0, "Specific version to retrieve (default: latest)")
+	addCopyFlags(cmd, &clip, clipboard, "content")
 
 	_ = cmd.MarkFlagRequired("name")
 
@@ -460,8 +654,9 @@ func newDeleteSecretCmd(ctx context.Context, secretService domain.SecretService)
 	var name string
 
 	cmd := &cobra.Command{
-		Use:   "delete",
-		Short: "Permanently delete a secret with all its versions",
+		Use:         "delete",
+		Short:       "Permanently delete a secret with all its versions",
+		Annotations: map[string]string{secretNamesAnnotation: "true"},
 
 		RunE: func(cmd *cobra.Command, args []string) error {
 			fmt.Fprintf(cmd.OutOrStdout(), "Are you sure you want to delete '%s'? (y/n): ", name)
@@ -489,3 +684,54 @@ func newDeleteSecretCmd(ctx context.Context, secretService domain.SecretService)
 
 	return cmd
 }
+
+// printPaymentCard prints the card details in text output, skipping empty fields.
+func printPaymentCard(w io.Writer, card paymentCardView) {
+	fmt.Fprintf(w, "Card Number: %s\n", card.Number)
+	fields := []struct{ label, value string }{
+		{"Network", card.Network},
+		{"Holder", card.Holder},
+		{"Expiry", card.Expiry},
+		{"CVV", card.CVV},
+		{"PIN", card.PIN},
+		{"Issuer", card.Issuer},
+		{"Notes", card.Notes},
+	}
+	for _, f := range fields {
+		if f.value != "" {
+			fmt.Fprintf(w, "%s: %s\n", f.label, f.value)
+		}
+	}
+}
+
+// readTextInput streams lines from r until a line containing only 'end'.
+func readTextInput(r io.Reader) io.Reader {
+	pr, pw := io.Pipe()
+
+	go func() {
+		defer pw.Close()
+		scanner := bufio.NewScanner(r)
+
+		for scanner.Scan() {
+			line := scanner.Text()
+			if strings.TrimSpace(line) == "end" {
+				break
+			}
+			if _, err := pw.Write([]byte(line + "\n")); err != nil {
+				log.Error().Err(err).Msg("Error writing text")
+				return
+			}
+		}
+	}()
+
+	return pr
+}
+
+// printSecretInfo prints the common secret details in text output.
+func printSecretInfo(w io.Writer, info domain.SecretInfo) {
+	fmt.Fprintf(w, "Name: %s\n", info.Name)
+	fmt.Fprintf(w, "Version: %d\n", info.Version)
+	if info.Metadata != "" {
+		fmt.Fprintf(w, "Metadata: %s\n", info.Metadata)
+	}
+}
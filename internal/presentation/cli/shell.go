@@ -0,0 +1,391 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/domain"
+	"golang.org/x/term"
+)
+
+// shellPrompt is the prompt of the interactive shell.
+const shellPrompt = "gophkeeper> "
+
+// Commands handled by the shell itself.
+const (
+	shellExitCmd = "exit"
+	shellQuitCmd = "quit"
+	shellLockCmd = "lock"
+)
+
+// shellOptions holds the dependencies of the interactive shell.
+type shellOptions struct {
+	enabled        bool
+	requestTimeout time.Duration
+	lockVault      func() bool
+}
+
+// newShellCmd creates a command that reads commands at a prompt and runs them over the same connections.
+// newRoot builds a fresh command tree for every line, as cobra keeps flag values between executions.
+func newShellCmd(ctx context.Context, secretService domain.SecretService,
+	newRoot func(ctx context.Context) *cobra.Command, shell shellOptions) *cobra.Command {
+	var lockAfter time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "shell",
+		Short: "Run commands at an interactive prompt",
+		Long: `Reads commands at a prompt and runs them over the connections opened at startup,
+so configuration, connections and the session token are loaded once.
+Commands take the same arguments as on the command line, without the program name.
+Global flags such as --profile or --server apply when the shell starts and are rejected at the prompt.
+Arrow keys browse the history of this session, which is never written to disk.
+Tab completes command names and secret names after --name or -n;
+the secret names are listed at start and after commands that may add or remove secrets.
+The vault is locked after --lock-after without input; 'lock' locks it right away.
+Type 'exit' or press Ctrl-D to leave.`,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if lockAfter < 0 {
+				return fmt.Errorf("invalid --lock-after %s (must not be negative)", lockAfter)
+			}
+
+			s := &shellSession{
+				ctx:           untilCanceled(ctx),
+				cmd:           cmd,
+				secretService: secretService,
+				newRoot:       newRoot,
+				options:       shell,
+				lockAfter:     lockAfter,
+			}
+			return s.run()
+		},
+	}
+
+	cmd.Flags().DurationVar(&lockAfter, "lock-after", 5*time.Minute,
+		"Lock the vault after this time without input, 0 never locks it")
+
+	return cmd
+}
+
+// shellSession is a running interactive shell.
+type shellSession struct {
+	ctx           context.Context
+	cmd           *cobra.Command
+	secretService domain.SecretService
+	newRoot       func(ctx context.Context) *cobra.Command
+	options       shellOptions
+	lockAfter     time.Duration
+
+	// commands and secretNames are the completion candidates.
+	// Secret names are listed at start and after commands that may add or remove secrets,
+	// never while completing, so a slow listing does not block the prompt.
+	commands    []string
+	secretNames []string
+
+	// notify reports the idle lock while waiting for input
+	notifyMu sync.Mutex
+	notify   io.Writer
+}
+
+// run reads and executes lines until the input ends or the user exits.
+func (s *shellSession) run() error {
+	s.commands = []string{shellExitCmd, shellLockCmd, shellQuitCmd}
+	for _, c := range s.newRoot(s.ctx).Commands() {
+		if c.IsAvailableCommand() && c.Name() != s.cmd.Name() {
+			s.commands = append(s.commands, c.Name())
+		}
+	}
+	sort.Strings(s.commands)
+	s.loadSecretNames()
+
+	readLine, done, err := s.lineReader()
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	for {
+		line, err := s.readIdle(readLine)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to read shell input")
+			return fmt.Errorf("failed to read input")
+		}
+		if exit := s.execute(line); exit {
+			return nil
+		}
+	}
+}
+
+// lineReader returns a function reading the next line. A terminal gets a prompt with
+// line editing, history and completion, other input is read line by line.
+func (s *shellSession) lineReader() (func() (string, error), func(), error) {
+	in := s.cmd.InOrStdin()
+	out := s.cmd.OutOrStdout()
+
+	file, ok := in.(*os.File)
+	if !ok || !term.IsTerminal(int(file.Fd())) {
+		s.notify = s.cmd.ErrOrStderr()
+		scanner := bufio.NewScanner(in)
+		return func() (string, error) {
+			if !scanner.Scan() {
+				if err := scanner.Err(); err != nil {
+					return "", err
+				}
+				return "", io.EOF
+			}
+			return scanner.Text(), nil
+		}, func() {}, nil
+	}
+
+	fd := int(file.Fd())
+	terminal := term.NewTerminal(struct {
+		io.Reader
+		io.Writer
+	}{file, out}, shellPrompt)
+	terminal.AutoCompleteCallback = s.complete
+	s.notify = terminal
+
+	readLine := func() (string, error) {
+		// The terminal is raw only while reading, so commands and prompts see a normal terminal
+		state, err := term.MakeRaw(fd)
+		if err != nil {
+			return "", fmt.Errorf("failed to set terminal to raw mode: %w", err)
+		}
+		defer term.Restore(fd, state)
+
+		if width, height, err := term.GetSize(fd); err == nil {
+			_ = terminal.SetSize(width, height)
+		}
+		return terminal.ReadLine()
+	}
+	return readLine, func() { fmt.Fprintln(out) }, nil
+}
+
+// readIdle reads a line and locks the vault when no line is entered within the lock timeout.
+func (s *shellSession) readIdle(readLine func() (string, error)) (string, error) {
+	if s.lockAfter > 0 && s.options.lockVault != nil {
+		timer := time.AfterFunc(s.lockAfter, func() {
+			if s.options.lockVault() {
+				s.notifyMu.Lock()
+				defer s.notifyMu.Unlock()
+				fmt.Fprintf(s.notify, "Vault locked after %s of inactivity\n", s.lockAfter)
+			}
+		})
+		defer timer.Stop()
+	}
+	return readLine()
+}
+
+// execute runs a line and reports whether the shell should exit.
+// Command errors are printed by cobra and do not end the shell.
+func (s *shellSession) execute(line string) bool {
+	stderr := s.cmd.ErrOrStderr()
+
+	args, err := splitShellLine(line)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return false
+	}
+	if len(args) == 0 {
+		return false
+	}
+
+	switch args[0] {
+	case shellExitCmd, shellQuitCmd:
+		return true
+	case shellLockCmd:
+		if s.options.lockVault != nil && s.options.lockVault() {
+			fmt.Fprintln(stderr, "Vault locked")
+		}
+		return false
+	case s.cmd.Name():
+		fmt.Fprintln(stderr, "Error: already in the shell")
+		return false
+	}
+
+	if name := globalFlag(s.newRoot(s.ctx), args); name != "" {
+		fmt.Fprintf(stderr, "Error: --%s only applies when the shell starts\n", name)
+		return false
+	}
+
+	ctx, cancel := s.requestContext()
+	defer cancel()
+
+	root := s.newRoot(ctx)
+	root.SetArgs(args)
+	root.SetIn(s.cmd.InOrStdin())
+	root.SetOut(s.cmd.OutOrStdout())
+	root.SetErr(stderr)
+	executed, err := root.ExecuteC()
+	if err != nil {
+		log.Debug().Err(err).Str("command", args[0]).Msg("Shell command failed")
+	}
+
+	// Failed commands may still have stored some secrets, e.g. an interrupted import
+	if executed != nil && executed.Annotations[secretNamesAnnotation] != "" {
+		s.loadSecretNames()
+	}
+	return false
+}
+
+// globalFlag returns the name of the first global flag set on the command line, if any.
+// The connection is wired when the shell starts, so these flags would be silently ignored.
+func globalFlag(root *cobra.Command, args []string) string {
+	cmd, flags, err := root.Find(args)
+	if err != nil {
+		return ""
+	}
+	// Invalid flags are left for the command to report
+	if err := cmd.ParseFlags(flags); err != nil {
+		return ""
+	}
+
+	globals := pflag.NewFlagSet("global", pflag.ContinueOnError)
+	addGlobalFlags(globals, &GlobalFlags{})
+
+	var name string
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		if name == "" && globals.Lookup(f.Name) != nil {
+			name = f.Name
+		}
+	})
+	return name
+}
+
+// requestContext returns the context of a command or request, limited by the request timeout.
+func (s *shellSession) requestContext() (context.Context, context.CancelFunc) {
+	return requestContext(s.ctx, s.options.requestTimeout)
+}
+
+// complete completes the word before the cursor when tab is pressed:
+// the first word with command names, the value of --name or -n with secret names.
+// Several candidates are completed to their longest common prefix.
+func (s *shellSession) complete(line string, pos int, key rune) (string, int, bool) {
+	if key != '\t' {
+		return "", 0, false
+	}
+
+	head := line[:pos]
+	start := strings.LastIndexAny(head, " \t") + 1
+	word := head[start:]
+	previous := strings.Fields(head[:start])
+
+	var candidates []string
+	switch {
+	case len(previous) == 0:
+		candidates = s.commands
+	case strings.HasPrefix(word, "--name="):
+		start += len("--name=")
+		word = word[len("--name="):]
+		candidates = s.secretNames
+	case previous[len(previous)-1] == "--name" || previous[len(previous)-1] == "-n":
+		candidates = s.secretNames
+	default:
+		return "", 0, false
+	}
+
+	var matches []string
+	for _, c := range candidates {
+		if strings.HasPrefix(c, word) {
+			matches = append(matches, c)
+		}
+	}
+	if len(matches) == 0 {
+		return "", 0, false
+	}
+
+	completion := matches[0]
+	for _, m := range matches[1:] {
+		for !strings.HasPrefix(m, completion) {
+			_, size := utf8.DecodeLastRuneInString(completion)
+			completion = completion[:len(completion)-size]
+		}
+	}
+	if len(matches) == 1 {
+		completion += " "
+	}
+
+	newLine := line[:start] + completion + line[pos:]
+	return newLine, start + len(completion), true
+}
+
+// loadSecretNames lists the secret names completed by the shell.
+// Listing errors keep the previous names, completion is best effort.
+func (s *shellSession) loadSecretNames() {
+	ctx, cancel := s.requestContext()
+	defer cancel()
+
+	names, err := s.secretService.ListSecrets(ctx)
+	if err != nil {
+		log.Debug().Err(err).Msg("Failed to list secrets for completion")
+		return
+	}
+
+	sort.Strings(names)
+	s.secretNames = names
+}
+
+// splitShellLine splits a line into arguments like a POSIX shell:
+// words are separated by blanks, quotes group words and a backslash escapes the next character.
+func splitShellLine(line string) ([]string, error) {
+	var (
+		args    []string
+		current strings.Builder
+		inWord  bool
+		quote   rune
+		escaped bool
+	)
+
+	for _, r := range line {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped, inWord = true, true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote, inWord = r, true
+		case r == ' ' || r == '\t':
+			if inWord {
+				args = append(args, current.String())
+				current.Reset()
+				inWord = false
+			}
+		default:
+			current.WriteRune(r)
+			inWord = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if escaped {
+		return nil, fmt.Errorf("line ends with a backslash")
+	}
+	if inWord {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
@@ -0,0 +1,308 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/domain"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+// newCreateSSHKeySecretCmd creates a command for storing an SSH private key.
+func newCreateSSHKeySecretCmd(ctx context.Context, secretService domain.SecretService) *cobra.Command {
+	var (
+		name, keyFile, passphrase, comment string
+		conflict                           conflictFlags
+	)
+
+	cmd := &cobra.Command{
+		Use:   "create-sshkey",
+		Short: "Store an SSH private key",
+		Long: `Securely stores an SSH private key in OpenSSH or PEM format.
+The key is validated and its public key and fingerprint are stored as metadata.
+The passphrase of an encrypted key is asked for unless --passphrase is given.`,
+		Annotations: map[string]string{secretNamesAnnotation: "true"},
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			data, err := os.ReadFile(keyFile)
+			if err != nil {
+				log.Error().Err(err).Msgf("Failed to read key file '%s'", keyFile)
+				return fmt.Errorf("failed to read key file '%s'", keyFile)
+			}
+
+			key := domain.SSHKeySecret{PrivateKey: string(data)}
+			if key.Encrypted() {
+				if !cmd.Flags().Changed("passphrase") {
+					if passphrase, err = promptSecret("SSH key passphrase: "); err != nil {
+						return fmt.Errorf("%w, set --passphrase", err)
+					}
+				}
+				key.Passphrase = passphrase
+			}
+			publicKey, err := key.PublicKey()
+			if err != nil {
+				return err
+			}
+
+			if !cmd.Flags().Changed("comment") {
+				comment = name
+			}
+			secret := domain.Secret{
+				Info: domain.SecretInfo{
+					Name:     name,
+					Metadata: domain.SSHKeyMetadata(publicKey, comment),
+					Type:     domain.SSHKeySecretType,
+				},
+			}
+			marshaled, err := json.Marshal(key)
+			if err != nil {
+				log.Error().Err(err).Msg("failed to marshal SSH key")
+				return fmt.Errorf("failed to marshal SSH key")
+			}
+
+			stored, err := storeSecret(ctx, cmd, secretService, secret, bytes.NewReader(marshaled), conflict)
+			if err != nil {
+				log.Error().Err(err).Msg("failed to create secret")
+				return fmt.Errorf("failed to create secret: %w", err)
+			}
+
+			if stored {
+				fmt.Fprintf(cmd.OutOrStdout(), "Successfully stored SSH key '%s'\n", name)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&name, "name", "n", "", "Unique name for the SSH key (required)")
+	cmd.Flags().StringVarP(&keyFile, "key-file", "f", "", "Path to the private key file (required)")
+	cmd.Flags().StringVar(&passphrase, "passphrase", "", "Passphrase of an encrypted key")
+	cmd.Flags().StringVar(&comment, "comment", "", "Public key comment (default: the key name)")
+	addConflictFlags(cmd, &conflict)
+
+	_ = cmd.MarkFlagRequired("name")
+	_ = cmd.MarkFlagRequired("key-file")
+
+	return cmd
+}
+
+// newGetSSHKeySecretCmd creates a command to retrieve a stored SSH key.
+func newGetSSHKeySecretCmd(ctx context.Context, secretService domain.SecretService, clipboard clipboardSource) *cobra.Command {
+	var (
+		name    string
+		version int32
+		reveal  bool
+		clip    copyFlags
+	)
+
+	cmd := &cobra.Command{
+		Use:   "get-sshkey",
+		Short: "Retrieve a stored SSH key",
+		Long:  "Shows the public key and fingerprint of a stored SSH key, and the private key with --reveal",
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var (
+				secret *domain.Secret
+				err    error
+			)
+
+			if version == 0 {
+				secret, err = secretService.GetLatestSecret(ctx, name)
+			} else {
+				secret, err = secretService.GetSecretByVersion(ctx, name, version)
+			}
+			if err != nil {
+				log.Error().Err(err).Msg("Failed to retrieve SSH key")
+				return fmt.Errorf("failed to retrieve SSH key")
+			}
+			if secret.Info.Type != domain.SSHKeySecretType {
+				return fmt.Errorf("secret '%s' is of type %s, not %s", name, secret.Info.Type, domain.SSHKeySecretType)
+			}
+
+			key, err := decodeSSHKey(secret.Data)
+			if err != nil {
+				return err
+			}
+			publicKey, err := key.PublicKey()
+			if err != nil {
+				return err
+			}
+
+			if clip.copy {
+				return clipboard.copyColumn(ctx, cmd, clip, name, newSSHKeyView(key, publicKey, true).columns())
+			}
+
+			keyView := newSSHKeyView(key, publicKey, reveal)
+			v := secretView{
+				secretInfoView: newSecretInfoView(secret.Info),
+				SSHKey:         &keyView,
+			}
+			return renderOne(cmd, secretOutputKind, v, func(w io.Writer) error {
+				printSecretInfo(w, secret.Info)
+				fmt.Fprintf(w, "Algorithm: %s\n", keyView.Algorithm)
+				fmt.Fprintf(w, "Public key: %s\n", keyView.PublicKey)
+				fmt.Fprintf(w, "Fingerprint: %s\n", keyView.Fingerprint)
+				if reveal {
+					if keyView.Passphrase != "" {
+						fmt.Fprintf(w, "Passphrase: %s\n", keyView.Passphrase)
+					}
+					fmt.Fprintf(w, "Private key:\n%s", keyView.PrivateKey)
+				}
+				return nil
+			})
+		},
+	}
+
+	cmd.Flags().StringVarP(&name, "name", "n", "", "Name of the SSH key to retrieve (required)")
+	cmd.Flags().Int32VarP(&version, "version", "v", 0, "Specific version to retrieve (default: latest)")
+	cmd.Flags().BoolVar(&reveal, "reveal", false, "Show the private key and its passphrase")
+	addCopyFlags(cmd, &clip, clipboard, "public_key", "public_key", "fingerprint", "private_key", "passphrase")
+
+	_ = cmd.MarkFlagRequired("name")
+
+	return cmd
+}
+
+// newSSHAgentCmd creates a command that serves stored SSH keys over the SSH agent protocol.
+func newSSHAgentCmd(ctx context.Context, secretService domain.SecretService) *cobra.Command {
+	var (
+		names    []string
+		socket   string
+		lifetime time.Duration
+	)
+
+	cmd := &cobra.Command{
+		Use:   "ssh-agent",
+		Short: "Serve stored SSH keys to ssh over an agent socket",
+		Long: `Loads the selected SSH keys into memory and serves them over a Unix socket
+using the SSH agent protocol, so private keys never touch the filesystem.
+The printed line can be evaluated by the shell to point SSH_AUTH_SOCK at the agent.
+The agent runs until it is interrupted or its --lifetime passes.`,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			keyring := agent.NewKeyring()
+			for _, name := range names {
+				if err := addSSHKey(ctx, secretService, keyring, name); err != nil {
+					return err
+				}
+			}
+
+			if socket == "" {
+				dir, err := os.MkdirTemp("", "gophkeeper-agent-")
+				if err != nil {
+					log.Error().Err(err).Msg("Failed to create agent socket directory")
+					return fmt.Errorf("failed to create agent socket directory")
+				}
+				defer os.RemoveAll(dir)
+				socket = filepath.Join(dir, "agent.sock")
+			}
+
+			listener, err := net.Listen("unix", socket)
+			if err != nil {
+				log.Error().Err(err).Msgf("Failed to listen on '%s'", socket)
+				return fmt.Errorf("failed to listen on '%s'", socket)
+			}
+			if err := os.Chmod(socket, 0600); err != nil {
+				listener.Close()
+				log.Error().Err(err).Msgf("Failed to restrict access to '%s'", socket)
+				return fmt.Errorf("failed to restrict access to '%s'", socket)
+			}
+
+			// The command context carries the request timeout, the agent only stops on a signal
+			serveCtx, stop := signal.NotifyContext(untilCanceled(ctx), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			if lifetime > 0 {
+				var cancel context.CancelFunc
+				serveCtx, cancel = context.WithTimeout(serveCtx, lifetime)
+				defer cancel()
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "SSH_AUTH_SOCK=%s; export SSH_AUTH_SOCK;\n", shellQuote(socket))
+			fmt.Fprintf(cmd.ErrOrStderr(), "Serving %d SSH key(s), press Ctrl+C to stop\n", len(names))
+
+			return serveSSHAgent(serveCtx, listener, keyring)
+		},
+	}
+
+	cmd.Flags().StringSliceVarP(&names, "name", "n", nil, "Names of the SSH keys to serve (required)")
+	cmd.Flags().StringVarP(&socket, "socket", "s", "", "Path of the agent socket (default: a new temporary directory)")
+	cmd.Flags().DurationVar(&lifetime, "lifetime", 0, "Stop serving after this duration (default: until interrupted)")
+
+	_ = cmd.MarkFlagRequired("name")
+
+	return cmd
+}
+
+// addSSHKey loads the latest version of a stored SSH key into the keyring.
+func addSSHKey(ctx context.Context, secretService domain.SecretService, keyring agent.Agent, name string) error {
+	secret, err := secretService.GetLatestSecret(ctx, name)
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed to retrieve SSH key '%s'", name)
+		return fmt.Errorf("failed to retrieve SSH key '%s'", name)
+	}
+	if secret.Info.Type != domain.SSHKeySecretType {
+		return fmt.Errorf("secret '%s' is of type %s, not %s", name, secret.Info.Type, domain.SSHKeySecretType)
+	}
+
+	key, err := decodeSSHKey(secret.Data)
+	if err != nil {
+		return err
+	}
+	rawKey, err := key.RawKey()
+	if err != nil {
+		return fmt.Errorf("SSH key '%s': %w", name, err)
+	}
+
+	if err := keyring.Add(agent.AddedKey{PrivateKey: rawKey, Comment: name}); err != nil {
+		log.Error().Err(err).Msgf("Failed to add SSH key '%s' to the agent", name)
+		return fmt.Errorf("failed to add SSH key '%s' to the agent", name)
+	}
+	return nil
+}
+
+// serveSSHAgent serves agent connections until the context is done.
+func serveSSHAgent(ctx context.Context, listener net.Listener, keyring agent.Agent) error {
+	stop := context.AfterFunc(ctx, func() { listener.Close() })
+	defer stop()
+	defer listener.Close()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			log.Error().Err(err).Msg("Failed to accept agent connection")
+			return fmt.Errorf("failed to accept agent connection")
+		}
+
+		go func() {
+			defer conn.Close()
+			if err := agent.ServeAgent(keyring, conn); err != nil && !errors.Is(err, io.EOF) {
+				log.Debug().Err(err).Msg("Agent connection closed")
+			}
+		}()
+	}
+}
+
+// decodeSSHKey decodes the payload of an ssh_key secret.
+func decodeSSHKey(data string) (domain.SSHKeySecret, error) {
+	var key domain.SSHKeySecret
+	if err := json.Unmarshal([]byte(data), &key); err != nil {
+		log.Error().Err(err).Msg("Failed to decode SSH key")
+		return key, fmt.Errorf("failed to decode SSH key")
+	}
+	return key, nil
+}
@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/domain"
+)
+
+// newSyncCmd creates a command to replay offline changes and show sync status.
+func newSyncCmd(ctx context.Context, secretService domain.SecretService) *cobra.Command {
+	var statusOnly bool
+
+	cmd := &cobra.Command{
+		Use:         "sync",
+		Short:       "Synchronize offline changes with the server",
+		Long:        `Replays operations queued while the server was unavailable and shows pending operations and last sync time`,
+		Annotations: map[string]string{secretNamesAnnotation: "true"},
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var syncErr error
+			if !statusOnly {
+				if syncErr = secretService.Sync(ctx); syncErr != nil {
+					log.Error().Err(syncErr).Msg("Failed to sync pending operations")
+				}
+			}
+
+			status, err := secretService.GetSyncStatus(ctx)
+			if err != nil {
+				log.Error().Err(err).Msg("Failed to get sync status")
+				return fmt.Errorf("failed to get sync status")
+			}
+
+			err = renderOne(cmd, syncStatusOutputKind, newSyncStatusView(status), func(w io.Writer) error {
+				printSyncStatus(w, status)
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+
+			if syncErr != nil {
+				return fmt.Errorf("failed to sync pending operations, they will be retried later")
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVarP(&statusOnly, "status", "s", false, "Only show sync status without replaying operations")
+
+	return cmd
+}
+
+// printSyncStatus writes the last sync time and pending operations.
+func printSyncStatus(w io.Writer, status *domain.SyncStatus) {
+	if status.LastSync.IsZero() {
+		fmt.Fprintln(w, "Last sync: never")
+	} else {
+		fmt.Fprintf(w, "Last sync: %s\n", status.LastSync.Format(time.RFC3339))
+	}
+
+	if len(status.Pending) == 0 {
+		fmt.Fprintln(w, "No pending operations")
+		return
+	}
+
+	fmt.Fprintf(w, "Pending operations: %d\n", len(status.Pending))
+	for _, op := range status.Pending {
+		fmt.Fprintf(w, "  - %s '%s' queued at %s\n",
+			op.Type, op.Secret.Info.Name, op.QueuedAt.Format(time.RFC3339))
+	}
+}
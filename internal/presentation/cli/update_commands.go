@@ -0,0 +1,313 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/domain"
+)
+
+// newUpdateCredentialsSecretCmd creates a command to change some fields of stored credentials.
+func newUpdateCredentialsSecretCmd(ctx context.Context, secretService domain.SecretService) *cobra.Command {
+	var (
+		name, metadata string
+		flags          credentialsFlags
+		conflict       conflictFlags
+	)
+
+	cmd := &cobra.Command{
+		Use:   "update-credentials",
+		Short: "Update stored login/password credentials",
+		Long: `Stores a new version of credentials with only the given fields changed.
+An empty --import-uri removes the one-time password seed, --generate stores a random password.`,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := requireAnyFlag(cmd, append(credentialsFlagNames, "metadata")...); err != nil {
+				return err
+			}
+
+			latest, data, err := loadLatestSecret(ctx, secretService, name, domain.CredentialsSecretType)
+			if err != nil {
+				return err
+			}
+
+			var credentials domain.CredentialsSecret
+			if err := json.Unmarshal([]byte(data), &credentials); err != nil {
+				log.Error().Err(err).Msg("Failed to decode credentials")
+				return fmt.Errorf("failed to decode credentials")
+			}
+			if err := flags.apply(cmd, &credentials); err != nil {
+				return err
+			}
+
+			marshaled, err := json.Marshal(credentials)
+			if err != nil {
+				log.Error().Err(err).Msg("failed to marshal credentials")
+				return fmt.Errorf("failed to marshal credentials")
+			}
+
+			return updateSecret(ctx, cmd, secretService, *latest, metadata, bytes.NewReader(marshaled), conflict)
+		},
+	}
+
+	cmd.Flags().StringVarP(&name, "name", "n", "", "Name of the credentials (required)")
+	cmd.Flags().StringVarP(&metadata, "metadata", "m", "", "New metadata")
+	addCredentialsFlags(cmd, &flags)
+	addConflictFlags(cmd, &conflict)
+
+	_ = cmd.MarkFlagRequired("name")
+
+	return cmd
+}
+
+// newUpdatePaymentCardSecretCmd creates a command to change some fields of a stored payment card.
+func newUpdatePaymentCardSecretCmd(ctx context.Context, secretService domain.SecretService) *cobra.Command {
+	var (
+		name, metadata string
+		card           cardFlags
+		conflict       conflictFlags
+	)
+
+	cmd := &cobra.Command{
+		Use:   "update-paymentcard",
+		Short: "Update stored payment card information",
+		Long:  "Stores a new version of a payment card with only the given fields changed",
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := requireAnyFlag(cmd, append(cardFlagNames, "metadata")...); err != nil {
+				return err
+			}
+
+			latest, data, err := loadLatestSecret(ctx, secretService, name, domain.PaymentCardSecretType)
+			if err != nil {
+				return err
+			}
+
+			var paymentCard domain.PaymentCardSecret
+			if err := json.Unmarshal([]byte(data), &paymentCard); err != nil {
+				log.Error().Err(err).Msg("Failed to decode card data")
+				return fmt.Errorf("failed to decode card data")
+			}
+			if err := card.apply(cmd, &paymentCard); err != nil {
+				return err
+			}
+
+			marshaled, err := json.Marshal(paymentCard)
+			if err != nil {
+				log.Error().Err(err).Msg("failed to marshal card data")
+				return fmt.Errorf("failed to marshal card data")
+			}
+
+			return updateSecret(ctx, cmd, secretService, *latest, metadata, bytes.NewReader(marshaled), conflict)
+		},
+	}
+
+	cmd.Flags().StringVarP(&name, "name", "n", "", "Name of the card (required)")
+	cmd.Flags().StringVarP(&metadata, "metadata", "m", "", "New metadata")
+	addCardFlags(cmd, &card)
+	addConflictFlags(cmd, &conflict)
+
+	_ = cmd.MarkFlagRequired("name")
+
+	return cmd
+}
+
+// newUpdateTextSecretCmd creates a command to replace the content or metadata of a stored text.
+func newUpdateTextSecretCmd(ctx context.Context, secretService domain.SecretService) *cobra.Command {
+	var (
+		name, metadata string
+		content        bool
+		conflict       conflictFlags
+	)
+
+	cmd := &cobra.Command{
+		Use:   "update-text",
+		Short: "Update stored text content or metadata",
+		Long: `Stores a new version of a text secret. With --content the new text is read from input,
+type 'end' on a new line to finish. Otherwise the stored text is kept.`,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := requireAnyFlag(cmd, "content", "metadata"); err != nil {
+				return err
+			}
+
+			latest, reader, release, err := updatedStreamContent(ctx, secretService, name, domain.TextSecretType, content,
+				func() (io.Reader, error) {
+					return readTextInput(os.Stdin), nil
+				})
+			if err != nil {
+				return err
+			}
+			defer release()
+
+			return updateSecret(ctx, cmd, secretService, *latest, metadata, reader, conflict)
+		},
+	}
+
+	cmd.Flags().StringVarP(&name, "name", "n", "", "Name of the text content (required)")
+	cmd.Flags().BoolVar(&content, "content", false, "Replace the text with new content read from input")
+	cmd.Flags().StringVarP(&metadata, "metadata", "m", "", "New metadata")
+	addConflictFlags(cmd, &conflict)
+
+	_ = cmd.MarkFlagRequired("name")
+
+	return cmd
+}
+
+// newUpdateFileSecretCmd creates a command to replace the content or metadata of a stored file.
+func newUpdateFileSecretCmd(ctx context.Context, secretService domain.SecretService) *cobra.Command {
+	var (
+		name, metadata, filePath string
+		conflict                 conflictFlags
+	)
+
+	cmd := &cobra.Command{
+		Use:   "update-file",
+		Short: "Update a stored file or its metadata",
+		Long: `Stores a new version of a file secret. Without --file the stored file is streamed
+from the server back to the server with the new metadata.`,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := requireAnyFlag(cmd, "file", "metadata"); err != nil {
+				return err
+			}
+
+			replace := cmd.Flags().Changed("file")
+			latest, reader, release, err := updatedStreamContent(ctx, secretService, name, domain.FileSecretType, replace,
+				func() (io.Reader, error) {
+					file, err := os.Open(filePath)
+					if err != nil {
+						log.Error().Err(err).Msgf("Failed to open file '%s'", filePath)
+						return nil, fmt.Errorf("failed to open file '%s'", filePath)
+					}
+					return file, nil
+				})
+			if err != nil {
+				return err
+			}
+			defer release()
+
+			return updateSecret(ctx, cmd, secretService, *latest, metadata, reader, conflict)
+		},
+	}
+
+	cmd.Flags().StringVarP(&name, "name", "n", "", "Name of the file (required)")
+	cmd.Flags().StringVarP(&filePath, "file", "f", "", "Path to the new file")
+	cmd.Flags().StringVarP(&metadata, "metadata", "m", "", "New metadata")
+	addConflictFlags(cmd, &conflict)
+
+	_ = cmd.MarkFlagRequired("name")
+
+	return cmd
+}
+
+// requireAnyFlag checks that at least one of the flags was given.
+func requireAnyFlag(cmd *cobra.Command, names ...string) error {
+	for _, name := range names {
+		if cmd.Flags().Changed(name) {
+			return nil
+		}
+	}
+
+	flags := make([]string, len(names))
+	for i, name := range names {
+		flags[i] = "--" + name
+	}
+	return fmt.Errorf("nothing to update, specify at least one of %s", strings.Join(flags, ", "))
+}
+
+// loadLatestSecret retrieves the latest version of a non-streamable secret and checks that it has the expected type.
+func loadLatestSecret(ctx context.Context, secretService domain.SecretService,
+	name string, secretType domain.SecretType) (*domain.SecretInfo, string, error) {
+	secret, err := secretService.GetLatestSecret(ctx, name)
+	if err != nil {
+		return nil, "", latestSecretError(err, name)
+	}
+	if secret.Info.Type != secretType {
+		return nil, "", fmt.Errorf("secret '%s' is of type %s, not %s", name, secret.Info.Type, secretType)
+	}
+	return &secret.Info, secret.Data, nil
+}
+
+// updatedStreamContent retrieves the latest version of a streamable secret and checks that it has the expected type.
+// Returns its info and the stored content streamed from the server, or the content opened by open
+// if it is replaced, in which case the download is canceled right away.
+// release closes the returned content and ends the download; it has to be called once the content is stored.
+func updatedStreamContent(ctx context.Context, secretService domain.SecretService, name string,
+	secretType domain.SecretType, replace bool,
+	open func() (io.Reader, error)) (latest *domain.SecretInfo, content io.Reader, release func(), err error) {
+	downloadCtx, cancel := context.WithCancel(ctx)
+	stored, latest, err := secretService.GetLatestSecretStream(downloadCtx, name)
+	if err != nil {
+		cancel()
+		return nil, nil, nil, latestSecretError(err, name)
+	}
+	if latest.Type == secretType && !replace {
+		return latest, stored, func() {
+			closeReader(stored)
+			cancel()
+		}, nil
+	}
+
+	closeReader(stored)
+	cancel()
+	if latest.Type != secretType {
+		return nil, nil, nil, fmt.Errorf("secret '%s' is of type %s, not %s", name, latest.Type, secretType)
+	}
+	if content, err = open(); err != nil {
+		return nil, nil, nil, err
+	}
+	return latest, content, func() { closeReader(content) }, nil
+}
+
+// closeReader closes r if it holds resources.
+func closeReader(r io.Reader) {
+	if closer, ok := r.(io.Closer); ok {
+		closer.Close()
+	}
+}
+
+// latestSecretError converts an error retrieving the latest version of a secret into a user facing error.
+func latestSecretError(err error, name string) error {
+	if errors.Is(err, domain.ErrSecretNotFound) {
+		return fmt.Errorf("secret '%s' not found", name)
+	}
+	log.Error().Err(err).Msgf("Failed to retrieve secret '%s'", name)
+	return fmt.Errorf("failed to retrieve secret '%s'", name)
+}
+
+// updateSecret stores the content as a new version based on the latest one.
+// The metadata is kept unless it was given.
+func updateSecret(ctx context.Context, cmd *cobra.Command, secretService domain.SecretService,
+	latest domain.SecretInfo, metadata string, content io.Reader, conflict conflictFlags) error {
+	if !cmd.Flags().Changed("metadata") {
+		metadata = latest.Metadata
+	}
+	conflict.baseOn(cmd, latest.Version)
+
+	secret := domain.Secret{
+		Info: domain.SecretInfo{
+			Name:     latest.Name,
+			Metadata: metadata,
+			Type:     latest.Type,
+		},
+	}
+	stored, err := storeSecret(ctx, cmd, secretService, secret, content, conflict)
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed to update secret '%s'", latest.Name)
+		return storeSecretError(err, fmt.Sprintf("failed to update secret '%s'", latest.Name))
+	}
+
+	if stored {
+		fmt.Fprintf(cmd.OutOrStdout(), "Successfully updated '%s'\n", latest.Name)
+	}
+	return nil
+}
@@ -0,0 +1,385 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"github.com/ulixes-bloom/ya-gophkeeper-cli/internal/domain"
+)
+
+// newHistoryCmd creates a command to list all versions of a secret.
+func newHistoryCmd(ctx context.Context, secretService domain.SecretService) *cobra.Command {
+	var name string
+
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "List all versions of a secret",
+		Long:  `Displays every stored version of a secret with its creation time, metadata and encrypted payload size`,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			versions, err := secretService.ListSecretVersions(ctx, name)
+			if errors.Is(err, domain.ErrSecretNotFound) {
+				return fmt.Errorf("secret '%s' not found", name)
+			}
+			if err != nil {
+				log.Error().Err(err).Msgf("Failed to list versions of secret '%s'", name)
+				return fmt.Errorf("failed to list versions of secret '%s'", name)
+			}
+
+			views := make([]secretVersionView, len(versions))
+			for i, version := range versions {
+				views[i] = newSecretVersionView(version)
+			}
+
+			return renderList(cmd, secretHistoryOutputKind, views, func(w io.Writer) error {
+				if len(views) == 0 {
+					fmt.Fprintf(w, "No versions of '%s' found\n", name)
+					return nil
+				}
+
+				tableViews := make([]view, len(views))
+				for i, v := range views {
+					tableViews[i] = selectedColumnsView[secretVersionView]{
+						view: v,
+						keys: []string{"version", "created_at", "size", "metadata"},
+					}
+				}
+				return renderTable(w, tableViews)
+			})
+		},
+	}
+
+	cmd.Flags().StringVarP(&name, "name", "n", "", "Name of secret (required)")
+	_ = cmd.MarkFlagRequired("name")
+
+	return cmd
+}
+
+// newDiffCmd creates a command to compare two versions of a secret.
+func newDiffCmd(ctx context.Context, secretService domain.SecretService) *cobra.Command {
+	var (
+		name     string
+		from, to int32
+		reveal   bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "diff",
+		Short: "Show changes between two versions of a secret",
+		Long: `Compares two versions of a secret: fields of credentials and payment cards,
+lines of text secrets, and size and SHA-256 checksum of files.
+Sensitive values are masked unless --reveal is given.`,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			fromInfo, toInfo, err := resolveVersions(ctx, secretService, name, from, to)
+			if err != nil {
+				return err
+			}
+
+			diff, err := diffSecretVersions(ctx, secretService, *fromInfo, *toInfo, reveal)
+			if err != nil {
+				log.Error().Err(err).Msgf("Failed to compare versions of secret '%s'", name)
+				return fmt.Errorf("failed to compare versions of secret '%s'", name)
+			}
+
+			return renderOne(cmd, secretDiffOutputKind, diff, func(w io.Writer) error {
+				printSecretDiff(w, diff, reveal)
+				return nil
+			})
+		},
+	}
+
+	cmd.Flags().StringVarP(&name, "name", "n", "", "Name of secret (required)")
+	cmd.Flags().Int32Var(&from, "from", 0, "Version to compare from (required)")
+	cmd.Flags().Int32Var(&to, "to", 0, "Version to compare to (default: latest)")
+	cmd.Flags().BoolVar(&reveal, "reveal", false, "Show sensitive values")
+
+	_ = cmd.MarkFlagRequired("name")
+	_ = cmd.MarkFlagRequired("from")
+
+	return cmd
+}
+
+// newRestoreCmd creates a command to store an older version of a secret as the new latest version.
+func newRestoreCmd(ctx context.Context, secretService domain.SecretService) *cobra.Command {
+	var (
+		name     string
+		version  int32
+		conflict conflictFlags
+	)
+
+	cmd := &cobra.Command{
+		Use:   "restore",
+		Short: "Restore an older version of a secret as the latest version",
+		Long: `Stores a copy of an older version of a secret as a new version with the same type and metadata.
+Files are streamed from the server back to the server without being held in memory;
+like every read, the download is mirrored into the encrypted local vault.`,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			info, latest, err := resolveVersions(ctx, secretService, name, version, 0)
+			if err != nil {
+				return err
+			}
+			if info.Version == latest.Version {
+				fmt.Fprintf(cmd.OutOrStdout(), "Version %d is already the latest version of '%s'\n", info.Version, name)
+				return nil
+			}
+
+			content, _, err := secretVersionContent(ctx, secretService, *info)
+			if err != nil {
+				log.Error().Err(err).Msgf("Failed to retrieve version %d of secret '%s'", version, name)
+				return fmt.Errorf("failed to retrieve version %d of secret '%s'", version, name)
+			}
+
+			// The restored version replaces the latest one listed above
+			conflict.baseOn(cmd, latest.Version)
+
+			secret := domain.Secret{
+				Info: domain.SecretInfo{
+					Name:     name,
+					Metadata: info.Metadata,
+					Type:     info.Type,
+				},
+			}
+			stored, err := storeSecret(ctx, cmd, secretService, secret, content, conflict)
+			if err != nil {
+				log.Error().Err(err).Msgf("Failed to restore version %d of secret '%s'", version, name)
+				return storeSecretError(err, fmt.Sprintf("failed to restore version %d of secret '%s'", version, name))
+			}
+
+			if stored {
+				fmt.Fprintf(cmd.OutOrStdout(), "Successfully restored version %d of '%s' as the latest version\n", version, name)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&name, "name", "n", "", "Name of secret (required)")
+	cmd.Flags().Int32VarP(&version, "version", "v", 0, "Version to restore (required)")
+	addConflictFlags(cmd, &conflict)
+
+	_ = cmd.MarkFlagRequired("name")
+	_ = cmd.MarkFlagRequired("version")
+
+	return cmd
+}
+
+// secretVersionContent retrieves the content of a secret version, streamed for streamable types.
+// Returns the content and the info of the version as stored on the server.
+func secretVersionContent(ctx context.Context, secretService domain.SecretService,
+	info domain.SecretInfo) (io.Reader, *domain.SecretInfo, error) {
+	if info.Type.Streamable() {
+		return secretService.GetSecretStreamByVersion(ctx, info.Name, info.Version)
+	}
+
+	secret, err := secretService.GetSecretByVersion(ctx, info.Name, info.Version)
+	if err != nil {
+		return nil, nil, err
+	}
+	return strings.NewReader(secret.Data), &secret.Info, nil
+}
+
+// resolveVersions looks up the infos of two versions of a secret. Version 0 stands for the latest one.
+func resolveVersions(ctx context.Context, secretService domain.SecretService,
+	name string, from, to int32) (*domain.SecretInfo, *domain.SecretInfo, error) {
+	find := func(version int32) (*domain.SecretInfo, error) {
+		info, err := secretService.GetSecretInfo(ctx, name, version)
+		if errors.Is(err, domain.ErrSecretNotFound) {
+			if version == 0 {
+				return nil, fmt.Errorf("secret '%s' not found", name)
+			}
+			return nil, fmt.Errorf("version %d of secret '%s' not found", version, name)
+		}
+		if err != nil {
+			log.Error().Err(err).Msgf("Failed to retrieve version %d of secret '%s'", version, name)
+			return nil, fmt.Errorf("failed to retrieve secret '%s'", name)
+		}
+		return info, nil
+	}
+
+	// The later version is looked up first, so a missing secret is reported as such
+	toInfo, err := find(to)
+	if err != nil {
+		return nil, nil, err
+	}
+	if from == to {
+		return toInfo, toInfo, nil
+	}
+	fromInfo, err := find(from)
+	if err != nil {
+		return nil, nil, err
+	}
+	return fromInfo, toInfo, nil
+}
+
+// diffSecretVersions compares the infos and contents of two secret versions.
+func diffSecretVersions(ctx context.Context, secretService domain.SecretService,
+	from, to domain.SecretInfo, reveal bool) (secretDiffView, error) {
+	diff := secretDiffView{
+		Name:    from.Name,
+		Type:    string(to.Type),
+		From:    from.Version,
+		To:      to.Version,
+		Changes: []fieldChange{},
+	}
+
+	if c := newFieldChange("type", ptr(string(from.Type)), ptr(string(to.Type)), reveal); c != nil {
+		// Payloads of different types are not comparable
+		diff.Changes = append(diff.Changes, *c)
+		return diff, nil
+	}
+	if c := newFieldChange("metadata", optional(from.Metadata), optional(to.Metadata), reveal); c != nil {
+		diff.Changes = append(diff.Changes, *c)
+	}
+
+	switch to.Type {
+	case domain.CredentialsSecretType, domain.PaymentCardSecretType, domain.SSHKeySecretType:
+		fromSecret, err := secretService.GetSecretByVersion(ctx, from.Name, from.Version)
+		if err != nil {
+			return diff, err
+		}
+		toSecret, err := secretService.GetSecretByVersion(ctx, to.Name, to.Version)
+		if err != nil {
+			return diff, err
+		}
+
+		changes, err := diffJSONFields(fromSecret.Data, toSecret.Data, reveal)
+		if err != nil {
+			return diff, err
+		}
+		diff.Changes = append(diff.Changes, changes...)
+	case domain.TextSecretType:
+		fromText, err := readTextVersion(ctx, secretService, from)
+		if err != nil {
+			return diff, err
+		}
+		toText, err := readTextVersion(ctx, secretService, to)
+		if err != nil {
+			return diff, err
+		}
+
+		fromLines, toLines := splitLines(fromText.text), splitLines(toText.text)
+		if fromText.truncated || toText.truncated || !diffable(fromLines, toLines) {
+			if fromText.digest != toText.digest {
+				diff.Changes = append(diff.Changes, fieldChange{
+					Field:  "content",
+					Change: fieldChanged,
+					To:     ptr("contents differ, too large to compare lines"),
+				})
+			}
+			break
+		}
+
+		ops := diffLines(fromLines, toLines)
+		added, removed := countChangedLines(ops)
+		if added == 0 && removed == 0 {
+			break
+		}
+		diff.Changes = append(diff.Changes, fieldChange{
+			Field:  "content",
+			Change: fieldChanged,
+			To:     ptr(fmt.Sprintf("%d lines added, %d removed", added, removed)),
+		})
+		if reveal {
+			diff.Unified = unifiedDiff(versionLabel(from), versionLabel(to), ops)
+		}
+	case domain.FileSecretType:
+		fromDigest, err := digestSecretVersion(ctx, secretService, from)
+		if err != nil {
+			return diff, err
+		}
+		toDigest, err := digestSecretVersion(ctx, secretService, to)
+		if err != nil {
+			return diff, err
+		}
+
+		fromSize, toSize := strconv.FormatInt(fromDigest.Size, 10), strconv.FormatInt(toDigest.Size, 10)
+		if c := newFieldChange("size", &fromSize, &toSize, reveal); c != nil {
+			diff.Changes = append(diff.Changes, *c)
+		}
+		if c := newFieldChange("sha256", &fromDigest.SHA256, &toDigest.SHA256, reveal); c != nil {
+			diff.Changes = append(diff.Changes, *c)
+		}
+	}
+	return diff, nil
+}
+
+// readTextVersion reads the content of a text secret version for a line diff.
+func readTextVersion(ctx context.Context, secretService domain.SecretService, info domain.SecretInfo) (diffText, error) {
+	reader, _, err := secretService.GetSecretStreamByVersion(ctx, info.Name, info.Version)
+	if err != nil {
+		return diffText{}, err
+	}
+
+	text, err := readDiffText(reader)
+	if err != nil {
+		return diffText{}, fmt.Errorf("failed to read version %d: %w", info.Version, err)
+	}
+	return text, nil
+}
+
+// digestSecretVersion computes the size and checksum of a streamable secret version.
+func digestSecretVersion(ctx context.Context, secretService domain.SecretService, info domain.SecretInfo) (fileDigest, error) {
+	reader, _, err := secretService.GetSecretStreamByVersion(ctx, info.Name, info.Version)
+	if err != nil {
+		return fileDigest{}, err
+	}
+
+	digest, err := digestContent(reader)
+	if err != nil {
+		return fileDigest{}, fmt.Errorf("failed to read version %d: %w", info.Version, err)
+	}
+	return digest, nil
+}
+
+// printSecretDiff writes the changes between two versions in text output.
+func printSecretDiff(w io.Writer, diff secretDiffView, reveal bool) {
+	fmt.Fprintf(w, "--- %s version %d\n", diff.Name, diff.From)
+	fmt.Fprintf(w, "+++ %s version %d\n", diff.Name, diff.To)
+
+	if len(diff.Changes) == 0 {
+		fmt.Fprintln(w, "No differences")
+		return
+	}
+
+	for _, c := range diff.Changes {
+		switch c.Change {
+		case fieldAdded:
+			fmt.Fprintf(w, "+ %s: %s\n", c.Field, *c.To)
+		case fieldRemoved:
+			fmt.Fprintf(w, "- %s: %s\n", c.Field, *c.From)
+		case fieldChanged:
+			if c.From == nil {
+				fmt.Fprintf(w, "~ %s: %s\n", c.Field, *c.To)
+			} else {
+				fmt.Fprintf(w, "~ %s: %s -> %s\n", c.Field, *c.From, *c.To)
+			}
+		}
+	}
+
+	if diff.Unified != "" {
+		fmt.Fprintln(w)
+		fmt.Fprint(w, diff.Unified)
+	} else if diff.Type == string(domain.TextSecretType) && !reveal {
+		fmt.Fprintln(w, "Use --reveal to show the changed lines")
+	}
+}
+
+// versionLabel names a secret version in unified diff headers.
+func versionLabel(info domain.SecretInfo) string {
+	return fmt.Sprintf("%s@%d", info.Name, info.Version)
+}
+
+// optional returns nil for empty strings.
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
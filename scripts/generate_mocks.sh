@@ -1,3 +1,11 @@
 #!/bin/bash
 mockgen -source=internal/domain/secret.go -destination=internal/mocks/mock_secret_service.go -package=mocks
-mockgen -source=internal/domain/auth.go -destination=internal/mocks/mock_auth_service.go -package=mocks
\ No newline at end of file
+mockgen -source=internal/domain/auth.go -destination=internal/mocks/mock_auth_service.go -package=mocks
+mockgen -source=internal/domain/crypto.go -destination=internal/mocks/mock_crypto.go -package=mocks
+mockgen -source=internal/domain/vault.go -destination=internal/mocks/mock_vault.go -package=mocks
+mockgen -source=internal/domain/profile.go -destination=internal/mocks/mock_profile.go -package=mocks
+mockgen -source=internal/domain/config.go -destination=internal/mocks/mock_config.go -package=mocks
+mockgen -source=internal/domain/breach.go -destination=internal/mocks/mock_breach.go -package=mocks
+mockgen -source=internal/domain/clipboard.go -destination=internal/mocks/mock_clipboard.go -package=mocks
+mockgen -source=internal/domain/importer.go -destination=internal/mocks/mock_importer.go -package=mocks
+mockgen -source=internal/domain/backup.go -destination=internal/mocks/mock_backup.go -package=mocks